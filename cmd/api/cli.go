@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// command is what the command line asks for: a subcommand with its
+// arguments, and the settings given as flags, keyed by the environment
+// variable each one overrides.
+type command struct {
+	name string
+	args []string
+	env  map[string]string
+}
+
+// commandSpec describes a subcommand and how many arguments it takes.
+type commandSpec struct {
+	name, usage, summary string
+	minArgs, maxArgs     int
+}
+
+var commands = []commandSpec{
+	{"serve", "serve", "Run the HTTP and gRPC servers (the default)", 0, 0},
+	{"migrate", "migrate", "Apply database migrations and exit", 0, 0},
+	{"seed", "seed", "Apply migrations and load the starter genre list; existing genres are kept", 0, 0},
+	{"create-admin", "create-admin <email> <password> [username]",
+		"Create an admin user, or make an existing one admin and reset their password (username defaults to \"admin\")", 2, 3},
+}
+
+// settingFlags are the flags that override a setting otherwise read from the
+// environment or the config file.
+var settingFlags = []struct{ flag, env, usage string }{
+	{"port", "PORT", "HTTP port"},
+	{"config", "CONFIG_FILE", "YAML or JSON config file"},
+	{"migrations-path", "MIGRATIONS_PATH", "directory with the database migrations"},
+	{"migrations-source", "MIGRATIONS_SOURCE", "where to read migrations from: file or embed"},
+	{"log-level", "LOG_LEVEL", "log level: debug, info, warn or error"},
+}
+
+// parseArgs reads the flags and the subcommand from args, which exclude the
+// program name. Usage and errors are written to output; -help returns
+// flag.ErrHelp.
+func parseArgs(args []string, output io.Writer) (command, error) {
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	fs.SetOutput(output)
+	envByFlag := make(map[string]string, len(settingFlags))
+	for _, f := range settingFlags {
+		fs.String(f.flag, "", f.usage+" (overrides "+f.env+")")
+		envByFlag[f.flag] = f.env
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(output, "Usage: api [flags] [command]\n\nCommands:\n")
+		for _, c := range commands {
+			fmt.Fprintf(output, "  %s\n    \t%s\n", c.usage, c.summary)
+		}
+		fmt.Fprintf(output, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return command{}, err
+	}
+
+	cmd := command{name: "serve", env: make(map[string]string)}
+	fs.Visit(func(f *flag.Flag) { cmd.env[envByFlag[f.Name]] = f.Value.String() })
+	if fs.NArg() > 0 {
+		cmd.name, cmd.args = fs.Arg(0), fs.Args()[1:]
+	}
+
+	i := slices.IndexFunc(commands, func(c commandSpec) bool { return c.name == cmd.name })
+	if i < 0 {
+		err := fmt.Errorf("unknown command %q", cmd.name)
+		fmt.Fprintln(output, err)
+		fs.Usage()
+		return command{}, err
+	}
+	if spec := commands[i]; len(cmd.args) < spec.minArgs || len(cmd.args) > spec.maxArgs {
+		err := fmt.Errorf("usage: api [flags] %s", spec.usage)
+		fmt.Fprintln(output, err)
+		return command{}, err
+	}
+	return cmd, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+	"golang-project/pkg/jwt"
+)
+
+func TestParseArgs(t *testing.T) {
+	var out bytes.Buffer
+	cmd, err := parseArgs(nil, &out)
+	if err != nil || cmd.name != "serve" || len(cmd.env) != 0 {
+		t.Fatalf("expected serve with no overrides, got %+v, %v", cmd, err)
+	}
+
+	cmd, err = parseArgs([]string{"-port", "9000", "-log-level", "debug", "create-admin", "a@example.com", "secret"}, &out)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if cmd.name != "create-admin" || len(cmd.args) != 2 || cmd.env["PORT"] != "9000" || cmd.env["LOG_LEVEL"] != "debug" || len(cmd.env) != 2 {
+		t.Fatalf("unexpected command %+v", cmd)
+	}
+
+	for _, args := range [][]string{{"migrate", "extra"}, {"create-admin", "a@example.com"}, {"deploy"}} {
+		if _, err := parseArgs(args, &out); err == nil {
+			t.Fatalf("%v: expected an error", args)
+		}
+	}
+
+	out.Reset()
+	if _, err := parseArgs([]string{"-help"}, &out); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected flag.ErrHelp, got %v", err)
+	}
+	for _, want := range []string{"-port", "-config", "-migrations-path", "-log-level", "serve", "migrate", "seed", "create-admin <email>"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("help does not mention %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCreateAdmin(t *testing.T) {
+	ctx := context.Background()
+	users := testutil.NewMemUserRepo()
+
+	created, err := createAdmin(ctx, users, "root@example.com", "first-pass", "root")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Role != "admin" || created.Username != "root" || jwt.CheckPassword(created.PasswordHash, "first-pass") != nil {
+		t.Fatalf("unexpected admin %+v", created)
+	}
+
+	existing := &models.User{Email: "jane@example.com", Username: "jane", Role: "user"}
+	_ = users.Create(ctx, existing)
+	promoted, err := createAdmin(ctx, users, "jane@example.com", "new-pass", "admin")
+	if err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	stored, _ := users.GetByID(ctx, existing.ID)
+	if promoted.ID != existing.ID || stored.Role != "admin" || stored.Username != "jane" || jwt.CheckPassword(stored.PasswordHash, "new-pass") != nil {
+		t.Fatalf("expected jane to be promoted with the new password, got %+v", stored)
+	}
+
+	if _, err := createAdmin(ctx, users, "not-an-email", "pass", "admin"); err == nil {
+		t.Fatal("expected an invalid email to be rejected")
+	}
+}
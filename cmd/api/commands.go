@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/repository"
+	"golang-project/internal/service"
+	"golang-project/pkg/jwt"
+)
+
+// starterGenres is what the seed command loads into an empty catalogue.
+var starterGenres = []models.BulkGenreInput{
+	{Name: "Action"},
+	{Name: "Adventure"},
+	{Name: "Animation"},
+	{Name: "Comedy"},
+	{Name: "Crime"},
+	{Name: "Documentary"},
+	{Name: "Drama"},
+	{Name: "Fantasy"},
+	{Name: "Horror"},
+	{Name: "Romance"},
+	{Name: "Science Fiction"},
+	{Name: "Thriller"},
+}
+
+// seed imports starterGenres, skipping those that already exist.
+func seed(ctx context.Context, db *sql.DB, opts ...repository.Option) (*models.BulkGenreResult, error) {
+	genres := service.NewGenreService(repository.NewGenreRepository(db, opts...), validator.New())
+	return genres.BulkCreate(ctx, starterGenres)
+}
+
+// adminUsers is the part of the user repository create-admin needs.
+type adminUsers interface {
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	UpdatePassword(ctx context.Context, id int, passwordHash string) error
+	UpdateRole(ctx context.Context, id int, role string) error
+}
+
+// createAdmin creates an admin with the given credentials. When the email
+// is already registered, that user is made admin and gets the new password
+// instead; their username is left alone.
+func createAdmin(ctx context.Context, users adminUsers, email, password, username string) (*models.User, error) {
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("invalid email: %s", email)
+	}
+	hash, err := jwt.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+
+	user, err := users.GetByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		user = &models.User{Email: email, Username: username, PasswordHash: hash, Role: "admin"}
+		if err := users.Create(ctx, user); err != nil {
+			return nil, fmt.Errorf("create user: %w", err)
+		}
+		return user, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	if err := users.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return nil, fmt.Errorf("update password: %w", err)
+	}
+	if err := users.UpdateRole(ctx, user.ID, "admin"); err != nil {
+		return nil, fmt.Errorf("update role: %w", err)
+	}
+	user.Role = "admin"
+	return user, nil
+}
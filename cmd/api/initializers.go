@@ -3,150 +3,385 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"slices"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/config"
 	"golang-project/internal/database"
+	"golang-project/internal/grpcserver"
 	"golang-project/internal/handler"
+	"golang-project/internal/mail"
 	"golang-project/internal/repository"
 	"golang-project/internal/service"
 )
 
 // AppInitializer initializes application components
 type AppInitializer struct {
-	config *Config
+	config *config.Config
 	db     *sql.DB
 	router http.Handler
 	server *http.Server
+	grpc   *grpc.Server
 	events chan service.ReviewEvent
+	logger *slog.Logger
+
+	// reviewWorkerDone is closed once the review worker has stopped;
+	// stopReviewWorker cuts it off when shutdown cannot wait any longer.
+	reviewWorkerDone <-chan struct{}
+	stopReviewWorker context.CancelFunc
+	reviewWorker     *service.ReviewWorker
+
+	// listening is set once the HTTP server has bound its port and cleared
+	// when shutdown begins; the readiness probe fails while it is false.
+	listening atomic.Bool
+	// draining is set when shutdown begins: new requests other than the
+	// probes get 503 while the inFlight ones finish.
+	draining atomic.Bool
+	inFlight atomic.Int64
 }
 
-func NewAppInitializer() *AppInitializer {
-	return &AppInitializer{}
+func NewAppInitializer(logger *slog.Logger) *AppInitializer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AppInitializer{logger: logger}
 }
 
-// InitializeConfig loads application configuration
-func (ai *AppInitializer) InitializeConfig() error {
-	log.Println("loading configuration")
+// runPhase runs one lifecycle step and emits a structured record with its
+// duration, so startup and shutdown timing can be read from the logs.
+func (ai *AppInitializer) runPhase(component string, fn func() error) error {
+	start := time.Now()
+	ai.logger.Debug("lifecycle phase started", "component", component)
 
-	cfg, err := loadConfig()
+	err := fn()
+	attrs := []any{"component", component, "duration", time.Since(start)}
 	if err != nil {
-		return fmt.Errorf("load config: %w", err)
+		ai.logger.Error("lifecycle phase failed", append(attrs, "error", err)...)
+		return err
 	}
-
-	ai.config = cfg
-	log.Printf("configuration loaded (port=%s)", cfg.Port)
+	ai.logger.Info("lifecycle phase completed", attrs...)
 	return nil
 }
 
-// InitializeDatabase initializes database connection and runs migrations
-func (ai *AppInitializer) InitializeDatabase(ctx context.Context) error {
-	log.Println("initializing database")
+// InitializeConfig loads application configuration
+func (ai *AppInitializer) InitializeConfig() error {
+	return ai.runPhase("config", func() error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
 
-	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+		ai.config = cfg
+		ai.logger.Info("configuration loaded", "port", cfg.Port)
+		return nil
+	})
+}
 
-	if err := database.InitDB(dbCtx, ai.config.DBDsn); err != nil {
-		return fmt.Errorf("init database: %w", err)
-	}
+// Reload re-reads the configuration and applies the settings that can
+// change at runtime. An invalid configuration is reported and the running
+// one is kept.
+func (ai *AppInitializer) Reload() error {
+	return ai.runPhase("reload", func() error {
+		next, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
 
-	ai.db = database.DB
-	log.Println("database connected")
+		applied, restart := ai.config.Reload(next)
+		ai.logger.Info("configuration reloaded", "changed", applied)
+		if len(restart) > 0 {
+			ai.logger.Warn("changed settings need a restart to take effect", "settings", restart)
+		}
+		return nil
+	})
+}
 
-	if err := database.RunMigrations(ai.config.MigrationsPath); err != nil {
-		return fmt.Errorf("run migrations: %w", err)
-	}
+// InitializeDatabase initializes database connection and runs migrations
+func (ai *AppInitializer) InitializeDatabase(ctx context.Context) error {
+	return ai.runPhase("database", func() error {
+		dbCtx, cancel := context.WithTimeout(ctx, ai.config.DBConnectTimeout)
+		defer cancel()
 
-	log.Println("migrations completed")
-	return nil
+		db, err := database.InitDB(dbCtx, ai.config.DBDsn, ai.config.DBPool)
+		if err != nil {
+			return fmt.Errorf("init database: %w", err)
+		}
+
+		ai.db = db
+		ai.logger.Info("database connected")
+
+		return ai.runPhase("migrations", func() error {
+			if err := database.RunMigrationsFrom(ai.db, ai.config.MigrationsSource, ai.config.MigrationsPath); err != nil {
+				return fmt.Errorf("run migrations: %w", err)
+			}
+			return nil
+		})
+	})
 }
 
 // InitializeWorkers starts background workers
 func (ai *AppInitializer) InitializeWorkers(ctx context.Context) error {
-	if ai.db == nil {
-		return fmt.Errorf("database not initialized")
-	}
-
-	log.Println("initializing workers")
-	ai.events = make(chan service.ReviewEvent, 100)
-
-	auditRepo := repository.NewAuditRepository(ai.db)
-	movieRepo := repository.NewMovieRepository(ai.db)
-	service.StartReviewWorker(ctx, ai.events, movieRepo, auditRepo)
+	return ai.runPhase("workers", func() error {
+		if ai.db == nil {
+			return fmt.Errorf("database not initialized")
+		}
 
-	log.Println("review worker started")
-	return nil
+		ai.events = make(chan service.ReviewEvent, ai.config.ReviewEventBuffer)
+
+		queryTimeout := repository.WithQueryTimeout(ai.config.DBQueryTimeout)
+		auditRepo := repository.NewAuditRepository(ai.db, queryTimeout)
+		movieRepo := repository.NewMovieRepository(ai.db, queryTimeout)
+		reviewRepo := repository.NewReviewRepository(ai.db, queryTimeout)
+		// The worker outlives ctx: Shutdown closes the channel once the
+		// server has stopped so that queued events are still handled.
+		workerCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+		ai.stopReviewWorker = stop
+		ai.reviewWorker = service.StartReviewWorker(workerCtx, ai.events, movieRepo, auditRepo, reviewRepo, service.BuiltinSentimentAnalyzer{})
+		ai.reviewWorkerDone = ai.reviewWorker.Done()
+		ai.logger.Info("review worker started")
+
+		movies := service.NewMovieService(movieRepo, repository.NewGenreRepository(ai.db, queryTimeout), validator.New(), service.WithAuditWriter(auditRepo))
+		service.NewMoviePublisherScheduler(movieRepo, movies).Start(ctx)
+		ai.logger.Info("movie publisher started", "interval", service.MoviePublishInterval)
+
+		if ai.config.Mail.Addr != "" {
+			service.NewReminderScheduler(repository.NewUserRepository(ai.db, queryTimeout), movieRepo, mail.NewSMTPSender(ai.config.Mail)).Start(ctx)
+			ai.logger.Info("review reminders started", "interval", service.ReviewReminderInterval)
+		}
+		return nil
+	})
 }
 
 // InitializeRouter sets up HTTP routes
 func (ai *AppInitializer) InitializeRouter() error {
-	if ai.db == nil {
-		return fmt.Errorf("database not initialized")
-	}
+	return ai.runPhase("router", func() error {
+		if ai.db == nil {
+			return fmt.Errorf("database not initialized")
+		}
 
-	log.Println("initializing router")
-	ai.router = handler.SetupRoutes(ai.db, ai.config.JWTSecret, ai.events)
-	return nil
+		router := handler.SetupRoutes(ai.db, ai.config, ai.events, ai.reviewWorker, ai.Ready)
+		if ai.config.EnablePprof {
+			ai.logger.Info("debug endpoints enabled", "path", "/debug")
+		}
+		ai.router = router
+		return nil
+	})
 }
 
 // Initialize HTTP server
 func (ai *AppInitializer) InitializeServer() error {
-	if ai.router == nil {
-		return fmt.Errorf("router not initialized")
-	}
+	return ai.runPhase("server", func() error {
+		if ai.router == nil {
+			return fmt.Errorf("router not initialized")
+		}
 
-	ai.server = &http.Server{
-		Addr:         ":" + ai.config.Port,
-		Handler:      ai.router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
+		ai.server = &http.Server{
+			Addr:         ":" + ai.config.Port,
+			Handler:      ai.drainHandler(ai.router),
+			ReadTimeout:  ai.config.ReadTimeout,
+			WriteTimeout: ai.config.WriteTimeout,
+			IdleTimeout:  ai.config.IdleTimeout,
+		}
+		return nil
+	})
+}
 
-	log.Printf("server configured (port=%s)", ai.config.Port)
-	return nil
+// InitializeGRPCServer sets up the catalogue gRPC server
+func (ai *AppInitializer) InitializeGRPCServer() error {
+	return ai.runPhase("grpc", func() error {
+		if ai.db == nil {
+			return fmt.Errorf("database not initialized")
+		}
+
+		v := validator.New()
+		queryTimeout := repository.WithQueryTimeout(ai.config.DBQueryTimeout)
+		genreRepo := repository.NewGenreRepository(ai.db, queryTimeout)
+		movies := service.NewMovieService(repository.NewMovieRepository(ai.db, queryTimeout), genreRepo, v)
+		genres := service.NewGenreService(genreRepo, v)
+		ai.grpc = grpcserver.New(movies, genres, ai.config.JWTKeys)
+		return nil
+	})
 }
 
-// Starts HTTP server
+// Starts HTTP server, and the gRPC server when one is configured
 func (ai *AppInitializer) StartServer() error {
-	if ai.server == nil {
-		return fmt.Errorf("server not initialized")
-	}
-
-	log.Printf("starting server on :%s", ai.config.Port)
+	return ai.runPhase("listener", func() error {
+		if ai.server == nil {
+			return fmt.Errorf("server not initialized")
+		}
 
-	go func() {
-		if err := ai.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+		if ai.grpc != nil {
+			addr := ":" + ai.config.GRPCPort
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("listen grpc: %w", err)
+			}
+			ai.logger.Info("starting grpc server", "addr", lis.Addr().String())
+			go func() {
+				if err := ai.grpc.Serve(lis); err != nil {
+					ai.logger.Error("grpc server error", "error", err)
+					os.Exit(1)
+				}
+			}()
 		}
-	}()
 
-	return nil
+		lis, err := net.Listen("tcp", ai.server.Addr)
+		if err != nil {
+			return fmt.Errorf("listen http: %w", err)
+		}
+		ai.logger.Info("starting server", "addr", lis.Addr().String())
+		ai.listening.Store(true)
+
+		go func() {
+			if err := ai.server.Serve(lis); err != nil && err != http.ErrServerClosed {
+				ai.logger.Error("server error", "error", err)
+				os.Exit(1)
+			}
+		}()
+
+		return nil
+	})
 }
 
 // Shutdown server and database
 func (ai *AppInitializer) Shutdown(ctx context.Context) error {
-	if ai.server != nil {
-		if err := ai.server.Shutdown(ctx); err != nil {
-			log.Printf("server shutdown error: %v", err)
+	return ai.runPhase("shutdown", func() error {
+		ai.listening.Store(false)
+		var serverErr, grpcErr error
+		if ai.server != nil {
+			_ = ai.runPhase("shutdown.drain", func() error {
+				return ai.drain(ctx)
+			})
+			serverErr = ai.runPhase("shutdown.server", func() error {
+				return ai.server.Shutdown(ctx)
+			})
+		}
+
+		if ai.grpc != nil {
+			grpcErr = ai.runPhase("shutdown.grpc", func() error {
+				return stopGRPC(ctx, ai.grpc)
+			})
+		}
+
+		if ai.events != nil {
+			_ = ai.runPhase("shutdown.workers", func() error {
+				// A server that did not stop in time may still have
+				// handlers about to send events, and a send on a closed
+				// channel panics. Leave it open and stop the worker instead;
+				// those events are lost either way.
+				if serverErr != nil || grpcErr != nil {
+					if ai.stopReviewWorker != nil {
+						ai.stopReviewWorker()
+					}
+					return errors.New("servers did not stop in time, queued review events dropped")
+				}
+				close(ai.events)
+				return ai.waitReviewWorker(ctx)
+			})
+		}
+
+		if ai.db != nil {
+			_ = ai.runPhase("shutdown.database", ai.db.Close)
+		}
+		return nil
+	})
+}
+
+// probePaths are answered while draining, so the readiness probe can report
+// that the server is going away.
+var probePaths = []string{"/api/v1/health", "/api/v1/ready"}
+
+var errShuttingDown = apierror.Response{Error: apierror.Body{Code: "service_unavailable", Message: "server is shutting down"}}
+
+// drainHandler counts the requests next is serving and, once shutdown has
+// begun, answers new ones with 503 and Connection: close instead, so load
+// balancers see a clean refusal rather than a reset connection.
+func (ai *AppInitializer) drainHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ai.draining.Load() && !slices.Contains(probePaths, r.URL.Path) {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(errShuttingDown)
+			return
+		}
+		ai.inFlight.Add(1)
+		defer ai.inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// drain turns new requests away and waits up to the drain period, or until
+// ctx expires, for the ones in flight to finish.
+func (ai *AppInitializer) drain(ctx context.Context) error {
+	ai.draining.Store(true)
+	ctx, cancel := context.WithTimeout(ctx, ai.config.DrainPeriod)
+	defer cancel()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for ai.inFlight.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
 		}
 	}
+	return nil
+}
 
-	if ai.events != nil {
-		close(ai.events)
+// waitReviewWorker waits for the review worker to handle the events still
+// queued, stopping it when ctx expires first.
+func (ai *AppInitializer) waitReviewWorker(ctx context.Context) error {
+	if ai.reviewWorkerDone == nil {
+		return nil
 	}
+	select {
+	case <-ai.reviewWorkerDone:
+		return nil
+	case <-ctx.Done():
+		ai.stopReviewWorker()
+		return ctx.Err()
+	}
+}
 
-	if err := database.CloseDB(); err != nil {
-		log.Printf("database close error: %v", err)
+// stopGRPC waits for in-flight RPCs to finish, cutting them off when ctx
+// expires first.
+func stopGRPC(ctx context.Context, srv *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		srv.Stop()
+		return ctx.Err()
 	}
+}
 
-	return nil
+// Ready reports whether StartServer has begun listening and shutdown has not
+// started yet.
+func (ai *AppInitializer) Ready() bool {
+	return ai.listening.Load()
 }
 
 // Getters
-func (ai *AppInitializer) GetConfig() *Config {
+func (ai *AppInitializer) GetConfig() *config.Config {
 	return ai.config
 }
 
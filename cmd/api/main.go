@@ -2,56 +2,140 @@ package main
 
 import (
 	"context"
-	"log"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"golang-project/internal/repository"
+	"golang-project/internal/version"
 )
 
 func main() {
+	cmd, err := parseArgs(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+	// Flags take precedence over the environment, so they are applied
+	// before the config is loaded.
+	for key, value := range cmd.env {
+		os.Setenv(key, value)
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	initializer := NewAppInitializer()
+	// The level starts at info and switches to LOG_LEVEL once the config is
+	// loaded.
+	logLevel := new(slog.LevelVar)
+	build := version.BuildInfo()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})).
+		With("version", build.Version, "commit", build.Commit)
+	slog.SetDefault(logger)
+
+	initializer := NewAppInitializer(logger)
 
-	log.Println("application starting")
+	start := time.Now()
+	logger.Info("application starting", "build_date", build.BuildDate, "go_version", build.GoVersion)
 	//init each of the component
 	if err := initializer.InitializeConfig(); err != nil {
-		log.Fatalf("init config: %v", err)
+		fatal(logger, "init config", err)
 	}
+	logLevel.Set(initializer.GetConfig().LogLevel)
 
 	if err := initializer.InitializeDatabase(ctx); err != nil {
-		log.Fatalf("init database: %v", err)
+		fatal(logger, "init database", err)
+	}
+	if cmd.name != "serve" {
+		queryTimeout := repository.WithQueryTimeout(initializer.GetConfig().DBQueryTimeout)
+		err := runCommand(ctx, initializer.GetDB(), cmd, queryTimeout)
+		initializer.GetDB().Close()
+		if err != nil {
+			fatal(logger, cmd.name, err)
+		}
+		return
 	}
 
 	if err := initializer.InitializeWorkers(ctx); err != nil {
-		log.Fatalf("init workers: %v", err)
+		fatal(logger, "init workers", err)
 	}
 
 	if err := initializer.InitializeRouter(); err != nil {
-		log.Fatalf("init router: %v", err)
+		fatal(logger, "init router", err)
 	}
 
 	if err := initializer.InitializeServer(); err != nil {
-		log.Fatalf("init server: %v", err)
+		fatal(logger, "init server", err)
+	}
+
+	if err := initializer.InitializeGRPCServer(); err != nil {
+		fatal(logger, "init grpc server", err)
 	}
 
 	if err := initializer.StartServer(); err != nil {
-		log.Fatalf("start server: %v", err)
+		fatal(logger, "start server", err)
 	}
 
-	log.Println("application started")
+	logger.Info("application started", "duration", time.Since(start))
 
-	<-ctx.Done()
-	log.Println("shutdown signal received")
+	// SIGHUP reloads the configuration instead of stopping the server.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for ctx.Err() == nil {
+		select {
+		case <-hup:
+			if err := initializer.Reload(); err == nil {
+				logLevel.Set(initializer.GetConfig().Live().LogLevel())
+			}
+		case <-ctx.Done():
+		}
+	}
+	logger.Info("shutdown signal received")
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), initializer.GetConfig().ShutdownTimeout)
 	defer cancel()
 
 	if err := initializer.Shutdown(shutdownCtx); err != nil {
-		log.Printf("shutdown error: %v", err)
+		logger.Error("shutdown error", "error", err)
 	}
 
-	log.Println("application stopped")
+	logger.Info("application stopped")
+}
+
+// runCommand runs one of the one-off commands once the database is connected
+// and migrated, which is all migrate needs.
+func runCommand(ctx context.Context, db *sql.DB, cmd command, opts ...repository.Option) error {
+	switch cmd.name {
+	case "seed":
+		result, err := seed(ctx, db, opts...)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Genres created: %d, already present: %d\n", result.Created, result.Skipped)
+	case "create-admin":
+		username := "admin"
+		if len(cmd.args) == 3 {
+			username = cmd.args[2]
+		}
+		user, err := createAdmin(ctx, repository.NewUserRepository(db, opts...), cmd.args[0], cmd.args[1], username)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Admin user ready: id %d, email %s, username %s\n", user.ID, user.Email, user.Username)
+	}
+	return nil
+}
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
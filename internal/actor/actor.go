@@ -0,0 +1,16 @@
+// Package actor carries the authenticated caller through request contexts so
+// lower layers can attribute changes without depending on gin.
+package actor
+
+import "context"
+
+type contextKey struct{}
+
+func WithID(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+func ID(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(contextKey{}).(int)
+	return id, ok
+}
@@ -0,0 +1,115 @@
+// Package apierror defines the JSON error envelope returned by every handler
+// and middleware:
+//
+//	{"error": {"code": "movie_not_found", "message": "movie not found", "request_id": "42"}}
+//
+// Clients that send Accept: application/problem+json get the same error as an
+// RFC 7807 problem document instead (see Problem).
+//
+// Code is stable and meant for programs; Message is for humans and may change.
+// Messages are written in English and translated on the way out according to
+// the locale on the request context (see package i18n).
+package apierror
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/i18n"
+)
+
+// RequestIDHeader is the response header set by middleware.RequestID.
+const RequestIDHeader = "X-Request-ID"
+
+// ProblemContentType is the RFC 7807 media type for problem documents.
+const ProblemContentType = "application/problem+json"
+
+type Body struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	RequestID string         `json:"request_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+type Response struct {
+	Error Body `json:"error"`
+}
+
+// Problem is an RFC 7807 problem document. Code, RequestID and Details are
+// extension members carrying the same values as the default envelope.
+type Problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail"`
+	Instance  string         `json:"instance,omitempty"`
+	Code      string         `json:"code"`
+	RequestID string         `json:"request_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// Error is an error with a fixed HTTP status and machine-readable code.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]any
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of e carrying extra machine-readable fields.
+func (e *Error) WithDetails(details map[string]any) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// Abort writes e as the response, localized for the request, and stops the
+// handler chain. The body is a Problem when the client asks for one.
+func Abort(c *gin.Context, e *Error) {
+	locale := i18n.FromContext(c.Request.Context())
+	message := i18n.Message(locale, e.Code, e.Message, e.Details)
+	requestID := c.Writer.Header().Get(RequestIDHeader)
+	if wantsProblem(c.GetHeader("Accept")) {
+		c.Header("Content-Type", ProblemContentType)
+		c.AbortWithStatusJSON(e.Status, Problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(e.Status),
+			Status:    e.Status,
+			Detail:    message,
+			Instance:  c.Request.URL.Path,
+			Code:      e.Code,
+			RequestID: requestID,
+			Details:   e.Details,
+		})
+		return
+	}
+	c.AbortWithStatusJSON(e.Status, Response{Error: Body{
+		Code:      e.Code,
+		Message:   message,
+		RequestID: requestID,
+		Details:   e.Details,
+	}})
+}
+
+// wantsProblem reports whether the Accept header lists the problem media
+// type. Quality values are ignored: listing it at all opts in.
+func wantsProblem(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mt == ProblemContentType {
+			return true
+		}
+	}
+	return false
+}
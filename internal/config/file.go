@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ErrUnknownConfigKey is returned for a CONFIG_FILE key that does not name
+// a setting.
+type ErrUnknownConfigKey string
+
+func (e ErrUnknownConfigKey) Error() string {
+	return "unknown key in CONFIG_FILE: " + string(e)
+}
+
+// readConfigFile loads a YAML or JSON mapping whose keys are the
+// environment variable names in lower case. Sections may group settings
+// that share a prefix; their keys are joined with an underscore, so both
+// of these set DB_DSN and HTTP_READ_TIMEOUT:
+//
+//	db_dsn: postgres://app@db/movies
+//	http_read_timeout: 30s
+//
+//	db:
+//	  dsn: postgres://app@db/movies
+//	http:
+//	  read_timeout: 30s
+//
+// Files ending in .json are parsed as JSON, anything else as YAML. Values
+// are turned back into the strings the environment would hold; lists are
+// joined with commas.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CONFIG_FILE: %w", err)
+	}
+	var raw map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse CONFIG_FILE %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	if err := flattenConfig(values, "", raw); err != nil {
+		return nil, fmt.Errorf("parse CONFIG_FILE %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flattenConfig adds the settings in raw to values, prefixing each key with
+// the names of the sections it is nested in.
+func flattenConfig(values map[string]string, prefix string, raw map[string]any) error {
+	for key, value := range raw {
+		key = prefix + strings.ToLower(key)
+		if section, ok := value.(map[string]any); ok {
+			if err := flattenConfig(values, key+"_", section); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, dup := values[key]; dup {
+			return fmt.Errorf("%s is set twice", key)
+		}
+		switch v := value.(type) {
+		case nil:
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
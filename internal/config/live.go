@@ -0,0 +1,77 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Live holds the settings that can change while the server runs. Code that
+// reads them per request goes through Live rather than the Config fields,
+// which keep the values loaded at startup.
+type Live struct {
+	rateLimit          atomic.Int64
+	searchRateLimit    atomic.Int64
+	corsAllowedOrigins atomic.Pointer[[]string]
+	logLevel           slog.LevelVar
+}
+
+func newLive(c *Config) *Live {
+	l := &Live{}
+	l.store(c)
+	return l
+}
+
+func (l *Live) store(c *Config) {
+	l.rateLimit.Store(int64(c.RateLimit))
+	l.searchRateLimit.Store(int64(c.SearchRateLimit))
+	origins := slices.Clone(c.CORSAllowedOrigins)
+	l.corsAllowedOrigins.Store(&origins)
+	l.logLevel.Set(c.LogLevel)
+}
+
+func (l *Live) RateLimit() int               { return int(l.rateLimit.Load()) }
+func (l *Live) SearchRateLimit() int         { return int(l.searchRateLimit.Load()) }
+func (l *Live) CORSAllowedOrigins() []string { return *l.corsAllowedOrigins.Load() }
+func (l *Live) LogLevel() slog.Level         { return l.logLevel.Level() }
+
+// Live returns the holder of c's reloadable settings. Load creates it; for
+// a Config built by hand it is created from c's fields on first use, which
+// must happen before c is shared between goroutines.
+func (c *Config) Live() *Live {
+	if c.live == nil {
+		c.live = newLive(c)
+	}
+	return c.live
+}
+
+// reloadable are the Redacted keys that Reload applies in place. The JWT
+// keys are compared separately, since Redacted leaves out the secrets.
+var reloadable = []string{"rate_limit", "search_rate_limit", "cors_allowed_origins", "log_level", "jwt_current_kid"}
+
+// Reload applies the reloadable settings of next, a freshly loaded Config,
+// to c: the JWT keys, log level, rate limits and CORS origins. It returns
+// the settings that changed, split into those now in effect and those that
+// only take effect after a restart, named as in Redacted.
+func (c *Config) Reload(next *Config) (applied, restart []string) {
+	before, after := reflect.ValueOf(c.Redacted()), reflect.ValueOf(next.Redacted())
+	for i := range before.NumField() {
+		name, _, _ := strings.Cut(before.Type().Field(i).Tag.Get("json"), ",")
+		if name == "jwt_current_kid" || reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()) {
+			continue
+		}
+		if slices.Contains(reloadable, name) {
+			applied = append(applied, name)
+		} else {
+			restart = append(restart, name)
+		}
+	}
+	if c.JWTKeys != nil && next.JWTKeys != nil && !c.JWTKeys.Equal(next.JWTKeys) {
+		c.JWTKeys.Replace(next.JWTKeys)
+		applied = append(applied, "jwt_keys")
+	}
+	c.Live().store(next)
+	return applied, restart
+}
@@ -3,46 +3,75 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	_ "github.com/lib/pq"
+
+	"golang-project/internal/config"
+	"golang-project/internal/migrations"
 )
 
-var DB *sql.DB
+// Where migrations are read from, as selected by MIGRATIONS_SOURCE.
+const (
+	// MigrationsFromFile reads the migrations from a directory on disk.
+	MigrationsFromFile = "file"
+	// MigrationsFromEmbed uses the migrations compiled into the binary.
+	MigrationsFromEmbed = "embed"
+)
 
-func InitDB(ctx context.Context, dsn string) error {
-	db, err := sql.Open("postgres", dsn)
+// InitDB opens a connection pool to dsn and checks it can reach the
+// database before ctx expires.
+func InitDB(ctx context.Context, dsn string, pool config.PoolConfig) (*sql.DB, error) {
+	db, err := Open(dsn, pool)
 	if err != nil {
-		return fmt.Errorf("open db: %w", err)
+		return nil, err
 	}
 
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(time.Hour)
-
 	if err := db.PingContext(ctx); err != nil {
 		_ = db.Close()
-		return fmt.Errorf("ping db: %w", err)
+		return nil, fmt.Errorf("ping db: %w", err)
 	}
+	return db, nil
+}
 
-	DB = db
-	return nil
+// Open opens a connection pool to dsn sized by pool, without connecting yet.
+func Open(dsn string, pool config.PoolConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	return db, nil
 }
 
-func CloseDB() error {
-	if DB != nil {
-		return DB.Close()
+// RunMigrationsFrom applies the migrations db is missing, taking them from
+// the binary when source is MigrationsFromEmbed and from path otherwise.
+func RunMigrationsFrom(db *sql.DB, source, path string) error {
+	if source == MigrationsFromEmbed {
+		return RunEmbeddedMigrations(db)
 	}
-	return nil
+	return RunMigrations(db, path)
 }
 
-func RunMigrations(path string) error {
-	if DB == nil {
+// RunMigrations applies the up migrations in path that db is missing.
+func RunMigrations(db *sql.DB, path string) error {
+	if db == nil {
 		return fmt.Errorf("database is not initialized")
 	}
 
@@ -50,23 +79,131 @@ func RunMigrations(path string) error {
 	if err != nil {
 		return fmt.Errorf("resolve migrations path: %w", err)
 	}
+	if err := checkMigrationsDir(absPath); err != nil {
+		return err
+	}
 
-	driver, err := postgres.WithInstance(DB, &postgres.Config{})
+	src, err := (&file.File{}).Open("file://" + filepath.ToSlash(absPath))
 	if err != nil {
+		return fmt.Errorf("open migrations: %w", err)
+	}
+	return migrateUp(db, "file", src)
+}
+
+// RunEmbeddedMigrations applies the up migrations compiled into the binary
+// that db is missing.
+func RunEmbeddedMigrations(db *sql.DB) error {
+	if db == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return fmt.Errorf("open embedded migrations: %w", err)
+	}
+	return migrateUp(db, "iofs", src)
+}
+
+func migrateUp(db *sql.DB, sourceName string, src source.Driver) error {
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		_ = src.Close()
 		return fmt.Errorf("create migrate driver: %w", err)
 	}
+	return applyMigrations(sourceName, src, "postgres", driver)
+}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://"+filepath.ToSlash(absPath),
-		"postgres",
-		driver,
-	)
+// applyMigrations runs every up migration in src that driver is missing.
+func applyMigrations(sourceName string, src source.Driver, driverName string, driver migratedb.Driver) error {
+	m, err := migrate.NewWithInstance(sourceName, src, driverName, driver)
 	if err != nil {
 		return fmt.Errorf("new migrate instance: %w", err)
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		return err
 	}
 	return nil
 }
+
+// ErrMigrationsNotFound means the migrations path is missing, is not a
+// directory or holds no up migrations.
+var ErrMigrationsNotFound = errors.New("migrations not found")
+
+// checkMigrationsDir makes sure absPath is a directory with up migrations in
+// it, which golang-migrate would otherwise report only as an opaque
+// "file does not exist" or "no migration" error.
+func checkMigrationsDir(absPath string) error {
+	const hint = "set MIGRATIONS_PATH or -migrations-path to the directory with the *.up.sql files"
+	info, err := os.Stat(absPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%w: %s does not exist; %s", ErrMigrationsNotFound, absPath, hint)
+	case err != nil:
+		return fmt.Errorf("migrations path %s: %w", absPath, err)
+	case !info.IsDir():
+		return fmt.Errorf("%w: %s is not a directory; %s", ErrMigrationsNotFound, absPath, hint)
+	}
+	if _, err := LatestMigration(absPath); err != nil {
+		return fmt.Errorf("%w: no *.up.sql files in %s; %s", ErrMigrationsNotFound, absPath, hint)
+	}
+	return nil
+}
+
+// LatestMigrationFrom is LatestMigration for the migrations source selects,
+// as RunMigrationsFrom would apply them.
+func LatestMigrationFrom(source, path string) (uint, error) {
+	if source == MigrationsFromEmbed {
+		return LatestEmbeddedMigration()
+	}
+	return LatestMigration(path)
+}
+
+// LatestMigration returns the highest version among the up migrations in
+// path, i.e. the version the database should be at after RunMigrations.
+func LatestMigration(path string) (uint, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, fmt.Errorf("read migrations: %w", err)
+	}
+	latest := latestUp(entries)
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations found in %s", path)
+	}
+	return latest, nil
+}
+
+// LatestEmbeddedMigration is LatestMigration for the migrations compiled
+// into the binary.
+func LatestEmbeddedMigration() (uint, error) {
+	entries, err := fs.ReadDir(migrations.FS, ".")
+	if err != nil {
+		return 0, fmt.Errorf("read embedded migrations: %w", err)
+	}
+	latest := latestUp(entries)
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations embedded in the binary")
+	}
+	return latest, nil
+}
+
+func latestUp(entries []fs.DirEntry) uint {
+	var latest uint
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok || !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+		if v, err := strconv.ParseUint(prefix, 10, 64); err == nil && uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	return latest
+}
+
+// MigrationVersion reads the version golang-migrate recorded in db, and
+// whether the last migration failed halfway.
+func MigrationVersion(ctx context.Context, db *sql.DB) (version uint, dirty bool, err error) {
+	err = db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	return version, dirty, err
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"golang-project/internal/config"
+	"golang-project/internal/migrations"
+)
+
+func TestLatestMigration(t *testing.T) {
+	v, err := LatestMigration("../migrations")
+	if err != nil || v < 10 {
+		t.Fatalf("expected the latest migration to be at least 10, got %d (err %v)", v, err)
+	}
+	if _, err := LatestMigration(t.TempDir()); err == nil {
+		t.Fatalf("expected an error for a directory without migrations")
+	}
+}
+
+func TestOpen_PoolConfig(t *testing.T) {
+	db, err := Open("postgres://localhost/movies", config.PoolConfig{MaxOpenConns: 25, MaxIdleConns: 5, ConnMaxLifetime: time.Minute})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	if got := db.Stats().MaxOpenConnections; got != 25 {
+		t.Fatalf("expected 25 max open connections, got %d", got)
+	}
+}
+
+func TestRunMigrations_MissingPath(t *testing.T) {
+	db, err := Open("postgres://localhost/movies", config.PoolConfig{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	missing := filepath.Join(t.TempDir(), "migrations")
+	err = RunMigrations(db, missing)
+	if !errors.Is(err, ErrMigrationsNotFound) || !strings.Contains(err.Error(), missing+" does not exist") {
+		t.Fatalf("expected a missing-path error naming %s, got %v", missing, err)
+	}
+
+	empty := t.TempDir()
+	if err := RunMigrations(db, empty); !errors.Is(err, ErrMigrationsNotFound) || !strings.Contains(err.Error(), empty) {
+		t.Fatalf("expected an empty-directory error naming %s, got %v", empty, err)
+	}
+}
+
+// TestEmbeddedMigrations runs every embedded up migration through
+// golang-migrate against its stub driver, which records the scripts instead
+// of executing them. It checks that the embedded set is complete and in
+// order, not that the SQL is valid; TestEmbeddedMigrations_Postgres does
+// that when a database is available.
+func TestEmbeddedMigrations(t *testing.T) {
+	latest, err := LatestEmbeddedMigration()
+	if err != nil {
+		t.Fatalf("latest embedded migration: %v", err)
+	}
+	onDisk, err := LatestMigration("../migrations")
+	if err != nil || latest != onDisk {
+		t.Fatalf("embedded migrations end at %d, the directory at %d (err %v)", latest, onDisk, err)
+	}
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		t.Fatalf("open embedded migrations: %v", err)
+	}
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	if err != nil {
+		t.Fatalf("stub driver: %v", err)
+	}
+	if err := applyMigrations("iofs", src, "stub", driver); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	db := driver.(*stub.Stub)
+	if db.CurrentVersion != int(latest) || db.IsDirty {
+		t.Fatalf("expected clean version %d, got %d (dirty %v)", latest, db.CurrentVersion, db.IsDirty)
+	}
+	if len(db.MigrationSequence) != int(latest) {
+		t.Fatalf("expected %d migrations to run, got %d", latest, len(db.MigrationSequence))
+	}
+	if !strings.Contains(db.MigrationSequence[0], "CREATE TABLE") {
+		t.Fatalf("expected the first migration to create tables, got %.80q", db.MigrationSequence[0])
+	}
+}
+
+// TestEmbeddedMigrations_Postgres applies the embedded migrations to the
+// database in TEST_DATABASE_DSN, then rolls them all back and applies them
+// again, so every up and down script is run for real. The database is left
+// migrated; point it at a throwaway one.
+func TestEmbeddedMigrations_Postgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+	db, err := InitDB(context.Background(), dsn, config.PoolConfig{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer db.Close()
+
+	if err := RunEmbeddedMigrations(db); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		t.Fatalf("open embedded migrations: %v", err)
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		t.Fatalf("migrate driver: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		t.Fatalf("new migrate instance: %v", err)
+	}
+	if err := m.Down(); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("migrate up again: %v", err)
+	}
+
+	latest, err := LatestEmbeddedMigration()
+	if err != nil {
+		t.Fatalf("latest embedded migration: %v", err)
+	}
+	version, dirty, err := MigrationVersion(context.Background(), db)
+	if err != nil || version != latest || dirty {
+		t.Fatalf("expected clean version %d, got %d (dirty %v, err %v)", latest, version, dirty, err)
+	}
+}
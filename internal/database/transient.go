@@ -0,0 +1,45 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// transientCodes are PostgreSQL error codes that usually clear up on their
+// own, so the same statement may succeed if it is simply run again.
+var transientCodes = map[pq.ErrorCode]bool{
+	"53300": true, // too_many_connections
+	"57P03": true, // cannot_connect_now
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// transientMessages match driver errors that carry no SQLSTATE.
+var transientMessages = []string{
+	"connection pool exhausted",
+	"connection reset by peer",
+}
+
+// IsTransient reports whether err is a database error worth retrying.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return transientCodes[pqErr.Code] || pqErr.Code.Class() == "08"
+	}
+	msg := err.Error()
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
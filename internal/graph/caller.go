@@ -0,0 +1,30 @@
+package graph
+
+import "context"
+
+// Caller is the authenticated user behind a GraphQL request.
+type Caller struct {
+	UserID int
+	Admin  bool
+}
+
+type callerKey struct{}
+
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+func callerFrom(ctx context.Context) (Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(Caller)
+	return c, ok
+}
+
+// requireCaller returns the caller or the error mutations report for
+// anonymous requests.
+func requireCaller(ctx context.Context) (Caller, error) {
+	c, ok := callerFrom(ctx)
+	if !ok {
+		return Caller{}, errUnauthorized
+	}
+	return c, nil
+}
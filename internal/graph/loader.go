@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+)
+
+// loaderWait is how long a loader collects keys before fetching them. Sibling
+// fields of a list are resolved concurrently, so a couple of milliseconds is
+// enough to gather one batch per list.
+const loaderWait = 2 * time.Millisecond
+
+// loader batches Load calls made within loaderWait into a single fetch and
+// caches the results for the lifetime of the request. Keys missing from the
+// fetch result load as the zero value with ok == false.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	cache   map[K]V
+	pending *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys []K
+	done chan struct{}
+	res  map[K]V
+	err  error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, cache: make(map[K]V)}
+}
+
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	l.mu.Lock()
+	if v, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		return v, true, nil
+	}
+	b := l.pending
+	if b == nil {
+		b = &batch[K, V]{done: make(chan struct{})}
+		l.pending = b
+		time.AfterFunc(loaderWait, func() { l.run(ctx, b) })
+	}
+	b.keys = append(b.keys, key)
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, false, ctx.Err()
+	}
+	if b.err != nil {
+		var zero V
+		return zero, false, b.err
+	}
+	v, ok := b.res[key]
+	return v, ok, nil
+}
+
+func (l *loader[K, V]) run(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	l.pending = nil
+	keys := unique(b.keys)
+	l.mu.Unlock()
+
+	b.res, b.err = l.fetch(ctx, keys)
+	if b.err == nil {
+		l.mu.Lock()
+		for k, v := range b.res {
+			l.cache[k] = v
+		}
+		l.mu.Unlock()
+	}
+	close(b.done)
+}
+
+func unique[K comparable](keys []K) []K {
+	seen := make(map[K]bool, len(keys))
+	out := make([]K, 0, len(keys))
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// Loaders holds the per-request loaders. A fresh set is attached to every
+// operation so cached values never leak between callers.
+type Loaders struct {
+	genres *loader[int, []models.Genre]
+	users  *loader[int, models.User]
+	movies *loader[int, models.Movie]
+}
+
+func (r *Resolver) newLoaders() *Loaders {
+	return &Loaders{
+		genres: newLoader(r.MovieService.GenresByMovieIDs),
+		users: newLoader(func(ctx context.Context, ids []int) (map[int]models.User, error) {
+			users, err := r.UserService.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int]models.User, len(users))
+			for _, u := range users {
+				res[u.ID] = u
+			}
+			return res, nil
+		}),
+		movies: newLoader(func(ctx context.Context, ids []int) (map[int]models.Movie, error) {
+			res := make(map[int]models.Movie, len(ids))
+			for len(ids) > 0 {
+				n := min(len(ids), service.MaxMovieIDsPerRequest)
+				movies, err := r.MovieService.GetByIDs(ctx, ids[:n])
+				if err != nil {
+					return nil, err
+				}
+				for _, m := range movies {
+					res[m.ID] = m
+				}
+				ids = ids[n:]
+			}
+			return res, nil
+		}),
+	}
+}
+
+type loadersKey struct{}
+
+// WithLoaders returns ctx carrying a fresh set of loaders.
+func (r *Resolver) WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, r.newLoaders())
+}
+
+// loadersFor returns the loaders attached to ctx, creating an uncached set
+// when resolvers are called outside an operation (as in unit tests).
+func (r *Resolver) loadersFor(ctx context.Context) *Loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*Loaders); ok {
+		return l
+	}
+	return r.newLoaders()
+}
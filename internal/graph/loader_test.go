@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestLoader_BatchesConcurrentLoads(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]int
+	)
+	l := newLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		mu.Lock()
+		batches = append(batches, append([]int(nil), keys...))
+		mu.Unlock()
+		res := make(map[int]string)
+		for _, k := range keys {
+			if k != 4 {
+				res[k] = "v" + string(rune('0'+k))
+			}
+		}
+		return res, nil
+	})
+
+	ctx := context.Background()
+	keys := []int{1, 2, 3, 2, 1, 4}
+	got := make([]string, len(keys))
+	found := make([]bool, len(keys))
+	var wg sync.WaitGroup
+	for i, k := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, ok, err := l.Load(ctx, k)
+			if err != nil {
+				t.Errorf("load %d: %v", k, err)
+			}
+			got[i], found[i] = v, ok
+		}()
+	}
+	wg.Wait()
+
+	if len(batches) != 1 {
+		t.Fatalf("expected one fetch, got %d: %v", len(batches), batches)
+	}
+	sort.Ints(batches[0])
+	if len(batches[0]) != 4 {
+		t.Fatalf("expected the 4 distinct keys in one batch, got %v", batches[0])
+	}
+	for i, k := range keys {
+		if k == 4 {
+			if found[i] {
+				t.Fatalf("key 4 should be missing, got %q", got[i])
+			}
+			continue
+		}
+		if !found[i] || got[i] != "v"+string(rune('0'+k)) {
+			t.Fatalf("key %d: got %q, %v", k, got[i], found[i])
+		}
+	}
+
+	// Cached keys do not trigger another fetch.
+	if v, ok, _ := l.Load(ctx, 3); !ok || v != "v3" {
+		t.Fatalf("cached load: got %q, %v", v, ok)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("cached load fetched again: %v", batches)
+	}
+}
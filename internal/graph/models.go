@@ -0,0 +1,23 @@
+package graph
+
+import "golang-project/internal/models"
+
+type MoviePage struct {
+	Items      []models.Movie
+	Total      int
+	Page       int
+	Limit      int
+	TotalPages int
+}
+
+// UpdateReviewInput keeps GraphQL's distinction between an omitted field and
+// a zero value, which ReviewService.Update shares.
+type UpdateReviewInput struct {
+	Rating  *int
+	Title   *string
+	Content *string
+}
+
+func (in UpdateReviewInput) toRequest() models.UpdateReviewRequest {
+	return models.UpdateReviewRequest{Rating: in.Rating, Title: in.Title, Content: in.Content}
+}
@@ -0,0 +1,34 @@
+// Package graph serves the GraphQL API at /api/v1/graphql. Resolvers
+// delegate to the same services as the REST handlers; per-request loaders
+// batch the genre and author lookups that would otherwise run once per row.
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"net/http"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/service"
+)
+
+// Caps applied to list arguments so one query cannot fan out unboundedly.
+const (
+	maxMoviesPerPage   = 100
+	maxReviewsPerMovie = 50
+)
+
+// Errors the resolvers raise themselves. Service errors are passed through
+// and mapped by the handler's error presenter.
+var (
+	errUnauthorized  = apierror.New(http.StatusUnauthorized, "unauthorized", "authentication required")
+	errForbidden     = apierror.New(http.StatusForbidden, "forbidden", "forbidden")
+	errInvalidRating = apierror.New(http.StatusBadRequest, "invalid_rating", "rating must be between 1 and 10")
+)
+
+type Resolver struct {
+	MovieService  *service.MovieService
+	GenreService  *service.GenreService
+	ReviewService *service.ReviewService
+	UserService   *service.UserService
+}
@@ -0,0 +1,190 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.73
+
+import (
+	"context"
+	"errors"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+)
+
+// Genres is the resolver for the genres field.
+func (r *movieResolver) Genres(ctx context.Context, obj *models.Movie) ([]models.Genre, error) {
+	if obj.Genres != nil {
+		return obj.Genres, nil
+	}
+	genres, _, err := r.loadersFor(ctx).genres.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if genres == nil {
+		genres = []models.Genre{}
+	}
+	return genres, nil
+}
+
+// Reviews is the resolver for the reviews field.
+func (r *movieResolver) Reviews(ctx context.Context, obj *models.Movie, limit *int, sort *string) ([]models.Review, error) {
+	n := 5
+	if limit != nil && *limit > 0 {
+		n = min(*limit, maxReviewsPerMovie)
+	}
+	var filters models.ReviewFilters
+	if sort != nil {
+		filters.Sort = *sort
+	}
+	return r.ReviewService.ListByMovie(ctx, obj.ID, filters, 1, n)
+}
+
+// CreateReview is the resolver for the createReview field.
+func (r *mutationResolver) CreateReview(ctx context.Context, movieID int, input models.CreateReviewRequest) (*models.Review, error) {
+	caller, err := requireCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReviewService.Create(ctx, movieID, caller.UserID, input, caller.Admin)
+}
+
+// UpdateReview is the resolver for the updateReview field.
+func (r *mutationResolver) UpdateReview(ctx context.Context, id int, input UpdateReviewInput) (*models.Review, error) {
+	caller, err := requireCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if input.Rating != nil && (*input.Rating < 1 || *input.Rating > 10) {
+		return nil, errInvalidRating
+	}
+	review, err := r.ReviewService.Update(ctx, id, caller.UserID, input.toRequest(), caller.Admin)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		return nil, errForbidden
+	}
+	return review, err
+}
+
+// DeleteReview is the resolver for the deleteReview field.
+func (r *mutationResolver) DeleteReview(ctx context.Context, id int) (bool, error) {
+	caller, err := requireCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, err = r.ReviewService.Delete(ctx, id, caller.UserID, caller.Admin)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		return false, errForbidden
+	}
+	return err == nil, err
+}
+
+// Movies is the resolver for the movies field.
+func (r *queryResolver) Movies(ctx context.Context, page *int, limit *int, search *string, genreID *int, year *int, sort *string) (*MoviePage, error) {
+	filters := models.MovieFilters{GenreID: genreID}
+	if search != nil {
+		filters.Search = *search
+	}
+	if year != nil {
+		filters.Year = *year
+	}
+	if sort != nil {
+		filters.Sort = *sort
+	}
+	p, l := 1, 10
+	if page != nil {
+		p = *page
+	}
+	if limit != nil {
+		l = min(*limit, maxMoviesPerPage)
+	}
+	res, err := r.MovieService.List(ctx, filters, p, l)
+	if err != nil {
+		return nil, err
+	}
+	return &MoviePage{
+		Items:      res.Data.([]models.Movie),
+		Total:      res.Total,
+		Page:       res.Page,
+		Limit:      res.Limit,
+		TotalPages: res.TotalPages,
+	}, nil
+}
+
+// Movie is the resolver for the movie field.
+func (r *queryResolver) Movie(ctx context.Context, id int) (*models.Movie, error) {
+	caller, _ := callerFrom(ctx)
+	movie, err := r.MovieService.GetVisible(ctx, id, caller.Admin)
+	if errors.Is(err, service.ErrMovieNotFound) {
+		return nil, nil
+	}
+	return movie, err
+}
+
+// Genres is the resolver for the genres field.
+func (r *queryResolver) Genres(ctx context.Context) ([]models.Genre, error) {
+	return r.GenreService.List(ctx)
+}
+
+// Review is the resolver for the review field.
+func (r *queryResolver) Review(ctx context.Context, id int) (*models.Review, error) {
+	review, err := r.ReviewService.Get(ctx, id)
+	if errors.Is(err, service.ErrReviewNotFound) {
+		return nil, nil
+	}
+	return review, err
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*models.User, error) {
+	caller, ok := callerFrom(ctx)
+	if !ok {
+		return nil, nil
+	}
+	return r.UserService.GetByID(ctx, caller.UserID)
+}
+
+// Author is the resolver for the author field.
+func (r *reviewResolver) Author(ctx context.Context, obj *models.Review) (*models.User, error) {
+	if obj.User != nil {
+		return &models.User{ID: obj.User.ID, Username: obj.User.Username}, nil
+	}
+	user, ok, err := r.loadersFor(ctx).users.Load(ctx, obj.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, service.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// Movie is the resolver for the movie field.
+func (r *reviewResolver) Movie(ctx context.Context, obj *models.Review) (*models.Movie, error) {
+	if obj.Movie != nil {
+		return obj.Movie, nil
+	}
+	movie, ok, err := r.loadersFor(ctx).movies.Load(ctx, obj.MovieID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, service.ErrMovieNotFound
+	}
+	return &movie, nil
+}
+
+// Movie returns MovieResolver implementation.
+func (r *Resolver) Movie() MovieResolver { return &movieResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Review returns ReviewResolver implementation.
+func (r *Resolver) Review() ReviewResolver { return &reviewResolver{r} }
+
+type movieResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type reviewResolver struct{ *Resolver }
@@ -0,0 +1,145 @@
+// Package grpcserver serves the read-only catalogue API defined in
+// proto/catalog/v1 for internal consumers. It reuses the HTTP API's services
+// and accepts the same JWTs.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"golang-project/internal/actor"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/pkg/jwt"
+	catalogv1 "golang-project/proto/catalog/v1"
+)
+
+// New returns a gRPC server with the catalogue service registered behind
+// the JWT interceptor.
+func New(movies *service.MovieService, genres *service.GenreService, keys *jwt.KeySet) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(AuthInterceptor(keys)))
+	catalogv1.RegisterCatalogServiceServer(srv, &catalogServer{movies: movies, genres: genres})
+	return srv
+}
+
+// AuthInterceptor rejects calls without a valid "authorization: Bearer <jwt>"
+// metadata entry and puts the caller's ID on the context.
+func AuthInterceptor(keys *jwt.KeySet) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		claims, err := keys.Parse(strings.TrimPrefix(values[0], "Bearer "))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		if id, err := strconv.Atoi(claims.UserID); err == nil {
+			ctx = actor.WithID(ctx, id)
+		}
+		return handler(ctx, req)
+	}
+}
+
+type catalogServer struct {
+	catalogv1.UnimplementedCatalogServiceServer
+	movies *service.MovieService
+	genres *service.GenreService
+}
+
+func (s *catalogServer) GetMovie(ctx context.Context, req *catalogv1.GetMovieRequest) (*catalogv1.Movie, error) {
+	// The gRPC API is unauthenticated, so it only serves published movies.
+	movie, err := s.movies.GetVisible(ctx, int(req.GetId()), false)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toMovie(movie), nil
+}
+
+func (s *catalogServer) ListMovies(ctx context.Context, req *catalogv1.ListMoviesRequest) (*catalogv1.ListMoviesResponse, error) {
+	filters := models.MovieFilters{
+		Year:       int(req.GetYear()),
+		MinRating:  req.GetMinRating(),
+		Search:     req.GetSearch(),
+		DirectorIn: req.GetDirectorIn(),
+		Sort:       req.GetSort(),
+	}
+	if req.GenreId != nil {
+		id := int(req.GetGenreId())
+		filters.GenreID = &id
+	}
+	page, err := s.movies.List(ctx, filters, int(req.GetPage()), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	movies, _ := page.Data.([]models.Movie)
+	resp := &catalogv1.ListMoviesResponse{
+		Movies:     make([]*catalogv1.Movie, 0, len(movies)),
+		Total:      int32(page.Total),
+		Page:       int32(page.Page),
+		Limit:      int32(page.Limit),
+		TotalPages: int32(page.TotalPages),
+	}
+	for i := range movies {
+		resp.Movies = append(resp.Movies, toMovie(&movies[i]))
+	}
+	return resp, nil
+}
+
+func (s *catalogServer) ListGenres(ctx context.Context, _ *catalogv1.ListGenresRequest) (*catalogv1.ListGenresResponse, error) {
+	genres, err := s.genres.List(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &catalogv1.ListGenresResponse{Genres: make([]*catalogv1.Genre, 0, len(genres))}
+	for _, g := range genres {
+		resp.Genres = append(resp.Genres, toGenre(g))
+	}
+	return resp, nil
+}
+
+// toStatus maps service errors to gRPC codes. Unknown errors are logged and
+// reported as Internal without their message.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrMovieNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrTooManyDirectors):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Printf("grpc: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func toMovie(m *models.Movie) *catalogv1.Movie {
+	out := &catalogv1.Movie{
+		Id:              int64(m.ID),
+		Title:           m.Title,
+		Description:     m.Description,
+		ReleaseYear:     int32(m.ReleaseYear),
+		Director:        m.Director,
+		DurationMinutes: int32(m.DurationMinutes),
+		AverageRating:   m.AverageRating,
+		Genres:          make([]*catalogv1.Genre, 0, len(m.Genres)),
+		CreatedAt:       timestamppb.New(m.CreatedAt),
+		UpdatedAt:       timestamppb.New(m.UpdatedAt),
+	}
+	for _, g := range m.Genres {
+		out.Genres = append(out.Genres, toGenre(g))
+	}
+	return out
+}
+
+func toGenre(g models.Genre) *catalogv1.Genre {
+	return &catalogv1.Genre{Id: int64(g.ID), Name: g.Name}
+}
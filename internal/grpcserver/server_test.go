@@ -0,0 +1,124 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+	"golang-project/pkg/jwt"
+	catalogv1 "golang-project/proto/catalog/v1"
+)
+
+func newTestClient(t *testing.T, keys *jwt.KeySet) catalogv1.CatalogServiceClient {
+	t.Helper()
+
+	genres := testutil.NewMemGenreRepo()
+	genres.Add(&models.Genre{ID: 1, Name: "Drama"})
+	genres.Add(&models.Genre{ID: 2, Name: "Crime"})
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat", ReleaseYear: 1995, Director: "Michael Mann", AverageRating: 8.3}, 1, 2)
+	movies.Add(&models.Movie{ID: 2, Title: "Collateral", ReleaseYear: 2004, Director: "Michael Mann", AverageRating: 7.5}, 2)
+	movies.Add(&models.Movie{ID: 3, Title: "Magnolia", ReleaseYear: 1999, Director: "Paul Thomas Anderson", AverageRating: 8.0}, 1)
+
+	v := validator.New()
+	srv := New(service.NewMovieService(movies, genres, v), service.NewGenreService(genres, v), keys)
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return catalogv1.NewCatalogServiceClient(conn)
+}
+
+func TestCatalogServer_ListMoviesFilters(t *testing.T) {
+	keys := jwt.StaticKeySet("secret")
+	client := newTestClient(t, keys)
+	token, err := keys.Generate("5", "user", time.Hour)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	tests := []struct {
+		name string
+		req  *catalogv1.ListMoviesRequest
+		want []string
+	}{
+		{"no filters", &catalogv1.ListMoviesRequest{}, []string{"Heat", "Collateral", "Magnolia"}},
+		{"genre", &catalogv1.ListMoviesRequest{GenreId: proto.Int64(1)}, []string{"Heat", "Magnolia"}},
+		{"year", &catalogv1.ListMoviesRequest{Year: 2004}, []string{"Collateral"}},
+		{"min rating", &catalogv1.ListMoviesRequest{MinRating: 8}, []string{"Heat", "Magnolia"}},
+		{"search", &catalogv1.ListMoviesRequest{Search: "heat"}, []string{"Heat"}},
+		{"directors", &catalogv1.ListMoviesRequest{DirectorIn: []string{"Michael Mann"}}, []string{"Heat", "Collateral"}},
+		{"paged", &catalogv1.ListMoviesRequest{Page: 2, Limit: 2}, []string{"Magnolia"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.ListMovies(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("ListMovies: %v", err)
+			}
+			var got []string
+			for _, m := range resp.GetMovies() {
+				got = append(got, m.GetTitle())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	heat, err := client.GetMovie(ctx, &catalogv1.GetMovieRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("GetMovie: %v", err)
+	}
+	if len(heat.GetGenres()) != 2 {
+		t.Fatalf("expected Heat with 2 genres, got %v", heat.GetGenres())
+	}
+	if _, err := client.GetMovie(ctx, &catalogv1.GetMovieRequest{Id: 99}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
+func TestCatalogServer_RequiresToken(t *testing.T) {
+	client := newTestClient(t, jwt.StaticKeySet("secret"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.ListGenres(ctx, &catalogv1.ListGenresRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without a token, got %v", err)
+	}
+
+	forged, _ := jwt.StaticKeySet("other").Generate("5", "user", time.Hour)
+	bad := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+forged)
+	if _, err := client.ListGenres(bad, &catalogv1.ListGenresRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a token signed with another key, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/config"
+	"golang-project/internal/service"
+	"golang-project/internal/version"
+)
+
+type AdminHandler struct {
+	ratings *service.RatingRecalculator
+	config  *config.Config
+}
+
+func NewAdminHandler(ratings *service.RatingRecalculator, cfg *config.Config) *AdminHandler {
+	return &AdminHandler{ratings: ratings, config: cfg}
+}
+
+func (h *AdminHandler) RecalculateRatings(c *gin.Context) {
+	status, err := h.ratings.Start(c.Request.Context())
+	if err != nil {
+		if err == service.ErrRecalculationRunning {
+			respondError(c, toAPIError(err).WithDetails(map[string]any{"status": status}))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, status)
+}
+
+func (h *AdminHandler) RecalculateRatingsStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.ratings.Status())
+}
+
+func (h *AdminHandler) Config(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"config": h.config.Redacted(),
+		"build":  version.BuildInfo(),
+	})
+}
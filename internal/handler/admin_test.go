@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/config"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/storage"
+	"golang-project/internal/testutil"
+	"golang-project/internal/version"
+	"golang-project/pkg/jwt"
+)
+
+type blockingRatingRepo struct {
+	ids     []int
+	release chan struct{}
+}
+
+func (r *blockingRatingRepo) Count(ctx context.Context) (int, error) {
+	return len(r.ids), nil
+}
+
+func (r *blockingRatingRepo) ListIDsAfter(ctx context.Context, afterID, limit int) ([]int, error) {
+	var out []int
+	for _, id := range r.ids {
+		if id > afterID && len(out) < limit {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+func (r *blockingRatingRepo) RecalculateAverageRatings(ctx context.Context, movieIDs []int) error {
+	<-r.release
+	return nil
+}
+
+func TestAdminHandler_RecalculateRatings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &blockingRatingRepo{ids: []int{1, 2, 3}, release: make(chan struct{})}
+	job := service.NewRatingRecalculator(repo, 2)
+	h := NewAdminHandler(job, nil)
+
+	router := gin.New()
+	router.POST("/admin/recalculate-ratings", h.RecalculateRatings)
+	router.GET("/admin/recalculate-ratings/status", h.RecalculateRatingsStatus)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/admin/recalculate-ratings"); w.Code != http.StatusAccepted {
+		t.Fatalf("start expected 202, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/admin/recalculate-ratings"); w.Code != http.StatusConflict {
+		t.Fatalf("concurrent start expected 409, got %d", w.Code)
+	}
+
+	close(repo.release)
+	job.Wait()
+
+	w := do(http.MethodGet, "/admin/recalculate-ratings/status")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status expected 200, got %d", w.Code)
+	}
+	var status service.RatingJobStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("parse status: %v", err)
+	}
+	if status.State != service.RatingJobCompleted || status.Total != 3 || status.Processed != 3 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	if w := do(http.MethodPost, "/admin/recalculate-ratings"); w.Code != http.StatusAccepted {
+		t.Fatalf("restart after completion expected 202, got %d", w.Code)
+	}
+	job.Wait()
+}
+
+func TestAdminHandler_Config(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys, err := jwt.NewKeySet("2024-06", map[string]string{"2024-06": "super-secret-key"})
+	if err != nil {
+		t.Fatalf("key set: %v", err)
+	}
+	cfg := &config.Config{
+		Port:           "8080",
+		DBDsn:          "postgres://app:db-password@db:5432/movies?sslmode=disable",
+		JWTSecret:      "super-secret-key",
+		JWTKeys:        keys,
+		JWTTTL:         2 * time.Hour,
+		MigrationsPath: "internal/migrations",
+		PosterStorage:  "s3",
+		S3:             storage.S3Config{Endpoint: "https://s3.example.com", Bucket: "posters", AccessKey: "AKID", SecretKey: "s3-secret-key"},
+	}
+	h := NewAdminHandler(nil, cfg)
+
+	router := gin.New()
+	router.GET("/admin/config", h.Config)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	body := w.Body.String()
+	for _, secret := range []string{"db-password", "super-secret-key", "s3-secret-key"} {
+		if strings.Contains(body, secret) {
+			t.Fatalf("response leaks secret %q: %s", secret, body)
+		}
+	}
+
+	var resp struct {
+		Config config.Redacted `json:"config"`
+		Build  version.Info    `json:"build"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Config.JWTTTL != "2h0m0s" || resp.Config.JWTCurrentKID != "2024-06" {
+		t.Fatalf("unexpected config: %+v", resp.Config)
+	}
+	if resp.Config.S3Bucket != "posters" {
+		t.Fatalf("expected the S3 bucket to be shown, got %+v", resp.Config)
+	}
+	if !strings.Contains(resp.Config.DBDsn, "app:xxxxx@db:5432") {
+		t.Fatalf("expected masked dsn, got %q", resp.Config.DBDsn)
+	}
+	if resp.Build.GoVersion == "" || resp.Build.Version == "" {
+		t.Fatalf("expected build info, got %+v", resp.Build)
+	}
+}
+
+func TestAnalyticsHandler_Reviews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+	reviews := testutil.NewMemReviewRepo()
+	keywords := testutil.NewMemBlockedKeywordRepo()
+	_ = keywords.Create(ctx, &models.BlockedKeyword{Keyword: "spam"})
+	_ = keywords.Create(ctx, &models.BlockedKeyword{Keyword: "Scam", CaseSensitive: true})
+	// Review i has i+1 five-character words ("word " repeated, trimmed), so
+	// 5.5 words and 26.5 characters on average. Only the first and third
+	// titles contain a blocked keyword in the right case.
+	ratings := []int{8, 2, 7, 10, 3, 8, 5, 7, 6, 8}
+	titles := map[int]string{0: "Buy SPAM now", 1: "a scam?", 2: "Scam alert"}
+	for i, rating := range ratings {
+		review := &models.Review{MovieID: 1, UserID: i + 1, Rating: rating, Title: titles[i],
+			Content: strings.TrimSpace(strings.Repeat("word ", i+1))}
+		if err := reviews.Create(ctx, review); err != nil {
+			t.Fatalf("create review: %v", err)
+		}
+	}
+	h := NewAnalyticsHandler(service.NewReviewAnalyticsService(testutil.MemReviewAnalytics{Reviews: reviews, Keywords: keywords}))
+
+	router := gin.New()
+	router.GET("/admin/analytics/reviews", h.Reviews)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/analytics/reviews", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got models.ReviewAnalytics
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+
+	if got.TotalReviews != 10 || got.FlaggedReviews != 2 {
+		t.Fatalf("expected 2 of 10 reviews flagged, got %d of %d", got.FlaggedReviews, got.TotalReviews)
+	}
+	const eps = 1e-9
+	for name, f := range map[string]struct{ got, want float64 }{
+		"flagged_percent":    {got.FlaggedPercent, 20},
+		"avg_word_count":     {got.AvgWordCount, 5.5},
+		"median_rating":      {got.MedianRating, 7},
+		"avg_content_length": {got.AvgContentLength, 26.5},
+	} {
+		if math.Abs(f.got-f.want) > eps {
+			t.Fatalf("%s: expected %v, got %v", name, f.want, f.got)
+		}
+	}
+	want := map[int]int{1: 0, 2: 1, 3: 1, 4: 0, 5: 1, 6: 1, 7: 2, 8: 3, 9: 0, 10: 1}
+	if !maps.Equal(got.RatingDistribution, want) {
+		t.Fatalf("expected rating distribution %v, got %v", want, got.RatingDistribution)
+	}
+}
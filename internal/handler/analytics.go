@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/service"
+)
+
+type AnalyticsHandler struct {
+	reviews *service.ReviewAnalyticsService
+}
+
+func NewAnalyticsHandler(reviews *service.ReviewAnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{reviews: reviews}
+}
+
+// Reviews reports review quality metrics: how many reviews are flagged,
+// their average length and the rating distribution.
+func (h *AnalyticsHandler) Reviews(c *gin.Context) {
+	analytics, err := h.reviews.Get(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, analytics)
+}
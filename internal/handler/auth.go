@@ -1,11 +1,10 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 
 	"golang-project/internal/models"
 	"golang-project/internal/service"
@@ -22,53 +21,53 @@ func NewAuthHandler(auth *service.AuthService) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 
-	user, token, err := h.auth.Register(c.Request.Context(), req)
+	resp, err := h.auth.Register(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, service.ErrUserExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": "user already exists"})
-			return
-		}
-		if ve, ok := err.(validator.ValidationErrors); ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": ve.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})		
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"user":  user,
-		"token": token,
-	})
+	c.JSON(http.StatusCreated, resp)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 
-	user, token, err := h.auth.Login(c.Request.Context(), req)
+	resp, err := h.auth.Login(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidCredentials) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-			return
-		}
-		if _, ok := err.(validator.ValidationErrors); ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error in handler"})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"user":  user,
-		"token": token,
-	})
+	c.JSON(http.StatusOK, resp)
+}
+
+// Impersonate issues the calling admin a short-lived token for the user in
+// the path.
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	adminID, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+
+	resp, err := h.auth.Impersonate(c.Request.Context(), targetID, adminID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
 }
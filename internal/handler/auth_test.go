@@ -2,186 +2,24 @@ package handler
 
 import (
 	"bytes"
-	"context"
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
-	"sync"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
+	"golang-project/internal/apierror"
+	"golang-project/internal/config"
 	"golang-project/internal/models"
 	"golang-project/internal/service"
+	"golang-project/internal/testutil"
 	"golang-project/pkg/jwt"
 )
 
-type memoryUserRepo struct {
-	mu    sync.Mutex
-	users map[string]*models.User
-}
-
-func newMemoryUserRepo() *memoryUserRepo {
-	return &memoryUserRepo{users: make(map[string]*models.User)}
-}
-
-func (r *memoryUserRepo) Create(ctx context.Context, user *models.User) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if _, exists := r.users[user.Email]; exists {
-		return errors.New("duplicate")
-	}
-	now := time.Now()
-	user.ID = len(r.users) + 1
-	user.CreatedAt = now
-	user.UpdatedAt = now
-	r.users[user.Email] = user
-	return nil
-}
-
-func (r *memoryUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if u, ok := r.users[email]; ok {
-		return u, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) GetByID(ctx context.Context, id int) (*models.User, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for _, u := range r.users {
-		if u.ID == id {
-			return u, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) List(ctx context.Context, filters models.UserFilters, limit, offset int) ([]models.User, int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	result := make([]models.User, 0, len(r.users))
-	for _, u := range r.users {
-		if filters.Search != "" {
-			if !contains(u.Email, filters.Search) && !contains(u.Username, filters.Search) {
-				continue
-			}
-		}
-		if filters.Role != "" && u.Role != filters.Role {
-			continue
-		}
-		result = append(result, *u)
-	}
-	total := len(result)
-	if offset >= total {
-		return []models.User{}, total, nil
-	}
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-	return result[offset:end], total, nil
-}
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0)
-}
-
-func (r *memoryUserRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for _, u := range r.users {
-		if u.Username == username {
-			return u, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) UpdateRole(ctx context.Context, id int, role string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for _, u := range r.users {
-		if u.ID == id {
-			u.Role = role
-			return nil
-		}
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) Update(ctx context.Context, id int, email, username string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for emailKey, u := range r.users {
-		if u.ID == id {
-			if email != "" && email != u.Email {
-				delete(r.users, emailKey)
-				u.Email = email
-				r.users[email] = u
-			}
-			if username != "" {
-				u.Username = username
-			}
-			u.UpdatedAt = time.Now()
-			return nil
-		}
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) Delete(ctx context.Context, id int) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for email, u := range r.users {
-		if u.ID == id {
-			delete(r.users, email)
-			return nil
-		}
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for _, u := range r.users {
-		if u.ID == id {
-			u.PasswordHash = passwordHash
-			u.UpdatedAt = time.Now()
-			return nil
-		}
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) Count(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return len(r.users), nil
-}
-
-func (r *memoryUserRepo) CountLast7Days(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	count := 0
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
-	for _, u := range r.users {
-		if u.CreatedAt.After(sevenDaysAgo) {
-			count++
-		}
-	}
-	return count, nil
-}
-
 func TestAuthHandler_Register(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -217,14 +55,14 @@ func TestAuthHandler_Register(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := newMemoryUserRepo()
+			repo := testutil.NewMemUserRepo()
 			v := validator.New()
-			authService := service.NewAuthService(repo, v, "secret")
+			authService := service.NewAuthService(repo, v, jwt.StaticKeySet("secret"))
 			h := NewAuthHandler(authService)
 
 			if tt.prepopulate {
 				hash, _ := jwt.HashPassword("password123")
-				repo.users["dupe@example.com"] = &models.User{
+				repo.Add(&models.User{
 					ID:           1,
 					Email:        "dupe@example.com",
 					Username:     "dupe",
@@ -232,7 +70,7 @@ func TestAuthHandler_Register(t *testing.T) {
 					Role:         "user",
 					CreatedAt:    time.Now(),
 					UpdatedAt:    time.Now(),
-				}
+				})
 			}
 
 			r := gin.New()
@@ -306,10 +144,10 @@ func TestAuthHandler_Login(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := newMemoryUserRepo()
+			repo := testutil.NewMemUserRepo()
 			if tt.setupUser {
 				hash, _ := jwt.HashPassword(tt.password)
-				repo.users["user@example.com"] = &models.User{
+				repo.Add(&models.User{
 					ID:           1,
 					Email:        "user@example.com",
 					Username:     "user",
@@ -317,11 +155,11 @@ func TestAuthHandler_Login(t *testing.T) {
 					Role:         "user",
 					CreatedAt:    time.Now(),
 					UpdatedAt:    time.Now(),
-				}
+				})
 			}
 
 			v := validator.New()
-			authService := service.NewAuthService(repo, v, "secret")
+			authService := service.NewAuthService(repo, v, jwt.StaticKeySet("secret"))
 			h := NewAuthHandler(authService)
 
 			r := gin.New()
@@ -348,3 +186,41 @@ func TestAuthHandler_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestImpersonationCannotChangeAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The guard answers before any repository is used, so the database is
+	// never reached.
+	db, err := sql.Open("postgres", "postgres://localhost/unused?sslmode=disable")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	keys := jwt.StaticKeySet("secret")
+	router := SetupRoutes(db, &config.Config{JWTKeys: keys, RateLimit: 60, SearchRateLimit: 20, MaxBodyBytes: 1 << 20}, nil, nil, nil)
+
+	token, _, err := keys.IssueImpersonation("3", "admin", "1", time.Minute)
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	for _, target := range []string{"/api/v1/me", "/api/v1/me/password", "/api/v1/me/privacy", "/api/v1/admin/users/4/impersonate"} {
+		method := http.MethodPut
+		if target == "/api/v1/admin/users/4/impersonate" {
+			method = http.MethodPost
+		}
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp apierror.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: parse response: %v", target, err)
+		}
+		if w.Code != http.StatusForbidden || resp.Error.Code != "impersonation_forbidden" {
+			t.Fatalf("%s: expected 403 impersonation_forbidden, got %d %s", target, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+)
+
+type BlocklistHandler struct {
+	service *service.BlocklistService
+}
+
+func NewBlocklistHandler(s *service.BlocklistService) *BlocklistHandler {
+	return &BlocklistHandler{service: s}
+}
+
+func (h *BlocklistHandler) List(c *gin.Context) {
+	keywords, err := h.service.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": keywords})
+}
+
+func (h *BlocklistHandler) Create(c *gin.Context) {
+	var req models.BlockedKeywordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+	keyword, err := h.service.Create(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, keyword)
+}
+
+func (h *BlocklistHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	var req models.BlockedKeywordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+	keyword, err := h.service.Update(c.Request.Context(), id, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, keyword)
+}
+
+func (h *BlocklistHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	keyword, err := h.service.Delete(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondDeleted(c, keyword)
+}
+
+// Check lets admins try a text against the blocklist before it bites.
+func (h *BlocklistHandler) Check(c *gin.Context) {
+	var req models.CheckContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Content == "" {
+		respondError(c, errInvalidRequest)
+		return
+	}
+	matched, err := h.service.Match(c.Request.Context(), req.Content)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"matched": matched})
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/middleware"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+func TestBlocklist_RejectsReviewsWithBlockedKeyword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Fight Club"})
+	v := validator.New()
+	blocklist := service.NewBlocklistService(testutil.NewMemBlockedKeywordRepo(), v)
+	blocklistHandler := NewBlocklistHandler(blocklist)
+	reviews := NewReviewHandler(service.NewReviewService(testutil.NewMemReviewRepo(), movies, v, nil, service.WithContentFilter(blocklist)))
+
+	router := gin.New()
+	router.POST("/admin/blocked-keywords", blocklistHandler.Create)
+	router.POST("/admin/blocked-keywords/check", blocklistHandler.Check)
+	router.POST("/movies/:id/reviews", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+		c.Set(string(middleware.ContextRole), "user")
+	}, reviews.Create)
+	post := func(target, user string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, k := range []models.BlockedKeywordRequest{
+		{Keyword: "tyler is the narrator"},
+		{Keyword: "ACME", CaseSensitive: true},
+	} {
+		if w := post("/admin/blocked-keywords", "", k); w.Code != http.StatusCreated {
+			t.Fatalf("create %q: expected 201, got %d: %s", k.Keyword, w.Code, w.Body)
+		}
+	}
+
+	w := post("/admin/blocked-keywords/check", "", models.CheckContentRequest{Content: "Spoiler: Tyler Is The Narrator, says ACME"})
+	var check struct {
+		Matched []string `json:"matched"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &check); err != nil || len(check.Matched) != 2 {
+		t.Fatalf("expected both keywords to match, got %s", w.Body)
+	}
+
+	for _, tt := range []struct {
+		user, content string
+		want          int
+	}{
+		{"1", "Great twist. TYLER IS THE NARRATOR!", http.StatusUnprocessableEntity},
+		{"2", "Sponsored by ACME", http.StatusUnprocessableEntity},
+		{"3", "Not sponsored by acme, just a great film", http.StatusCreated},
+	} {
+		w := post("/movies/1/reviews", tt.user, models.CreateReviewRequest{Rating: 9, Title: "Wow", Content: tt.content})
+		if w.Code != tt.want {
+			t.Fatalf("%q: expected %d, got %d: %s", tt.content, tt.want, w.Code, w.Body)
+		}
+		if tt.want != http.StatusUnprocessableEntity {
+			continue
+		}
+		var resp apierror.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error.Code != "content_blocked" {
+			t.Fatalf("%q: expected content_blocked, got %s", tt.content, w.Body)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/middleware"
+)
+
+// currentUserID returns the id AuthMiddleware stored for the caller. A
+// missing or malformed id is errInvalidUser rather than a panic, so a route
+// registered without the middleware fails closed.
+func currentUserID(c *gin.Context) (int, error) {
+	val, ok := c.Get(string(middleware.ContextUserID))
+	if !ok {
+		return 0, errInvalidUser
+	}
+	idStr, ok := val.(string)
+	if !ok {
+		return 0, errInvalidUser
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, errInvalidUser
+	}
+	return id, nil
+}
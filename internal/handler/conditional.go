@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notModified sets Last-Modified from modified and, when the request's
+// If-Modified-Since is not older, answers 304 with no body and reports true.
+// HTTP dates only carry whole seconds, so modified is truncated first;
+// otherwise a resource updated at 12:00:00.5 would always look newer than
+// the 12:00:00 the client sends back. A zero modified sets nothing.
+func notModified(c *gin.Context, modified time.Time) bool {
+	if modified.IsZero() {
+		return false
+	}
+	modified = modified.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", modified.Format(http.TimeFormat))
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || modified.After(since) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// listNotModified is notModified for a list that depends on the query string
+// as well as on when its rows last changed. It also sets a weak ETag over
+// both; when the client sends If-None-Match, that decides and
+// If-Modified-Since is ignored, as RFC 9110 requires.
+func listNotModified(c *gin.Context, modified time.Time) bool {
+	if modified.IsZero() {
+		return false
+	}
+	sum := sha256.Sum256([]byte(modified.UTC().Format(time.RFC3339Nano) + "?" + c.Request.URL.RawQuery))
+	etag := `W/"` + hex.EncodeToString(sum[:12]) + `"`
+	c.Header("ETag", etag)
+
+	match := c.GetHeader("If-None-Match")
+	if match == "" {
+		return notModified(c, modified)
+	}
+	c.Header("Last-Modified", modified.UTC().Truncate(time.Second).Format(http.TimeFormat))
+	for _, candidate := range strings.Split(match, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
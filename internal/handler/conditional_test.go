@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+func TestConditionalGet_LastModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Sub-second parts must not make the resource look newer than the
+	// second-precision date the client echoes back.
+	movieUpdated := time.Date(2024, 5, 1, 12, 0, 0, 700_000_000, time.UTC)
+	reviewUpdated := time.Date(2024, 5, 2, 8, 30, 15, 250_000_000, time.UTC)
+
+	movies, genres, _ := newMHRepos()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat", UpdatedAt: movieUpdated, ReviewsUpdatedAt: reviewUpdated})
+	movies.Add(&models.Movie{ID: 2, Title: "Ronin"})
+	reviews := testutil.NewMemReviewRepo()
+	reviews.Add(&models.Review{MovieID: 1, UserID: 1, Rating: 8, UpdatedAt: reviewUpdated.Add(-time.Hour)})
+	reviews.Add(&models.Review{MovieID: 1, UserID: 2, Rating: 6, UpdatedAt: reviewUpdated})
+
+	router := gin.New()
+	router.GET("/movies/:id", NewMovieHandler(service.NewMovieService(movies, genres, validator.New())).Get)
+	router.GET("/movies/:id/reviews", NewReviewHandler(service.NewReviewService(reviews, movies, validator.New(), nil)).ListByMovie)
+
+	get := func(target, ifModifiedSince string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifModifiedSince != "" {
+			req.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, tt := range []struct {
+		target   string
+		modified time.Time
+	}{
+		{"/movies/1", movieUpdated},
+		{"/movies/1/reviews", reviewUpdated},
+	} {
+		lastModified := tt.modified.Truncate(time.Second).Format(http.TimeFormat)
+
+		w := get(tt.target, "")
+		if w.Code != http.StatusOK || w.Header().Get("Last-Modified") != lastModified {
+			t.Fatalf("%s: expected 200 with Last-Modified %q, got %d %q", tt.target, lastModified, w.Code, w.Header().Get("Last-Modified"))
+		}
+
+		for _, since := range []time.Time{tt.modified, tt.modified.Add(time.Minute)} {
+			w = get(tt.target, since.Format(http.TimeFormat))
+			if w.Code != http.StatusNotModified {
+				t.Fatalf("%s since %s: expected 304, got %d", tt.target, since, w.Code)
+			}
+			if w.Body.Len() != 0 {
+				t.Fatalf("%s: 304 must not have a body, got %q", tt.target, w.Body)
+			}
+		}
+
+		w = get(tt.target, tt.modified.Add(-time.Second).Format(http.TimeFormat))
+		if w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Fatalf("%s: expected 200 with a body for an older date, got %d", tt.target, w.Code)
+		}
+		if w = get(tt.target, "not a date"); w.Code != http.StatusOK {
+			t.Fatalf("%s: expected an unparsable date to be ignored, got %d", tt.target, w.Code)
+		}
+	}
+
+	if w := get("/movies/2/reviews", time.Now().Format(http.TimeFormat)); w.Code != http.StatusOK || w.Header().Get("Last-Modified") != "" {
+		t.Fatalf("expected no Last-Modified without reviews, got %d %q", w.Code, w.Header().Get("Last-Modified"))
+	}
+}
+
+func TestConditionalGet_ReviewListETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reviewsUpdated := time.Date(2024, 5, 2, 8, 30, 15, 0, time.UTC)
+	movies, _, _ := newMHRepos()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat", ReviewsUpdatedAt: reviewsUpdated})
+	reviews := testutil.NewMemReviewRepo()
+	reviews.Add(&models.Review{MovieID: 1, UserID: 1, Rating: 8, UpdatedAt: reviewsUpdated.Add(-time.Hour)})
+	reviews.Add(&models.Review{MovieID: 1, UserID: 2, Rating: 6, UpdatedAt: reviewsUpdated.Add(-time.Hour)})
+
+	router := gin.New()
+	router.GET("/movies/:id/reviews", NewReviewHandler(service.NewReviewService(reviews, movies, validator.New(), nil)).ListByMovie)
+
+	get := func(target string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("/movies/1/reviews?page=1&limit=1").Header().Get("ETag")
+	second := get("/movies/1/reviews?page=2&limit=1").Header().Get("ETag")
+	if first == "" || first == second {
+		t.Fatalf("expected distinct ETags per query, got %q and %q", first, second)
+	}
+	if w := get("/movies/1/reviews?page=1&limit=1", "If-None-Match", first); w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for a matching ETag, got %d", w.Code)
+	}
+	if w := get("/movies/1/reviews?page=1&limit=1", "If-None-Match", second); w.Code != http.StatusOK {
+		t.Fatalf("expected another page's ETag not to validate, got %d", w.Code)
+	}
+
+	// Deleting a review on page 2 leaves page 1's rows untouched, but the
+	// movie's timestamp still moves, so page 1 must not be served stale.
+	if err := reviews.Delete(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	movies.Add(&models.Movie{ID: 1, Title: "Heat", ReviewsUpdatedAt: reviewsUpdated.Add(time.Minute)})
+	if w := get("/movies/1/reviews?page=1&limit=1", "If-None-Match", first); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after a review changed, got %d", w.Code)
+	}
+	if w := get("/movies/1/reviews?page=1&limit=1", "If-Modified-Since", reviewsUpdated.Format(http.TimeFormat)); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a date before the change, got %d", w.Code)
+	}
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/csv"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/models"
+)
+
+// csvPageSize is how many rows a CSV export fetches per query. Exports cover
+// every matching row, so they page through the results instead of loading
+// them at once.
+const csvPageSize = 500
+
+// wantsCSV reports whether the client asked for CSV with ?format=csv or an
+// Accept header listing text/csv.
+func wantsCSV(c *gin.Context) bool {
+	if format := c.Query("format"); format != "" {
+		return format == "csv"
+	}
+	for _, part := range strings.Split(c.GetHeader("Accept"), ",") {
+		if mt, _, err := mime.ParseMediaType(strings.TrimSpace(part)); err == nil && mt == "text/csv" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCSV streams header and the rows returned by next, page by page
+// starting at 1, until next reports there are no more. The first page is
+// fetched before anything is written so that an early failure still gets
+// the usual JSON error; later failures can only cut the download short.
+func writeCSV(c *gin.Context, filename string, header []string, next func(page int) (rows [][]string, more bool, err error)) {
+	rows, more, err := next(1)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(header)
+	for page := 1; ; page++ {
+		if page > 1 {
+			if rows, more, err = next(page); err != nil {
+				log.Printf("%s %s: csv export: %v", c.Request.Method, c.FullPath(), err)
+				break
+			}
+		}
+		_ = w.WriteAll(rows)
+		if !more {
+			break
+		}
+	}
+	w.Flush()
+}
+
+var movieCSVHeader = []string{"id", "title", "description", "release_year", "director", "duration_minutes", "average_rating", "normalized_average_rating", "genres", "created_at"}
+
+func movieCSVRow(m models.Movie) []string {
+	genres := make([]string, len(m.Genres))
+	for i, g := range m.Genres {
+		genres[i] = g.Name
+	}
+	return []string{
+		strconv.Itoa(m.ID),
+		csvText(m.Title),
+		csvText(m.Description),
+		strconv.Itoa(m.ReleaseYear),
+		csvText(m.Director),
+		strconv.Itoa(m.DurationMinutes),
+		strconv.FormatFloat(m.AverageRating, 'f', -1, 64),
+		strconv.FormatFloat(m.NormalizedAverageRating, 'f', -1, 64),
+		csvText(strings.Join(genres, ";")),
+		m.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+var userCSVHeader = []string{"id", "email", "username", "role", "created_at"}
+
+func userCSVRow(u models.User) []string {
+	return []string{strconv.Itoa(u.ID), csvText(u.Email), csvText(u.Username), u.Role, u.CreatedAt.Format(time.RFC3339)}
+}
+
+var reviewCSVHeader = []string{"id", "movie_id", "user_id", "rating", "title", "content", "created_at"}
+
+func reviewCSVRow(r models.Review) []string {
+	return []string{
+		strconv.Itoa(r.ID),
+		strconv.Itoa(r.MovieID),
+		strconv.Itoa(r.UserID),
+		strconv.Itoa(r.Rating),
+		csvText(r.Title),
+		csvText(r.Content),
+		r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// csvText neutralises user-supplied text that a spreadsheet would run as a
+// formula: a cell starting with =, +, -, @, tab or carriage return gets a
+// leading apostrophe, which spreadsheets show as plain text.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// csvRows converts one page of items with row.
+func csvRows[T any](items []T, row func(T) []string) [][]string {
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		rows[i] = row(item)
+	}
+	return rows
+}
+
+// writeReviewsCSV exports the reviews returned by list, which takes the
+// same page and limit arguments as the ReviewService list methods.
+func writeReviewsCSV(c *gin.Context, filename string, list func(page, limit int) ([]models.Review, error)) {
+	writeCSV(c, filename, reviewCSVHeader, func(page int) ([][]string, bool, error) {
+		reviews, err := list(page, csvPageSize)
+		if err != nil {
+			return nil, false, err
+		}
+		return csvRows(reviews, reviewCSVRow), len(reviews) == csvPageSize, nil
+	})
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+// getCSV requests target as CSV, either with ?format=csv or through the
+// Accept header, and returns the data rows without the header.
+func getCSV(t *testing.T, router http.Handler, target string, viaAccept bool) [][]string {
+	t.Helper()
+	if !viaAccept {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + "format=csv"
+	}
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if viaAccept {
+		req.Header.Set("Accept", "text/csv")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: expected 200, got %d: %s", target, w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("%s: expected text/csv, got %q", target, ct)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("%s: parse csv: %v", target, err)
+	}
+	if len(records) == 0 {
+		t.Fatalf("%s: missing header row", target)
+	}
+	return records[1:]
+}
+
+func getJSON(t *testing.T, router http.Handler, target string, out any) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: expected 200, got %d: %s", target, w.Code, w.Body)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+		t.Fatalf("%s: parse response: %v", target, err)
+	}
+}
+
+func TestCSV_Movies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies, genres, dramaID := newMHRepos()
+	for i := 1; i <= csvPageSize+20; i++ {
+		year := 1990 + i%3
+		movies.Add(&models.Movie{ID: i, Title: fmt.Sprintf("Movie %d", i), ReleaseYear: year}, dramaID)
+	}
+	movies.Add(&models.Movie{ID: 9999, Title: `Say "hi", then leave`, ReleaseYear: 1990})
+	router := gin.New()
+	router.GET("/movies", NewMovieHandler(service.NewMovieService(movies, genres, validator.New())).List)
+
+	for _, query := range []string{"", "?year=1990", "?genre_id=1&limit=5", "?search=hi"} {
+		var resp struct {
+			Total int `json:"total"`
+		}
+		getJSON(t, router, "/movies"+query, &resp)
+		for _, viaAccept := range []bool{false, true} {
+			if rows := getCSV(t, router, "/movies"+query, viaAccept); len(rows) != resp.Total {
+				t.Fatalf("%q (accept=%v): expected %d rows, got %d", query, viaAccept, resp.Total, len(rows))
+			}
+		}
+	}
+
+	rows := getCSV(t, router, "/movies?search=hi", false)
+	if rows[0][1] != `Say "hi", then leave` {
+		t.Fatalf("title was not escaped correctly: %q", rows[0][1])
+	}
+}
+
+func TestCSV_MovieRowJoinsGenres(t *testing.T) {
+	row := movieCSVRow(models.Movie{ID: 1, Title: "Heat", Genres: []models.Genre{{Name: "Crime"}, {Name: "Drama"}}})
+	if got := row[8]; got != "Crime;Drama" {
+		t.Fatalf("expected genres joined by ;, got %q", got)
+	}
+}
+
+func TestCSV_NeutralisesFormulas(t *testing.T) {
+	row := reviewCSVRow(models.Review{ID: 1, MovieID: 2, UserID: 3, Rating: 8, Title: "=HYPERLINK(\"http://evil\")", Content: "@SUM(A1)"})
+	if row[4] != `'=HYPERLINK("http://evil")` || row[5] != "'@SUM(A1)" {
+		t.Fatalf("expected formula cells to be prefixed, got %q and %q", row[4], row[5])
+	}
+	for in, want := range map[string]string{
+		"+1":         "'+1",
+		"-2+3":       "'-2+3",
+		"\tcmd":      "'\tcmd",
+		"Heat":       "Heat",
+		"1 = 1":      "1 = 1",
+		"":           "",
+		"e@mail.com": "e@mail.com",
+	} {
+		if got := csvText(in); got != want {
+			t.Fatalf("csvText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCSV_UsersAndReviews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	for i := 1; i <= 30; i++ {
+		role := "user"
+		if i%4 == 0 {
+			role = "admin"
+		}
+		repos.users.Add(&models.User{Email: fmt.Sprintf("u%d@example.com", i), Username: fmt.Sprintf("user%d", i), Role: role})
+		repos.reviews.Add(&models.Review{MovieID: 1, UserID: i, Rating: 1 + i%10, Title: "t", Content: "line one\nline two"})
+	}
+	reviews := NewReviewHandler(service.NewReviewService(repos.reviews, testutil.NewMemMovieRepo(), validator.New(), nil))
+
+	router := gin.New()
+	router.GET("/users", h.ListUsers)
+	router.GET("/users/:id/reviews", h.UserReviews)
+	router.GET("/movies/:id/reviews", reviews.ListByMovie)
+
+	for _, query := range []string{"", "?role=admin", "?search=user1"} {
+		var resp struct {
+			Total int `json:"total"`
+		}
+		getJSON(t, router, "/users"+query, &resp)
+		if rows := getCSV(t, router, "/users"+query, false); len(rows) != resp.Total {
+			t.Fatalf("users%s: expected %d rows, got %d", query, resp.Total, len(rows))
+		}
+	}
+
+	for _, target := range []string{"/movies/1/reviews?limit=100", "/movies/1/reviews?min_rating=5&limit=100", "/users/3/reviews"} {
+		var resp struct {
+			Data []models.Review `json:"data"`
+		}
+		getJSON(t, router, target, &resp)
+		rows := getCSV(t, router, target, true)
+		if len(rows) != len(resp.Data) {
+			t.Fatalf("%s: expected %d rows, got %d", target, len(resp.Data), len(rows))
+		}
+		if len(rows) > 0 && rows[0][5] != "line one\nline two" {
+			t.Fatalf("%s: content was not escaped correctly: %q", target, rows[0][5])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"expvar"
+	"net/http/pprof"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/middleware"
+	"golang-project/internal/service"
+	"golang-project/internal/version"
+	"golang-project/pkg/jwt"
+)
+
+// expvar names are process-wide, so the vars are published once and read
+// whatever the last registered router was given.
+var (
+	debugVarsOnce sync.Once
+	debugVarsM    sync.RWMutex
+	reviewEvents  chan service.ReviewEvent
+	reviewWorker  *service.ReviewWorker
+)
+
+// RegisterDebugRoutes mounts pprof and expvar handlers under /debug.
+// The routes are restricted to admins; SetupRoutes registers them only when
+// profiling is enabled in the configuration. worker may be nil when no
+// review worker runs.
+func RegisterDebugRoutes(r *gin.Engine, jwtKeys *jwt.KeySet, events chan service.ReviewEvent, worker *service.ReviewWorker) {
+	debugVarsM.Lock()
+	reviewEvents, reviewWorker = events, worker
+	debugVarsM.Unlock()
+
+	debugVarsOnce.Do(func() {
+		expvar.NewString("version").Set(version.Version)
+		expvar.Publish("review_events_backlog", expvar.Func(func() any {
+			debugVarsM.RLock()
+			defer debugVarsM.RUnlock()
+			return len(reviewEvents)
+		}))
+		expvar.Publish("review_event_lag_ms", expvar.Func(func() any {
+			debugVarsM.RLock()
+			defer debugVarsM.RUnlock()
+			if reviewWorker == nil {
+				return int64(0)
+			}
+			return reviewWorker.EventLag().Milliseconds()
+		}))
+		expvar.Publish("review_events_stale", expvar.Func(func() any {
+			debugVarsM.RLock()
+			defer debugVarsM.RUnlock()
+			if reviewWorker == nil {
+				return int64(0)
+			}
+			return reviewWorker.StaleEvents()
+		}))
+	})
+
+	debug := r.Group("/debug", middleware.AuthMiddleware(jwtKeys), middleware.RequireRoles("admin"))
+	debug.GET("/vars", gin.WrapH(expvar.Handler()))
+	debug.GET("/pprof/*profile", pprofHandler)
+	debug.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+}
+
+func pprofHandler(c *gin.Context) {
+	switch c.Param("profile") {
+	case "/cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "/profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "/symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "/trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/config"
+	"golang-project/internal/service"
+	"golang-project/pkg/jwt"
+)
+
+func TestDebugRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := "secret"
+
+	adminToken, err := jwt.Generate("1", "admin", secret, time.Hour)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	userToken, err := jwt.Generate("2", "user", secret, time.Hour)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	do := func(r *gin.Engine, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// sql.Open does not connect, which is enough for wiring repositories.
+	db, err := sql.Open("postgres", "postgres://localhost/unused?sslmode=disable")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	setup := func(enabled bool, events chan service.ReviewEvent) *gin.Engine {
+		return SetupRoutes(db, &config.Config{
+			JWTKeys:         jwt.StaticKeySet(secret),
+			RateLimit:       60,
+			SearchRateLimit: 20,
+			MaxBodyBytes:    1 << 20,
+			EnablePprof:     enabled,
+		}, events, nil, nil)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		r := setup(false, nil)
+		for _, path := range []string{"/debug/pprof/", "/debug/vars"} {
+			if w := do(r, path, adminToken); w.Code != http.StatusNotFound {
+				t.Fatalf("%s expected 404, got %d", path, w.Code)
+			}
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		events := make(chan service.ReviewEvent, 10)
+		events <- service.ReviewEvent{Type: service.EventReviewCreated}
+		r := setup(true, events)
+
+		if w := do(r, "/debug/pprof/", adminToken); w.Code != http.StatusOK {
+			t.Fatalf("pprof index expected 200, got %d", w.Code)
+		}
+
+		w := do(r, "/debug/vars", adminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("vars expected 200, got %d", w.Code)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, `"version"`) || !strings.Contains(body, `"review_events_backlog": 1`) {
+			t.Fatalf("expected version and backlog vars, got %s", body)
+		}
+
+		if w := do(r, "/debug/vars", userToken); w.Code != http.StatusForbidden {
+			t.Fatalf("vars as user expected 403, got %d", w.Code)
+		}
+		if w := do(r, "/debug/vars", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("vars without token expected 401, got %d", w.Code)
+		}
+	})
+}
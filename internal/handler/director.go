@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/service"
+)
+
+type DirectorHandler struct {
+	movies *service.MovieService
+}
+
+func NewDirectorHandler(movies *service.MovieService) *DirectorHandler {
+	return &DirectorHandler{movies: movies}
+}
+
+// List returns directors and their movie counts for autocomplete, optionally
+// narrowed to names containing ?q=.
+func (h *DirectorHandler) List(c *gin.Context) {
+	limit, err := parseLimit(c, 10)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	directors, err := h.movies.ListDirectors(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": directors})
+}
+
+// Movies lists a director's filmography. The name comes URL-encoded in the
+// path (/directors/Christopher%20Nolan/movies) and is matched case-insensitively.
+func (h *DirectorHandler) Movies(c *gin.Context) {
+	pagination, err := ParsePagination(c, 10)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp, err := h.movies.GetDirectorFilmography(c.Request.Context(), c.Param("name"), pagination.Page, pagination.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondPage(c, resp)
+}
+
+// GetStats summarizes a director's movies for their profile page.
+func (h *DirectorHandler) GetStats(c *gin.Context) {
+	stats, err := h.movies.GetDirectorStats(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// Similar lists directors whose movies were reviewed by the same people as
+// the named director's.
+func (h *DirectorHandler) Similar(c *gin.Context) {
+	limit, err := parseLimit(c, 10)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	directors, err := h.movies.FindSimilarDirectors(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": directors})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/database"
+	"golang-project/internal/i18n"
+	"golang-project/internal/service"
+)
+
+// Errors raised by the handlers themselves, before a service is involved.
+var (
+	errInvalidID          = apierror.New(http.StatusBadRequest, "invalid_id", "invalid id")
+	errInvalidRequest     = apierror.New(http.StatusBadRequest, "invalid_request", "invalid request")
+	errInvalidRating      = apierror.New(http.StatusBadRequest, "invalid_rating", "rating must be between 1 and 10")
+	errInvalidReviewRange = apierror.New(http.StatusBadRequest, "invalid_review_range", "min_reviews and max_reviews must be non-negative integers, min_reviews not above max_reviews")
+	errInvalidUser        = apierror.New(http.StatusUnauthorized, "unauthorized", "invalid user")
+	errNotOwner           = apierror.New(http.StatusForbidden, "forbidden", "forbidden")
+	errReviewsPrivate     = apierror.New(http.StatusForbidden, "reviews_private", "this user's reviews are private")
+	errUnknownGenre       = apierror.New(http.StatusBadRequest, "unknown_genre", "genre not found")
+	errWrongPassword      = apierror.New(http.StatusUnauthorized, "invalid_current_password", "invalid current password")
+	errInternal           = apierror.New(http.StatusInternalServerError, "internal_error", "internal server error")
+	errUnavailable        = apierror.New(http.StatusServiceUnavailable, "service_unavailable", "service temporarily unavailable")
+)
+
+// serviceErrors maps service sentinel errors to their HTTP representation.
+// Entries are matched with errors.Is, so wrapped sentinels resolve too.
+var serviceErrors = []struct {
+	err error
+	api *apierror.Error
+}{
+	{service.ErrUserNotFound, apierror.New(http.StatusNotFound, "user_not_found", "user not found")},
+	{service.ErrUserExists, apierror.New(http.StatusConflict, "user_exists", "email or username already exists")},
+	{service.ErrInvalidCredentials, apierror.New(http.StatusUnauthorized, "invalid_credentials", "invalid credentials")},
+	{service.ErrInvalidRole, apierror.New(http.StatusBadRequest, "invalid_role", "invalid role")},
+	{service.ErrCannotDeleteSelf, apierror.New(http.StatusBadRequest, "cannot_delete_self", "cannot delete yourself")},
+	{service.ErrCannotMergeSelf, apierror.New(http.StatusBadRequest, "cannot_merge_self", service.ErrCannotMergeSelf.Error())},
+	{service.ErrAccountMergeDisabled, apierror.New(http.StatusServiceUnavailable, "account_merge_disabled", service.ErrAccountMergeDisabled.Error())},
+	{service.ErrCannotImpersonateSelf, apierror.New(http.StatusBadRequest, "cannot_impersonate_self", "cannot impersonate yourself")},
+	{service.ErrCannotImpersonateAdmin, apierror.New(http.StatusForbidden, "cannot_impersonate_admin", "cannot impersonate another admin")},
+	{service.ErrCannotUpdateSelf, apierror.New(http.StatusBadRequest, "cannot_update_self", "cannot change your own role")},
+	{service.ErrNoUserIDs, apierror.New(http.StatusBadRequest, "user_ids_required", "user_ids required")},
+	{service.ErrUserLookupQuery, apierror.New(http.StatusBadRequest, "invalid_lookup", service.ErrUserLookupQuery.Error())},
+	{service.ErrInvalidAuditSort, apierror.New(http.StatusBadRequest, "invalid_sort", service.ErrInvalidAuditSort.Error())},
+	{service.ErrTooManyUserIDs, apierror.New(http.StatusBadRequest, "too_many_user_ids", service.ErrTooManyUserIDs.Error())},
+	{service.ErrGenreNotFound, apierror.New(http.StatusNotFound, "genre_not_found", "genre not found")},
+	{service.ErrGenreExists, apierror.New(http.StatusConflict, "genre_exists", "genre already exists")},
+	{service.ErrNoGenresToImport, apierror.New(http.StatusBadRequest, "genres_required", "genres required")},
+	{service.ErrTooManyGenres, apierror.New(http.StatusBadRequest, "too_many_genres", service.ErrTooManyGenres.Error())},
+	{service.ErrMovieNotFound, apierror.New(http.StatusNotFound, "movie_not_found", "movie not found")},
+	{service.ErrInvalidReaction, apierror.New(http.StatusBadRequest, "invalid_reaction", service.ErrInvalidReaction.Error())},
+	{service.ErrReactionNotFound, apierror.New(http.StatusNotFound, "reaction_not_found", "reaction not found")},
+	{service.ErrMovieVersionNotFound, apierror.New(http.StatusNotFound, "movie_version_not_found", "movie version not found")},
+	{service.ErrDirectorNotFound, apierror.New(http.StatusNotFound, "director_not_found", "director not found")},
+	{service.ErrMovieGone, apierror.New(http.StatusGone, "movie_gone", "movie has been deleted")},
+	{service.ErrMovieExists, apierror.New(http.StatusConflict, "movie_exists", "movie with this title and release year already exists")},
+	{service.ErrNoGenresProvided, apierror.New(http.StatusBadRequest, "genre_ids_required", "genre_ids required")},
+	{service.ErrTooManyDirectors, apierror.New(http.StatusBadRequest, "too_many_directors", service.ErrTooManyDirectors.Error())},
+	{service.ErrPosterTooLarge, apierror.New(http.StatusRequestEntityTooLarge, "poster_too_large", service.ErrPosterTooLarge.Error())},
+	{service.ErrUnsupportedPosterType, apierror.New(http.StatusUnsupportedMediaType, "unsupported_poster_type", service.ErrUnsupportedPosterType.Error())},
+	{service.ErrPostersDisabled, apierror.New(http.StatusServiceUnavailable, "posters_disabled", service.ErrPostersDisabled.Error())},
+	{service.ErrTooManyMovieIDs, apierror.New(http.StatusBadRequest, "too_many_ids", service.ErrTooManyMovieIDs.Error())},
+	{service.ErrReviewNotFound, apierror.New(http.StatusNotFound, "review_not_found", "review not found")},
+	{service.ErrNoReviewIDs, apierror.New(http.StatusBadRequest, "review_ids_required", "review_ids required")},
+	{service.ErrTooManyReviewIDs, apierror.New(http.StatusBadRequest, "too_many_review_ids", service.ErrTooManyReviewIDs.Error())},
+	{service.ErrContentBlocked, apierror.New(http.StatusUnprocessableEntity, "content_blocked", service.ErrContentBlocked.Error())},
+	{service.ErrBlockedKeywordNotFound, apierror.New(http.StatusNotFound, "blocked_keyword_not_found", "blocked keyword not found")},
+	{service.ErrSearchQueryTooShort, apierror.New(http.StatusBadRequest, "search_query_too_short", service.ErrSearchQueryTooShort.Error())},
+	{service.ErrWatchNotFound, apierror.New(http.StatusNotFound, "watch_not_found", "watch history entry not found")},
+	{service.ErrSitemapNotFound, apierror.New(http.StatusNotFound, "sitemap_not_found", "sitemap not found")},
+	{service.ErrYearNotFound, apierror.New(http.StatusNotFound, "year_not_found", service.ErrYearNotFound.Error())},
+	{service.ErrReviewExists, apierror.New(http.StatusConflict, "review_exists", "review already exists")},
+	{service.ErrEditWindowClosed, apierror.New(http.StatusForbidden, "edit_window_closed", service.ErrEditWindowClosed.Error())},
+	{service.ErrRecalculationRunning, apierror.New(http.StatusConflict, "recalculation_running", "recalculation already running")},
+}
+
+// toAPIError resolves err to the status and code it is reported with.
+// Anything unrecognised becomes a 500 without leaking the original message.
+func toAPIError(err error) *apierror.Error {
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	for _, e := range serviceErrors {
+		if errors.Is(err, e.err) {
+			return e.api
+		}
+	}
+	var embargoed service.ErrReviewEmbargoed
+	if errors.As(err, &embargoed) {
+		return apierror.New(http.StatusForbidden, "review_embargoed", embargoed.Error()).
+			WithDetails(map[string]any{"embargo_until": embargoed.Until.Format(time.RFC3339)})
+	}
+	var similar service.ErrSimilarReviewExists
+	if errors.As(err, &similar) {
+		return apierror.New(http.StatusConflict, "similar_review_exists", similar.Error()).
+			WithDetails(map[string]any{"existing_id": similar.ExistingID})
+	}
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		return apierror.New(http.StatusBadRequest, "validation_failed", ve.Error())
+	}
+	if database.IsTransient(err) {
+		return errUnavailable
+	}
+	return errInternal
+}
+
+// respondError writes err using the shared error envelope and aborts the
+// request. err is also recorded on the context for middleware such as
+// RetryOnTransient.
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	apiErr := localizeValidation(c.Request.Context(), err, toAPIError(err))
+	if apiErr.Status >= http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request.Method, c.FullPath(), err)
+	}
+	apierror.Abort(c, apiErr)
+}
+
+// localizeValidation replaces the validator's raw text with per-field
+// messages in the request's language. Other errors are translated by code
+// when they are written.
+func localizeValidation(ctx context.Context, err error, apiErr *apierror.Error) *apierror.Error {
+	var ve validator.ValidationErrors
+	if apiErr.Code != "validation_failed" || !errors.As(err, &ve) {
+		return apiErr
+	}
+	cp := *apiErr
+	cp.Message = i18n.ValidationMessage(i18n.FromContext(ctx), ve)
+	return &cp
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/i18n"
+	"golang-project/internal/middleware"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+)
+
+func TestRespondError_Mapping(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validationErr := validator.New().Struct(struct {
+		Name string `validate:"required"`
+	}{})
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"movie not found", service.ErrMovieNotFound, http.StatusNotFound, "movie_not_found"},
+		{"director not found", service.ErrDirectorNotFound, http.StatusNotFound, "director_not_found"},
+		{"movie gone", service.ErrMovieGone, http.StatusGone, "movie_gone"},
+		{"movie exists", service.ErrMovieExists, http.StatusConflict, "movie_exists"},
+		{"no genres", service.ErrNoGenresProvided, http.StatusBadRequest, "genre_ids_required"},
+		{"too many directors", service.ErrTooManyDirectors, http.StatusBadRequest, "too_many_directors"},
+		{"too many ids", service.ErrTooManyMovieIDs, http.StatusBadRequest, "too_many_ids"},
+		{"genre not found", service.ErrGenreNotFound, http.StatusNotFound, "genre_not_found"},
+		{"genre exists", service.ErrGenreExists, http.StatusConflict, "genre_exists"},
+		{"user not found", service.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+		{"user exists", service.ErrUserExists, http.StatusConflict, "user_exists"},
+		{"invalid credentials", service.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+		{"invalid role", service.ErrInvalidRole, http.StatusBadRequest, "invalid_role"},
+		{"delete self", service.ErrCannotDeleteSelf, http.StatusBadRequest, "cannot_delete_self"},
+		{"review not found", service.ErrReviewNotFound, http.StatusNotFound, "review_not_found"},
+		{"review exists", service.ErrReviewExists, http.StatusConflict, "review_exists"},
+		{"recalculation running", service.ErrRecalculationRunning, http.StatusConflict, "recalculation_running"},
+		{"wrapped sentinel", fmt.Errorf("get movie: %w", service.ErrMovieNotFound), http.StatusNotFound, "movie_not_found"},
+		{"embargoed", service.ErrReviewEmbargoed{Until: time.Now()}, http.StatusForbidden, "review_embargoed"},
+		{"similar review", service.ErrSimilarReviewExists{ExistingID: 7}, http.StatusConflict, "similar_review_exists"},
+		{"validation", validationErr, http.StatusBadRequest, "validation_failed"},
+		{"handler error", errInvalidID, http.StatusBadRequest, "invalid_id"},
+		{"not owner", ownershipError(service.ErrInvalidCredentials), http.StatusForbidden, "forbidden"},
+		{"unknown genre in movie", genreRefError(service.ErrGenreNotFound), http.StatusBadRequest, "unknown_genre"},
+		{"unexpected", errors.New("pq: connection refused"), http.StatusInternalServerError, "internal_error"},
+		{"transient database error", errors.New("pq: connection pool exhausted"), http.StatusServiceUnavailable, "service_unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Writer.Header().Set(apierror.RequestIDHeader, "req-7")
+
+			respondError(c, tt.err)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, w.Code)
+			}
+			var resp apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("parse response: %v", err)
+			}
+			if resp.Error.Code != tt.code {
+				t.Fatalf("expected code %q, got %q", tt.code, resp.Error.Code)
+			}
+			if resp.Error.Message == "" || resp.Error.RequestID != "req-7" {
+				t.Fatalf("expected message and request id, got %+v", resp.Error)
+			}
+			if tt.status == http.StatusInternalServerError && resp.Error.Message != "internal server error" {
+				t.Fatalf("internal error message leaked: %q", resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestRespondError_Localized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	v := validator.New()
+	if err := i18n.RegisterValidator(v); err != nil {
+		t.Fatalf("register translations: %v", err)
+	}
+	validationErr := v.Struct(models.CreateReviewRequest{Rating: 11, Title: "t", Content: "c"})
+
+	tests := []struct {
+		name, acceptLanguage string
+		err                  error
+		code, message        string
+	}{
+		{"english", "en-US,en;q=0.9", service.ErrMovieNotFound, "movie_not_found", "movie not found"},
+		{"russian", "ru-RU,ru;q=0.9,en;q=0.8", service.ErrMovieNotFound, "movie_not_found", "фильм не найден"},
+		{"unsupported falls back to english", "fr-FR", service.ErrMovieNotFound, "movie_not_found", "movie not found"},
+		{"no header", "", errInvalidID, "invalid_id", "invalid id"},
+		{"russian with details", "ru", unknownField("budget"), "unknown_field", "неизвестное поле: budget"},
+		{"english validation", "en", validationErr, "validation_failed", "Rating must be 10 or less"},
+		{"russian validation", "ru", validationErr, "validation_failed", "Rating должен быть меньше или равно 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(middleware.Locale())
+			router.GET("/", func(c *gin.Context) { respondError(c, tt.err) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("parse response: %v", err)
+			}
+			if resp.Error.Code != tt.code || resp.Error.Message != tt.message {
+				t.Fatalf("expected %s %q, got %s %q", tt.code, tt.message, resp.Error.Code, resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestErrorCodes_Translated(t *testing.T) {
+	apiErrors := []*apierror.Error{
+		errInvalidID, errInvalidRequest, errInvalidRating, errInvalidReviewRange, errInvalidUser, errNotOwner,
+		errReviewsPrivate, errUnknownGenre, errWrongPassword, errInternal, errUnavailable,
+		errFileRequired, errUnreadableFile,
+	}
+	for _, e := range serviceErrors {
+		apiErrors = append(apiErrors, e.api)
+	}
+	for _, e := range apiErrors {
+		if i18n.Message(i18n.Russian, e.Code, "", nil) == "" {
+			t.Errorf("no %s message for %s", i18n.Russian, e.Code)
+		}
+	}
+}
+
+func TestRespondError_ProblemJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/movies/:id", func(c *gin.Context) { respondError(c, service.ErrMovieNotFound) })
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/movies/7", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("%q: expected 404, got %d", accept, w.Code)
+		}
+		return w
+	}
+
+	for _, accept := range []string{"", "application/json", "*/*"} {
+		w := get(accept)
+		if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Fatalf("%q: expected the default JSON content type, got %q", accept, ct)
+		}
+		var resp apierror.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		if resp.Error.Code != "movie_not_found" {
+			t.Fatalf("%q: expected the error envelope, got %s", accept, w.Body)
+		}
+	}
+
+	for _, accept := range []string{"application/problem+json", "application/json;q=0.5, application/problem+json"} {
+		w := get(accept)
+		if ct := w.Header().Get("Content-Type"); ct != apierror.ProblemContentType {
+			t.Fatalf("%q: expected %s, got %q", accept, apierror.ProblemContentType, ct)
+		}
+		var problem apierror.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		want := apierror.Problem{
+			Type:     "about:blank",
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "movie not found",
+			Instance: "/movies/7",
+			Code:     "movie_not_found",
+		}
+		if !reflect.DeepEqual(problem, want) {
+			t.Fatalf("%q: expected %+v, got %+v", accept, want, problem)
+		}
+	}
+}
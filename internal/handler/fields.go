@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/apierror"
+)
+
+// fieldSelection is a parsed ?fields= value. Keys are top-level JSON names;
+// a non-nil value restricts a nested object (or array of objects) to those
+// names, so "genres.name" keeps only the name of each genre.
+type fieldSelection map[string]map[string]bool
+
+// parseFields reads ?fields= and validates every name against the JSON
+// shape of model. It returns nil when the parameter is absent.
+func parseFields(c *gin.Context, model any) (fieldSelection, error) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, nil
+	}
+	known := jsonFields(reflect.TypeOf(model))
+	sel := make(fieldSelection)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		top, sub, nested := strings.Cut(name, ".")
+		typ, ok := known[top]
+		if !ok {
+			return nil, unknownField(name)
+		}
+		if !nested {
+			sel[top] = nil
+			continue
+		}
+		if _, ok := jsonFields(typ)[sub]; !ok {
+			return nil, unknownField(name)
+		}
+		if existing, ok := sel[top]; ok && existing == nil {
+			continue
+		}
+		if sel[top] == nil {
+			sel[top] = make(map[string]bool)
+		}
+		sel[top][sub] = true
+	}
+	if len(sel) == 0 {
+		return nil, apierror.New(http.StatusBadRequest, "invalid_fields", "fields must name at least one field")
+	}
+	return sel, nil
+}
+
+func unknownField(name string) error {
+	return apierror.New(http.StatusBadRequest, "unknown_field", "unknown field: "+name).
+		WithDetails(map[string]any{"field": name})
+}
+
+// jsonFields maps the JSON names of a struct's fields to their types,
+// following pointers, slices and embedded structs. Non-structs have none.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	fields := make(map[string]reflect.Type)
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			for k, v := range jsonFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// project serialises v and keeps only the selected fields. Objects inside
+// arrays are projected element by element. Unselected fields are removed,
+// not nulled.
+func project(v any, sel fieldSelection) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	return sel.apply(decoded), nil
+}
+
+func (sel fieldSelection) apply(v any) any {
+	switch v := v.(type) {
+	case []any:
+		for i := range v {
+			v[i] = sel.apply(v[i])
+		}
+		return v
+	case map[string]any:
+		for name, value := range v {
+			sub, ok := sel[name]
+			if !ok {
+				delete(v, name)
+				continue
+			}
+			if sub != nil {
+				v[name] = keepOnly(value, sub)
+			}
+		}
+		return v
+	}
+	return v
+}
+
+func keepOnly(v any, names map[string]bool) any {
+	switch v := v.(type) {
+	case []any:
+		for i := range v {
+			v[i] = keepOnly(v[i], names)
+		}
+	case map[string]any:
+		for name := range v {
+			if !names[name] {
+				delete(v, name)
+			}
+		}
+	}
+	return v
+}
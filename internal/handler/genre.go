@@ -21,7 +21,7 @@ func NewGenreHandler(s *service.GenreService) *GenreHandler {
 func (h *GenreHandler) List(c *gin.Context) {
 	genres, err := h.service.List(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list genres"})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": genres})
@@ -31,16 +31,17 @@ func (h *GenreHandler) Get(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondError(c, errInvalidID)
 		return
 	}
-	genre, err := h.service.Get(c.Request.Context(), id)
+	var genre interface{}
+	if c.Query("with_stats") == "true" {
+		genre, err = h.service.GetWithStats(c.Request.Context(), id)
+	} else {
+		genre, err = h.service.Get(c.Request.Context(), id)
+	}
 	if err != nil {
-		if err == service.ErrGenreNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "genre not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get genre"})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, genre)
@@ -49,44 +50,48 @@ func (h *GenreHandler) Get(c *gin.Context) {
 func (h *GenreHandler) Create(c *gin.Context) {
 	var req models.CreateGenreRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 	genre, err := h.service.Create(c.Request.Context(), req)
 	if err != nil {
-		switch err {
-		case service.ErrGenreExists:
-			c.JSON(http.StatusConflict, gin.H{"error": "genre already exists"})
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, genre)
 }
 
+// Import creates genres in bulk from {"genres":[{"name":...,"parent":...}]}.
+// Items that cannot be imported are listed in the response; the rest still are.
+func (h *GenreHandler) Import(c *gin.Context) {
+	var req models.ImportGenresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+	result, err := h.service.BulkCreate(c.Request.Context(), req.Genres)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *GenreHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondError(c, errInvalidID)
 		return
 	}
 	var req models.CreateGenreRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 	genre, err := h.service.Update(c.Request.Context(), id, req)
 	if err != nil {
-		switch err {
-		case service.ErrGenreNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "genre not found"})
-		case service.ErrGenreExists:
-			c.JSON(http.StatusConflict, gin.H{"error": "genre already exists"})
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, genre)
@@ -96,16 +101,13 @@ func (h *GenreHandler) Delete(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondError(c, errInvalidID)
 		return
 	}
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		if err == service.ErrGenreNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "genre not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete genre"})
+	genre, err := h.service.Delete(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	c.Status(http.StatusNoContent)
+	respondDeleted(c, genre)
 }
@@ -2,8 +2,6 @@ package handler
 
 import (
 	"bytes"
-	"context"
-	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -15,74 +13,19 @@ import (
 
 	"golang-project/internal/models"
 	"golang-project/internal/service"
+	"golang-project/internal/testutil"
 )
 
-// in-memory repo for handler tests
-type ghRepo struct {
-	data map[int]*models.Genre
-}
-
-func newGHRepo() *ghRepo {
-	return &ghRepo{data: make(map[int]*models.Genre)}
-}
-
-func (r *ghRepo) GetAll(ctx context.Context) ([]models.Genre, error) {
-	result := make([]models.Genre, 0, len(r.data))
-	for _, g := range r.data {
-		result = append(result, *g)
-	}
-	return result, nil
-}
-
-func (r *ghRepo) GetByID(ctx context.Context, id int) (*models.Genre, error) {
-	if g, ok := r.data[id]; ok {
-		return g, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *ghRepo) GetByName(ctx context.Context, name string) (*models.Genre, error) {
-	for _, g := range r.data {
-		if g.Name == name {
-			return g, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *ghRepo) Create(ctx context.Context, genre *models.Genre) error {
-	genre.ID = len(r.data) + 1
-	genre.CreatedAt = time.Now()
-	r.data[genre.ID] = genre
-	return nil
-}
-
-func (r *ghRepo) Update(ctx context.Context, genre *models.Genre) error {
-	if _, ok := r.data[genre.ID]; !ok {
-		return sql.ErrNoRows
-	}
-	r.data[genre.ID] = genre
-	return nil
-}
-
-func (r *ghRepo) Delete(ctx context.Context, id int) error {
-	if _, ok := r.data[id]; !ok {
-		return sql.ErrNoRows
-	}
-	delete(r.data, id)
-	return nil
-}
-
 func TestGenreHandler_CRUD(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	repo := newGHRepo()
+	repo := testutil.NewMemGenreRepo()
 	svc := service.NewGenreService(repo, validator.New())
 	h := NewGenreHandler(svc)
 
 	// seed
 	existing := &models.Genre{ID: 1, Name: "Drama", CreatedAt: time.Now()}
-	repo.data[existing.ID] = existing
+	repo.Add(existing)
 
 	router := gin.New()
 	router.GET("/genres", h.List)
@@ -157,3 +100,85 @@ func TestGenreHandler_CRUD(t *testing.T) {
 		}
 	}
 }
+
+func TestGenreHandler_GetWithStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := testutil.NewMemGenreRepo()
+	repo.Add(&models.Genre{ID: 1, Name: "Drama", CreatedAt: time.Now()})
+	repo.SetStats(1, models.GenreStats{MovieCount: 2, ReviewCount: 5})
+	h := NewGenreHandler(service.NewGenreService(repo, validator.New()))
+
+	router := gin.New()
+	router.GET("/genres/:id", h.Get)
+
+	get := func(path string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s expected 200, got %d", path, w.Code)
+		}
+		var resp map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		return resp
+	}
+
+	withStats := get("/genres/1?with_stats=true")
+	if withStats["movie_count"] != float64(2) || withStats["review_count"] != float64(5) {
+		t.Fatalf("expected stats in response, got %v", withStats)
+	}
+	if withStats["name"] != "Drama" {
+		t.Fatalf("expected genre fields in response, got %v", withStats)
+	}
+
+	plain := get("/genres/1")
+	if _, ok := plain["movie_count"]; ok {
+		t.Fatalf("expected no stats without flag, got %v", plain)
+	}
+}
+
+func TestGenreHandler_DeleteReturnFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := testutil.NewMemGenreRepo()
+	repo.Add(&models.Genre{ID: 7, Name: "Noir"})
+	repo.Add(&models.Genre{ID: 8, Name: "Heist"})
+	h := NewGenreHandler(service.NewGenreService(repo, validator.New()))
+	router := gin.New()
+	router.GET("/genres/:id", h.Get)
+	router.DELETE("/genres/:id", h.Delete)
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	for _, target := range []string{"/genres/7", "/genres/7?return=false"} {
+		repo.Add(&models.Genre{ID: 7, Name: "Noir"})
+		if w := do(http.MethodDelete, target); w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+			t.Fatalf("%s: expected an empty 204, got %d %s", target, w.Code, w.Body)
+		}
+	}
+
+	w := do(http.MethodDelete, "/genres/8?return=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with the deleted genre, got %d %s", w.Code, w.Body)
+	}
+	var deleted models.Genre
+	if err := json.Unmarshal(w.Body.Bytes(), &deleted); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if deleted.ID != 8 || deleted.Name != "Heist" {
+		t.Fatalf("expected genre 8 Heist, got %+v", deleted)
+	}
+	if w := do(http.MethodGet, "/genres/8"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected the genre to be gone, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/genres/8?return=true"); w.Code != http.StatusNotFound {
+		t.Fatalf("deleting again expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	gqlhandler "github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"golang-project/internal/graph"
+	"golang-project/internal/i18n"
+	"golang-project/internal/middleware"
+)
+
+// graphQLComplexityLimit bounds the cost of a single query; each field
+// counts as one, so a page of movies with genres and reviews fits easily.
+const graphQLComplexityLimit = 500
+
+type GraphQLHandler struct {
+	srv *gqlhandler.Server
+}
+
+func NewGraphQLHandler(resolver *graph.Resolver) *GraphQLHandler {
+	srv := gqlhandler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(graphQLComplexityLimit))
+	srv.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		return next(resolver.WithLoaders(ctx))
+	})
+	srv.SetErrorPresenter(presentGraphQLError)
+	return &GraphQLHandler{srv: srv}
+}
+
+// presentGraphQLError reports resolver errors with the same codes as the REST
+// API, under extensions.code. Parse and validation errors keep gqlgen's own.
+func presentGraphQLError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	var wrapped *gqlerror.Error
+	if errors.As(err, &wrapped) && wrapped.Err == nil {
+		return gqlErr
+	}
+	apiErr := localizeValidation(ctx, err, toAPIError(err))
+	if apiErr.Status >= http.StatusInternalServerError {
+		log.Printf("graphql %s: %v", gqlErr.Path, err)
+	}
+	gqlErr.Message = i18n.Message(i18n.FromContext(ctx), apiErr.Code, apiErr.Message, apiErr.Details)
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]any{}
+	}
+	gqlErr.Extensions["code"] = apiErr.Code
+	if apiErr.Details != nil {
+		gqlErr.Extensions["details"] = apiErr.Details
+	}
+	return gqlErr
+}
+
+// Serve handles POST /graphql. Authentication is optional; when a token was
+// accepted the caller is passed on to the resolvers.
+func (h *GraphQLHandler) Serve(c *gin.Context) {
+	ctx := c.Request.Context()
+	if val, ok := c.Get(string(middleware.ContextUserID)); ok {
+		idStr, _ := val.(string)
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			respondError(c, errInvalidUser)
+			return
+		}
+		role, _ := c.Get(string(middleware.ContextRole))
+		ctx = graph.WithCaller(ctx, graph.Caller{UserID: id, Admin: role == "admin"})
+	}
+	h.srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
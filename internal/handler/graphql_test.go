@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/graph"
+	"golang-project/internal/middleware"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+// countingMovieRepo counts batched genre lookups so the test can tell
+// whether the loader collapsed per-movie queries into one.
+type countingMovieRepo struct {
+	*testutil.MemMovieRepo
+	genreBatches atomic.Int32
+}
+
+func (r *countingMovieRepo) GetGenresByMovieIDs(ctx context.Context, movieIDs []int) (map[int][]models.Genre, error) {
+	r.genreBatches.Add(1)
+	return r.MemMovieRepo.GetGenresByMovieIDs(ctx, movieIDs)
+}
+
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newGraphQLRouter(t *testing.T) (*gin.Engine, *countingMovieRepo, *testutil.MemReviewRepo) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	genres := testutil.NewMemGenreRepo()
+	genres.Add(&models.Genre{ID: 1, Name: "Drama"})
+	genres.Add(&models.Genre{ID: 2, Name: "Sci-Fi"})
+	movies := &countingMovieRepo{MemMovieRepo: testutil.NewMemMovieRepo()}
+	movies.Add(&models.Movie{ID: 1, Title: "Solaris", ReleaseYear: 1972}, 1, 2)
+	movies.Add(&models.Movie{ID: 2, Title: "Stalker", ReleaseYear: 1979}, 1)
+	movies.Add(&models.Movie{ID: 3, Title: "Mirror", ReleaseYear: 1975})
+	users := testutil.NewMemUserRepo()
+	users.Add(&models.User{ID: 1, Username: "alice", Email: "alice@example.com", Role: "user"})
+	users.Add(&models.User{ID: 2, Username: "bob", Email: "bob@example.com", Role: "user"})
+	reviews := testutil.NewMemReviewRepo()
+	reviews.Add(&models.Review{ID: 1, MovieID: 1, UserID: 1, Rating: 9, Title: "Great", Content: "..."})
+	reviews.Add(&models.Review{ID: 2, MovieID: 2, UserID: 2, Rating: 7, Title: "Good", Content: "..."})
+
+	v := validator.New()
+	h := NewGraphQLHandler(&graph.Resolver{
+		MovieService:  service.NewMovieService(movies, genres, v),
+		GenreService:  service.NewGenreService(genres, v),
+		ReviewService: service.NewReviewService(reviews, movies, v, nil),
+		UserService:   service.NewUserService(users, reviews, v, nil),
+	})
+
+	router := gin.New()
+	router.POST("/graphql", func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Set(string(middleware.ContextUserID), user)
+			c.Set(string(middleware.ContextRole), "user")
+		}
+	}, h.Serve)
+	return router, movies, reviews
+}
+
+func postGraphQL(t *testing.T, router *gin.Engine, user, query string, vars map[string]any) graphQLResponse {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"query": query, "variables": vars})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp graphQLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	return resp
+}
+
+func TestGraphQL_MoviesWithGenresAndAuthors(t *testing.T) {
+	router, movies, _ := newGraphQLRouter(t)
+
+	resp := postGraphQL(t, router, "", `{
+		movies(limit: 10, sort: "title_asc") {
+			total
+			items { id title genres { name } reviews { rating author { username } } }
+		}
+	}`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+
+	var page struct {
+		Total int `json:"total"`
+		Items []struct {
+			ID      string `json:"id"`
+			Title   string `json:"title"`
+			Genres  []struct{ Name string }
+			Reviews []struct {
+				Rating int
+				Author struct{ Username string }
+			}
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(resp.Data["movies"], &page); err != nil {
+		t.Fatalf("parse movies: %v", err)
+	}
+	if page.Total != 3 || len(page.Items) != 3 {
+		t.Fatalf("expected 3 movies, got %+v", page)
+	}
+	byTitle := make(map[string]int)
+	for i, m := range page.Items {
+		byTitle[m.Title] = i
+	}
+	solaris := page.Items[byTitle["Solaris"]]
+	if len(solaris.Genres) != 2 {
+		t.Fatalf("expected Solaris to have 2 genres, got %+v", solaris.Genres)
+	}
+	if len(solaris.Reviews) != 1 || solaris.Reviews[0].Author.Username != "alice" {
+		t.Fatalf("expected alice's review on Solaris, got %+v", solaris.Reviews)
+	}
+	if mirror := page.Items[byTitle["Mirror"]]; len(mirror.Genres) != 0 || len(mirror.Reviews) != 0 {
+		t.Fatalf("expected Mirror without genres or reviews, got %+v", mirror)
+	}
+	if n := movies.genreBatches.Load(); n != 1 {
+		t.Fatalf("expected genres of all movies in 1 batch, got %d", n)
+	}
+}
+
+func TestGraphQL_QueriesReturnNullWhenMissing(t *testing.T) {
+	router, _, _ := newGraphQLRouter(t)
+
+	resp := postGraphQL(t, router, "", `{ movie(id: 99) { id } review(id: 99) { id } me { id } }`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+	for _, field := range []string{"movie", "review", "me"} {
+		if string(resp.Data[field]) != "null" {
+			t.Fatalf("expected %s to be null, got %s", field, resp.Data[field])
+		}
+	}
+
+	resp = postGraphQL(t, router, "2", `{ me { username email } }`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+	var me struct{ Username, Email string }
+	if err := json.Unmarshal(resp.Data["me"], &me); err != nil || me.Username != "bob" {
+		t.Fatalf("expected bob, got %s", resp.Data["me"])
+	}
+}
+
+func TestGraphQL_ReviewMutations(t *testing.T) {
+	router, _, reviews := newGraphQLRouter(t)
+	create := `mutation($movie: ID!) {
+		createReview(movieId: $movie, input: {rating: 8, title: "Slow", content: "but worth it"}) { id rating author { username } movie { title } }
+	}`
+
+	resp := postGraphQL(t, router, "", create, map[string]any{"movie": 3})
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "unauthorized" {
+		t.Fatalf("expected unauthorized for anonymous caller, got %+v", resp.Errors)
+	}
+
+	resp = postGraphQL(t, router, "1", create, map[string]any{"movie": 3})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+	var created struct {
+		ID     string
+		Author struct{ Username string }
+		Movie  struct{ Title string }
+	}
+	if err := json.Unmarshal(resp.Data["createReview"], &created); err != nil {
+		t.Fatalf("parse review: %v", err)
+	}
+	if created.Author.Username != "alice" || created.Movie.Title != "Mirror" {
+		t.Fatalf("unexpected review: %+v", created)
+	}
+
+	resp = postGraphQL(t, router, "1", create, map[string]any{"movie": 3})
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "review_exists" {
+		t.Fatalf("expected review_exists, got %+v", resp.Errors)
+	}
+
+	update := `mutation($id: ID!) { updateReview(id: $id, input: {rating: 10}) { rating title } }`
+	resp = postGraphQL(t, router, "2", update, map[string]any{"id": created.ID})
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "forbidden" {
+		t.Fatalf("expected forbidden for another user's review, got %+v", resp.Errors)
+	}
+	resp = postGraphQL(t, router, "1", update, map[string]any{"id": created.ID})
+	if len(resp.Errors) > 0 || string(resp.Data["updateReview"]) != `{"rating":10,"title":"Slow"}` {
+		t.Fatalf("unexpected update result: %s %+v", resp.Data["updateReview"], resp.Errors)
+	}
+
+	resp = postGraphQL(t, router, "1", `mutation($id: ID!) { deleteReview(id: $id) }`, map[string]any{"id": created.ID})
+	if len(resp.Errors) > 0 || string(resp.Data["deleteReview"]) != "true" {
+		t.Fatalf("unexpected delete result: %s %+v", resp.Data["deleteReview"], resp.Errors)
+	}
+	if n, _ := reviews.Count(context.Background()); n != 2 {
+		t.Fatalf("expected 2 reviews after delete, got %d", n)
+	}
+}
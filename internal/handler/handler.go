@@ -7,6 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
+	"golang-project/internal/config"
+	"golang-project/internal/graph"
+	"golang-project/internal/i18n"
 	"golang-project/internal/middleware"
 	"golang-project/internal/repository"
 	"golang-project/internal/router"
@@ -24,68 +27,170 @@ func (h *jwtPasswordHasher) CheckPassword(hash, password string) error {
 	return jwt.CheckPassword(hash, password)
 }
 
-func SetupRoutes(db *sql.DB, jwtSecret string, events chan service.ReviewEvent) *gin.Engine {
-	router := router.New()
+// SetupRoutes builds the HTTP router. worker, if any, is the one consuming
+// events and is reported on under /debug. serving tells the readiness probe
+// whether the server has started listening; nil means it always has.
+func SetupRoutes(db *sql.DB, cfg *config.Config, events chan service.ReviewEvent, worker *service.ReviewWorker, serving func() bool) *gin.Engine {
+	router := router.New(cfg)
+	jwtKeys := cfg.JWTKeys
 
 	v := validator.New()
-	userRepo := repository.NewUserRepository(db)
-	authService := service.NewAuthService(userRepo, v, jwtSecret)
+	if err := i18n.RegisterValidator(v); err != nil {
+		panic(err)
+	}
+	queryTimeout := repository.WithQueryTimeout(cfg.DBQueryTimeout)
+	auditRepo := repository.NewAuditRepository(db, queryTimeout)
+	audit := service.WithAuditWriter(auditRepo)
+	userRepo := repository.NewUserRepository(db, queryTimeout)
+	authService := service.NewAuthService(userRepo, v, jwtKeys, service.WithTokenTTL(cfg.JWTTTL), audit, service.WithAdminImpersonation(cfg.AllowAdminImpersonation))
 	authHandler := NewAuthHandler(authService)
-	reviewRepo := repository.NewReviewRepository(db)
+	reviewRepo := repository.NewReviewRepository(db, queryTimeout)
 	passwordHasher := &jwtPasswordHasher{}
-	userService := service.NewUserService(userRepo, reviewRepo, v, passwordHasher)
-
-	genreRepo := repository.NewGenreRepository(db)
-	movieRepo := repository.NewMovieRepository(db)
-	genreService := service.NewGenreService(genreRepo, v)
-	movieService := service.NewMovieService(movieRepo, genreRepo, v)
-	reviewService := service.NewReviewService(reviewRepo, movieRepo, v, events)
+	watchHistoryRepo := repository.NewWatchHistoryRepository(db, queryTimeout)
+	userService := service.NewUserService(userRepo, reviewRepo, v, passwordHasher, audit, service.WithAccountMerger(userRepo), service.WithWatchCounter(watchHistoryRepo))
+
+	genreRepo := repository.NewGenreRepository(db, queryTimeout)
+	movieRepo := repository.NewMovieRepository(db, queryTimeout)
+	genreService := service.NewGenreService(genreRepo, v, audit)
+	posters, err := newPosterStorage(cfg)
+	if err != nil {
+		panic(err)
+	}
+	statsCache := NewAdminStatsCache(userService, userRepo, movieRepo, reviewRepo, genreRepo)
+	invalidateStats := service.WithCacheInvalidator(statsCache)
+	movieService := service.NewMovieService(movieRepo, genreRepo, v, audit, service.WithPosterStorage(posters), service.WithWatchHistory(watchHistoryRepo), service.WithMovieVersions(repository.NewMovieVersionRepository(db, queryTimeout)), service.WithGoneForDeletedMovies(cfg.DeletedMoviesGone), invalidateStats)
+	notifications := service.NewInAppNotificationSender(repository.NewNotificationRepository(db, queryTimeout))
+	reactionRepo := repository.NewReviewReactionRepository(db, queryTimeout)
+	blocklist := service.NewBlocklistService(repository.NewBlockedKeywordRepository(db, queryTimeout), v, audit)
+	reviewService := service.NewReviewService(reviewRepo, movieRepo, v, events, service.WithNotificationSender(notifications), service.WithUserLookup(userRepo), service.WithReviewTargetLookup(reviewRepo), service.WithReviewEditWindow(cfg.ReviewEditWindow), service.WithContentFilter(blocklist), service.WithReactionCounts(reactionRepo), service.WithReviewActivity(userRepo), audit, invalidateStats)
 	genreHandler := NewGenreHandler(genreService)
 	movieHandler := NewMovieHandler(movieService)
 	reviewHandler := NewReviewHandler(reviewService)
-	auditRepo := repository.NewAuditRepository(db)
-	userHandler := NewUserHandler(userService, reviewService, userRepo, movieRepo, reviewRepo, genreRepo, auditRepo)
+	directorHandler := NewDirectorHandler(movieService)
+	yearHandler := NewYearHandler(service.NewYearSummaryService(movieRepo))
+	notificationHandler := NewNotificationHandler(notifications)
+	searchHandler := NewSearchHandler(service.NewSearchService(movieRepo, genreRepo, userRepo))
+	blocklistHandler := NewBlocklistHandler(blocklist)
+	analyticsHandler := NewAnalyticsHandler(service.NewReviewAnalyticsService(reviewRepo))
+	reactionHandler := NewReactionHandler(service.NewReactService(reactionRepo, reviewRepo))
+	watchHistoryHandler := NewWatchHistoryHandler(service.NewWatchHistoryService(watchHistoryRepo, movieRepo, v))
+	userHandler := NewUserHandler(userService, reviewService, userRepo, movieRepo, reviewRepo, genreRepo, auditRepo, statsCache)
+	graphQLHandler := NewGraphQLHandler(&graph.Resolver{
+		MovieService:  movieService,
+		GenreService:  genreService,
+		ReviewService: reviewService,
+		UserService:   userService,
+	})
+	healthHandler := NewHealthHandler(db, cfg.MigrationsSource, cfg.MigrationsPath, serving)
+	adminHandler := NewAdminHandler(service.NewRatingRecalculator(movieRepo, 0), cfg)
+
+	router.GET("/docs", SwaggerUI)
+	router.GET("/static/posters/:key", NewPosterHandler(posters).Serve)
+	sitemapHandler := NewSitemapHandler(service.NewSitemapService(movieRepo, cfg.SiteURL))
+	router.GET("/sitemap.xml", sitemapHandler.Index)
+	router.GET("/sitemaps/:file", sitemapHandler.Part)
 
 	api := router.Group("/api/v1")
+	api.Use(middleware.QueryGuard(cfg.QueryMaxLength, cfg.QueryMaxParams))
 
 	public := api.Group("/")
-	public.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	public.GET("/health", healthHandler.Live)
+	public.GET("/ready", healthHandler.Ready)
+	public.GET("/version", Version)
+	public.GET("/openapi.json", OpenAPI)
 	public.POST("/auth/register", authHandler.Register)
 	public.POST("/auth/login", authHandler.Login)
 	public.GET("/genres", genreHandler.List)
 	public.GET("/genres/:id", genreHandler.Get)
 	public.GET("/movies", movieHandler.List)
-	public.GET("/movies/:id", movieHandler.Get)
+	public.GET("/movies/controversial", movieHandler.ListControversial)
+	public.GET("/movies/featured", movieHandler.ListFeatured)
+	public.GET("/movies/:id", middleware.OptionalAuth(jwtKeys), movieHandler.Get)
 	public.GET("/movies/:id/reviews", reviewHandler.ListByMovie)
-
-	protected := api.Group("/", middleware.AuthMiddleware(jwtSecret))
+	public.GET("/reviews/:id", reviewHandler.Get)
+	public.GET("/directors", directorHandler.List)
+	public.GET("/directors/:name/movies", directorHandler.Movies)
+	public.GET("/directors/:name/similar", directorHandler.Similar)
+	public.GET("/directors/:name/stats", directorHandler.GetStats)
+	public.GET("/years/:year/summary", yearHandler.Summary)
+	public.GET("/search", middleware.RateLimitFunc(cfg.Live().SearchRateLimit), searchHandler.Search)
+
+	protected := api.Group("/", middleware.AuthMiddleware(jwtKeys))
 	protected.GET("/me", userHandler.Me)
-	protected.PUT("/me", userHandler.UpdateProfile)
-	protected.PUT("/me/password", userHandler.UpdatePassword)
+	protected.PUT("/me", middleware.RejectImpersonation(), userHandler.UpdateProfile)
+	protected.PUT("/me/password", middleware.RejectImpersonation(), userHandler.UpdatePassword)
+	protected.PUT("/me/privacy", middleware.RejectImpersonation(), userHandler.UpdatePrivacy)
 	protected.GET("/me/reviews", userHandler.MyReviews)
+	protected.GET("/me/activity", userHandler.MyActivity)
+	protected.GET("/me/audit-logs", userHandler.MyAuditLogs)
+	protected.GET("/me/notifications", notificationHandler.Mine)
+	protected.POST("/me/watch-history", watchHistoryHandler.Record)
+	protected.GET("/me/watch-history", watchHistoryHandler.Mine)
+	protected.DELETE("/me/watch-history/:movieID", watchHistoryHandler.Delete)
 	protected.POST("/movies/:id/reviews", reviewHandler.Create)
 	protected.PUT("/reviews/:id", reviewHandler.Update)
+	protected.PATCH("/reviews/:id", reviewHandler.Update)
 	protected.DELETE("/reviews/:id", reviewHandler.Delete)
+	protected.POST("/reviews/:id/react", reactionHandler.React)
+	protected.DELETE("/reviews/:id/react", reactionHandler.Unreact)
 
-	api.GET("/users/:id/reviews", userHandler.UserReviews)
+	api.GET("/users/:id/reviews", middleware.OptionalAuth(jwtKeys), userHandler.UserReviews)
+	api.GET("/users/:id/profile", userHandler.GetPublicProfile)
+	api.POST("/graphql", middleware.OptionalAuth(jwtKeys), graphQLHandler.Serve)
 
-	admin := api.Group("/", middleware.AuthMiddleware(jwtSecret), middleware.RequireRoles("admin"))
+	admin := api.Group("/", middleware.AuthMiddleware(jwtKeys), middleware.RequireRoles("admin"))
 	admin.GET("/users", userHandler.ListUsers)
 	admin.GET("/users/:id", userHandler.GetUser)
 	admin.PUT("/users/:id", userHandler.UpdateUser)
 	admin.PUT("/users/:id/role", userHandler.UpdateRole)
+	admin.GET("/admin/users/lookup", userHandler.LookupUser)
+	admin.POST("/admin/users/bulk-role", userHandler.BulkUpdateRole)
+	admin.POST("/admin/users/merge", userHandler.MergeAccounts)
+	admin.POST("/admin/users/:id/impersonate", middleware.RejectImpersonation(), authHandler.Impersonate)
 	admin.DELETE("/users/:id", userHandler.DeleteUser)
 	admin.GET("/stats", userHandler.GetStats)
+	admin.GET("/stats/movies-by-decade", userHandler.GetMoviesByDecade)
 	admin.GET("/audit-logs", userHandler.ListAuditLogs)
+	admin.GET("/audit-logs/export", userHandler.ExportAuditLogs)
+	admin.GET("/reviews/export", reviewHandler.Export)
+	admin.POST("/admin/reviews/delete", reviewHandler.BulkDelete)
+	admin.GET("/admin/analytics/reviews", analyticsHandler.Reviews)
 	admin.POST("/genres", genreHandler.Create)
+	admin.POST("/admin/genres/import", genreHandler.Import)
 	admin.PUT("/genres/:id", genreHandler.Update)
 	admin.DELETE("/genres/:id", genreHandler.Delete)
 
 	admin.POST("/movies", movieHandler.Create)
 	admin.PUT("/movies/:id", movieHandler.Update)
 	admin.DELETE("/movies/:id", movieHandler.Delete)
+	admin.POST("/movies/:id/feature", movieHandler.Feature)
+	admin.POST("/movies/:id/unfeature", movieHandler.Unfeature)
+	admin.GET("/admin/movies/:id/history", movieHandler.History)
+	admin.POST("/admin/movies/:id/history/:version_id/revert", movieHandler.Revert)
+	admin.POST("/movies/:id/poster", middleware.BodyLimit(service.MaxPosterSize+(1<<20)), movieHandler.UploadPoster)
+	admin.POST("/admin/movies/import/preview", movieHandler.PreviewImport)
+	admin.POST("/admin/recalculate-ratings", adminHandler.RecalculateRatings)
+	admin.GET("/admin/recalculate-ratings/status", adminHandler.RecalculateRatingsStatus)
+	admin.GET("/admin/config", adminHandler.Config)
+	admin.GET("/admin/blocked-keywords", blocklistHandler.List)
+	admin.POST("/admin/blocked-keywords", blocklistHandler.Create)
+	admin.POST("/admin/blocked-keywords/check", blocklistHandler.Check)
+	admin.PUT("/admin/blocked-keywords/:id", blocklistHandler.Update)
+	admin.DELETE("/admin/blocked-keywords/:id", blocklistHandler.Delete)
+
+	if cfg.EnablePprof {
+		RegisterDebugRoutes(router, jwtKeys, events, worker)
+	}
 
 	return router
 }
+
+// respondDeleted answers a successful DELETE with 204, or with 200 and the
+// deleted resource when the client passed ?return=true, e.g. to offer undo.
+func respondDeleted(c *gin.Context, deleted any) {
+	if c.Query("return") == "true" {
+		c.JSON(http.StatusOK, deleted)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
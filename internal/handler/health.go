@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/database"
+	"golang-project/internal/version"
+)
+
+// readinessTimeout bounds each dependency check so a hung database cannot
+// stall the probe past the orchestrator's own timeout.
+const readinessTimeout = 500 * time.Millisecond
+
+// dependencyStatus is what the probe tells about one dependency. The probe
+// is unauthenticated, so a failure is only classified by Code; the error
+// itself, which may name hosts or schema versions, goes to the log.
+type dependencyStatus struct {
+	Status string `json:"status"`
+	Code   string `json:"code,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                      `json:"status"`
+	Checks map[string]dependencyStatus `json:"checks,omitempty"`
+}
+
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// HealthHandler serves the liveness and readiness probes. Liveness only
+// says the process is up; readiness also checks every hard dependency.
+type HealthHandler struct {
+	serving func() bool
+	checks  []readinessCheck
+}
+
+// NewHealthHandler checks the database connection and that it has every
+// migration applied, reading them as database.RunMigrationsFrom does. Until
+// serving reports true the readiness probe fails without running the checks;
+// nil means always serving.
+func NewHealthHandler(db *sql.DB, migrationsSource, migrationsPath string, serving func() bool) *HealthHandler {
+	return &HealthHandler{
+		serving: serving,
+		checks: []readinessCheck{
+			{"database", db.PingContext},
+			{"migrations", func(ctx context.Context) error {
+				return checkMigrations(ctx, db, migrationsSource, migrationsPath)
+			}},
+		},
+	}
+}
+
+func checkMigrations(ctx context.Context, db *sql.DB, source, path string) error {
+	want, err := database.LatestMigrationFrom(source, path)
+	if err != nil {
+		return err
+	}
+	got, dirty, err := database.MigrationVersion(ctx, db)
+	if err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("migration %d did not complete", got)
+	}
+	if got != want {
+		return fmt.Errorf("schema at version %d, expected %d", got, want)
+	}
+	return nil
+}
+
+// Live also names the build, so a probe shows which commit is running.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "build": version.BuildInfo()})
+}
+
+// Version reports the build of the running binary.
+func Version(c *gin.Context) {
+	c.JSON(http.StatusOK, version.BuildInfo())
+}
+
+// Ready runs every check and answers 503 if any of them fails, listing the
+// state of each dependency either way.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.serving != nil && !h.serving() {
+		c.JSON(http.StatusServiceUnavailable, readinessResponse{Status: "starting"})
+		return
+	}
+
+	resp := readinessResponse{Status: "ok", Checks: make(map[string]dependencyStatus, len(h.checks))}
+	code := http.StatusOK
+	for _, rc := range h.checks {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		err := rc.check(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("readiness check %s: %v", rc.name, err)
+			reason := "check_failed"
+			if errors.Is(err, context.DeadlineExceeded) {
+				reason = "timeout"
+			}
+			resp.Checks[rc.name] = dependencyStatus{Status: "error", Code: reason}
+			resp.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[rc.name] = dependencyStatus{Status: "ok"}
+	}
+	c.JSON(code, resp)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/version"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serving := false
+	dbErr := errors.New("connection refused")
+	h := &HealthHandler{
+		serving: func() bool { return serving },
+		checks: []readinessCheck{
+			{"database", func(ctx context.Context) error { return dbErr }},
+			{"migrations", func(ctx context.Context) error { return nil }},
+		},
+	}
+	router := gin.New()
+	router.GET("/ready", h.Ready)
+
+	var w *httptest.ResponseRecorder
+	probe := func() (int, readinessResponse) {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var resp readinessResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		return w.Code, resp
+	}
+
+	if code, resp := probe(); code != http.StatusServiceUnavailable || resp.Status != "starting" {
+		t.Fatalf("expected 503 starting before the server listens, got %d %+v", code, resp)
+	}
+
+	serving = true
+	code, resp := probe()
+	if code != http.StatusServiceUnavailable || resp.Status != "unavailable" {
+		t.Fatalf("expected 503 with the database down, got %d %+v", code, resp)
+	}
+	if db := resp.Checks["database"]; db.Status != "error" || db.Code != "check_failed" || resp.Checks["migrations"].Status != "ok" {
+		t.Fatalf("expected per-dependency status, got %+v", resp.Checks)
+	}
+	if body := w.Body.String(); strings.Contains(body, dbErr.Error()) {
+		t.Fatalf("error details leaked into the probe: %s", body)
+	}
+
+	dbErr = nil
+	if code, resp := probe(); code != http.StatusOK || resp.Status != "ok" {
+		t.Fatalf("expected 200 once every check passes, got %d %+v", code, resp)
+	}
+}
+
+func TestHealthHandler_LiveReportsBuild(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/health", (&HealthHandler{}).Live)
+	router.GET("/version", Version)
+
+	var health struct {
+		Status string       `json:"status"`
+		Build  version.Info `json:"build"`
+	}
+	getJSON(t, router, "/health", &health)
+	if health.Status != "ok" || health.Build.Version != "dev" || health.Build.GoVersion == "" {
+		t.Fatalf("expected ok with the dev build, got %+v", health)
+	}
+
+	var info version.Info
+	getJSON(t, router, "/version", &info)
+	if info != health.Build {
+		t.Fatalf("expected /version to match /health, got %+v and %+v", info, health.Build)
+	}
+}
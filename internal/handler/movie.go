@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"golang-project/internal/middleware"
 	"golang-project/internal/models"
 	"golang-project/internal/service"
 )
@@ -18,21 +22,23 @@ func NewMovieHandler(s *service.MovieService) *MovieHandler {
 	return &MovieHandler{service: s}
 }
 
-func (h *MovieHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-
+// parseMovieFilters reads the list filters from the query string. Malformed
+// numbers are ignored, as if the parameter had not been given.
+func parseMovieFilters(c *gin.Context) models.MovieFilters {
 	var filters models.MovieFilters
 	filters.Genre = c.Query("genre")
 	filters.Search = c.Query("search")
+	filters.Director = c.Query("director")
 	filters.Sort = c.Query("sort")
+	filters.NormalizedRating = c.Query("normalized") == "true"
 	if yearStr := c.Query("year"); yearStr != "" {
 		if year, err := strconv.Atoi(yearStr); err == nil {
 			filters.Year = year
 		}
 	}
 	if minRatingStr := c.Query("min_rating"); minRatingStr != "" {
-		if rating, err := strconv.ParseFloat(minRatingStr, 64); err == nil {
+		// ParseFloat accepts "NaN" and "Inf", which Postgres would reject.
+		if rating, err := strconv.ParseFloat(minRatingStr, 64); err == nil && !math.IsNaN(rating) && !math.IsInf(rating, 0) {
 			filters.MinRating = rating
 		}
 	}
@@ -41,29 +47,237 @@ func (h *MovieHandler) List(c *gin.Context) {
 			filters.GenreID = &genreID
 		}
 	}
+	if directorIn := c.Query("director_in"); directorIn != "" {
+		for _, director := range strings.Split(directorIn, "|") {
+			if director = strings.TrimSpace(director); director != "" {
+				filters.DirectorIn = append(filters.DirectorIn, director)
+			}
+		}
+	}
+	return filters
+}
+
+func (h *MovieHandler) List(c *gin.Context) {
+	fields, err := parseFields(c, models.Movie{})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if ids := c.Query("ids"); ids != "" {
+		h.listByIDs(c, ids, fields)
+		return
+	}
+
+	pagination, err := ParsePagination(c, 10)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	filters := parseMovieFilters(c)
+
+	if wantsCSV(c) {
+		h.listCSV(c, filters)
+		return
+	}
+
+	resp, err := h.service.List(c.Request.Context(), filters, pagination.Page, pagination.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if filters.GenreID == nil {
+		counts, err := h.service.GenreCounts(c.Request.Context())
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		resp.Meta = models.MovieListMeta{GenreCounts: counts}
+	}
+	if fields != nil {
+		if resp.Data, err = project(resp.Data, fields); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	respondPage(c, resp)
+}
+
+// listCSV exports every movie matching filters; page and limit are ignored.
+// List does not load genres, so they are fetched per page in one query.
+func (h *MovieHandler) listCSV(c *gin.Context, filters models.MovieFilters) {
+	writeCSV(c, "movies.csv", movieCSVHeader, func(page int) ([][]string, bool, error) {
+		resp, err := h.service.List(c.Request.Context(), filters, page, csvPageSize)
+		if err != nil {
+			return nil, false, err
+		}
+		movies, _ := resp.Data.([]models.Movie)
+		ids := make([]int, len(movies))
+		for i, m := range movies {
+			ids[i] = m.ID
+		}
+		genres, err := h.service.GenresByMovieIDs(c.Request.Context(), ids)
+		if err != nil {
+			return nil, false, err
+		}
+		for i := range movies {
+			movies[i].Genres = genres[movies[i].ID]
+		}
+		return csvRows(movies, movieCSVRow), page < resp.TotalPages, nil
+	})
+}
+
+// listByIDs serves GET /movies?ids=3,1,2: exactly those movies, in that
+// order, without pagination. Unknown IDs are omitted.
+func (h *MovieHandler) listByIDs(c *gin.Context, raw string, fields fieldSelection) {
+	var ids []int
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			respondError(c, errInvalidID)
+			return
+		}
+		ids = append(ids, id)
+	}
 
-	resp, err := h.service.List(c.Request.Context(), filters, page, limit)
+	movies, err := h.service.GetByIDs(c.Request.Context(), ids)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list movies"})
+		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	var data any = movies
+	if fields != nil {
+		if data, err = project(movies, fields); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
 }
 
 func (h *MovieHandler) Get(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondError(c, errInvalidID)
 		return
 	}
-	movie, err := h.service.Get(c.Request.Context(), id)
+	fields, err := parseFields(c, models.Movie{})
 	if err != nil {
-		if err == service.ErrMovieNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
+		respondError(c, err)
+		return
+	}
+	// The route uses OptionalAuth, so a signed-in caller also learns whether
+	// they have watched the movie.
+	uid, _ := currentUserID(c)
+	role, _ := c.Get(string(middleware.ContextRole))
+	movie, err := h.service.GetWithUserContext(c.Request.Context(), id, uid, role == "admin")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// watched is not reflected in updated_at, so only anonymous responses
+	// can be revalidated by date.
+	if movie.Watched == nil && notModified(c, movie.UpdatedAt) {
+		return
+	}
+	if fields != nil {
+		projected, err := project(movie, fields)
+		if err != nil {
+			respondError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get movie"})
+		c.JSON(http.StatusOK, projected)
+		return
+	}
+	c.JSON(http.StatusOK, movie)
+}
+
+func (h *MovieHandler) ListControversial(c *gin.Context) {
+	limit, err := parseLimit(c, 10)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	movies, err := h.service.ListControversial(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": movies})
+}
+
+func (h *MovieHandler) ListFeatured(c *gin.Context) {
+	limit, err := parseLimit(c, 10)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	movies, err := h.service.ListFeatured(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": movies})
+}
+
+func (h *MovieHandler) Feature(c *gin.Context)   { h.setFeatured(c, true) }
+func (h *MovieHandler) Unfeature(c *gin.Context) { h.setFeatured(c, false) }
+
+func (h *MovieHandler) setFeatured(c *gin.Context, featured bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	movie, err := h.service.SetFeatured(c.Request.Context(), id, featured)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, movie)
+}
+
+// History lists the versions an admin's updates left behind, newest first.
+func (h *MovieHandler) History(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	pagination, err := ParsePagination(c, 10)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	resp, err := h.service.History(c.Request.Context(), id, pagination.Page, pagination.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondPage(c, resp)
+}
+
+// Revert restores the details stored in a movie version.
+func (h *MovieHandler) Revert(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	versionID, err := strconv.Atoi(c.Param("version_id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	movie, err := h.service.Revert(c.Request.Context(), id, versionID)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, movie)
@@ -72,19 +286,13 @@ func (h *MovieHandler) Get(c *gin.Context) {
 func (h *MovieHandler) Create(c *gin.Context) {
 	var req models.CreateMovieRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
-	movie, err := h.service.Create(c.Request.Context(), req)
+	allowDuplicate := c.Query("allow_duplicate") == "true"
+	movie, err := h.service.Create(c.Request.Context(), req, allowDuplicate)
 	if err != nil {
-		switch err {
-		case service.ErrNoGenresProvided:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "genre_ids required"})
-		case service.ErrGenreNotFound:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "genre not found"})
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		respondError(c, genreRefError(err))
 		return
 	}
 	c.JSON(http.StatusCreated, movie)
@@ -94,27 +302,18 @@ func (h *MovieHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondError(c, errInvalidID)
 		return
 	}
 	var req models.UpdateMovieRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 
 	movie, err := h.service.Update(c.Request.Context(), id, req)
 	if err != nil {
-		switch err {
-		case service.ErrMovieNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
-		case service.ErrNoGenresProvided:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "genre_ids required"})
-		case service.ErrGenreNotFound:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "genre not found"})
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		respondError(c, genreRefError(err))
 		return
 	}
 	c.JSON(http.StatusOK, movie)
@@ -124,16 +323,22 @@ func (h *MovieHandler) Delete(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondError(c, errInvalidID)
 		return
 	}
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		if err == service.ErrMovieNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete movie"})
+	movie, err := h.service.Delete(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	c.Status(http.StatusNoContent)
+	respondDeleted(c, movie)
+}
+
+// genreRefError reports an unknown genre in a movie request as a bad request
+// rather than a missing resource.
+func genreRefError(err error) error {
+	if errors.Is(err, service.ErrGenreNotFound) {
+		return errUnknownGenre
+	}
+	return err
 }
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/models"
+)
+
+var (
+	errFileRequired   = apierror.New(http.StatusBadRequest, "file_required", "file is required")
+	errUnreadableFile = apierror.New(http.StatusBadRequest, "unreadable_file", "cannot read file")
+)
+
+type importInvalidRow struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+type importPreviewResponse struct {
+	TotalRows int                `json:"total_rows"`
+	Valid     int                `json:"valid"`
+	Invalid   []importInvalidRow `json:"invalid"`
+}
+
+// PreviewImport validates an uploaded movie CSV and reports per-row problems
+// without writing anything to the database.
+func (h *MovieHandler) PreviewImport(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, errFileRequired)
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		respondError(c, errUnreadableFile)
+		return
+	}
+	defer f.Close()
+
+	reqs, parseErrs, err := parseMovieCSV(f)
+	if err != nil {
+		respondError(c, apierror.New(http.StatusBadRequest, "invalid_csv", err.Error()).
+			WithDetails(map[string]any{"error": err.Error()}))
+		return
+	}
+
+	results := h.service.ValidateCreateRequests(c.Request.Context(), reqs)
+	resp := importPreviewResponse{TotalRows: len(reqs), Invalid: []importInvalidRow{}}
+	for _, res := range results {
+		errs := slices.Concat(parseErrs[res.Row], res.Errors)
+		if len(errs) == 0 {
+			resp.Valid++
+			continue
+		}
+		resp.Invalid = append(resp.Invalid, importInvalidRow{Row: res.Row, Errors: errs})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseMovieCSV reads movie rows from CSV with a header line. Genre IDs within a
+// cell are separated by ";". Rows are numbered from 1, excluding the header.
+// Cells that cannot be converted are reported per row instead of failing the whole file.
+func parseMovieCSV(r io.Reader) ([]models.CreateMovieRequest, map[int][]string, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("csv is empty")
+		}
+		return nil, nil, errors.New("invalid csv header")
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := index["title"]; !ok {
+		return nil, nil, errors.New("csv header must include title")
+	}
+
+	var reqs []models.CreateMovieRequest
+	parseErrs := make(map[int][]string)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		row := len(reqs) + 1
+		if err != nil {
+			reqs = append(reqs, models.CreateMovieRequest{})
+			parseErrs[row] = append(parseErrs[row], "malformed csv row")
+			continue
+		}
+
+		cell := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) int {
+			v := cell(name)
+			if v == "" {
+				return 0
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				parseErrs[row] = append(parseErrs[row], name+" must be a number")
+			}
+			return n
+		}
+
+		req := models.CreateMovieRequest{
+			Title:           cell("title"),
+			Description:     cell("description"),
+			ReleaseYear:     number("release_year"),
+			Director:        cell("director"),
+			DurationMinutes: number("duration_minutes"),
+		}
+		if ids := cell("genre_ids"); ids != "" {
+			for _, id := range strings.Split(ids, ";") {
+				if id = strings.TrimSpace(id); id != "" {
+					req.GenreIDs = append(req.GenreIDs, id)
+				}
+			}
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, parseErrs, nil
+}
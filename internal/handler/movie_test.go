@@ -2,106 +2,31 @@ package handler
 
 import (
 	"bytes"
-	"context"
-	"database/sql"
 	"encoding/json"
+	"fmt"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
+	"golang-project/internal/actor"
 	"golang-project/internal/models"
 	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+	"golang-project/internal/testutil/factory"
 )
 
-type mhMovieRepo struct {
-	movies      map[int]*models.Movie
-	movieGenres map[int][]int
-}
-
-type mhGenreLookup struct {
-	data map[int]*models.Genre
-}
-
-func (r *mhGenreLookup) GetByID(ctx context.Context, id int) (*models.Genre, error) {
-	if g, ok := r.data[id]; ok {
-		return g, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func newMHRepos() (*mhMovieRepo, *mhGenreLookup, int) {
+func newMHRepos() (*testutil.MemMovieRepo, *testutil.MemGenreRepo, int) {
 	genreID := 1
-	genreRepo := &mhGenreLookup{data: map[int]*models.Genre{
-		genreID: {ID: genreID, Name: "Drama"},
-	}}
-	movieRepo := &mhMovieRepo{
-		movies:      make(map[int]*models.Movie),
-		movieGenres: make(map[int][]int),
-	}
-	return movieRepo, genreRepo, genreID
-}
-
-func (r *mhMovieRepo) List(ctx context.Context, filters models.MovieFilters, limit, offset int) ([]models.Movie, int, error) {
-	result := make([]models.Movie, 0, len(r.movies))
-	for _, m := range r.movies {
-		result = append(result, *m)
-	}
-	return result, len(result), nil
-}
-
-func (r *mhMovieRepo) GetByID(ctx context.Context, id int) (*models.Movie, error) {
-	if m, ok := r.movies[id]; ok {
-		return m, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *mhMovieRepo) Create(ctx context.Context, movie *models.Movie) error {
-	now := time.Now()
-	movie.ID = len(r.movies) + 1
-	movie.CreatedAt = now
-	movie.UpdatedAt = now
-	r.movies[movie.ID] = movie
-	return nil
-}
-
-func (r *mhMovieRepo) Update(ctx context.Context, movie *models.Movie) error {
-	if _, ok := r.movies[movie.ID]; !ok {
-		return sql.ErrNoRows
-	}
-	movie.UpdatedAt = time.Now()
-	r.movies[movie.ID] = movie
-	return nil
-}
-
-func (r *mhMovieRepo) Delete(ctx context.Context, id int) error {
-	if _, ok := r.movies[id]; !ok {
-		return sql.ErrNoRows
-	}
-	delete(r.movies, id)
-	delete(r.movieGenres, id)
-	return nil
-}
-
-func (r *mhMovieRepo) SetGenres(ctx context.Context, movieID int, genreIDs []int) error {
-	if _, ok := r.movies[movieID]; !ok {
-		return sql.ErrNoRows
-	}
-	r.movieGenres[movieID] = genreIDs
-	return nil
-}
-
-func (r *mhMovieRepo) GetGenresByMovieID(ctx context.Context, movieID int) ([]models.Genre, error) {
-	ids := r.movieGenres[movieID]
-	result := make([]models.Genre, 0, len(ids))
-	for _, id := range ids {
-		result = append(result, models.Genre{ID: id, Name: ""})
-	}
-	return result, nil
+	genreRepo := testutil.NewMemGenreRepo()
+	genreRepo.Add(&models.Genre{ID: genreID, Name: "Drama"})
+	return testutil.NewMemMovieRepo(), genreRepo, genreID
 }
 
 func TestMovieHandler_CRUD(t *testing.T) {
@@ -149,3 +74,690 @@ func TestMovieHandler_CRUD(t *testing.T) {
 		t.Fatalf("get expected 404, got %d", w.Code)
 	}
 }
+
+func TestMovieHandler_CreateDuplicate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	h := NewMovieHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+
+	router := gin.New()
+	router.POST("/movies", h.Create)
+
+	create := func(path, title string) int {
+		body, _ := json.Marshal(models.CreateMovieRequest{
+			Title:           title,
+			ReleaseYear:     1999,
+			DurationMinutes: 136,
+			GenreIDs:        []string{"1"},
+		})
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := create("/movies", "The Matrix"); code != http.StatusCreated {
+		t.Fatalf("first create expected 201, got %d", code)
+	}
+	if code := create("/movies", "  the   MATRIX "); code != http.StatusConflict {
+		t.Fatalf("duplicate create expected 409, got %d", code)
+	}
+	if code := create("/movies?allow_duplicate=true", "The Matrix"); code != http.StatusCreated {
+		t.Fatalf("forced duplicate expected 201, got %d", code)
+	}
+	if mRepo.Len() != 2 {
+		t.Fatalf("expected 2 movies, got %d", mRepo.Len())
+	}
+}
+
+func TestMovieHandler_ListDirectorIn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	h := NewMovieHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+	for _, director := range []string{"Nolan", "Nolan", "Kubrick", "Tarantino"} {
+		mRepo.Add(factory.NewMovie(factory.Director(director)))
+	}
+
+	router := gin.New()
+	router.GET("/movies", h.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?director_in=Nolan|Kubrick", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Data  []models.Movie `json:"data"`
+		Total int            `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Total != 3 {
+		t.Fatalf("expected 3 movies, got %d", resp.Total)
+	}
+	for _, m := range resp.Data {
+		if m.Director == "Tarantino" {
+			t.Fatalf("unexpected movie from excluded director: %+v", m)
+		}
+	}
+
+	tooMany := strings.Repeat("Director|", service.MaxDirectorFilterValues+1)
+	req = httptest.NewRequest(http.MethodGet, "/movies?director_in="+tooMany, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("too many directors expected 400, got %d", w.Code)
+	}
+}
+
+func TestMovieHandler_ListDirector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	h := NewMovieHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+	for _, director := range []string{"Christopher Nolan", "Jonathan Nolan", "Stanley Kubrick"} {
+		mRepo.Add(factory.NewMovie(factory.Director(director)))
+	}
+
+	router := gin.New()
+	router.GET("/movies", h.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?director=nOLAN", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Data  []models.Movie `json:"data"`
+		Total int            `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Total != 2 {
+		t.Fatalf("expected 2 Nolan movies, got %d: %+v", resp.Total, resp.Data)
+	}
+	for _, m := range resp.Data {
+		if !strings.Contains(m.Director, "Nolan") {
+			t.Fatalf("unexpected director %q", m.Director)
+		}
+	}
+}
+
+func FuzzParseMovieFilters(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"genre=Drama&year=1995&min_rating=7.5&sort=rating_desc&normalized=true",
+		"min_rating=NaN",
+		"min_rating=-Inf",
+		"genre_id=-1&year=99999999999999999999",
+		"director_in=||Nolan| |Kubrick&director=%25",
+		"search=%zz&sort=;DROP",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, rawQuery string) {
+		filters := parseMovieFilters(queryContext(rawQuery))
+		if math.IsNaN(filters.MinRating) || math.IsInf(filters.MinRating, 0) {
+			t.Fatalf("%q: min_rating %v is not finite", rawQuery, filters.MinRating)
+		}
+		for _, director := range filters.DirectorIn {
+			if director == "" || director != strings.TrimSpace(director) {
+				t.Fatalf("%q: director_in kept %q", rawQuery, director)
+			}
+		}
+	})
+}
+
+func TestMovieHandler_PreviewImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	svc := service.NewMovieService(mRepo, gRepo, validator.New())
+	h := NewMovieHandler(svc)
+
+	router := gin.New()
+	router.POST("/admin/movies/import/preview", h.PreviewImport)
+
+	csvBody := "title,description,release_year,director,duration_minutes,genre_ids\n" +
+		"Movie 1,Desc,2001,Dir,100,1\n" +
+		"Movie 2,Desc,2002,Dir,100,1\n" +
+		"Movie 3,Desc,2003,Dir,100,1;2\n" +
+		",Missing title,2004,Dir,100,1\n" +
+		"Movie 5,Desc,2005,Dir,100,1\n" +
+		"Movie 6,Desc,2006,Dir,100,1\n" +
+		"Movie 7,Desc,abc,Dir,100,1\n" +
+		"Movie 8,Desc,2008,Dir,100,1\n" +
+		"Movie 9,Desc,2009,Dir,100,1\n" +
+		"Movie 10,Desc,2010,Dir,100,1\n"
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreateFormFile("file", "movies.csv")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write([]byte(csvBody))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/movies/import/preview", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("preview expected 200, got %d body %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		TotalRows int `json:"total_rows"`
+		Valid     int `json:"valid"`
+		Invalid   []struct {
+			Row    int      `json:"row"`
+			Errors []string `json:"errors"`
+		} `json:"invalid"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.TotalRows != 10 || resp.Valid != 8 || len(resp.Invalid) != 2 {
+		t.Fatalf("expected 10 rows, 8 valid, 2 invalid, got %+v", resp)
+	}
+	if resp.Invalid[0].Row != 4 || resp.Invalid[1].Row != 7 {
+		t.Fatalf("expected rows 4 and 7 invalid, got %+v", resp.Invalid)
+	}
+	if mRepo.Len() != 0 {
+		t.Fatalf("preview must not create movies, got %d", mRepo.Len())
+	}
+}
+
+func TestMovieHandler_Fields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, genreID := newMHRepos()
+	mRepo.Add(&models.Movie{ID: 1, Title: "Heat", Description: "A long description", ReleaseYear: 1995, AverageRating: 8.1}, genreID)
+	h := NewMovieHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+
+	router := gin.New()
+	router.GET("/movies", h.List)
+	router.GET("/movies/:id", h.Get)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	assertKeys := func(t *testing.T, obj map[string]any, want ...string) {
+		t.Helper()
+		if len(obj) != len(want) {
+			t.Fatalf("expected exactly %v, got %v", want, obj)
+		}
+		for _, k := range want {
+			if _, ok := obj[k]; !ok {
+				t.Fatalf("expected key %q in %v", k, obj)
+			}
+		}
+	}
+
+	w := get("/movies?fields=id,title,average_rating")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var list struct {
+		Data  []map[string]any `json:"data"`
+		Total int              `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("parse list: %v", err)
+	}
+	if list.Total != 1 || len(list.Data) != 1 {
+		t.Fatalf("expected one movie and pagination kept, got %s", w.Body.String())
+	}
+	assertKeys(t, list.Data[0], "id", "title", "average_rating")
+
+	w = get("/movies/1?fields=title,genres.name")
+	if w.Code != http.StatusOK {
+		t.Fatalf("get expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var movie map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &movie); err != nil {
+		t.Fatalf("parse movie: %v", err)
+	}
+	assertKeys(t, movie, "title", "genres")
+	genres, _ := movie["genres"].([]any)
+	if len(genres) != 1 {
+		t.Fatalf("expected one genre, got %v", movie["genres"])
+	}
+	assertKeys(t, genres[0].(map[string]any), "name")
+
+	for _, target := range []string{"/movies?fields=id,budget", "/movies/1?fields=genres.budget"} {
+		if w := get(target); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unknown_field") {
+			t.Fatalf("%s: expected 400 unknown_field, got %d: %s", target, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestDirectorHandler_Movies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	for i := 0; i < 5; i++ {
+		mRepo.Add(&models.Movie{Title: fmt.Sprintf("Nolan %d", i), Director: "Christopher Nolan", ReleaseYear: 2010 - i})
+	}
+	mRepo.Add(&models.Movie{Title: "Heat", Director: "Michael Mann", ReleaseYear: 1995})
+	mRepo.Add(&models.Movie{Title: "Alien", Director: "Ridley Scott", ReleaseYear: 1979})
+	h := NewDirectorHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+
+	router := gin.New()
+	router.GET("/directors/:name/movies", h.Movies)
+
+	req := httptest.NewRequest(http.MethodGet, "/directors/christopher%20nolan/movies?limit=3", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data       []models.Movie `json:"data"`
+		Total      int            `json:"total"`
+		TotalPages int            `json:"total_pages"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Total != 5 || resp.TotalPages != 2 || len(resp.Data) != 3 {
+		t.Fatalf("expected 5 Nolan movies over 2 pages, got total %d pages %d len %d", resp.Total, resp.TotalPages, len(resp.Data))
+	}
+	for _, m := range resp.Data {
+		if m.Director != "Christopher Nolan" {
+			t.Fatalf("unexpected director %q", m.Director)
+		}
+	}
+	if resp.Data[0].ReleaseYear > resp.Data[1].ReleaseYear {
+		t.Fatalf("expected filmography oldest first, got %+v", resp.Data)
+	}
+}
+
+func TestDirectorHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	for _, director := range []string{"Ridley Scott", "Tony Scott", "Ridley Scott", "Michael Mann", ""} {
+		mRepo.Add(factory.NewMovie(factory.Director(director)))
+	}
+	mRepo.Add(factory.NewMovie(factory.Director("Ridley Scott"), func(m *models.Movie) { m.Status = models.MovieStatusPending }))
+	h := NewDirectorHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+
+	router := gin.New()
+	router.GET("/directors", h.List)
+
+	list := func(target string) []models.DirectorCount {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", target, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Data []models.DirectorCount `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		return resp.Data
+	}
+
+	all := list("/directors")
+	want := []models.DirectorCount{{Name: "Ridley Scott", MovieCount: 2}, {Name: "Michael Mann", MovieCount: 1}, {Name: "Tony Scott", MovieCount: 1}}
+	if !slices.Equal(all, want) {
+		t.Fatalf("expected %+v, got %+v", want, all)
+	}
+	if scotts := list("/directors?q=scott&limit=1"); len(scotts) != 1 || scotts[0].Name != "Ridley Scott" {
+		t.Fatalf("q=scott&limit=1: got %+v", scotts)
+	}
+	if none := list("/directors?q=kubrick"); none == nil || len(none) != 0 {
+		t.Fatalf("expected an empty list, got %#v", none)
+	}
+}
+
+func TestDirectorHandler_Similar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	mRepo.Add(&models.Movie{ID: 1, Title: "Inception", Director: "Christopher Nolan"})
+	mRepo.Add(&models.Movie{ID: 2, Title: "Memento", Director: "Christopher Nolan"})
+	mRepo.Add(&models.Movie{ID: 3, Title: "Arrival", Director: "Denis Villeneuve"})
+	mRepo.Add(&models.Movie{ID: 4, Title: "Dune", Director: "Denis Villeneuve"})
+	mRepo.Add(&models.Movie{ID: 5, Title: "Alien", Director: "Ridley Scott"})
+	mRepo.Add(&models.Movie{ID: 6, Title: "Heat", Director: "Michael Mann"})
+	// Users 1-3 reviewed Nolan; Villeneuve shares three of them (user 1
+	// twice), Scott two, and Mann only reviewers who never saw a Nolan film.
+	mRepo.AddReviewers(1, 1, 2)
+	mRepo.AddReviewers(2, 3)
+	mRepo.AddReviewers(3, 1, 2, 3)
+	mRepo.AddReviewers(4, 1)
+	mRepo.AddReviewers(5, 2, 3, 4)
+	mRepo.AddReviewers(6, 4, 5)
+	h := NewDirectorHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+
+	router := gin.New()
+	router.GET("/directors/:name/similar", h.Similar)
+
+	var resp struct {
+		Data []models.SimilarDirector `json:"data"`
+	}
+	getJSON(t, router, "/directors/christopher%20nolan/similar", &resp)
+	want := []models.SimilarDirector{{Name: "Denis Villeneuve", CoReviewerCount: 3}, {Name: "Ridley Scott", CoReviewerCount: 2}}
+	if !slices.Equal(resp.Data, want) {
+		t.Fatalf("expected %+v, got %+v", want, resp.Data)
+	}
+
+	getJSON(t, router, "/directors/christopher%20nolan/similar?limit=1", &resp)
+	if len(resp.Data) != 1 || resp.Data[0].Name != "Denis Villeneuve" {
+		t.Fatalf("expected only the top match with limit=1, got %+v", resp.Data)
+	}
+}
+
+func TestDirectorHandler_GetStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	mRepo.Add(&models.Movie{ID: 1, Title: "Memento", Director: "Nolan", ReleaseYear: 2000, AverageRating: 7})
+	mRepo.Add(&models.Movie{ID: 2, Title: "Inception", Director: "Nolan", ReleaseYear: 2010, AverageRating: 9})
+	mRepo.Add(&models.Movie{ID: 3, Title: "Tenet", Director: "Nolan", ReleaseYear: 2020, AverageRating: 8})
+	mRepo.Add(&models.Movie{ID: 4, Title: "Heat", Director: "Michael Mann", ReleaseYear: 1995, AverageRating: 10})
+	h := NewDirectorHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+
+	router := gin.New()
+	router.GET("/directors/:name/stats", h.GetStats)
+
+	var stats models.DirectorStats
+	getJSON(t, router, "/directors/nolan/stats", &stats)
+	if stats.MovieCount != 3 || stats.AverageRating != 8.0 {
+		t.Fatalf("expected 3 movies averaging 8, got %+v", stats)
+	}
+	if stats.BestMovie == nil || stats.BestMovie.AverageRating != 9 || stats.BestMovie.Title != "Inception" {
+		t.Fatalf("expected Inception as the best movie, got %+v", stats.BestMovie)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/directors/Kubrick/stats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a director without movies, got %d", w.Code)
+	}
+}
+
+func TestYearHandler_Summary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repos := factory.NewRepos()
+	users := make([]*models.User, 6)
+	for i := range users {
+		users[i] = factory.NewUser()
+		repos.Users.Add(users[i])
+	}
+	movies := make(map[string]*models.Movie)
+	for _, title := range []string{"Tenet", "Soul", "Nomadland", "Palm Springs", "Mank"} {
+		movies[title] = factory.NewMovie(factory.Title(title), factory.Year(2020))
+		repos.Movies.Add(movies[title])
+	}
+	movies["Parasite"] = factory.NewMovie(factory.Title("Parasite"), factory.Year(2019))
+	repos.Movies.Add(movies["Parasite"])
+	review := func(title string, rating int, reviewers ...*models.User) {
+		for _, user := range reviewers {
+			repos.Reviews.Add(factory.NewReview(factory.ReviewOf(movies[title]), factory.ReviewBy(user), factory.Rated(rating)))
+		}
+	}
+	review("Tenet", 8, users[:5]...)      // best: averages 8 over the 5 reviews it needs
+	review("Soul", 6, users...)           // most reviewed
+	review("Nomadland", 10, users[:2]...) // rated higher, but by too few
+	review("Palm Springs", 4, users[0])
+	review("Parasite", 10, users[5]) // another year
+	h := NewYearHandler(service.NewYearSummaryService(testutil.MemYearSummaries{Movies: repos.Movies, Reviews: repos.Reviews, Users: repos.Users}))
+
+	router := gin.New()
+	router.GET("/years/:year/summary", h.Summary)
+
+	var summary models.YearSummary
+	getJSON(t, router, "/years/2020/summary", &summary)
+	if summary.Year != 2020 || summary.TotalMovies != 5 || summary.TotalReviews != 14 || summary.AverageRating != 7 {
+		t.Fatalf("unexpected totals: %+v", summary)
+	}
+	if summary.BestMovie == nil || summary.BestMovie.Title != "Tenet" {
+		t.Fatalf("expected Tenet as the best movie, got %+v", summary.BestMovie)
+	}
+	if summary.MostReviewedMovie == nil || summary.MostReviewedMovie.Title != "Soul" {
+		t.Fatalf("expected Soul as the most reviewed movie, got %+v", summary.MostReviewedMovie)
+	}
+	want := []models.TopReviewer{
+		{PublicUser: *users[0].Public(), ReviewCount: 4},
+		{PublicUser: *users[1].Public(), ReviewCount: 3},
+		{PublicUser: *users[2].Public(), ReviewCount: 2},
+	}
+	if !slices.Equal(summary.TopReviewers, want) {
+		t.Fatalf("expected top reviewers %+v, got %+v", want, summary.TopReviewers)
+	}
+
+	for target, code := range map[string]int{
+		"/years/1999/summary": http.StatusNotFound,
+		"/years/soon/summary": http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != code {
+			t.Fatalf("%s: expected %d, got %d", target, code, w.Code)
+		}
+	}
+}
+
+func TestMovieHandler_ListByIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, genreID := newMHRepos()
+	for _, title := range []string{"One", "Two", "Three"} {
+		mRepo.Add(&models.Movie{Title: title}, genreID)
+	}
+	h := NewMovieHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+
+	router := gin.New()
+	router.GET("/movies", h.List)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/movies?ids=3,99,1,3")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data []models.Movie `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if len(resp.Data) != 2 || resp.Data[0].Title != "Three" || resp.Data[1].Title != "One" {
+		t.Fatalf("expected [Three One] in request order, got %+v", resp.Data)
+	}
+	if len(resp.Data[0].Genres) != 1 {
+		t.Fatalf("expected genres to be loaded, got %+v", resp.Data[0])
+	}
+
+	if w := get("/movies?ids=1,x"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a non-numeric id, got %d", w.Code)
+	}
+	tooMany := strings.Repeat("1,", service.MaxMovieIDsPerRequest+1)
+	if w := get("/movies?ids=" + tooMany); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 above the id cap, got %d", w.Code)
+	}
+}
+
+func TestMovieHandler_DeleteReturnFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	mRepo.Add(&models.Movie{ID: 1, Title: "Heat"})
+	mRepo.Add(&models.Movie{ID: 2, Title: "Ronin"})
+	router := gin.New()
+	router.DELETE("/movies/:id", NewMovieHandler(service.NewMovieService(mRepo, gRepo, validator.New())).Delete)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/movies/1", nil))
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("expected an empty 204, got %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/movies/2?return=true", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"title":"Ronin"`) {
+		t.Fatalf("expected 200 with the deleted movie, got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestMovieHandler_Featured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, _ := newMHRepos()
+	for i := 1; i <= 3; i++ {
+		mRepo.Add(&models.Movie{ID: i, Title: fmt.Sprintf("Movie %d", i)})
+	}
+	h := NewMovieHandler(service.NewMovieService(mRepo, gRepo, validator.New()))
+	router := gin.New()
+	router.GET("/movies/featured", h.ListFeatured)
+	router.GET("/movies/:id", h.Get)
+	router.POST("/movies/:id/feature", h.Feature)
+	router.POST("/movies/:id/unfeature", h.Unfeature)
+
+	post := func(target string, wantCode int) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, nil))
+		if w.Code != wantCode {
+			t.Fatalf("%s: expected %d, got %d: %s", target, wantCode, w.Code, w.Body)
+		}
+	}
+	featuredIDs := func() []int {
+		t.Helper()
+		var resp struct {
+			Data []models.Movie `json:"data"`
+		}
+		getJSON(t, router, "/movies/featured", &resp)
+		ids := make([]int, len(resp.Data))
+		for i, m := range resp.Data {
+			if !m.Featured {
+				t.Fatalf("listed movie %d is not marked featured", m.ID)
+			}
+			ids[i] = m.ID
+		}
+		return ids
+	}
+
+	if ids := featuredIDs(); len(ids) != 0 {
+		t.Fatalf("expected no featured movies, got %v", ids)
+	}
+	post("/movies/1/feature", http.StatusOK)
+	post("/movies/3/feature", http.StatusOK)
+	post("/movies/3/feature", http.StatusOK)
+	if ids := featuredIDs(); len(ids) != 2 || !slices.Contains(ids, 1) || !slices.Contains(ids, 3) {
+		t.Fatalf("expected movies 1 and 3 featured, got %v", ids)
+	}
+
+	var movie models.Movie
+	getJSON(t, router, "/movies/3", &movie)
+	if !movie.Featured {
+		t.Fatal("expected featured in the movie JSON")
+	}
+
+	post("/movies/1/unfeature", http.StatusOK)
+	if ids := featuredIDs(); !slices.Equal(ids, []int{3}) {
+		t.Fatalf("expected only movie 3 featured, got %v", ids)
+	}
+	post("/movies/99/feature", http.StatusNotFound)
+	post("/movies/abc/feature", http.StatusBadRequest)
+}
+
+func TestMovieHandler_HistoryAndRevert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mRepo, gRepo, dramaID := newMHRepos()
+	mRepo.Add(&models.Movie{ID: 1, Title: "Original", ReleaseYear: 1995, Director: "Michael Mann", DurationMinutes: 170}, dramaID)
+	h := NewMovieHandler(service.NewMovieService(mRepo, gRepo, validator.New(), service.WithMovieVersions(testutil.NewMemMovieVersionRepo(mRepo))))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(actor.WithID(c.Request.Context(), 7))
+	})
+	router.PUT("/movies/:id", h.Update)
+	router.GET("/admin/movies/:id/history", h.History)
+	router.POST("/admin/movies/:id/history/:version_id/revert", h.Revert)
+
+	send := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	for _, title := range []string{"First edit", "Second edit"} {
+		if w := send(http.MethodPut, "/movies/1", `{"title":"`+title+`","description":"A heist goes wrong"}`); w.Code != http.StatusOK {
+			t.Fatalf("update to %q: expected 200, got %d: %s", title, w.Code, w.Body)
+		}
+	}
+	// A rejected update must not leave a version behind.
+	if w := send(http.MethodPut, "/movies/1", `{"title":"Third edit","genre_ids":["999"]}`); w.Code == http.StatusOK {
+		t.Fatalf("expected an unknown genre to be rejected, got %d", w.Code)
+	}
+
+	var history struct {
+		Data  []models.MovieVersion `json:"data"`
+		Total int                   `json:"total"`
+	}
+	getJSON(t, router, "/admin/movies/1/history", &history)
+	if history.Total != 2 || len(history.Data) != 2 {
+		t.Fatalf("expected two versions, got %+v", history)
+	}
+	if history.Data[0].Title != "First edit" || history.Data[1].Title != "Original" {
+		t.Fatalf("expected the first edit, then the original, got %q and %q", history.Data[0].Title, history.Data[1].Title)
+	}
+	if by := history.Data[0].EditedByUserID; by == nil || *by != 7 {
+		t.Fatalf("expected the editor to be recorded, got %v", by)
+	}
+
+	w := send(http.MethodPost, fmt.Sprintf("/admin/movies/1/history/%d/revert", history.Data[1].ID), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("revert: expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var movie models.Movie
+	if err := json.Unmarshal(w.Body.Bytes(), &movie); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	// The original had no description; an empty value is restored too.
+	if movie.Title != "Original" || movie.Description != "" {
+		t.Fatalf("expected the original details back, got %q / %q", movie.Title, movie.Description)
+	}
+	getJSON(t, router, "/admin/movies/1/history", &history)
+	if history.Total != 3 || history.Data[0].Title != "Second edit" {
+		t.Fatalf("expected the revert to keep the second edit as a version, got %+v", history)
+	}
+
+	for target, want := range map[string]int{
+		"/admin/movies/1/history/99/revert": http.StatusNotFound,
+		"/admin/movies/2/history/1/revert":  http.StatusNotFound,
+		"/admin/movies/1/history/x/revert":  http.StatusBadRequest,
+	} {
+		if w := send(http.MethodPost, target, ""); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", target, want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+// batchRecordingAuditRepo records the largest batch an export asked for.
+type batchRecordingAuditRepo struct {
+	*testutil.MemAuditRepo
+	calls, maxLimit int
+}
+
+func (r *batchRecordingAuditRepo) ListAfter(ctx context.Context, filters models.AuditLogFilters, afterID, limit int) ([]models.AuditLog, error) {
+	r.calls++
+	r.maxLimit = max(r.maxLimit, limit)
+	return r.MemAuditRepo.ListAfter(ctx, filters, afterID, limit)
+}
+
+// readNDJSON requests target and decodes every line into ids.
+func readNDJSON(t *testing.T, router http.Handler, target string) []int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: expected 200, got %d: %s", target, w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("%s: unexpected content type %q", target, ct)
+	}
+	if !w.Flushed {
+		t.Fatalf("%s: expected the response to be flushed", target)
+	}
+	var ids []int
+	sc := bufio.NewScanner(w.Body)
+	for sc.Scan() {
+		var row struct {
+			ID int `json:"id"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &row); err != nil {
+			t.Fatalf("%s: line %d is not JSON: %v", target, len(ids)+1, err)
+		}
+		ids = append(ids, row.ID)
+	}
+	return ids
+}
+
+func TestExportAuditLogs_NDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	audit := &batchRecordingAuditRepo{MemAuditRepo: testutil.NewMemAuditRepo()}
+	ctx := context.Background()
+	const rows = 3000
+	for i := 1; i <= rows; i++ {
+		event := "login_success"
+		if i%3 == 0 {
+			event = "login_failed"
+		}
+		audit.Insert(ctx, &models.AuditLog{Event: event})
+	}
+	users := service.NewUserService(testutil.NewMemUserRepo(), testutil.NewMemReviewRepo(), validator.New(), nil)
+	h := NewUserHandler(users, nil, testutil.NewMemUserRepo(), nil, nil, nil, audit, nil)
+	router := gin.New()
+	router.GET("/audit-logs/export", h.ExportAuditLogs)
+
+	ids := readNDJSON(t, router, "/audit-logs/export?format=ndjson")
+	if len(ids) != rows || ids[0] != 1 || ids[rows-1] != rows {
+		t.Fatalf("expected ids 1..%d, got %d rows", rows, len(ids))
+	}
+	if audit.maxLimit > ndjsonBatchSize || audit.calls < rows/ndjsonBatchSize {
+		t.Fatalf("expected batches of at most %d, got %d calls with limit up to %d", ndjsonBatchSize, audit.calls, audit.maxLimit)
+	}
+
+	if ids := readNDJSON(t, router, "/audit-logs/export?event=login_failed"); len(ids) != rows/3 {
+		t.Fatalf("expected the event filter to leave %d rows, got %d", rows/3, len(ids))
+	}
+	if ids := readNDJSON(t, router, "/audit-logs/export?after_id=2500"); len(ids) != 500 || ids[0] != 2501 {
+		t.Fatalf("expected to resume at 2501, got %d rows starting at %v", len(ids), ids)
+	}
+
+	for _, target := range []string{"/audit-logs/export?format=csv", "/audit-logs/export?after_id=abc"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestExportReviews_NDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reviews := testutil.NewMemReviewRepo()
+	for i := 1; i <= 1200; i++ {
+		reviews.Add(&models.Review{MovieID: i, UserID: i, Rating: 1 + i%10, Title: "t", Content: "review " + strconv.Itoa(i)})
+	}
+	h := NewReviewHandler(service.NewReviewService(reviews, testutil.NewMemMovieRepo(), validator.New(), nil))
+	router := gin.New()
+	router.GET("/reviews/export", h.Export)
+
+	if ids := readNDJSON(t, router, "/reviews/export"); len(ids) != 1200 {
+		t.Fatalf("expected 1200 reviews, got %d", len(ids))
+	}
+	if ids := readNDJSON(t, router, "/reviews/export?min_rating=10"); len(ids) != 120 {
+		t.Fatalf("expected 120 reviews rated 10, got %d", len(ids))
+	}
+}
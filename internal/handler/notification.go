@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/service"
+)
+
+type NotificationHandler struct {
+	notifications *service.InAppNotificationSender
+}
+
+func NewNotificationHandler(notifications *service.InAppNotificationSender) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications}
+}
+
+// Mine lists the caller's notifications, newest first.
+func (h *NotificationHandler) Mine(c *gin.Context) {
+	uid, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+	pagination, err := ParsePagination(c, 20)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp, err := h.notifications.List(c.Request.Context(), uid, pagination.Page, pagination.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondPage(c, resp)
+}
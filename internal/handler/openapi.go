@@ -0,0 +1,320 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/config"
+	"golang-project/internal/models"
+	"golang-project/internal/openapi"
+	"golang-project/internal/service"
+	"golang-project/internal/version"
+)
+
+var pageQuery = []openapi.Parameter{
+	openapi.Query("page", "integer", "page number, starting at 1"),
+	openapi.Query("limit", "integer", "page size"),
+}
+
+var fieldsQuery = openapi.Query("fields", "string", "comma-separated fields to return, e.g. id,title,genres.name")
+
+var returnQuery = []openapi.Parameter{openapi.Query("return", "boolean", "true answers 200 with the deleted resource instead of 204")}
+
+var formatQuery = openapi.Query("format", "string", "csv exports every matching row as text/csv, ignoring page and limit (same as Accept: text/csv)")
+
+func withPage(params ...openapi.Parameter) []openapi.Parameter {
+	return append(append([]openapi.Parameter{}, pageQuery...), params...)
+}
+
+// apiRoutes documents every route registered under /api/v1 in SetupRoutes.
+// TestOpenAPISpec_CoversRoutes fails when the two drift apart.
+func apiRoutes() []openapi.Route {
+	const (
+		public = openapi.Public
+		authed = openapi.Authenticated
+		admin  = openapi.Admin
+	)
+	bad, notFound, conflict := http.StatusBadRequest, http.StatusNotFound, http.StatusConflict
+
+	return []openapi.Route{
+		{Method: http.MethodGet, Path: "/health", Tag: "system", Summary: "Health check, with the running build", Access: public,
+			Response: openapi.Object{"status": "", "build": version.Info{}}},
+		{Method: http.MethodGet, Path: "/version", Tag: "system", Summary: "Version, commit and build date of the running binary", Access: public,
+			Response: version.Info{}},
+		{Method: http.MethodGet, Path: "/ready", Tag: "system", Summary: "Readiness check of the database and schema version; 503 if a dependency is down", Access: public,
+			Response: readinessResponse{}, Errors: []int{http.StatusServiceUnavailable}},
+		{Method: http.MethodGet, Path: "/openapi.json", Tag: "system", Summary: "This OpenAPI document", Access: public,
+			Response: &openapi.Schema{Type: "object"}},
+
+		{Method: http.MethodPost, Path: "/auth/register", Tag: "auth", Summary: "Register a new user", Access: public,
+			Request: models.CreateUserRequest{}, Status: http.StatusCreated, Response: models.AuthResponse{}, Errors: []int{bad, conflict}},
+		{Method: http.MethodPost, Path: "/auth/login", Tag: "auth", Summary: "Log in and obtain a JWT", Access: public,
+			Request: models.LoginRequest{}, Response: models.AuthResponse{}, Errors: []int{bad, http.StatusUnauthorized}},
+
+		{Method: http.MethodGet, Path: "/genres", Tag: "genres", Summary: "List genres", Access: public,
+			Response: openapi.List{Of: models.Genre{}}},
+		{Method: http.MethodGet, Path: "/genres/:id", Tag: "genres", Summary: "Get a genre", Access: public,
+			Query:    []openapi.Parameter{openapi.Query("with_stats", "boolean", "include movie_count and review_count")},
+			Response: models.GenreWithStats{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodPost, Path: "/genres", Tag: "genres", Summary: "Create a genre", Access: admin,
+			Request: models.CreateGenreRequest{}, Status: http.StatusCreated, Response: models.Genre{}, Errors: []int{bad, conflict}},
+		{Method: http.MethodPost, Path: "/admin/genres/import", Tag: "genres", Summary: "Import genres in bulk; existing names are skipped, items with an unknown parent are reported and left out", Access: admin,
+			Request: models.ImportGenresRequest{}, Response: models.BulkGenreResult{}, Errors: []int{bad}},
+		{Method: http.MethodPut, Path: "/genres/:id", Tag: "genres", Summary: "Rename a genre", Access: admin,
+			Request: models.CreateGenreRequest{}, Response: models.Genre{}, Errors: []int{bad, notFound, conflict}},
+		{Method: http.MethodDelete, Path: "/genres/:id", Tag: "genres", Summary: "Delete a genre", Access: admin,
+			Query: returnQuery, Status: http.StatusNoContent, Errors: []int{bad, notFound}},
+
+		{Method: http.MethodGet, Path: "/movies", Tag: "movies", Summary: "List movies", Access: public,
+			Query: withPage(
+				openapi.Query("genre", "string", "genre name substring"),
+				openapi.Query("genre_id", "integer", "exact genre"),
+				openapi.Query("year", "integer", "release year"),
+				openapi.Query("min_rating", "number", "minimum average rating"),
+				openapi.Query("search", "string", "title or description substring"),
+				openapi.Query("director_in", "string", "pipe-separated list of directors"),
+				openapi.Query("director", "string", "director name substring, ignoring case"),
+				openapi.Query("ids", "string", "comma-separated movie IDs; returns exactly those movies in that order, unpaginated"),
+				openapi.Query("sort", "string", "rating_desc, rating_asc, year_desc, year_asc, title_asc or title_desc"),
+				openapi.Query("normalized", "boolean", "sort rating_desc and rating_asc by normalized_average_rating"),
+				fieldsQuery,
+				formatQuery,
+			),
+			Response: openapi.Page{Of: models.Movie{}, Meta: models.MovieListMeta{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/movies/controversial", Tag: "movies", Summary: "Movies with the most divided review sentiment", Access: public,
+			Query: []openapi.Parameter{openapi.Query("limit", "integer", "maximum number of movies")}, Response: openapi.List{Of: models.Movie{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/movies/featured", Tag: "movies", Summary: "Movies pinned to the homepage, most recently updated first", Access: public,
+			Query: []openapi.Parameter{openapi.Query("limit", "integer", "maximum number of movies")}, Response: openapi.List{Of: models.Movie{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/movies/:id", Tag: "movies", Summary: "Get a movie; a bearer token is optional and adds watched", Access: public,
+			Query: []openapi.Parameter{fieldsQuery}, Response: models.Movie{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodPost, Path: "/movies", Tag: "movies", Summary: "Create a movie", Access: admin,
+			Query:   []openapi.Parameter{openapi.Query("allow_duplicate", "boolean", "skip the title and year duplicate check")},
+			Request: models.CreateMovieRequest{}, Status: http.StatusCreated, Response: models.Movie{}, Errors: []int{bad, conflict}},
+		{Method: http.MethodPut, Path: "/movies/:id", Tag: "movies", Summary: "Update a movie", Access: admin,
+			Request: models.UpdateMovieRequest{}, Response: models.Movie{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodDelete, Path: "/movies/:id", Tag: "movies", Summary: "Delete a movie", Access: admin,
+			Query: returnQuery, Status: http.StatusNoContent, Errors: []int{bad, notFound}},
+		{Method: http.MethodPost, Path: "/movies/:id/feature", Tag: "movies", Summary: "Pin a movie to the homepage", Access: admin,
+			Response: models.Movie{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodPost, Path: "/movies/:id/unfeature", Tag: "movies", Summary: "Unpin a movie from the homepage", Access: admin,
+			Response: models.Movie{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodGet, Path: "/admin/movies/:id/history", Tag: "movies", Summary: "Earlier versions of a movie's details, newest first", Access: admin,
+			Query: pageQuery, Response: openapi.Page{Of: models.MovieVersion{}}, Errors: []int{bad, notFound}},
+		{Method: http.MethodPost, Path: "/admin/movies/:id/history/:version_id/revert", Tag: "movies", Summary: "Restore a movie's details from one of its versions", Access: admin,
+			Response: models.Movie{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodPost, Path: "/movies/:id/poster", Tag: "movies", Summary: "Upload a movie poster (JPEG, PNG or WebP, up to 5 MB)", Access: admin,
+			Request: openapi.Upload{Field: "poster"}, Response: models.Movie{},
+			Errors: []int{bad, notFound, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType}},
+
+		{Method: http.MethodGet, Path: "/directors", Tag: "movies", Summary: "Directors and their movie counts, for autocomplete", Access: public,
+			Query:    []openapi.Parameter{openapi.Query("q", "string", "director name substring, ignoring case"), openapi.Query("limit", "integer", "maximum number of directors")},
+			Response: openapi.List{Of: models.DirectorCount{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/directors/:name/movies", Tag: "movies", Summary: "A director's filmography, matched case-insensitively", Access: public,
+			Query: pageQuery, Response: openapi.Page{Of: models.Movie{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/directors/:name/similar", Tag: "movies", Summary: "Directors most often reviewed by the same users", Access: public,
+			Query: []openapi.Parameter{openapi.Query("limit", "integer", "maximum number of directors")}, Response: openapi.List{Of: models.SimilarDirector{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/directors/:name/stats", Tag: "movies", Summary: "Movie count, average rating and best-rated movie of a director", Access: public,
+			Response: models.DirectorStats{}, Errors: []int{notFound}},
+		{Method: http.MethodGet, Path: "/years/:year/summary", Tag: "movies", Summary: "Best and most reviewed movie, top reviewers and totals for the movies released in a year", Access: public,
+			Response: models.YearSummary{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodGet, Path: "/search", Tag: "search", Summary: "Site-wide search: up to 5 movies and genres, and optionally users, matching q", Access: public,
+			Query: []openapi.Parameter{
+				openapi.Query("q", "string", "search text, at least 2 characters"),
+				openapi.Query("include_users", "boolean", "also match usernames"),
+			},
+			Response: models.SearchResults{}, Errors: []int{bad, http.StatusTooManyRequests}},
+
+		{Method: http.MethodGet, Path: "/movies/:id/reviews", Tag: "reviews", Summary: "List reviews of a movie", Access: public,
+			Query:    withPage(reviewFilterQuery()...),
+			Response: openapi.List{Of: models.Review{}}, Errors: []int{bad}},
+		{Method: http.MethodPost, Path: "/movies/:id/reviews", Tag: "reviews", Summary: "Review a movie", Access: authed,
+			Request: models.CreateReviewRequest{}, Status: http.StatusCreated, Response: models.Review{},
+			Errors: []int{bad, http.StatusForbidden, notFound, conflict, http.StatusUnprocessableEntity}},
+		{Method: http.MethodGet, Path: "/reviews/:id", Tag: "reviews", Summary: "Get a review", Access: public,
+			Response: models.Review{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodPut, Path: "/reviews/:id", Tag: "reviews", Summary: "Update own review", Access: authed,
+			Request: models.UpdateReviewRequest{}, Response: models.Review{}, Errors: []int{bad, http.StatusForbidden, notFound, http.StatusUnprocessableEntity}},
+		{Method: http.MethodPatch, Path: "/reviews/:id", Tag: "reviews", Summary: "Update only the given fields of own review", Access: authed,
+			Request: models.UpdateReviewRequest{}, Response: models.Review{}, Errors: []int{bad, http.StatusForbidden, notFound, http.StatusUnprocessableEntity}},
+		{Method: http.MethodDelete, Path: "/reviews/:id", Tag: "reviews", Summary: "Delete own review", Access: authed,
+			Query: returnQuery, Status: http.StatusNoContent, Errors: []int{bad, http.StatusForbidden, notFound}},
+		{Method: http.MethodPost, Path: "/reviews/:id/react", Tag: "reviews", Summary: "React to a review (helpful, insightful, funny or disagree), replacing your earlier reaction", Access: authed,
+			Request: models.ReactRequest{}, Response: models.Review{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodDelete, Path: "/reviews/:id/react", Tag: "reviews", Summary: "Remove your reaction to a review", Access: authed,
+			Response: models.Review{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodGet, Path: "/users/:id/reviews", Tag: "reviews", Summary: "List reviews written by a user; private reviews are shown only to the user and admins, so a bearer token is optional", Access: public,
+			Query:    withPage(reviewFilterQuery()...),
+			Response: openapi.Page{Of: models.Review{}}, Errors: []int{bad, http.StatusForbidden, notFound}},
+		{Method: http.MethodGet, Path: "/users/:id/profile", Tag: "users", Summary: "Public profile of a user: username, join date and review stats, without the email", Access: public,
+			Response: models.PublicUserProfile{}, Errors: []int{bad, notFound}},
+
+		{Method: http.MethodPost, Path: "/graphql", Tag: "graphql", Summary: "GraphQL endpoint; a bearer token is optional and enables me and the review mutations", Access: public,
+			Request:  openapi.Object{"query": "", "operationName": openapi.Optional{Of: ""}, "variables": openapi.Optional{Of: &openapi.Schema{Type: "object"}}},
+			Response: openapi.Object{"data": &openapi.Schema{Type: "object"}, "errors": openapi.Optional{Of: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "object"}}}},
+			Errors:   []int{http.StatusUnauthorized, http.StatusUnprocessableEntity}},
+
+		{Method: http.MethodGet, Path: "/me", Tag: "me", Summary: "Current user profile with review statistics", Access: authed,
+			Response: openapi.Object{"user": models.User{}, "reviews_count": 0, "average_rating": openapi.Optional{Of: 0.0}, "favorite_genre": openapi.Optional{Of: models.Genre{}}},
+			Errors:   []int{notFound}},
+		{Method: http.MethodPut, Path: "/me", Tag: "me", Summary: "Update own profile", Access: authed,
+			Request: models.UpdateUserRequest{}, Response: models.User{}, Errors: []int{bad, notFound, conflict}},
+		{Method: http.MethodPut, Path: "/me/password", Tag: "me", Summary: "Change own password", Access: authed,
+			Request: models.UpdatePasswordRequest{}, Status: http.StatusNoContent, Errors: []int{bad}},
+		{Method: http.MethodPut, Path: "/me/privacy", Tag: "me", Summary: "Choose whether other users may list your reviews", Access: authed,
+			Request: models.UpdatePrivacyRequest{}, Status: http.StatusNoContent, Errors: []int{bad, notFound}},
+		{Method: http.MethodGet, Path: "/me/reviews", Tag: "me", Summary: "List own reviews", Access: authed,
+			Query: withPage(reviewFilterQuery()...), Response: openapi.Page{Of: models.Review{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/me/activity", Tag: "me", Summary: "Own activity summary for the profile header", Access: authed,
+			Response: models.AccountActivity{}},
+		{Method: http.MethodGet, Path: "/me/audit-logs", Tag: "me", Summary: "Audit entries about the current user", Access: authed,
+			Query: pageQuery, Response: openapi.Page{Of: models.OwnAuditLog{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/me/notifications", Tag: "me", Summary: "Notifications for the current user, newest first", Access: authed,
+			Query: pageQuery, Response: openapi.Page{Of: models.Notification{}}, Errors: []int{bad}},
+		{Method: http.MethodPost, Path: "/me/watch-history", Tag: "me", Summary: "Mark a movie as watched, replacing any earlier entry for it", Access: authed,
+			Request: models.RecordWatchRequest{}, Response: models.WatchHistory{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodGet, Path: "/me/watch-history", Tag: "me", Summary: "Own watch history, most recently watched first", Access: authed,
+			Query: pageQuery, Response: openapi.Page{Of: models.WatchHistory{}}, Errors: []int{bad}},
+		{Method: http.MethodDelete, Path: "/me/watch-history/:movieID", Tag: "me", Summary: "Remove a movie from own watch history", Access: authed,
+			Status: http.StatusNoContent, Errors: []int{bad, notFound}},
+
+		{Method: http.MethodGet, Path: "/users", Tag: "users", Summary: "List users", Access: admin,
+			Query: withPage(
+				openapi.Query("search", "string", "email or username"),
+				openapi.Query("role", "string", "user or admin"),
+				openapi.Query("min_reviews", "integer", "minimum number of reviews written"),
+				openapi.Query("max_reviews", "integer", "maximum number of reviews written"),
+				formatQuery,
+			),
+			Response: openapi.Page{Of: models.User{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/users/:id", Tag: "users", Summary: "Get a user", Access: admin,
+			Response: models.User{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodGet, Path: "/admin/users/lookup", Tag: "users", Summary: "Find a user by exact email or username", Access: admin,
+			Query: []openapi.Parameter{
+				openapi.Query("email", "string", "email to look up; give either this or username"),
+				openapi.Query("username", "string", "username to look up; give either this or email"),
+			},
+			Response: models.User{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodPut, Path: "/users/:id", Tag: "users", Summary: "Update a user", Access: admin,
+			Request: models.UpdateUserRequest{}, Response: models.User{}, Errors: []int{bad, notFound, conflict}},
+		{Method: http.MethodPut, Path: "/users/:id/role", Tag: "users", Summary: "Change a user's role", Access: admin,
+			Request: updateRoleRequest{}, Status: http.StatusNoContent, Errors: []int{bad, notFound}},
+		{Method: http.MethodPost, Path: "/admin/users/bulk-role", Tag: "users", Summary: "Change the role of up to 50 users", Access: admin,
+			Request: bulkRoleRequest{}, Response: bulkRoleResponse{}, Errors: []int{bad}},
+		{Method: http.MethodPost, Path: "/admin/users/merge", Tag: "users", Summary: "Merge a duplicate account into another and soft-delete it", Access: admin,
+			Request: mergeAccountsRequest{}, Status: http.StatusNoContent, Errors: []int{bad, notFound}},
+		{Method: http.MethodPost, Path: "/admin/users/:id/impersonate", Tag: "users", Summary: "Issue a 15-minute token acting as a user", Access: admin,
+			Response: models.AuthResponse{}, Errors: []int{bad, http.StatusForbidden, notFound}},
+		{Method: http.MethodDelete, Path: "/users/:id", Tag: "users", Summary: "Delete a user", Access: admin,
+			Status: http.StatusNoContent, Errors: []int{bad, notFound}},
+
+		{Method: http.MethodGet, Path: "/stats", Tag: "admin", Summary: "System statistics, cached for a short time", Access: admin,
+			Query:    []openapi.Parameter{openapi.Query("refresh", "boolean", "bypass the cache")},
+			Response: models.AdminStats{}},
+		{Method: http.MethodGet, Path: "/stats/movies-by-decade", Tag: "admin", Summary: "Movie counts per release decade", Access: admin,
+			Query:    []openapi.Parameter{openapi.Query("include_empty", "boolean", "include decades without movies")},
+			Response: openapi.List{Of: models.DecadeCount{}}},
+		{Method: http.MethodGet, Path: "/audit-logs", Tag: "admin", Summary: "List audit log entries", Access: admin,
+			Query:    withPage(append(auditLogFilterQuery(), openapi.Query("sort", "string", "created_desc (default) or created_asc"))...),
+			Response: openapi.Page{Of: models.AuditLog{}}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/audit-logs/export", Tag: "admin", Summary: "Stream audit log entries as NDJSON, one entry per line in ID order", Access: admin,
+			Query: append(auditLogFilterQuery(), exportQuery...), Response: models.AuditLog{}, Errors: []int{bad}},
+		{Method: http.MethodPost, Path: "/admin/reviews/delete", Tag: "admin", Summary: "Delete up to 100 reviews in one transaction, reporting the result per ID", Access: admin,
+			Request: bulkDeleteReviewsRequest{}, Response: bulkDeleteReviewsResponse{}, Errors: []int{bad}},
+		{Method: http.MethodGet, Path: "/admin/analytics/reviews", Tag: "admin", Summary: "Review quality metrics: flagged share, average length and rating distribution", Access: admin,
+			Response: models.ReviewAnalytics{}},
+		{Method: http.MethodGet, Path: "/reviews/export", Tag: "admin", Summary: "Stream reviews as NDJSON, one review per line in ID order", Access: admin,
+			Query: append([]openapi.Parameter{
+				openapi.Query("min_rating", "integer", "minimum rating"),
+				openapi.Query("max_rating", "integer", "maximum rating"),
+			}, exportQuery...),
+			Response: models.Review{}, Errors: []int{bad}},
+		{Method: http.MethodPost, Path: "/admin/movies/import/preview", Tag: "admin", Summary: "Validate a movie CSV without importing it", Access: admin,
+			Request: openapi.Upload{Field: "file"}, Response: importPreviewResponse{}, Errors: []int{bad}},
+		{Method: http.MethodPost, Path: "/admin/recalculate-ratings", Tag: "admin", Summary: "Start recalculating every movie's average rating", Access: admin,
+			Status: http.StatusAccepted, Response: service.RatingJobStatus{}, Errors: []int{conflict}},
+		{Method: http.MethodGet, Path: "/admin/recalculate-ratings/status", Tag: "admin", Summary: "Progress of the rating recalculation", Access: admin,
+			Response: service.RatingJobStatus{}},
+		{Method: http.MethodGet, Path: "/admin/config", Tag: "admin", Summary: "Effective configuration without secrets", Access: admin,
+			Response: openapi.Object{"config": config.Redacted{}, "build": version.Info{}}},
+		{Method: http.MethodGet, Path: "/admin/blocked-keywords", Tag: "admin", Summary: "Keywords reviews may not contain", Access: admin,
+			Response: openapi.List{Of: models.BlockedKeyword{}}},
+		{Method: http.MethodPost, Path: "/admin/blocked-keywords", Tag: "admin", Summary: "Block a keyword in reviews", Access: admin,
+			Request: models.BlockedKeywordRequest{}, Status: http.StatusCreated, Response: models.BlockedKeyword{}, Errors: []int{bad}},
+		{Method: http.MethodPost, Path: "/admin/blocked-keywords/check", Tag: "admin", Summary: "List the blocked keywords a text contains", Access: admin,
+			Request: models.CheckContentRequest{}, Response: openapi.Object{"matched": []string{}}, Errors: []int{bad}},
+		{Method: http.MethodPut, Path: "/admin/blocked-keywords/:id", Tag: "admin", Summary: "Update a blocked keyword", Access: admin,
+			Request: models.BlockedKeywordRequest{}, Response: models.BlockedKeyword{}, Errors: []int{bad, notFound}},
+		{Method: http.MethodDelete, Path: "/admin/blocked-keywords/:id", Tag: "admin", Summary: "Unblock a keyword", Access: admin,
+			Query: returnQuery, Status: http.StatusNoContent, Errors: []int{bad, notFound}},
+	}
+}
+
+func auditLogFilterQuery() []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("event", "string", "event name"),
+		openapi.Query("user_id", "integer", "affected user"),
+		openapi.Query("actor_id", "integer", "user who performed the action"),
+		openapi.Query("from_date", "string", "RFC3339 lower bound"),
+		openapi.Query("to_date", "string", "RFC3339 upper bound"),
+		openapi.Query("search", "string", "case-insensitive substring of the event or details"),
+	}
+}
+
+// exportQuery documents the parameters shared by the NDJSON exports.
+var exportQuery = []openapi.Parameter{
+	openapi.Query("format", "string", "ndjson (the default and only format)"),
+	openapi.Query("after_id", "integer", "resume after this id"),
+}
+
+func reviewFilterQuery() []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("min_rating", "integer", "minimum rating"),
+		openapi.Query("max_rating", "integer", "maximum rating"),
+		openapi.Query("sort", "string", "sort order"),
+		formatQuery,
+	}
+}
+
+var (
+	specOnce sync.Once
+	spec     *openapi.Document
+)
+
+// APISpec returns the OpenAPI document for the /api/v1 routes.
+func APISpec() *openapi.Document {
+	specOnce.Do(func() {
+		b := openapi.NewBuilder(openapi.Info{
+			Title:   "Golang Project API",
+			Version: version.Version,
+		}, "/api/v1")
+		b.Add(apiRoutes()...)
+		spec = b.Document()
+	})
+	return spec
+}
+
+func OpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, APISpec())
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Golang Project API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>`
+
+func SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/config"
+	"golang-project/pkg/jwt"
+)
+
+func TestOpenAPISpec_CoversRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// sql.Open does not connect, which is enough for wiring repositories.
+	db, err := sql.Open("postgres", "postgres://localhost/unused?sslmode=disable")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	cfg := &config.Config{
+		JWTKeys:            jwt.StaticKeySet("secret"),
+		RateLimit:          60,
+		SearchRateLimit:    20,
+		MaxBodyBytes:       1 << 20,
+		CORSAllowedOrigins: []string{"*"},
+	}
+	router := SetupRoutes(db, cfg, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("openapi.json expected 200, got %d", w.Code)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("parse document: %v", err)
+	}
+	validateOpenAPI3(t, doc)
+
+	paths := doc["paths"].(map[string]interface{})
+	ginParam := regexp.MustCompile(`:([A-Za-z_]+)`)
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		path, ok := strings.CutPrefix(route.Path, "/api/v1")
+		if !ok {
+			continue
+		}
+		path = ginParam.ReplaceAllString(path, "{$1}")
+		key := strings.ToLower(route.Method) + " " + path
+		registered[key] = true
+
+		item, ok := paths[path].(map[string]interface{})
+		if !ok || item[strings.ToLower(route.Method)] == nil {
+			t.Errorf("route %s %s is not documented", route.Method, route.Path)
+		}
+	}
+	for path, item := range paths {
+		for method := range item.(map[string]interface{}) {
+			if !registered[method+" "+path] {
+				t.Errorf("documented operation %s %s is not registered", method, path)
+			}
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/docs", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/api/v1/openapi.json") {
+		t.Fatalf("docs expected swagger ui page, got %d", w.Code)
+	}
+}
+
+// validateOpenAPI3 checks the structural rules of OpenAPI 3.0 that the
+// builder could plausibly get wrong.
+func validateOpenAPI3(t *testing.T, doc map[string]interface{}) {
+	t.Helper()
+
+	if v, _ := doc["openapi"].(string); !strings.HasPrefix(v, "3.0.") {
+		t.Fatalf("expected openapi 3.0.x, got %q", v)
+	}
+	info, _ := doc["info"].(map[string]interface{})
+	if info["title"] == "" || info["version"] == nil {
+		t.Fatalf("info.title and info.version are required, got %v", info)
+	}
+	components := doc["components"].(map[string]interface{})
+	schemas := components["schemas"].(map[string]interface{})
+	schemes := components["securitySchemes"].(map[string]interface{})
+	if bearer, ok := schemes["bearerAuth"].(map[string]interface{}); !ok || bearer["scheme"] != "bearer" {
+		t.Fatalf("expected bearer security scheme, got %v", schemes)
+	}
+
+	var checkRefs func(where string, node interface{})
+	checkRefs = func(where string, node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := n["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				if _, ok := schemas[name]; !ok {
+					t.Errorf("%s: unresolved $ref %s", where, ref)
+				}
+			}
+			for _, v := range n {
+				checkRefs(where, v)
+			}
+		case []interface{}:
+			for _, v := range n {
+				checkRefs(where, v)
+			}
+		}
+	}
+	checkRefs("components", components)
+
+	operationIDs := make(map[string]bool)
+	pathParam := regexp.MustCompile(`\{([^}]+)\}`)
+	for path, rawItem := range doc["paths"].(map[string]interface{}) {
+		if !strings.HasPrefix(path, "/") {
+			t.Errorf("path %q must start with /", path)
+		}
+		for method, rawOp := range rawItem.(map[string]interface{}) {
+			where := method + " " + path
+			op := rawOp.(map[string]interface{})
+			checkRefs(where, op)
+
+			id, _ := op["operationId"].(string)
+			if id == "" || operationIDs[id] {
+				t.Errorf("%s: operationId %q missing or duplicated", where, id)
+			}
+			operationIDs[id] = true
+
+			responses, _ := op["responses"].(map[string]interface{})
+			if len(responses) == 0 {
+				t.Errorf("%s: no responses", where)
+			}
+			for code, resp := range responses {
+				if desc, _ := resp.(map[string]interface{})["description"].(string); desc == "" {
+					t.Errorf("%s: response %s has no description", where, code)
+				}
+			}
+
+			declared := make(map[string]bool)
+			params, _ := op["parameters"].([]interface{})
+			for _, raw := range params {
+				p := raw.(map[string]interface{})
+				if p["in"] == "path" {
+					if p["required"] != true {
+						t.Errorf("%s: path parameter %v must be required", where, p["name"])
+					}
+					declared[p["name"].(string)] = true
+				}
+			}
+			for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+				if !declared[m[1]] {
+					t.Errorf("%s: path parameter %s is not declared", where, m[1])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/models"
+)
+
+var errInvalidPagination = apierror.New(http.StatusBadRequest, "invalid_pagination", "page and limit must be positive integers")
+
+// ParsePagination reads ?page= and ?limit=, defaulting to the first page of
+// defaultLimit items. Values that are not positive integers are rejected
+// rather than silently replaced by the defaults, and limit is capped to
+// models.MaxPageLimit. So is a page whose offset would exceed maxOffset.
+func ParsePagination(c *gin.Context, defaultLimit int) (models.PaginationParams, error) {
+	page, err := positiveQuery(c, "page", 1)
+	if err != nil {
+		return models.PaginationParams{}, err
+	}
+	limit, err := parseLimit(c, defaultLimit)
+	if err != nil {
+		return models.PaginationParams{}, err
+	}
+	if page-1 > maxOffset/limit {
+		return models.PaginationParams{}, errInvalidPagination.WithDetails(map[string]any{"param": "page", "value": c.Query("page")})
+	}
+	return models.PaginationParams{Page: page, Limit: limit}, nil
+}
+
+// maxOffset bounds (page-1)*limit well below the point where it, or the
+// page arithmetic built on it, would overflow.
+const maxOffset = math.MaxInt32
+
+// parseLimit reads ?limit= the way ParsePagination does, for lists that
+// are not paged.
+func parseLimit(c *gin.Context, defaultLimit int) (int, error) {
+	limit, err := positiveQuery(c, "limit", defaultLimit)
+	if err != nil {
+		return 0, err
+	}
+	return min(limit, models.MaxPageLimit), nil
+}
+
+func positiveQuery(c *gin.Context, name string, def int) (int, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, errInvalidPagination.WithDetails(map[string]any{"param": name, "value": raw})
+	}
+	return n, nil
+}
+
+// respondPage writes resp with its has_next and has_prev flags filled in and
+// a Link header pointing at the neighbouring, first and last pages.
+func respondPage(c *gin.Context, resp *models.PaginatedResponse) {
+	resp.HasNext = resp.Page < resp.TotalPages
+	resp.HasPrev = resp.Page > 1
+	if links := pageLinks(c.Request.URL, resp.Page, resp.Limit, resp.TotalPages); links != "" {
+		c.Header("Link", links)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// pageLinks builds an RFC 5988 Link header value for page out of
+// totalPages. Every link keeps the path and query of u, only page and limit
+// change. An empty result has at least one page.
+func pageLinks(u *url.URL, page, limit, totalPages int) string {
+	last := max(totalPages, 1)
+	link := func(p int, rel string) string {
+		q := u.Query()
+		q.Set("page", strconv.Itoa(p))
+		q.Set("limit", strconv.Itoa(limit))
+		return "<" + u.Path + "?" + q.Encode() + `>; rel="` + rel + `"`
+	}
+
+	var links []string
+	if page < totalPages {
+		links = append(links, link(page+1, "next"))
+	}
+	if page > 1 {
+		links = append(links, link(min(page-1, last), "prev"))
+	}
+	links = append(links, link(1, "first"), link(last, "last"))
+	return strings.Join(links, ", ")
+}
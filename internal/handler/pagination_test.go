@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+func TestPageLinks(t *testing.T) {
+	u, _ := url.Parse("/api/v1/movies?genre=drama&director_in=Nolan%7CKubrick&page=2&limit=5&sort=year_desc")
+
+	tests := []struct {
+		name                   string
+		page, limit, totalPage int
+		want                   string
+	}{
+		{"middle page", 2, 5, 4,
+			`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=3&sort=year_desc>; rel="next", ` +
+				`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=1&sort=year_desc>; rel="prev", ` +
+				`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=1&sort=year_desc>; rel="first", ` +
+				`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=4&sort=year_desc>; rel="last"`},
+		{"first page", 1, 5, 2,
+			`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=2&sort=year_desc>; rel="next", ` +
+				`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=1&sort=year_desc>; rel="first", ` +
+				`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=2&sort=year_desc>; rel="last"`},
+		{"no results", 1, 5, 0,
+			`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=1&sort=year_desc>; rel="first", ` +
+				`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=1&sort=year_desc>; rel="last"`},
+		{"past the end", 9, 5, 3,
+			`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=3&sort=year_desc>; rel="prev", ` +
+				`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=1&sort=year_desc>; rel="first", ` +
+				`</api/v1/movies?director_in=Nolan%7CKubrick&genre=drama&limit=5&page=3&sort=year_desc>; rel="last"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageLinks(u, tt.page, tt.limit, tt.totalPage); got != tt.want {
+				t.Fatalf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRespondPage_MovieList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies, genres, _ := newMHRepos()
+	for i := 1; i <= 5; i++ {
+		movies.Add(&models.Movie{ID: i, Title: "Movie", ReleaseYear: 2000})
+	}
+	router := gin.New()
+	router.GET("/movies", NewMovieHandler(service.NewMovieService(movies, genres, validator.New())).List)
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?year=2000&limit=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	want := `</movies?limit=2&page=2&year=2000>; rel="next", </movies?limit=2&page=1&year=2000>; rel="first", </movies?limit=2&page=3&year=2000>; rel="last"`
+	if got := w.Header().Get("Link"); got != want {
+		t.Fatalf("unexpected Link header\ngot  %s\nwant %s", got, want)
+	}
+
+	var resp models.PaginatedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if !resp.HasNext || resp.HasPrev {
+		t.Fatalf("expected has_next and not has_prev on page 1, got %+v", resp)
+	}
+}
+
+func TestParsePagination_RejectsInvalidValues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies, genres, _ := newMHRepos()
+	movieSvc := service.NewMovieService(movies, genres, validator.New())
+	router := gin.New()
+	router.GET("/movies", NewMovieHandler(movieSvc).List)
+	router.GET("/movies/controversial", NewMovieHandler(movieSvc).ListControversial)
+	router.GET("/directors/:name/movies", NewDirectorHandler(movieSvc).Movies)
+	router.GET("/movies/:id/reviews", NewReviewHandler(service.NewReviewService(testutil.NewMemReviewRepo(), movies, validator.New(), nil)).ListByMovie)
+	users, repos := newUserHandler()
+	repos.users.Add(&models.User{ID: 1, Email: "u1@example.com", Username: "user1"})
+	router.GET("/users/:id/reviews", users.UserReviews)
+	router.GET("/audit-logs", users.ListAuditLogs)
+
+	for _, target := range []string{
+		"/movies?page=abc",
+		"/movies?limit=-5",
+		"/movies?page=0",
+		"/movies/controversial?limit=ten",
+		"/directors/nolan/movies?limit=-5",
+		"/movies/1/reviews?page=abc",
+		"/users/1/reviews?limit=1.5",
+		"/audit-logs?page=-1",
+		"/movies?page=9223372036854775807&limit=100",
+		"/movies/1/reviews?page=21474838&limit=100",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, w.Code)
+		}
+		var resp apierror.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: parse response: %v", target, err)
+		}
+		if resp.Error.Code != "invalid_pagination" {
+			t.Fatalf("%s: expected invalid_pagination, got %+v", target, resp.Error)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?page=2&limit=5", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected valid values to be accepted, got %d", w.Code)
+	}
+}
+
+func TestParsePagination_CapsLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies, genres, _ := newMHRepos()
+	for i := 1; i <= models.MaxPageLimit+5; i++ {
+		movies.Add(&models.Movie{ID: i, Title: "Movie", ReleaseYear: 2000})
+	}
+	router := gin.New()
+	router.GET("/movies", NewMovieHandler(service.NewMovieService(movies, genres, validator.New())).List)
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?limit=100000", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected a huge limit to be capped, not rejected, got %d", w.Code)
+	}
+	var resp struct {
+		Data  []models.Movie `json:"data"`
+		Limit int            `json:"limit"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Limit != models.MaxPageLimit || len(resp.Data) != models.MaxPageLimit {
+		t.Fatalf("expected %d movies with limit %d, got %d with limit %d", models.MaxPageLimit, models.MaxPageLimit, len(resp.Data), resp.Limit)
+	}
+	if link := w.Header().Get("Link"); !strings.Contains(link, "limit=100&page=2") {
+		t.Fatalf("expected the Link header to use the capped limit, got %s", link)
+	}
+}
+
+func TestParsePagination_Params(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for query, want := range map[string]models.PaginationParams{
+		"":                   {Page: 1, Limit: 20},
+		"page=3&limit=5":     {Page: 3, Limit: 5},
+		"limit=100000":       {Page: 1, Limit: models.MaxPageLimit},
+		"page=2&limit=101":   {Page: 2, Limit: models.MaxPageLimit},
+		"page=7&unrelated=x": {Page: 7, Limit: 20},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		got, err := ParsePagination(c, 20)
+		if err != nil || got != want {
+			t.Fatalf("%q: expected %+v, got %+v (%v)", query, want, got, err)
+		}
+		if err := validator.New().Struct(got); err != nil {
+			t.Fatalf("%q: %+v does not validate: %v", query, got, err)
+		}
+	}
+}
+
+func TestMovieList_GenreCountsMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies, genres, dramaID := newMHRepos()
+	genres.Add(&models.Genre{ID: 2, Name: "Comedy"})
+	for i := 1; i <= 3; i++ {
+		movies.Add(&models.Movie{ID: i, Title: "Movie", ReleaseYear: 2000}, dramaID)
+	}
+	router := gin.New()
+	router.GET("/movies", NewMovieHandler(service.NewMovieService(movies, genres, validator.New())).List)
+
+	var resp struct {
+		Meta *models.MovieListMeta `json:"meta"`
+	}
+	getJSON(t, router, "/movies?limit=1", &resp)
+	if resp.Meta == nil {
+		t.Fatal("expected meta in an unfiltered list")
+	}
+	if got := resp.Meta.GenreCounts; len(got) != 2 || got[dramaID] != 3 || got[2] != 0 {
+		t.Fatalf("unexpected genre counts %v", got)
+	}
+
+	resp.Meta = nil
+	getJSON(t, router, "/movies?genre_id=1", &resp)
+	if resp.Meta != nil {
+		t.Fatalf("expected no meta when filtering by genre, got %+v", resp.Meta)
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/config"
+	"golang-project/internal/service"
+	"golang-project/internal/storage"
+)
+
+// posterLinkTTL is how long a presigned poster link stays valid. Clients
+// are redirected on every request, so it only needs to outlive the download.
+const posterLinkTTL = 15 * time.Minute
+
+var errPosterNotFound = apierror.New(http.StatusNotFound, "poster_not_found", "poster not found")
+
+// newPosterStorage builds the storage backend selected by cfg.PosterStorage.
+func newPosterStorage(cfg *config.Config) (storage.Storage, error) {
+	if cfg.PosterStorage == "s3" {
+		return storage.NewS3(cfg.S3)
+	}
+	dir := cfg.PosterDir
+	if dir == "" {
+		dir = "uploads/posters"
+	}
+	return storage.NewLocal(dir), nil
+}
+
+// UploadPoster serves POST /movies/:id/poster. The image is sent as the
+// "poster" field of a multipart form.
+func (h *MovieHandler) UploadPoster(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	file, header, err := c.Request.FormFile("poster")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, service.ErrPosterTooLarge)
+			return
+		}
+		respondError(c, errInvalidRequest)
+		return
+	}
+	defer file.Close()
+	if header.Size > service.MaxPosterSize {
+		respondError(c, service.ErrPosterTooLarge)
+		return
+	}
+
+	movie, err := h.service.UploadPoster(c.Request.Context(), id, file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, movie)
+}
+
+type PosterHandler struct {
+	store storage.Storage
+}
+
+func NewPosterHandler(store storage.Storage) *PosterHandler {
+	return &PosterHandler{store: store}
+}
+
+// Serve serves GET /static/posters/:key. Backends that can presign links
+// redirect there; the rest stream the file. Keys are never reused, so
+// responses may be cached indefinitely.
+func (h *PosterHandler) Serve(c *gin.Context) {
+	key := c.Param("key")
+	if !storage.ValidKey(key) {
+		respondError(c, errPosterNotFound)
+		return
+	}
+	if p, ok := h.store.(storage.Presigner); ok {
+		link, err := p.PresignGet(key, posterLinkTTL)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.Redirect(http.StatusFound, link)
+		return
+	}
+
+	rc, err := h.store.Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			err = errPosterNotFound
+		}
+		respondError(c, err)
+		return
+	}
+	defer rc.Close()
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.DataFromReader(http.StatusOK, -1, mime.TypeByExtension(path.Ext(key)), rc, nil)
+}
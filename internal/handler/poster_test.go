@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/storage"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func uploadPoster(t *testing.T, router http.Handler, target string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreateFormFile("poster", "poster.bin")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMovieHandler_UploadPoster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	posters := storage.NewLocal(dir)
+	movies, genres, _ := newMHRepos()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat", ReleaseYear: 1995})
+	svc := service.NewMovieService(movies, genres, validator.New(), service.WithPosterStorage(posters))
+
+	router := gin.New()
+	router.POST("/movies/:id/poster", NewMovieHandler(svc).UploadPoster)
+	router.GET("/static/posters/:key", NewPosterHandler(posters).Serve)
+
+	upload := func(data string) string {
+		w := uploadPoster(t, router, "/movies/1/poster", []byte(data))
+		if w.Code != http.StatusOK {
+			t.Fatalf("upload expected 200, got %d: %s", w.Code, w.Body)
+		}
+		var movie models.Movie
+		if err := json.Unmarshal(w.Body.Bytes(), &movie); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		if !strings.HasPrefix(movie.PosterURL, service.PosterURLPrefix) || !strings.HasSuffix(movie.PosterURL, ".png") {
+			t.Fatalf("unexpected poster_url %q", movie.PosterURL)
+		}
+		return movie.PosterURL
+	}
+
+	first := upload(pngHeader + "first")
+	req := httptest.NewRequest(http.MethodGet, first, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != pngHeader+"first" || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("serve: got %d %q %q", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+
+	second := upload(pngHeader + "second")
+	if second == first {
+		t.Fatalf("expected a new poster key")
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || service.PosterURLPrefix+files[0].Name() != second {
+		t.Fatalf("expected only the new poster on disk, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, strings.TrimPrefix(first, service.PosterURLPrefix))); !os.IsNotExist(err) {
+		t.Fatalf("expected the old poster to be removed")
+	}
+
+	for name, tc := range map[string]struct {
+		target string
+		data   []byte
+		want   int
+	}{
+		"not an image":  {"/movies/1/poster", []byte("GIF89a not allowed"), http.StatusUnsupportedMediaType},
+		"too large":     {"/movies/1/poster", append([]byte(pngHeader), make([]byte, service.MaxPosterSize)...), http.StatusRequestEntityTooLarge},
+		"missing movie": {"/movies/2/poster", []byte(pngHeader), http.StatusNotFound},
+	} {
+		if w := uploadPoster(t, router, tc.target, tc.data); w.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d: %s", name, tc.want, w.Code, w.Body)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/static/posters/..", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an invalid key, got %d", w.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+)
+
+type ReactionHandler struct {
+	service *service.ReactService
+}
+
+func NewReactionHandler(s *service.ReactService) *ReactionHandler {
+	return &ReactionHandler{service: s}
+}
+
+// React sets the caller's reaction to a review, replacing any earlier one,
+// and returns the review with its reaction counts.
+func (h *ReactionHandler) React(c *gin.Context) {
+	reviewID, userID, ok := reactionParams(c)
+	if !ok {
+		return
+	}
+	var req models.ReactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+
+	review, err := h.service.React(c.Request.Context(), reviewID, userID, req.Reaction)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// Unreact removes the caller's reaction to a review.
+func (h *ReactionHandler) Unreact(c *gin.Context) {
+	reviewID, userID, ok := reactionParams(c)
+	if !ok {
+		return
+	}
+	review, err := h.service.Unreact(c.Request.Context(), reviewID, userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// reactionParams reads the review ID from the path and the caller's user ID,
+// responding with an error when either is missing.
+func reactionParams(c *gin.Context) (reviewID, userID int, ok bool) {
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return 0, 0, false
+	}
+	userID, err = currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return 0, 0, false
+	}
+	return reviewID, userID, true
+}
@@ -1,12 +1,13 @@
 package handler
 
 import (
-	"log"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"golang-project/internal/i18n"
 	"golang-project/internal/middleware"
 	"golang-project/internal/models"
 	"golang-project/internal/service"
@@ -24,22 +25,55 @@ func (h *ReviewHandler) ListByMovie(c *gin.Context) {
 	movieIDStr := c.Param("id")
 	movieID, err := strconv.Atoi(movieIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie id"})
+		respondError(c, errInvalidID)
+		return
+	}
+	pagination, err := ParsePagination(c, 10)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
 	filters := parseReviewFilters(c)
-	reviews, err := h.service.ListByMovie(c.Request.Context(), movieID, filters, page, limit)
+	if wantsCSV(c) {
+		writeReviewsCSV(c, "reviews.csv", func(page, limit int) ([]models.Review, error) {
+			return h.service.ListByMovie(c.Request.Context(), movieID, filters, page, limit)
+		})
+		return
+	}
+	// Any review change moves the movie's timestamp, including deletes and
+	// reviews landing on other pages, so it is checked before listing.
+	updated, err := h.service.ReviewsUpdatedAt(c.Request.Context(), movieID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if listNotModified(c, updated) {
+		return
+	}
+	reviews, err := h.service.ListByMovie(c.Request.Context(), movieID, filters, pagination.Page, pagination.Limit)
 	if err != nil {
-		log.Printf("ListByMovie error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reviews"})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": reviews})
 }
 
+func (h *ReviewHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	review, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
 func parseReviewFilters(c *gin.Context) models.ReviewFilters {
 	f := models.ReviewFilters{}
 	if minStr := c.Query("min_rating"); minStr != "" {
@@ -60,100 +94,72 @@ func (h *ReviewHandler) Create(c *gin.Context) {
 	movieIDStr := c.Param("id")
 	movieID, err := strconv.Atoi(movieIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movie id"})
+		respondError(c, errInvalidID)
 		return
 	}
 
-	val, ok := c.Get(string(middleware.ContextUserID))
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-	userIDStr, ok := val.(string)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
-		return
-	}
-	userID, err := strconv.Atoi(userIDStr)
+	userID, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		respondError(c, err)
 		return
 	}
 
 	var req models.CreateReviewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 
 	if req.Rating < 1 || req.Rating > 10 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "rating must be between 1 and 10"})
+		respondError(c, errInvalidRating)
 		return
 	}
 
-	review, err := h.service.Create(c.Request.Context(), movieID, userID, req)
+	roleVal, _ := c.Get(string(middleware.ContextRole))
+	isAdmin := roleVal == "admin"
+
+	review, err := h.service.Create(c.Request.Context(), movieID, userID, req, isAdmin)
 	if err != nil {
-		log.Printf("CreateReview error: %v", err)
-		switch err {
-		case service.ErrMovieNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "movie not found"})
-		case service.ErrReviewExists:
-			c.JSON(http.StatusConflict, gin.H{"error": "review already exists"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-		}
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, review)
 }
 
+// Update serves both PUT and PATCH: fields left out of the body keep their
+// current value.
 func (h *ReviewHandler) Update(c *gin.Context) {
 	reviewIDStr := c.Param("id")
 	reviewID, err := strconv.Atoi(reviewIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		respondError(c, errInvalidID)
 		return
 	}
 
-	val, ok := c.Get(string(middleware.ContextUserID))
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-	userIDStr, ok := val.(string)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
-		return
-	}
-	userID, err := strconv.Atoi(userIDStr)
+	userID, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		respondError(c, err)
 		return
 	}
 
 	var req models.UpdateReviewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 
-	if req.Rating != 0 && (req.Rating < 1 || req.Rating > 10) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "rating must be between 1 and 10"})
+	if req.Rating != nil && (*req.Rating < 1 || *req.Rating > 10) {
+		respondError(c, errInvalidRating)
 		return
 	}
 
-	review, err := h.service.Update(c.Request.Context(), reviewID, userID, req)
+	roleVal, _ := c.Get(string(middleware.ContextRole))
+	isAdmin := roleVal == "admin"
+
+	review, err := h.service.Update(c.Request.Context(), reviewID, userID, req, isAdmin)
 	if err != nil {
-		log.Printf("UpdateReview error: %v", err)
-		switch err {
-		case service.ErrReviewNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
-		case service.ErrInvalidCredentials:
-			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-		}
+		respondError(c, ownershipError(err))
 		return
 	}
 
@@ -164,41 +170,82 @@ func (h *ReviewHandler) Delete(c *gin.Context) {
 	reviewIDStr := c.Param("id")
 	reviewID, err := strconv.Atoi(reviewIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		respondError(c, errInvalidID)
 		return
 	}
 
-	val, ok := c.Get(string(middleware.ContextUserID))
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-	userIDStr, ok := val.(string)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
-		return
-	}
-	userID, err := strconv.Atoi(userIDStr)
+	userID, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		respondError(c, err)
 		return
 	}
 
 	roleVal, _ := c.Get(string(middleware.ContextRole))
 	isAdmin := roleVal == "admin"
 
-	if err := h.service.Delete(c.Request.Context(), reviewID, userID, isAdmin); err != nil {
-		log.Printf("DeleteReview error: %v", err)
-		switch err {
-		case service.ErrReviewNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
-		case service.ErrInvalidCredentials:
-			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete review"})
-		}
+	review, err := h.service.Delete(c.Request.Context(), reviewID, userID, isAdmin)
+	if err != nil {
+		respondError(c, ownershipError(err))
+		return
+	}
+
+	respondDeleted(c, review)
+}
+
+type bulkDeleteReviewsRequest struct {
+	ReviewIDs []int `json:"review_ids"`
+}
+
+type bulkDeleteReviewResult struct {
+	ReviewID int    `json:"review_id"`
+	Deleted  bool   `json:"deleted"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type bulkDeleteReviewsResponse struct {
+	Deleted int                      `json:"deleted"`
+	Results []bulkDeleteReviewResult `json:"results"`
+}
+
+// BulkDelete serves POST /admin/reviews/delete. Each requested ID gets a
+// result in request order; IDs without a review are reported with the code
+// a single delete would return.
+func (h *ReviewHandler) BulkDelete(c *gin.Context) {
+	var req bulkDeleteReviewsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errInvalidRequest)
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	results, err := h.service.BulkDelete(c.Request.Context(), req.ReviewIDs)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	resp := bulkDeleteReviewsResponse{Results: make([]bulkDeleteReviewResult, 0, len(results))}
+	locale := i18n.FromContext(c.Request.Context())
+	for _, r := range results {
+		if r.Err == nil {
+			resp.Deleted++
+			resp.Results = append(resp.Results, bulkDeleteReviewResult{ReviewID: r.ReviewID, Deleted: true})
+			continue
+		}
+		apiErr := toAPIError(r.Err)
+		resp.Results = append(resp.Results, bulkDeleteReviewResult{
+			ReviewID: r.ReviewID,
+			Code:     apiErr.Code,
+			Message:  i18n.Message(locale, apiErr.Code, apiErr.Message, apiErr.Details),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ownershipError reports ErrInvalidCredentials from the review service as 403:
+// the caller is authenticated but does not own the review.
+func ownershipError(err error) error {
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		return errNotOwner
+	}
+	return err
 }
@@ -0,0 +1,511 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/middleware"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+func TestReviewHandler_CreateEmbargo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	embargo := now.Add(time.Hour)
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Premiere", ReviewEmbargoUntil: &embargo})
+	svc := service.NewReviewService(testutil.NewMemReviewRepo(), movies, validator.New(), nil, service.WithClock(func() time.Time { return now }))
+	h := NewReviewHandler(svc)
+
+	router := gin.New()
+	router.POST("/movies/:id/reviews", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+		c.Set(string(middleware.ContextRole), c.GetHeader("X-Role"))
+		c.Next()
+	}, h.Create)
+
+	content := map[string]string{"2": "Worth the wait", "3": "Slow first act, great finale"}
+	post := func(userID, role string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.CreateReviewRequest{Rating: 8, Title: "Early look", Content: content[userID]})
+		req := httptest.NewRequest(http.MethodPost, "/movies/1/reviews", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", userID)
+		req.Header.Set("X-Role", role)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("2", "user")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 during embargo, got %d", w.Code)
+	}
+	var resp apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Error.Code != "review_embargoed" || resp.Error.Details["embargo_until"] != embargo.Format(time.RFC3339) {
+		t.Fatalf("expected review_embargoed until %s, got %+v", embargo.Format(time.RFC3339), resp.Error)
+	}
+
+	if w := post("3", "admin"); w.Code != http.StatusCreated {
+		t.Fatalf("expected admin to bypass embargo, got %d", w.Code)
+	}
+
+	now = embargo.Add(time.Minute)
+	if w := post("2", "user"); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 after embargo, got %d body %s", w.Code, w.Body.String())
+	}
+}
+
+func TestReviewHandler_CreateRejectsSimilarContent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	movies.Add(&models.Movie{ID: 2, Title: "Ronin"})
+	reviews := testutil.NewMemReviewRepo()
+	existing := &models.Review{MovieID: 1, UserID: 1, Rating: 9, Title: "Classic",
+		Content: "A tense, beautifully shot heist film with the best shootout ever filmed in downtown Los Angeles"}
+	reviews.Add(existing)
+	h := NewReviewHandler(service.NewReviewService(reviews, movies, validator.New(), nil))
+
+	router := gin.New()
+	router.POST("/movies/:id/reviews", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), "2")
+		c.Set(string(middleware.ContextRole), "user")
+	}, h.Create)
+	post := func(movieID int, content string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.CreateReviewRequest{Rating: 8, Title: "Great", Content: content})
+		req := httptest.NewRequest(http.MethodPost, "/movies/"+strconv.Itoa(movieID)+"/reviews", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	reworded := "A tense, beautifully shot heist film with the best shootout ever filmed in downtown LA"
+	w := post(1, reworded)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a near-copy, got %d body %s", w.Code, w.Body)
+	}
+	var resp apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Error.Code != "similar_review_exists" || resp.Error.Details["existing_id"] != float64(existing.ID) {
+		t.Fatalf("expected similar_review_exists pointing at %d, got %+v", existing.ID, resp.Error)
+	}
+
+	if w := post(2, reworded); w.Code != http.StatusCreated {
+		t.Fatalf("expected the same text on another movie to be accepted, got %d", w.Code)
+	}
+	if w := post(1, "Overlong, and the romance subplot goes nowhere"); w.Code != http.StatusCreated {
+		t.Fatalf("expected a different review to be accepted, got %d", w.Code)
+	}
+}
+
+func TestReviewHandler_CreateIncludesAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	users := testutil.NewMemUserRepo()
+	author := &models.User{Email: "critic@example.com", Username: "critic", Role: "user"}
+	users.Add(author)
+	svc := service.NewReviewService(testutil.NewMemReviewRepo(), movies, validator.New(), nil, service.WithUserLookup(users))
+	h := NewReviewHandler(svc)
+
+	router := gin.New()
+	router.POST("/movies/:id/reviews", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+		c.Set(string(middleware.ContextRole), "user")
+	}, h.Create)
+
+	body, _ := json.Marshal(models.CreateReviewRequest{Rating: 9, Title: "Classic", Content: "Still holds up"})
+	req := httptest.NewRequest(http.MethodPost, "/movies/1/reviews", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", strconv.Itoa(author.ID))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body)
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	user, _ := resp["user"].(map[string]any)
+	if user["username"] != "critic" {
+		t.Fatalf("expected the author's username, got %v", resp["user"])
+	}
+	if _, ok := user["email"]; ok {
+		t.Fatalf("author email must not be exposed: %v", user)
+	}
+}
+
+func TestReviewHandler_UpdateEditWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	posted := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := posted
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	reviews := testutil.NewMemReviewRepo()
+	reviews.Add(&models.Review{ID: 1, MovieID: 1, UserID: 2, Rating: 7, Title: "Good", Content: "Solid heist", CreatedAt: posted})
+	reviews.Add(&models.Review{ID: 2, MovieID: 1, UserID: 3, Rating: 7, Title: "Good", Content: "Great shootout", CreatedAt: posted})
+	svc := service.NewReviewService(reviews, movies, validator.New(), nil,
+		service.WithClock(func() time.Time { return now }), service.WithReviewEditWindow(time.Hour))
+	h := NewReviewHandler(svc)
+
+	router := gin.New()
+	router.PUT("/reviews/:id", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+		c.Set(string(middleware.ContextRole), c.GetHeader("X-Role"))
+	}, h.Update)
+
+	put := func(id, userID, role string) *httptest.ResponseRecorder {
+		body := []byte(`{"rating": 9}`)
+		req := httptest.NewRequest(http.MethodPut, "/reviews/"+id, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", userID)
+		req.Header.Set("X-Role", role)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	now = posted.Add(59 * time.Minute)
+	if w := put("1", "2", "user"); w.Code != http.StatusOK {
+		t.Fatalf("expected an edit within the window to succeed, got %d: %s", w.Code, w.Body)
+	}
+
+	now = posted.Add(61 * time.Minute)
+	w := put("1", "2", "user")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 after the window, got %d", w.Code)
+	}
+	var resp apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Error.Code != "edit_window_closed" {
+		t.Fatalf("expected edit_window_closed, got %+v", resp.Error)
+	}
+
+	if w := put("2", "3", "admin"); w.Code != http.StatusOK {
+		t.Fatalf("expected an admin to edit their review after the window, got %d", w.Code)
+	}
+}
+
+func TestReviewHandler_UpdatePartial(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// PATCH is served by the same handler as PUT, so both must be partial.
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		t.Run(method, func(t *testing.T) {
+			movies := testutil.NewMemMovieRepo()
+			movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+			reviews := testutil.NewMemReviewRepo()
+			reviews.Add(&models.Review{ID: 1, MovieID: 1, UserID: 2, Rating: 7, Title: "Good", Content: "Solid heist"})
+			h := NewReviewHandler(service.NewReviewService(reviews, movies, validator.New(), nil))
+
+			router := gin.New()
+			router.Handle(method, "/reviews/:id", func(c *gin.Context) {
+				c.Set(string(middleware.ContextUserID), "2")
+			}, h.Update)
+
+			update := func(body string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, "/reviews/1", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w
+			}
+			stored := func() models.Review {
+				review, err := reviews.GetByID(context.Background(), 1)
+				if err != nil {
+					t.Fatalf("get review: %v", err)
+				}
+				return *review
+			}
+
+			w := update(`{"rating": 9}`)
+			if w.Code != http.StatusOK {
+				t.Fatalf("only rating: expected 200, got %d: %s", w.Code, w.Body)
+			}
+			var got models.Review
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("parse response: %v", err)
+			}
+			if got.Rating != 9 || got.Title != "Good" || got.Content != "Solid heist" {
+				t.Fatalf("only rating: expected the response to keep title and content, got %+v", got)
+			}
+			if got := stored(); got.Rating != 9 || got.Title != "Good" || got.Content != "Solid heist" {
+				t.Fatalf("only rating: expected title and content to stay, got %+v", got)
+			}
+
+			if w := update(`{"content": "Best shootout ever filmed"}`); w.Code != http.StatusOK {
+				t.Fatalf("only content: expected 200, got %d: %s", w.Code, w.Body)
+			}
+			if got := stored(); got.Rating != 9 || got.Title != "Good" || got.Content != "Best shootout ever filmed" {
+				t.Fatalf("only content: expected rating and title to stay, got %+v", got)
+			}
+
+			if w := update(`{"title": ""}`); w.Code != http.StatusBadRequest {
+				t.Fatalf("empty title: expected 400, got %d: %s", w.Code, w.Body)
+			}
+			if got := stored(); got.Title != "Good" {
+				t.Fatalf("empty title: expected the title to stay, got %q", got.Title)
+			}
+
+			if w := update(`{"rating": 0}`); w.Code != http.StatusBadRequest {
+				t.Fatalf("zero rating: expected 400, got %d: %s", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestReactionHandler_ReactReplacesEarlierReaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reviews := testutil.NewMemReviewRepo()
+	reviews.Add(&models.Review{ID: 1, MovieID: 1, UserID: 1, Rating: 8, Title: "Solid", Content: "Good pacing"})
+	h := NewReactionHandler(service.NewReactService(testutil.NewMemReviewReactionRepo(), reviews))
+
+	router := gin.New()
+	withUser := func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+		c.Next()
+	}
+	router.POST("/reviews/:id/react", withUser, h.React)
+	router.DELETE("/reviews/:id/react", withUser, h.Unreact)
+
+	send := func(method, target, userID, reaction string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.ReactRequest{Reaction: reaction})
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	send(http.MethodPost, "/reviews/1/react", "2", "helpful")
+	send(http.MethodPost, "/reviews/1/react", "3", "funny")
+	w := send(http.MethodPost, "/reviews/1/react", "2", "funny")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body %s", w.Code, w.Body.String())
+	}
+	var review models.Review
+	if err := json.Unmarshal(w.Body.Bytes(), &review); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if got := review.ReactionCounts; got["helpful"] != 0 || got["funny"] != 2 || len(got) != len(models.ReactionTypes) {
+		t.Fatalf("unexpected reaction counts %v", got)
+	}
+
+	if w := send(http.MethodDelete, "/reviews/1/react", "3", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 removing a reaction, got %d", w.Code)
+	}
+	if w := send(http.MethodDelete, "/reviews/1/react", "3", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 removing a missing reaction, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/reviews/1/react", "2", "love"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown reaction, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/reviews/99/react", "2", "helpful"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown review, got %d", w.Code)
+	}
+}
+
+func TestReviewLists_IncludeReactionCounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	users := testutil.NewMemUserRepo()
+	users.Add(&models.User{ID: 1, Email: "a@example.com", Username: "alice"})
+	reviews := testutil.NewMemReviewRepo()
+	reviews.Add(&models.Review{ID: 1, MovieID: 1, UserID: 1, Rating: 8, Title: "Solid", Content: "Good pacing"})
+	reviews.Add(&models.Review{ID: 2, MovieID: 1, UserID: 2, Rating: 6, Title: "Long", Content: "Too long"})
+	reactions := testutil.NewMemReviewReactionRepo()
+	for userID, reaction := range map[int]string{2: "helpful", 3: "helpful", 4: "funny"} {
+		_ = reactions.Upsert(context.Background(), &models.ReviewReaction{ReviewID: 1, UserID: userID, Reaction: reaction})
+	}
+
+	v := validator.New()
+	reviewSvc := service.NewReviewService(reviews, movies, v, nil, service.WithReactionCounts(reactions))
+	userH := NewUserHandler(service.NewUserService(users, reviews, v, nil), reviewSvc, users, movies, reviews, testutil.NewMemGenreRepo(), testutil.NewMemAuditRepo(), nil)
+	router := gin.New()
+	router.GET("/movies/:id/reviews", NewReviewHandler(reviewSvc).ListByMovie)
+	router.GET("/me/reviews", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), "1")
+	}, userH.MyReviews)
+	router.GET("/users/:id/reviews", userH.UserReviews)
+
+	for _, target := range []string{"/movies/1/reviews", "/me/reviews", "/users/1/reviews"} {
+		var resp struct {
+			Data []models.Review `json:"data"`
+		}
+		getJSON(t, router, target, &resp)
+		counts := map[int]map[string]int{}
+		for _, r := range resp.Data {
+			counts[r.ID] = r.ReactionCounts
+		}
+		if got := counts[1]; got["helpful"] != 2 || got["funny"] != 1 || len(got) != len(models.ReactionTypes) {
+			t.Fatalf("%s: unexpected counts for review 1: %v", target, got)
+		}
+		if got, ok := counts[2]; ok && (len(got) != len(models.ReactionTypes) || got["helpful"] != 0) {
+			t.Fatalf("%s: expected zero counts for review 2, got %v", target, got)
+		}
+	}
+}
+
+func TestReviewHandler_BulkDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+	reviews := testutil.NewMemReviewRepo()
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	for userID := 1; userID <= 3; userID++ {
+		if err := reviews.Create(ctx, &models.Review{MovieID: 1, UserID: userID, Rating: 5}); err != nil {
+			t.Fatalf("create review: %v", err)
+		}
+	}
+	// A backed-up event queue must not cost the audit trail.
+	events := make(chan service.ReviewEvent, 1)
+	events <- service.ReviewEvent{Type: service.EventReviewCreated, MovieID: 1}
+	audit := testutil.NewMemAuditRepo()
+	h := NewReviewHandler(service.NewReviewService(reviews, movies, validator.New(), events, service.WithAuditWriter(audit)))
+
+	router := gin.New()
+	router.POST("/admin/reviews/delete", h.BulkDelete)
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/reviews/delete", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`{"review_ids":[3,99,1,3]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp bulkDeleteReviewsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	want := []bulkDeleteReviewResult{
+		{ReviewID: 3, Deleted: true},
+		{ReviewID: 99, Code: "review_not_found", Message: "review not found"},
+		{ReviewID: 1, Deleted: true},
+	}
+	if resp.Deleted != 2 || !slices.Equal(resp.Results, want) {
+		t.Fatalf("expected 2 deleted with results %+v, got %+v", want, resp)
+	}
+	if n, _ := reviews.Count(ctx); n != 1 {
+		t.Fatalf("expected 1 review left, got %d", n)
+	}
+	var audited []int
+	for _, entry := range audit.Logs() {
+		if entry.Event == string(service.EventReviewDeleted) && *entry.MovieID == 1 {
+			audited = append(audited, *entry.ReviewID)
+		}
+	}
+	if !slices.Equal(audited, []int{3, 1}) {
+		t.Fatalf("expected an audit entry per deleted review, got %v", audited)
+	}
+
+	for body, code := range map[string]string{
+		`{"review_ids":[]}`: "review_ids_required",
+		`{"review_ids":[` + strings.Repeat("1,", service.MaxBulkDeleteReviews) + `1]}`: "too_many_review_ids",
+	} {
+		if w := post(body); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), code) {
+			t.Fatalf("expected 400 %s, got %d: %s", code, w.Code, w.Body.String())
+		}
+	}
+}
+
+// queryContext returns a test context for a GET request with the raw query,
+// which need not be well-formed.
+func queryContext(rawQuery string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.URL.RawQuery = rawQuery
+	return c
+}
+
+func FuzzParseReviewFilters(f *testing.F) {
+	for _, seed := range []string{"", "min_rating=3&max_rating=8&sort=rating_desc", "min_rating=-1", "max_rating=99999999999999999999", "min_rating=%zz", "sort=;DROP"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, rawQuery string) {
+		parseReviewFilters(queryContext(rawQuery))
+	})
+}
+
+func TestReviewHandler_DeleteReturnFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	reviews := testutil.NewMemReviewRepo()
+	reviews.Add(&models.Review{ID: 1, MovieID: 1, UserID: 5, Rating: 6, Title: "Car chases"})
+	reviews.Add(&models.Review{ID: 2, MovieID: 1, UserID: 5, Rating: 9, Title: "Twisty", Content: "Keeps you guessing."})
+	h := NewReviewHandler(service.NewReviewService(reviews, movies, validator.New(), nil))
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+		c.Set(string(middleware.ContextRole), "user")
+	})
+	router.DELETE("/reviews/:id", h.Delete)
+
+	do := func(target, userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, target, nil)
+		req.Header.Set("X-User", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("/reviews/1", "5"); w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("expected an empty 204, got %d %s", w.Code, w.Body)
+	}
+
+	// Someone else's review is neither deleted nor shown.
+	if w := do("/reviews/2?return=true", "6"); w.Code != http.StatusForbidden || strings.Contains(w.Body.String(), "Twisty") {
+		t.Fatalf("expected 403 without the review, got %d %s", w.Code, w.Body)
+	}
+
+	w := do("/reviews/2?return=true", "5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with the deleted review, got %d %s", w.Code, w.Body)
+	}
+	var deleted models.Review
+	if err := json.Unmarshal(w.Body.Bytes(), &deleted); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if deleted.ID != 2 || deleted.MovieID != 1 || deleted.UserID != 5 || deleted.Rating != 9 || deleted.Title != "Twisty" || deleted.Content != "Keeps you guessing." {
+		t.Fatalf("expected the deleted review, got %+v", deleted)
+	}
+	if _, err := reviews.GetByID(context.Background(), 2); err == nil {
+		t.Fatal("expected the review to be gone")
+	}
+}
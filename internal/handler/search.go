@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/service"
+)
+
+type SearchHandler struct {
+	service *service.SearchService
+}
+
+func NewSearchHandler(s *service.SearchService) *SearchHandler {
+	return &SearchHandler{service: s}
+}
+
+// Search returns up to five movies and genres matching ?q, plus usernames
+// with ?include_users=true.
+func (h *SearchHandler) Search(c *gin.Context) {
+	results, err := h.service.Search(c.Request.Context(), c.Query("q"), c.Query("include_users") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
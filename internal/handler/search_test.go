@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/middleware"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+func TestSearchHandler_Search(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	movies := testutil.NewMemMovieRepo()
+	for i := 1; i <= 7; i++ {
+		movies.Add(&models.Movie{Title: fmt.Sprintf("The Matrix %d", i), Director: "Wachowski"})
+	}
+	movies.Add(&models.Movie{Title: "Bound", Director: "Wachowski", Description: "Before the matrix"})
+	movies.Add(&models.Movie{Title: "Heat", Director: "Mann"})
+	genres := testutil.NewMemGenreRepo()
+	genres.Add(&models.Genre{Name: "Matrix-core"})
+	genres.Add(&models.Genre{Name: "Drama"})
+	users := testutil.NewMemUserRepo()
+	users.Add(&models.User{Username: "matrixfan", Email: "neo@example.com"})
+	users.Add(&models.User{Username: "someone", Email: "matrix@example.com"})
+
+	router := gin.New()
+	router.GET("/search", NewSearchHandler(service.NewSearchService(movies, genres, users)).Search)
+
+	var resp models.SearchResults
+	getJSON(t, router, "/search?q=MATRIX", &resp)
+	if len(resp.Movies) != service.SearchResultsPerType {
+		t.Fatalf("expected %d movies, got %+v", service.SearchResultsPerType, resp.Movies)
+	}
+	for _, hit := range resp.Movies {
+		if hit.Type != "movie" || hit.Director != "Wachowski" {
+			t.Fatalf("unexpected movie hit %+v", hit)
+		}
+	}
+	if len(resp.Genres) != 1 || resp.Genres[0].Type != "genre" || resp.Genres[0].Title != "Matrix-core" {
+		t.Fatalf("unexpected genres %+v", resp.Genres)
+	}
+	if resp.Users != nil {
+		t.Fatalf("users must be left out unless requested, got %+v", resp.Users)
+	}
+
+	resp = models.SearchResults{}
+	getJSON(t, router, "/search?q=bound", &resp)
+	if len(resp.Movies) != 1 || resp.Movies[0].Title != "Bound" || len(resp.Genres) != 0 {
+		t.Fatalf("unexpected results for bound: %+v", resp)
+	}
+
+	resp = models.SearchResults{}
+	getJSON(t, router, "/search?q=matrix&include_users=true", &resp)
+	if len(resp.Users) != 1 || resp.Users[0].Type != "user" || resp.Users[0].Title != "matrixfan" {
+		t.Fatalf("expected only the username match, got %+v", resp.Users)
+	}
+
+	for _, target := range []string{"/search", "/search?q=", "/search?q=%20m%20"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestSearchHandler_RateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	search := NewSearchHandler(service.NewSearchService(testutil.NewMemMovieRepo(), testutil.NewMemGenreRepo(), testutil.NewMemUserRepo()))
+	const searchRateLimit = 20
+	router := gin.New()
+	router.Use(middleware.RateLimit(60))
+	router.GET("/search", middleware.RateLimit(searchRateLimit), search.Search)
+
+	for i := 1; i <= searchRateLimit+1; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?q=heat", nil))
+		want := http.StatusOK
+		if i > searchRateLimit {
+			want = http.StatusTooManyRequests
+		}
+		if w.Code != want {
+			t.Fatalf("request %d: expected %d, got %d", i, want, w.Code)
+		}
+	}
+}
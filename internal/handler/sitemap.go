@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/service"
+)
+
+type SitemapHandler struct {
+	sitemaps *service.SitemapService
+}
+
+func NewSitemapHandler(sitemaps *service.SitemapService) *SitemapHandler {
+	return &SitemapHandler{sitemaps: sitemaps}
+}
+
+// Index serves /sitemap.xml: every movie page, or an index of the parts
+// once there are more than fit in one file.
+func (h *SitemapHandler) Index(c *gin.Context) {
+	h.serve(c, 0)
+}
+
+// Part serves /sitemaps/:file, named <n>.xml by the index.
+func (h *SitemapHandler) Part(c *gin.Context) {
+	name, ok := strings.CutSuffix(c.Param("file"), ".xml")
+	n, err := strconv.Atoi(name)
+	if !ok || err != nil || n < 1 {
+		respondError(c, service.ErrSitemapNotFound)
+		return
+	}
+	h.serve(c, n)
+}
+
+func (h *SitemapHandler) serve(c *gin.Context, n int) {
+	file, err := h.sitemaps.File(c.Request.Context(), n)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", file)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+func TestSitemapHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat", UpdatedAt: now.Add(-time.Hour)})
+	h := NewSitemapHandler(service.NewSitemapService(movies, "https://movies.example/", service.WithClock(func() time.Time { return now })))
+
+	router := gin.New()
+	router.GET("/sitemap.xml", h.Index)
+	router.GET("/sitemaps/:file", h.Part)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := get("/sitemap.xml")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/xml; charset=utf-8" {
+		t.Fatalf("expected XML, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "<loc>https://movies.example/movies/1-heat</loc>") || !strings.Contains(body, "<lastmod>2025-03-01T11:00:00Z</lastmod>") {
+		t.Fatalf("unexpected sitemap:\n%s", body)
+	}
+
+	movies.Add(&models.Movie{ID: 2, Title: "Ronin", UpdatedAt: now})
+	if body := get("/sitemap.xml").Body.String(); strings.Contains(body, "2-ronin") {
+		t.Fatalf("expected the cached sitemap within the hour")
+	}
+	now = now.Add(service.SitemapTTL + time.Second)
+	if body := get("/sitemap.xml").Body.String(); !strings.Contains(body, "2-ronin") {
+		t.Fatalf("expected the sitemap to be rebuilt after an hour:\n%s", body)
+	}
+
+	for _, target := range []string{"/sitemaps/1.xml", "/sitemaps/0.xml", "/sitemaps/one.xml"} {
+		if w := get(target); w.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404 while the sitemap fits in one file, got %d", target, w.Code)
+		}
+	}
+}
@@ -1,16 +1,21 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"golang-project/internal/i18n"
 	"golang-project/internal/middleware"
 	"golang-project/internal/models"
 	"golang-project/internal/repository"
 	"golang-project/internal/service"
+	"golang-project/pkg/bind"
 )
 
 type UserHandler struct {
@@ -21,10 +26,11 @@ type UserHandler struct {
 	reviewRepo service.ReviewCountRepo
 	genreRepo  service.GenreCountRepo
 	auditRepo  service.AuditLogRepo
+	statsCache *service.AdminStatsCache
 }
 
-func NewUserHandler(users *service.UserService, reviews *service.ReviewService, userRepo repository.UserRepository, movieRepo service.MovieCountRepo, reviewRepo service.ReviewCountRepo, genreRepo service.GenreCountRepo, auditRepo service.AuditLogRepo) *UserHandler {
-	return &UserHandler{
+func NewUserHandler(users *service.UserService, reviews *service.ReviewService, userRepo repository.UserRepository, movieRepo service.MovieCountRepo, reviewRepo service.ReviewCountRepo, genreRepo service.GenreCountRepo, auditRepo service.AuditLogRepo, statsCache *service.AdminStatsCache) *UserHandler {
+	h := &UserHandler{
 		users:      users,
 		reviews:    reviews,
 		userRepo:   userRepo,
@@ -32,24 +38,32 @@ func NewUserHandler(users *service.UserService, reviews *service.ReviewService,
 		reviewRepo: reviewRepo,
 		genreRepo:  genreRepo,
 		auditRepo:  auditRepo,
+		statsCache: statsCache,
 	}
+	if h.statsCache == nil {
+		h.statsCache = NewAdminStatsCache(users, userRepo, movieRepo, reviewRepo, genreRepo)
+	}
+	return h
+}
+
+// NewAdminStatsCache caches users.GetAdminStats over the given repositories.
+// SetupRoutes shares it with the services whose writes invalidate it.
+func NewAdminStatsCache(users *service.UserService, userRepo repository.UserRepository, movieRepo service.MovieCountRepo, reviewRepo service.ReviewCountRepo, genreRepo service.GenreCountRepo) *service.AdminStatsCache {
+	return service.NewAdminStatsCache(func(ctx context.Context) (*models.AdminStats, error) {
+		return users.GetAdminStats(ctx, userRepo, movieRepo, reviewRepo, genreRepo)
+	}, service.DefaultAdminStatsTTL)
 }
 
 func (h *UserHandler) Me(c *gin.Context) {
-	userIDStr, _ := c.Get(string(middleware.ContextUserID))
-	uid, err := strconv.Atoi(userIDStr.(string))
+	uid, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		respondError(c, errInvalidUser)
 		return
 	}
 
 	user, err := h.users.GetByID(c.Request.Context(), uid)
 	if err != nil {
-		if err == service.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+		respondError(c, err)
 		return
 	}
 
@@ -71,35 +85,111 @@ func (h *UserHandler) Me(c *gin.Context) {
 }
 
 func (h *UserHandler) MyReviews(c *gin.Context) {
-	userIDStr, _ := c.Get(string(middleware.ContextUserID))
-	uid, err := strconv.Atoi(userIDStr.(string))
+	uid, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		respondError(c, errInvalidUser)
 		return
 	}
 	h.listReviewsByUser(c, uid)
 }
 
+// MyActivity serves the caller's activity summary for their profile header.
+func (h *UserHandler) MyActivity(c *gin.Context) {
+	uid, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+
+	activity, err := h.users.GetActivity(c.Request.Context(), uid)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, activity)
+}
+
+func (h *UserHandler) MyAuditLogs(c *gin.Context) {
+	uid, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+	pagination, err := ParsePagination(c, 20)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp, err := h.users.ListOwnAuditLogs(c.Request.Context(), h.auditRepo, uid, pagination.Page, pagination.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondPage(c, resp)
+}
+
+// UserReviews lists another user's reviews. A user who made their reviews
+// private is only visible to themselves and to admins.
 func (h *UserHandler) UserReviews(c *gin.Context) {
 	uid, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		respondError(c, errInvalidID)
 		return
 	}
+
+	user, err := h.users.GetByID(c.Request.Context(), uid)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if user.ReviewsPrivate {
+		callerID, _ := currentUserID(c)
+		if role, _ := c.Get(string(middleware.ContextRole)); callerID != uid && role != "admin" {
+			respondError(c, errReviewsPrivate)
+			return
+		}
+	}
 	h.listReviewsByUser(c, uid)
 }
 
+// GetPublicProfile serves a user's profile page without authentication, so
+// it leaves out the email and everything else only admins may see.
+func (h *UserHandler) GetPublicProfile(c *gin.Context) {
+	uid, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+
+	profile, err := h.users.GetPublicProfile(c.Request.Context(), uid)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
 func (h *UserHandler) listReviewsByUser(c *gin.Context, uid int) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	pagination, err := ParsePagination(c, 10)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 	filters := parseReviewFilters(c)
+	if wantsCSV(c) {
+		writeReviewsCSV(c, "reviews.csv", func(page, limit int) ([]models.Review, error) {
+			return h.reviews.ListByUser(c.Request.Context(), uid, filters, page, limit)
+		})
+		return
+	}
 
-	reviews, err := h.reviews.ListByUser(c.Request.Context(), uid, filters, page, limit)
+	resp, err := h.reviews.ListByUserPage(c.Request.Context(), uid, filters, pagination.Page, pagination.Limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reviews"})
+		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": reviews})
+	respondPage(c, resp)
 }
 
 type updateRoleRequest struct {
@@ -107,35 +197,81 @@ type updateRoleRequest struct {
 }
 
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	pagination, err := ParsePagination(c, 20)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 	
 	filters := models.UserFilters{
 		Search: c.Query("search"),
 		Role:   c.Query("role"),
 	}
+	if filters.MinReviews, err = reviewCountQuery(c, "min_reviews"); err != nil {
+		respondError(c, err)
+		return
+	}
+	if filters.MaxReviews, err = reviewCountQuery(c, "max_reviews"); err != nil {
+		respondError(c, err)
+		return
+	}
+	if filters.MinReviews != nil && filters.MaxReviews != nil && *filters.MinReviews > *filters.MaxReviews {
+		respondError(c, errInvalidReviewRange.WithDetails(map[string]any{"param": "min_reviews", "value": c.Query("min_reviews")}))
+		return
+	}
+	if wantsCSV(c) {
+		writeCSV(c, "users.csv", userCSVHeader, func(page int) ([][]string, bool, error) {
+			resp, err := h.users.List(c.Request.Context(), filters, page, csvPageSize)
+			if err != nil {
+				return nil, false, err
+			}
+			users, _ := resp.Data.([]models.User)
+			return csvRows(users, userCSVRow), page < resp.TotalPages, nil
+		})
+		return
+	}
 	
-	resp, err := h.users.List(c.Request.Context(), filters, page, limit)
+	resp, err := h.users.List(c.Request.Context(), filters, pagination.Page, pagination.Limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list users"})
+		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	respondPage(c, resp)
+}
+
+// reviewCountQuery reads a review count bound; nil means it was not given.
+func reviewCountQuery(c *gin.Context, name string) (*int, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return nil, errInvalidReviewRange.WithDetails(map[string]any{"param": name, "value": raw})
+	}
+	return &n, nil
 }
 
 func (h *UserHandler) GetUser(c *gin.Context) {
 	uid, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		respondError(c, errInvalidID)
 		return
 	}
 	user, err := h.users.GetByID(c.Request.Context(), uid)
 	if err != nil {
-		if err == service.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// LookupUser finds a user by ?email= or ?username=, for admins who have one
+// of those rather than an ID.
+func (h *UserHandler) LookupUser(c *gin.Context) {
+	user, err := h.users.Lookup(c.Request.Context(), c.Query("email"), c.Query("username"))
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, user)
@@ -144,23 +280,90 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 func (h *UserHandler) UpdateRole(c *gin.Context) {
 	uid, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		respondError(c, errInvalidID)
 		return
 	}
 	var req updateRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 	if err := h.users.UpdateRole(c.Request.Context(), uid, req.Role); err != nil {
-		switch err {
-		case service.ErrInvalidRole:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role"})
-		case service.ErrUserNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update role"})
-		}
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type bulkRoleRequest struct {
+	UserIDs []int  `json:"user_ids"`
+	Role    string `json:"role"`
+}
+
+type bulkRoleFailure struct {
+	UserID  int    `json:"user_id"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type bulkRoleResponse struct {
+	Updated int               `json:"updated"`
+	Errors  []bulkRoleFailure `json:"errors"`
+}
+
+// BulkUpdateRole serves POST /admin/users/bulk-role. Users that cannot be
+// updated are listed in errors with the code they would get individually;
+// the rest are updated.
+func (h *UserHandler) BulkUpdateRole(c *gin.Context) {
+	adminID, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+	var req bulkRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+
+	updated, failures, err := h.users.BulkUpdateRole(c.Request.Context(), req.UserIDs, req.Role, adminID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	resp := bulkRoleResponse{Updated: updated, Errors: make([]bulkRoleFailure, 0, len(failures))}
+	locale := i18n.FromContext(c.Request.Context())
+	for _, f := range failures {
+		apiErr := toAPIError(f.Err)
+		resp.Errors = append(resp.Errors, bulkRoleFailure{
+			UserID:  f.UserID,
+			Code:    apiErr.Code,
+			Message: i18n.Message(locale, apiErr.Code, apiErr.Message, apiErr.Details),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type mergeAccountsRequest struct {
+	SourceID int `json:"source_id"`
+	TargetID int `json:"target_id"`
+}
+
+// MergeAccounts folds a duplicate account into another one.
+func (h *UserHandler) MergeAccounts(c *gin.Context) {
+	adminID, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+	var req mergeAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+
+	if err := h.users.MergeAccounts(c.Request.Context(), req.SourceID, req.TargetID, adminID); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -169,31 +372,24 @@ func (h *UserHandler) UpdateRole(c *gin.Context) {
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	uid, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		respondError(c, errInvalidID)
 		return
 	}
 
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 
 	if err := h.users.Update(c.Request.Context(), uid, req); err != nil {
-		switch err {
-		case service.ErrUserNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		case service.ErrUserExists:
-			c.JSON(http.StatusConflict, gin.H{"error": "email or username already exists"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
-		}
+		respondError(c, err)
 		return
 	}
 
 	user, err := h.users.GetByID(c.Request.Context(), uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get updated user"})
+		respondError(c, err)
 		return
 	}
 
@@ -203,26 +399,18 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	uid, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		respondError(c, errInvalidID)
 		return
 	}
 
-	adminIDStr, _ := c.Get(string(middleware.ContextUserID))
-	adminID, err := strconv.Atoi(adminIDStr.(string))
+	adminID, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid admin user"})
+		respondError(c, errInvalidUser)
 		return
 	}
 
 	if err := h.users.Delete(c.Request.Context(), uid, adminID); err != nil {
-		switch err {
-		case service.ErrUserNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		case service.ErrCannotDeleteSelf:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete yourself"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete user"})
-		}
+		respondError(c, err)
 		return
 	}
 
@@ -230,58 +418,71 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 }
 
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
-	userIDStr, _ := c.Get(string(middleware.ContextUserID))
-	uid, err := strconv.Atoi(userIDStr.(string))
+	uid, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		respondError(c, errInvalidUser)
 		return
 	}
 
-	var req models.UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	req, err := bind.BindStrict[models.UpdateUserRequest](c, []string{"email", "username", "email_on_digest"})
+	if err != nil {
+		var unknown *bind.UnknownFieldError
+		if errors.As(err, &unknown) {
+			respondError(c, unknownField(unknown.Field))
+			return
+		}
+		respondError(c, errInvalidRequest)
 		return
 	}
 
 	user, err := h.users.UpdateProfile(c.Request.Context(), uid, req)
 	if err != nil {
-		switch err {
-		case service.ErrUserNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		case service.ErrUserExists:
-			c.JSON(http.StatusConflict, gin.H{"error": "email or username already exists"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
-		}
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
 
+// UpdatePrivacy changes who may list the caller's reviews.
+func (h *UserHandler) UpdatePrivacy(c *gin.Context) {
+	uid, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+
+	var req models.UpdatePrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ReviewsPublic == nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+
+	if err := h.users.UpdatePrivacy(c.Request.Context(), uid, *req.ReviewsPublic); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 func (h *UserHandler) UpdatePassword(c *gin.Context) {
-	userIDStr, _ := c.Get(string(middleware.ContextUserID))
-	uid, err := strconv.Atoi(userIDStr.(string))
+	uid, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		respondError(c, errInvalidUser)
 		return
 	}
 
 	var req models.UpdatePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		respondError(c, errInvalidRequest)
 		return
 	}
 
 	if err := h.users.UpdatePassword(c.Request.Context(), uid, req.CurrentPassword, req.NewPassword); err != nil {
-		switch err {
-		case service.ErrUserNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		case service.ErrInvalidCredentials:
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid current password"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update password"})
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			err = errWrongPassword
 		}
+		respondError(c, err)
 		return
 	}
 
@@ -289,27 +490,49 @@ func (h *UserHandler) UpdatePassword(c *gin.Context) {
 }
 
 func (h *UserHandler) GetStats(c *gin.Context) {
-	stats, err := h.users.GetAdminStats(
-		c.Request.Context(),
-		h.userRepo,
-		h.movieRepo,
-		h.reviewRepo,
-		h.genreRepo,
-	)
+	refresh := c.Query("refresh") == "true"
+	stats, err := h.statsCache.Get(c.Request.Context(), refresh)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get stats"})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, stats)
 }
 
+func (h *UserHandler) GetMoviesByDecade(c *gin.Context) {
+	includeEmpty := c.Query("include_empty") == "true"
+
+	counts, err := h.users.GetMoviesByDecade(c.Request.Context(), h.movieRepo, includeEmpty)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": counts})
+}
+
 func (h *UserHandler) ListAuditLogs(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	pagination, err := ParsePagination(c, 20)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
+	resp, err := h.users.ListAuditLogs(c.Request.Context(), h.auditRepo, parseAuditLogFilters(c), pagination.Page, pagination.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPage(c, resp)
+}
+
+func parseAuditLogFilters(c *gin.Context) models.AuditLogFilters {
 	filters := models.AuditLogFilters{
-		Event: c.Query("event"),
+		Event:  c.Query("event"),
+		Search: strings.TrimSpace(c.Query("search")),
+		Sort:   c.Query("sort"),
 	}
 
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
@@ -318,6 +541,12 @@ func (h *UserHandler) ListAuditLogs(c *gin.Context) {
 		}
 	}
 
+	if actorIDStr := c.Query("actor_id"); actorIDStr != "" {
+		if actorID, err := strconv.Atoi(actorIDStr); err == nil {
+			filters.ActorID = &actorID
+		}
+	}
+
 	if fromDateStr := c.Query("from_date"); fromDateStr != "" {
 		if fromDate, err := time.Parse("2006-01-02", fromDateStr); err == nil {
 			filters.FromDate = &fromDate
@@ -330,11 +559,5 @@ func (h *UserHandler) ListAuditLogs(c *gin.Context) {
 		}
 	}
 
-	resp, err := h.users.ListAuditLogs(c.Request.Context(), h.auditRepo, filters, page, limit)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list audit logs"})
-		return
-	}
-
-	c.JSON(http.StatusOK, resp)
+	return filters
 }
@@ -0,0 +1,515 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/apierror"
+	"golang-project/internal/middleware"
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+	"golang-project/pkg/jwt"
+)
+
+type userHandlerRepos struct {
+	users   *testutil.MemUserRepo
+	reviews *testutil.MemReviewRepo
+	audit   *testutil.MemAuditRepo
+}
+
+func newUserHandler() (*UserHandler, userHandlerRepos) {
+	repos := userHandlerRepos{
+		users:   testutil.NewMemUserRepo(),
+		reviews: testutil.NewMemReviewRepo(),
+		audit:   testutil.NewMemAuditRepo(),
+	}
+	movies := testutil.NewMemMovieRepo()
+	v := validator.New()
+	users := service.NewUserService(repos.users, repos.reviews, v, nil)
+	reviews := service.NewReviewService(repos.reviews, movies, v, nil)
+	h := NewUserHandler(users, reviews, repos.users, movies, repos.reviews, testutil.NewMemGenreRepo(), repos.audit, nil)
+	return h, repos
+}
+
+func TestUserHandler_ListUsersByReviewCount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	for i, count := range []int{0, 3, 8} {
+		u := &models.User{Email: "u" + string(rune('a'+i)) + "@example.com", Username: "user" + string(rune('a'+i)), Role: "user"}
+		repos.users.Add(u)
+		repos.users.SetReviewCount(u.ID, count)
+	}
+
+	router := gin.New()
+	router.GET("/users", h.ListUsers)
+
+	list := func(query string) []models.User {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/users?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", query, w.Code)
+		}
+		var resp struct {
+			Data  []models.User `json:"data"`
+			Total int           `json:"total"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		if resp.Total != len(resp.Data) {
+			t.Fatalf("%s: total %d does not match %d users", query, resp.Total, len(resp.Data))
+		}
+		return resp.Data
+	}
+
+	if got := list("min_reviews=4"); len(got) != 1 || got[0].Username != "userc" {
+		t.Fatalf("min_reviews=4: expected only the 8-review user, got %+v", got)
+	}
+	if got := list("min_reviews=1&max_reviews=5"); len(got) != 1 || got[0].Username != "userb" {
+		t.Fatalf("min_reviews=1&max_reviews=5: expected only the 3-review user, got %+v", got)
+	}
+	if got := list("max_reviews=3"); len(got) != 2 {
+		t.Fatalf("max_reviews=3: expected users with 0 and 3 reviews, got %+v", got)
+	}
+	if got := list("max_reviews=0"); len(got) != 1 || got[0].Username != "usera" {
+		t.Fatalf("max_reviews=0: expected only the user without reviews, got %+v", got)
+	}
+
+	for _, query := range []string{"min_reviews=5&max_reviews=2", "min_reviews=-1", "max_reviews=x"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?"+query, nil))
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_review_range") {
+			t.Fatalf("%s: expected 400 invalid_review_range, got %d %s", query, w.Code, w.Body)
+		}
+	}
+}
+
+func TestUserHandler_MeWithoutValidCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, _ := newUserHandler()
+	for name, set := range map[string]func(*gin.Context){
+		"missing":    func(*gin.Context) {},
+		"not string": func(c *gin.Context) { c.Set(string(middleware.ContextUserID), 7) },
+		"not number": func(c *gin.Context) { c.Set(string(middleware.ContextUserID), "seven") },
+	} {
+		router := gin.New()
+		router.GET("/me", func(c *gin.Context) { set(c) }, h.Me)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me", nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("%s user id: expected 401, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestUserHandler_MyAuditLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	me, other, admin := 1, 2, 3
+	ctx := context.Background()
+	repos.audit.Insert(ctx, &models.AuditLog{UserID: &me, Event: "login_success", Details: "email=me@example.com"})
+	repos.audit.Insert(ctx, &models.AuditLog{UserID: &other, Event: "login_failed", Details: "email=other@example.com"})
+	repos.audit.Insert(ctx, &models.AuditLog{UserID: &me, ActorID: &admin, Event: "user_role_updated", Details: "role=admin"})
+
+	router := gin.New()
+	router.GET("/me/audit-logs", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+	}, h.MyAuditLogs)
+
+	req := httptest.NewRequest(http.MethodGet, "/me/audit-logs", nil)
+	req.Header.Set("X-User", strconv.Itoa(me))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var resp struct {
+		Data  []map[string]any `json:"data"`
+		Total int              `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Total != 2 || len(resp.Data) != 2 {
+		t.Fatalf("expected the caller's 2 entries, got %+v", resp)
+	}
+	for _, entry := range resp.Data {
+		if entry["event"] == "login_failed" {
+			t.Fatalf("another user's entry leaked: %v", entry)
+		}
+		if _, ok := entry["details"]; ok {
+			t.Fatalf("details must not be exposed: %v", entry)
+		}
+		if _, ok := entry["actor_id"]; ok {
+			t.Fatalf("actor_id must not be exposed: %v", entry)
+		}
+	}
+}
+
+func TestUserHandler_ReviewsArePaginated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	for id := 1; id <= 2; id++ {
+		repos.users.Add(&models.User{ID: id, Email: fmt.Sprintf("u%d@example.com", id), Username: fmt.Sprintf("user%d", id)})
+	}
+	for i := 1; i <= 7; i++ {
+		repos.reviews.Add(&models.Review{MovieID: i, UserID: 1, Rating: i, Title: "t", Content: "c"})
+	}
+	repos.reviews.Add(&models.Review{MovieID: 1, UserID: 2, Rating: 5, Title: "t", Content: "c"})
+
+	router := gin.New()
+	router.GET("/users/:id/reviews", h.UserReviews)
+	router.GET("/me/reviews", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+	}, h.MyReviews)
+
+	for target, want := range map[string]struct{ total, pages, onPage int }{
+		"/users/1/reviews?limit=3":               {7, 3, 3},
+		"/users/1/reviews?limit=3&page=3":        {7, 3, 1},
+		"/users/1/reviews?min_rating=5&limit=10": {3, 1, 3},
+		"/users/2/reviews":                       {1, 1, 1},
+		"/me/reviews?limit=5":                    {7, 2, 5},
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("X-User", "1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", target, w.Code)
+		}
+		var resp struct {
+			Data       []models.Review `json:"data"`
+			Total      int             `json:"total"`
+			TotalPages int             `json:"total_pages"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: parse response: %v", target, err)
+		}
+		if resp.Total != want.total || resp.TotalPages != want.pages || len(resp.Data) != want.onPage {
+			t.Fatalf("%s: expected total %d, %d pages and %d on the page, got %d, %d and %d",
+				target, want.total, want.pages, want.onPage, resp.Total, resp.TotalPages, len(resp.Data))
+		}
+	}
+}
+
+func TestUserHandler_PrivateReviews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	owner := &models.User{Email: "owner@example.com", Username: "owner", Role: "user"}
+	repos.users.Add(owner)
+	repos.reviews.Add(&models.Review{MovieID: 1, UserID: owner.ID, Rating: 8, Title: "t", Content: "c"})
+
+	caller := func(c *gin.Context) {
+		if id := c.GetHeader("X-User"); id != "" {
+			c.Set(string(middleware.ContextUserID), id)
+			c.Set(string(middleware.ContextRole), c.GetHeader("X-Role"))
+		}
+	}
+	router := gin.New()
+	router.GET("/users/:id/reviews", caller, h.UserReviews)
+	router.GET("/users/:id", caller, h.GetUser)
+	router.GET("/me/reviews", caller, h.MyReviews)
+	router.PUT("/me/privacy", caller, h.UpdatePrivacy)
+
+	do := func(method, target, userID, role, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", userID)
+		req.Header.Set("X-Role", role)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	ownerID := strconv.Itoa(owner.ID)
+	reviewsPath := "/users/" + ownerID + "/reviews"
+
+	if w := do(http.MethodGet, reviewsPath, "", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("public reviews: expected 200, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/me/privacy", ownerID, "user", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("privacy without reviews_public: expected 400, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/me/privacy", ownerID, "user", `{"reviews_public": false}`); w.Code != http.StatusNoContent {
+		t.Fatalf("privacy: expected 204, got %d: %s", w.Code, w.Body)
+	}
+
+	w := do(http.MethodGet, reviewsPath, "", "", "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("anonymous: expected 403, got %d", w.Code)
+	}
+	var resp apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error.Code != "reviews_private" {
+		t.Fatalf("anonymous: expected reviews_private, got %s", w.Body)
+	}
+	if w := do(http.MethodGet, reviewsPath, "99", "user", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("another user: expected 403, got %d", w.Code)
+	}
+
+	for _, tc := range []struct{ target, userID, role string }{
+		{reviewsPath, ownerID, "user"},
+		{"/me/reviews", ownerID, "user"},
+		{reviewsPath, "99", "admin"},
+		{"/users/" + ownerID, "99", "admin"},
+	} {
+		if w := do(http.MethodGet, tc.target, tc.userID, tc.role, ""); w.Code != http.StatusOK {
+			t.Fatalf("%s as %s %s: expected 200, got %d", tc.target, tc.role, tc.userID, w.Code)
+		}
+	}
+
+	if w := do(http.MethodGet, "/users/999/reviews", "", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown user: expected 404, got %d", w.Code)
+	}
+}
+
+func TestUserHandler_UpdateProfileRejectsUnknownFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	u := &models.User{Email: "me@example.com", Username: "me", Role: "user"}
+	repos.users.Add(u)
+
+	router := gin.New()
+	router.PUT("/me", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), c.GetHeader("X-User"))
+	}, h.UpdateProfile)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/me", strings.NewReader(body))
+		req.Header.Set("X-User", strconv.Itoa(u.ID))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := put(`{"email":"x","role":"admin"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body)
+	}
+	var resp apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Error.Code != "unknown_field" || resp.Error.Details["field"] != "role" {
+		t.Fatalf("expected unknown_field for role, got %+v", resp.Error)
+	}
+	if got, _ := repos.users.GetByID(context.Background(), u.ID); got.Role != "user" {
+		t.Fatalf("role must not change, got %q", got.Role)
+	}
+
+	if w := put(`{"username":"renamed","email_on_digest":true}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for allowed fields, got %d: %s", w.Code, w.Body)
+	}
+	if got, _ := repos.users.GetByID(context.Background(), u.ID); !got.EmailOnDigest {
+		t.Fatal("expected email_on_digest to be turned on")
+	}
+}
+
+func TestUserHandler_BulkUpdateRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	admin := &models.User{Email: "admin@example.com", Username: "admin", Role: "admin"}
+	member := &models.User{Email: "member@example.com", Username: "member", Role: "user"}
+	repos.users.Add(admin)
+	repos.users.Add(member)
+
+	router := gin.New()
+	router.POST("/admin/users/bulk-role", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), strconv.Itoa(admin.ID))
+	}, h.BulkUpdateRole)
+
+	body := fmt.Sprintf(`{"user_ids":[%d,404,%d],"role":"admin"}`, member.ID, admin.ID)
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/bulk-role", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	var resp bulkRoleResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	want := []bulkRoleFailure{
+		{UserID: 404, Code: "user_not_found", Message: "user not found"},
+		{UserID: admin.ID, Code: "cannot_update_self", Message: "cannot change your own role"},
+	}
+	if resp.Updated != 1 || !reflect.DeepEqual(resp.Errors, want) {
+		t.Fatalf("expected 1 update and failures %+v, got %+v", want, resp)
+	}
+}
+
+func TestUserHandler_MergeAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	users, reviews, audit := testutil.NewMemUserRepo(), testutil.NewMemReviewRepo(), testutil.NewMemAuditRepo()
+	admin := &models.User{Email: "admin@example.com", Username: "admin", Role: "admin"}
+	target := &models.User{Email: "ann@example.com", Username: "ann", Role: "user"}
+	source := &models.User{Email: "ann.other@example.com", Username: "ann2", Role: "user"}
+	for _, u := range []*models.User{admin, target, source} {
+		users.Add(u)
+	}
+	reviews.Add(&models.Review{MovieID: 1, UserID: target.ID, Rating: 8})
+	reviews.Add(&models.Review{MovieID: 3, UserID: target.ID, Rating: 7})
+	reviews.Add(&models.Review{MovieID: 1, UserID: source.ID, Rating: 6})
+	reviews.Add(&models.Review{MovieID: 2, UserID: source.ID, Rating: 9})
+
+	merger := service.WithAccountMerger(testutil.MemAccountMerger{Users: users, Reviews: reviews})
+	userService := service.NewUserService(users, reviews, validator.New(), nil, service.WithAuditWriter(audit), merger)
+	h := NewUserHandler(userService, nil, users, testutil.NewMemMovieRepo(), reviews, testutil.NewMemGenreRepo(), audit, nil)
+	router := gin.New()
+	router.POST("/admin/users/merge", func(c *gin.Context) {
+		c.Set(string(middleware.ContextUserID), strconv.Itoa(admin.ID))
+	}, h.MergeAccounts)
+	merge := func(sourceID, targetID int) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"source_id":%d,"target_id":%d}`, sourceID, targetID)
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/merge", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := merge(source.ID, target.ID); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body)
+	}
+	if n, _ := reviews.CountByUserID(context.Background(), target.ID); n != 3 {
+		t.Fatalf("expected the target to have 3 reviews, got %d", n)
+	}
+	if !users.Deleted(source.ID) {
+		t.Fatal("expected the source account to be soft-deleted")
+	}
+	if _, err := users.GetByID(context.Background(), source.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected the source account to be hidden, got %v", err)
+	}
+	logs, _, _ := audit.List(context.Background(), models.AuditLogFilters{Event: "users_merged"}, 10, 0)
+	if len(logs) != 1 || *logs[0].UserID != target.ID || logs[0].Details != fmt.Sprintf("source_id=%d target_id=%d reviews_moved=1", source.ID, target.ID) {
+		t.Fatalf("unexpected audit entries: %+v", logs)
+	}
+
+	// The merged account's email and username are free again.
+	auth := service.NewAuthService(users, validator.New(), jwt.StaticKeySet("secret"))
+	if _, err := auth.Register(context.Background(), models.CreateUserRequest{Email: source.Email, Username: source.Username, Password: "password123"}); err != nil {
+		t.Fatalf("re-register the merged account's email: %v", err)
+	}
+
+	if w := merge(target.ID, target.ID); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "cannot_merge_self") {
+		t.Fatalf("expected 400 cannot_merge_self, got %d: %s", w.Code, w.Body)
+	}
+	if w := merge(source.ID, target.ID); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 merging a deleted account, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestUserHandler_LookupUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	alice := &models.User{Email: "alice@example.com", Username: "alice", Role: "user"}
+	repos.users.Add(alice)
+	repos.users.Add(&models.User{Email: "bob@example.com", Username: "bob", Role: "user"})
+
+	router := gin.New()
+	router.GET("/admin/users/lookup", h.LookupUser)
+
+	for _, query := range []string{"email=alice@example.com", "username=alice"} {
+		var user models.User
+		getJSON(t, router, "/admin/users/lookup?"+query, &user)
+		if user.ID != alice.ID {
+			t.Fatalf("%s: expected user %d, got %+v", query, alice.ID, user)
+		}
+	}
+
+	for query, want := range map[string]struct {
+		status int
+		code   string
+	}{
+		"email=nobody@example.com":             {http.StatusNotFound, "user_not_found"},
+		"":                                     {http.StatusBadRequest, "invalid_lookup"},
+		"email=&username=":                     {http.StatusBadRequest, "invalid_lookup"},
+		"email=alice@example.com&username=bob": {http.StatusBadRequest, "invalid_lookup"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/users/lookup?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp apierror.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%q: parse response: %v", query, err)
+		}
+		if w.Code != want.status || resp.Error.Code != want.code {
+			t.Fatalf("%q: expected %d %s, got %d %+v", query, want.status, want.code, w.Code, resp.Error)
+		}
+	}
+}
+
+func TestUserHandler_ListAuditLogsSearchAndSort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, repos := newUserHandler()
+	ctx := context.Background()
+	for _, log := range []models.AuditLog{
+		{Event: "login_failed", Details: "wrong password"},
+		{Event: "role_changed", Details: "user -> admin"},
+		{Event: "login_success", Details: "Password reset link used"},
+	} {
+		repos.audit.Insert(ctx, &log)
+	}
+	router := gin.New()
+	router.GET("/audit-logs", h.ListAuditLogs)
+
+	ids := func(query string) []int {
+		t.Helper()
+		var resp struct {
+			Data []models.AuditLog `json:"data"`
+		}
+		getJSON(t, router, "/audit-logs?"+query, &resp)
+		ids := make([]int, len(resp.Data))
+		for i, log := range resp.Data {
+			ids[i] = log.ID
+		}
+		return ids
+	}
+
+	if got := ids(""); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Fatalf("expected newest first by default, got %v", got)
+	}
+	if got := ids("sort=created_asc"); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("expected oldest first, got %v", got)
+	}
+	if got := ids("search=PASSWORD&sort=created_asc"); !slices.Equal(got, []int{1, 3}) {
+		t.Fatalf("expected the details search to ignore case, got %v", got)
+	}
+	if got := ids("search=role"); !slices.Equal(got, []int{2}) {
+		t.Fatalf("expected the search to match events, got %v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/audit-logs?sort=newest", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_sort") {
+		t.Fatalf("expected 400 invalid_sort, got %d %s", w.Code, w.Body.String())
+	}
+}
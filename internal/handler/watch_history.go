@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/models"
+	"golang-project/internal/service"
+)
+
+type WatchHistoryHandler struct {
+	service *service.WatchHistoryService
+}
+
+func NewWatchHistoryHandler(s *service.WatchHistoryService) *WatchHistoryHandler {
+	return &WatchHistoryHandler{service: s}
+}
+
+// Record marks a movie as watched by the caller, replacing any earlier entry
+// for the same movie.
+func (h *WatchHistoryHandler) Record(c *gin.Context) {
+	uid, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+
+	var req models.RecordWatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+
+	watch, err := h.service.RecordWatch(c.Request.Context(), uid, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, watch)
+}
+
+// Mine lists the caller's watch history, most recently watched first.
+func (h *WatchHistoryHandler) Mine(c *gin.Context) {
+	uid, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+	pagination, err := ParsePagination(c, 20)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp, err := h.service.List(c.Request.Context(), uid, pagination.Page, pagination.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondPage(c, resp)
+}
+
+func (h *WatchHistoryHandler) Delete(c *gin.Context) {
+	movieID, err := strconv.Atoi(c.Param("movieID"))
+	if err != nil {
+		respondError(c, errInvalidID)
+		return
+	}
+	uid, err := currentUserID(c)
+	if err != nil {
+		respondError(c, errInvalidUser)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), uid, movieID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/service"
+)
+
+type YearHandler struct {
+	summaries *service.YearSummaryService
+}
+
+func NewYearHandler(summaries *service.YearSummaryService) *YearHandler {
+	return &YearHandler{summaries: summaries}
+}
+
+// Summary serves the "year in review" for the movies released in :year.
+func (h *YearHandler) Summary(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		respondError(c, errInvalidRequest)
+		return
+	}
+
+	summary, err := h.summaries.Get(c.Request.Context(), year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,77 @@
+package i18n
+
+// catalog maps locale -> error code -> message. English is not listed; its
+// text comes from where each error is defined.
+var catalog = map[string]map[string]string{
+	Russian: {
+		"invalid_id":                "некорректный идентификатор",
+		"invalid_request":           "некорректный запрос",
+		"invalid_rating":            "оценка должна быть от 1 до 10",
+		"invalid_review_range":      "min_reviews и max_reviews должны быть неотрицательными целыми числами, min_reviews не больше max_reviews",
+		"unauthorized":              "требуется аутентификация",
+		"forbidden":                 "доступ запрещён",
+		"impersonation_forbidden":   "недоступно при входе от имени другого пользователя",
+		"reviews_private":           "отзывы пользователя скрыты",
+		"unknown_genre":             "жанр не найден",
+		"invalid_current_password":  "неверный текущий пароль",
+		"internal_error":            "внутренняя ошибка сервера",
+		"service_unavailable":       "сервис временно недоступен",
+		"missing_token":             "отсутствует bearer-токен",
+		"invalid_token":             "недействительный токен",
+		"rate_limited":              "превышен лимит запросов",
+		"query_too_large":           "слишком длинная строка запроса или слишком много параметров",
+		"file_required":             "требуется файл",
+		"unreadable_file":           "не удалось прочитать файл",
+		"invalid_csv":               "не удалось разобрать CSV: {error}",
+		"invalid_fields":            "в fields должно быть указано хотя бы одно поле",
+		"unknown_field":             "неизвестное поле: {field}",
+		"user_not_found":            "пользователь не найден",
+		"user_exists":               "email или имя пользователя уже заняты",
+		"invalid_credentials":       "неверные учётные данные",
+		"invalid_role":              "некорректная роль",
+		"cannot_delete_self":        "нельзя удалить самого себя",
+		"cannot_merge_self":         "source_id и target_id должны различаться",
+		"account_merge_disabled":    "объединение аккаунтов не настроено",
+		"cannot_update_self":        "нельзя изменить собственную роль",
+		"cannot_impersonate_self":   "нельзя войти от имени самого себя",
+		"cannot_impersonate_admin":  "нельзя войти от имени другого администратора",
+		"user_ids_required":         "необходимо указать user_ids",
+		"invalid_lookup":            "укажите ровно один из параметров email или username",
+		"invalid_sort":              "sort должен быть created_desc или created_asc",
+		"too_many_user_ids":         "в user_ids указано слишком много идентификаторов",
+		"genre_not_found":           "жанр не найден",
+		"genre_exists":              "жанр уже существует",
+		"movie_not_found":           "фильм не найден",
+		"invalid_reaction":          "реакция должна быть одной из: helpful, insightful, funny, disagree",
+		"reaction_not_found":        "реакция не найдена",
+		"movie_version_not_found":   "версия фильма не найдена",
+		"director_not_found":        "режиссёр не найден",
+		"movie_gone":                "фильм удалён",
+		"content_blocked":           "текст содержит запрещённое слово",
+		"blocked_keyword_not_found": "запрещённое слово не найдено",
+		"movie_exists":              "фильм с таким названием и годом выпуска уже существует",
+		"genre_ids_required":        "необходимо указать genre_ids",
+		"genres_required":           "требуется список genres",
+		"too_many_genres":           "в genres указано слишком много жанров",
+		"too_many_directors":        "в director_in указано слишком много режиссёров",
+		"too_many_ids":              "в ids указано слишком много идентификаторов",
+		"poster_too_large":          "постер должен быть не больше 5 МБ",
+		"unsupported_poster_type":   "постер должен быть изображением JPEG, PNG или WebP",
+		"posters_disabled":          "хранилище постеров не настроено",
+		"poster_not_found":          "постер не найден",
+		"unsupported_format":        "поддерживается только формат ndjson",
+		"invalid_pagination":        "параметр {param} должен быть положительным целым числом",
+		"review_not_found":          "отзыв не найден",
+		"review_ids_required":       "необходимо указать review_ids",
+		"too_many_review_ids":       "в review_ids указано слишком много идентификаторов",
+		"sitemap_not_found":         "карта сайта не найдена",
+		"year_not_found":            "в этом году не вышло ни одного фильма",
+		"search_query_too_short":    "поисковый запрос q слишком короткий",
+		"watch_not_found":           "запись в истории просмотров не найдена",
+		"review_exists":             "отзыв уже существует",
+		"edit_window_closed":        "срок редактирования отзыва истёк",
+		"similar_review_exists":     "похожий отзыв уже существует",
+		"recalculation_running":     "пересчёт уже выполняется",
+		"review_embargoed":          "отзывы на этот фильм принимаются с {embargo_until}",
+	},
+}
@@ -0,0 +1,67 @@
+// Package i18n localizes user-facing error messages. Error codes stay the
+// same in every language; only the message changes. English is the source
+// language: the messages passed to apierror.New are the English text, and
+// the catalog holds the translations.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	English = "en"
+	Russian = "ru"
+
+	Default = English
+)
+
+var (
+	supported = []string{English, Russian}
+	matcher   = language.NewMatcher([]language.Tag{language.English, language.Russian})
+)
+
+// Negotiate picks the supported locale that best matches an Accept-Language
+// header, falling back to English.
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, idx, conf := matcher.Match(tags...)
+	if conf == language.No {
+		return Default
+	}
+	return supported[idx]
+}
+
+type contextKey struct{}
+
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the request's locale, or English when none was set.
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok {
+		return locale
+	}
+	return Default
+}
+
+// Message returns the text for code in locale. Placeholders such as
+// {field} are filled from details. When the catalog has no entry, the
+// English fallback is returned unchanged.
+func Message(locale, code, fallback string, details map[string]any) string {
+	msg, ok := catalog[locale][code]
+	if !ok {
+		return fallback
+	}
+	for k, v := range details {
+		msg = strings.ReplaceAll(msg, "{"+k+"}", fmt.Sprint(v))
+	}
+	return msg
+}
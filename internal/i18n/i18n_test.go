@@ -0,0 +1,82 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header, want string
+	}{
+		{"", English},
+		{"en-US,en;q=0.9", English},
+		{"ru", Russian},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8", Russian},
+		{"de-DE,ru;q=0.5", Russian},
+		{"fr-FR,fr;q=0.9", English},
+		{"not a header;;", English},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	if got := Message(English, "movie_not_found", "movie not found", nil); got != "movie not found" {
+		t.Fatalf("english should use the fallback, got %q", got)
+	}
+	if got := Message(Russian, "movie_not_found", "movie not found", nil); got != "фильм не найден" {
+		t.Fatalf("unexpected russian message %q", got)
+	}
+	if got := Message(Russian, "no_such_code", "something broke", nil); got != "something broke" {
+		t.Fatalf("missing translation should fall back to english, got %q", got)
+	}
+	if got := Message("de", "movie_not_found", "movie not found", nil); got != "movie not found" {
+		t.Fatalf("unknown locale should fall back to english, got %q", got)
+	}
+	got := Message(Russian, "review_embargoed", "", map[string]any{"embargo_until": "2030-01-01T00:00:00Z"})
+	if got != "отзывы на этот фильм принимаются с 2030-01-01T00:00:00Z" {
+		t.Fatalf("details not substituted: %q", got)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != English {
+		t.Fatalf("expected english by default, got %q", got)
+	}
+	if got := FromContext(WithLocale(context.Background(), Russian)); got != Russian {
+		t.Fatalf("expected russian, got %q", got)
+	}
+}
+
+func TestValidationMessage(t *testing.T) {
+	type req struct {
+		Rating int `validate:"min=1,max=10"`
+	}
+	// Registering on several validators must work; every router builds one.
+	for i := 0; i < 2; i++ {
+		v := validator.New()
+		if err := RegisterValidator(v); err != nil {
+			t.Fatalf("register #%d: %v", i, err)
+		}
+		var ve validator.ValidationErrors
+		if !errors.As(v.Struct(req{Rating: 0}), &ve) {
+			t.Fatalf("expected validation errors")
+		}
+		if got := ValidationMessage(English, ve); got != "Rating must be 1 or greater" {
+			t.Fatalf("english: %q", got)
+		}
+		if got := ValidationMessage(Russian, ve); got != "Rating должен быть больше или равно 1" {
+			t.Fatalf("russian: %q", got)
+		}
+		if got := ValidationMessage("de", ve); got != "Rating must be 1 or greater" {
+			t.Fatalf("unknown locale: %q", got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	ru_translations "github.com/go-playground/validator/v10/translations/ru"
+)
+
+var (
+	universal   = ut.New(en.New(), en.New(), ru.New())
+	translators = map[string]ut.Translator{
+		English: newTranslator(English),
+		Russian: newTranslator(Russian),
+	}
+)
+
+// RegisterValidator installs English and Russian messages on v. Only errors
+// produced by a registered validator can be translated; others keep the
+// validator's default text.
+func RegisterValidator(v *validator.Validate) error {
+	if err := en_translations.RegisterDefaultTranslations(v, translators[English]); err != nil {
+		return err
+	}
+	return ru_translations.RegisterDefaultTranslations(v, translators[Russian])
+}
+
+// ValidationMessage renders every field error in locale, joined with "; ".
+func ValidationMessage(locale string, ve validator.ValidationErrors) string {
+	trans, ok := translators[locale]
+	if !ok {
+		trans = translators[Default]
+	}
+	msgs := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		msgs = append(msgs, fe.Translate(trans))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// reusableTranslator lets the default translations be registered on more
+// than one validator. The stock translator rejects a second Add of the same
+// key, which would leave every validator after the first untranslated.
+type reusableTranslator struct {
+	ut.Translator
+}
+
+func newTranslator(locale string) ut.Translator {
+	trans, _ := universal.GetTranslator(locale)
+	return &reusableTranslator{trans}
+}
+
+func (t *reusableTranslator) Add(key interface{}, text string, _ bool) error {
+	return t.Translator.Add(key, text, true)
+}
+
+func (t *reusableTranslator) AddCardinal(key interface{}, text string, rule locales.PluralRule, _ bool) error {
+	return t.Translator.AddCardinal(key, text, rule, true)
+}
+
+func (t *reusableTranslator) AddOrdinal(key interface{}, text string, rule locales.PluralRule, _ bool) error {
+	return t.Translator.AddOrdinal(key, text, rule, true)
+}
+
+func (t *reusableTranslator) AddRange(key interface{}, text string, rule locales.PluralRule, _ bool) error {
+	return t.Translator.AddRange(key, text, rule, true)
+}
@@ -2,36 +2,87 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/actor"
+	"golang-project/internal/apierror"
 	"golang-project/pkg/jwt"
 )
 
 type ContextKey string
 
+var (
+	errMissingToken = apierror.New(http.StatusUnauthorized, "missing_token", "missing bearer token")
+	errInvalidToken = apierror.New(http.StatusUnauthorized, "invalid_token", "invalid token")
+	errForbidden    = apierror.New(http.StatusForbidden, "forbidden", "forbidden")
+	errImpersonated = apierror.New(http.StatusForbidden, "impersonation_forbidden", "not allowed while impersonating a user")
+)
+
 const (
 	ContextUserID ContextKey = "userID"
 	ContextRole   ContextKey = "role"
+	// ContextImpersonatedBy holds the admin id from the impersonated_by
+	// claim. It is only set for impersonation tokens.
+	ContextImpersonatedBy ContextKey = "impersonatedBy"
 )
 
-func AuthMiddleware(secret string) gin.HandlerFunc {
+func AuthMiddleware(keys *jwt.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			apierror.Abort(c, errMissingToken)
 			return
 		}
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := jwt.Parse(token, secret)
+		claims, err := keys.Parse(token)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			apierror.Abort(c, errInvalidToken)
 			return
 		}
 
 		c.Set(string(ContextUserID), claims.UserID)
 		c.Set(string(ContextRole), claims.Role)
+		// Changes made with an impersonation token are attributed to the
+		// admin holding it, not to the user being impersonated.
+		actorID := claims.UserID
+		if claims.ImpersonatedBy != "" {
+			c.Set(string(ContextImpersonatedBy), claims.ImpersonatedBy)
+			actorID = claims.ImpersonatedBy
+		}
+		if id, err := strconv.Atoi(actorID); err == nil {
+			c.Request = c.Request.WithContext(actor.WithID(c.Request.Context(), id))
+		}
+		c.Next()
+	}
+}
+
+// OptionalAuth authenticates the request like AuthMiddleware when an
+// Authorization header is present and lets anonymous requests through.
+// A header carrying a bad token is still rejected.
+func OptionalAuth(keys *jwt.KeySet) gin.HandlerFunc {
+	auth := AuthMiddleware(keys)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// RejectImpersonation refuses requests made with an impersonation token. It
+// guards the account's credentials and settings, and impersonation itself,
+// so an admin acting as a user cannot take the account over.
+func RejectImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(string(ContextImpersonatedBy)); ok {
+			apierror.Abort(c, errImpersonated)
+			return
+		}
 		c.Next()
 	}
 }
@@ -45,16 +96,16 @@ func RequireRoles(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		roleValue, exists := c.Get(string(ContextRole))
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "role not found"})
+			apierror.Abort(c, errForbidden)
 			return
 		}
 		role, ok := roleValue.(string)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid role"})
+			apierror.Abort(c, errForbidden)
 			return
 		}
 		if _, ok := allowed[role]; !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			apierror.Abort(c, errForbidden)
 			return
 		}
 		c.Next()
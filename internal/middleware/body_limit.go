@@ -1,15 +1,25 @@
 package middleware
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+const rawBodyKey = "middleware.rawBody"
+
 // BodyLimit restricts request body size to prevent oversized payloads.
+// A BodyLimit on a route replaces the one installed globally, so single
+// endpoints such as uploads can accept more.
 func BodyLimit(maxBytes int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		body, ok := c.Get(rawBodyKey)
+		if !ok {
+			body = c.Request.Body
+			c.Set(rawBodyKey, body)
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, body.(io.ReadCloser), maxBytes)
 		c.Next()
 	}
 }
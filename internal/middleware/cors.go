@@ -2,13 +2,31 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 )
 
-func CORS() gin.HandlerFunc {
+// CORS allows browsers on allowedOrigins to call the API. An entry of "*"
+// allows every origin; otherwise the request's Origin is echoed back only
+// when it is listed.
+func CORS(allowedOrigins []string) gin.HandlerFunc {
+	return CORSFunc(func() []string { return allowedOrigins })
+}
+
+// CORSFunc is CORS with the allowed origins looked up on every request, so
+// they can change while the server runs.
+func CORSFunc(origins func() []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		allowedOrigins := origins()
+		if slices.Contains(allowedOrigins, "*") {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Writer.Header().Add("Vary", "Origin")
+			if origin := c.GetHeader("Origin"); origin != "" && slices.Contains(allowedOrigins, origin) {
+				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
 
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/apierror"
+)
+
+// LeakyBucketLimiter is a token bucket per key: a bucket holds up to
+// capacity tokens, refills continuously at refillPerSecond and each request
+// takes one. Unlike the fixed window of RateLimit, a client cannot get twice
+// the limit through by straddling a window boundary.
+type LeakyBucketLimiter struct {
+	capacity        float64
+	refillPerSecond float64
+	now             func() time.Time
+	buckets         sync.Map // key -> *atomic.Pointer[bucketState]
+}
+
+// bucketState is a bucket's level as of updated. States are never modified,
+// only replaced, so a bucket can be updated with a compare-and-swap.
+type bucketState struct {
+	tokens  float64
+	updated time.Time
+}
+
+// evicted replaces the state of a bucket Evict removes, so a Consume that
+// still holds the bucket starts over on the one now in the map.
+var evicted = &bucketState{}
+
+// LeakyBucketEvictInterval is how often LeakyBucketRateLimit drops the
+// buckets of idle clients.
+var LeakyBucketEvictInterval = time.Minute
+
+func NewLeakyBucketLimiter(capacity, refillPerSecond float64) *LeakyBucketLimiter {
+	return &LeakyBucketLimiter{capacity: capacity, refillPerSecond: refillPerSecond, now: time.Now}
+}
+
+// Consume takes a token from key's bucket, which starts out full. It
+// reports whether there was one, how many whole tokens are left and, when
+// there was none, how long until the next one.
+func (l *LeakyBucketLimiter) Consume(key string) (allowed bool, remaining int, retryAfter time.Duration) {
+	v, ok := l.buckets.Load(key)
+	if !ok {
+		v, _ = l.buckets.LoadOrStore(key, new(atomic.Pointer[bucketState]))
+	}
+	bucket := v.(*atomic.Pointer[bucketState])
+
+	for {
+		now := l.now()
+		old := bucket.Load()
+		if old == evicted {
+			v, _ = l.buckets.LoadOrStore(key, new(atomic.Pointer[bucketState]))
+			bucket = v.(*atomic.Pointer[bucketState])
+			continue
+		}
+		tokens := l.capacity
+		if old != nil {
+			tokens = min(l.capacity, old.tokens+now.Sub(old.updated).Seconds()*l.refillPerSecond)
+		}
+		allowed = tokens >= 1
+		if allowed {
+			tokens--
+		}
+		if !bucket.CompareAndSwap(old, &bucketState{tokens: tokens, updated: now}) {
+			continue
+		}
+		if !allowed {
+			return false, 0, time.Duration((1 - tokens) / l.refillPerSecond * float64(time.Second))
+		}
+		return true, int(tokens), 0
+	}
+}
+
+// Evict drops the buckets that have refilled to capacity, which behave
+// exactly like the full bucket a new key starts with, and returns how many
+// it dropped.
+func (l *LeakyBucketLimiter) Evict() int {
+	now := l.now()
+	dropped := 0
+	l.buckets.Range(func(key, v any) bool {
+		bucket := v.(*atomic.Pointer[bucketState])
+		old := bucket.Load()
+		if old == nil || old == evicted {
+			return true
+		}
+		if old.tokens+now.Sub(old.updated).Seconds()*l.refillPerSecond < l.capacity {
+			return true
+		}
+		if bucket.CompareAndSwap(old, evicted) {
+			l.buckets.CompareAndDelete(key, v)
+			dropped++
+		}
+		return true
+	})
+	return dropped
+}
+
+// LeakyBucketRateLimit limits each client IP with a LeakyBucketLimiter.
+// Every response carries X-RateLimit-Remaining; rejected ones also carry
+// Retry-After in whole seconds. Idle clients' buckets are evicted every
+// LeakyBucketEvictInterval until ctx is cancelled.
+func LeakyBucketRateLimit(ctx context.Context, capacity, refillPerSecond float64) gin.HandlerFunc {
+	limiter := NewLeakyBucketLimiter(capacity, refillPerSecond)
+	go func() {
+		ticker := time.NewTicker(LeakyBucketEvictInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				limiter.Evict()
+			}
+		}
+	}()
+	return func(c *gin.Context) {
+		allowed, remaining, retryAfter := limiter.Consume(c.ClientIP())
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			apierror.Abort(c, errRateLimited)
+			return
+		}
+		c.Next()
+	}
+}
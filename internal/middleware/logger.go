@@ -7,7 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Logger produces structured log lines with request metadata.
+// Logger produces structured log lines with request metadata. Requests made
+// with an impersonation token also log the impersonating admin.
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		reqID := param.Request.Header.Get(requestIDHeader)
@@ -18,7 +19,11 @@ func Logger() gin.HandlerFunc {
 				}
 			}
 		}
-		return fmt.Sprintf(`{"time":"%s","method":"%s","path":"%s","status":%d,"latency":"%s","ip":"%s","user_agent":"%s","request_id":"%s"}`+"\n",
+		impersonation := ""
+		if by, ok := param.Keys[string(ContextImpersonatedBy)].(string); ok {
+			impersonation = fmt.Sprintf(`,"impersonated_by":"%s"`, by)
+		}
+		return fmt.Sprintf(`{"time":"%s","method":"%s","path":"%s","status":%d,"latency":"%s","ip":"%s","user_agent":"%s","request_id":"%s"%s}`+"\n",
 			param.TimeStamp.Format(time.RFC3339),
 			param.Method,
 			param.Path,
@@ -27,6 +32,7 @@ func Logger() gin.HandlerFunc {
 			param.ClientIP,
 			param.Request.UserAgent(),
 			reqID,
+			impersonation,
 		)
 	})
 }
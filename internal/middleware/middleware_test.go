@@ -1,13 +1,19 @@
 package middleware
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"golang-project/internal/actor"
+	"golang-project/internal/apierror"
 	"golang-project/pkg/jwt"
 )
 
@@ -20,7 +26,7 @@ func TestAuthMiddlewareValidToken(t *testing.T) {
 	}
 
 	r := gin.New()
-	r.Use(AuthMiddleware(secret))
+	r.Use(AuthMiddleware(jwt.StaticKeySet(secret)))
 	r.GET("/protected", func(c *gin.Context) {
 		userID, _ := c.Get(string(ContextUserID))
 		role, _ := c.Get(string(ContextRole))
@@ -43,13 +49,14 @@ func TestAuthMiddlewareInvalidToken(t *testing.T) {
 	secret := "secret"
 
 	r := gin.New()
-	r.Use(AuthMiddleware(secret))
+	r.Use(RequestID(), AuthMiddleware(jwt.StaticKeySet(secret)))
 	r.GET("/protected", func(c *gin.Context) {
 		c.Status(http.StatusOK)
 	})
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
 	req.Header.Set("Authorization", "Bearer invalid")
+	req.Header.Set("X-Request-ID", "req-1")
 	w := httptest.NewRecorder()
 
 	r.ServeHTTP(w, req)
@@ -57,6 +64,13 @@ func TestAuthMiddlewareInvalidToken(t *testing.T) {
 	if w.Code != http.StatusUnauthorized {
 		t.Fatalf("expected 401, got %d", w.Code)
 	}
+	var resp apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if resp.Error.Code != "invalid_token" || resp.Error.RequestID != "req-1" {
+		t.Fatalf("expected invalid_token with request id, got %+v", resp.Error)
+	}
 }
 
 func TestRequireRoles(t *testing.T) {
@@ -68,7 +82,7 @@ func TestRequireRoles(t *testing.T) {
 	}
 
 	r := gin.New()
-	r.Use(AuthMiddleware(secret))
+	r.Use(AuthMiddleware(jwt.StaticKeySet(secret)))
 	r.GET("/admin", RequireRoles("admin"), func(c *gin.Context) {
 		c.Status(http.StatusOK)
 	})
@@ -107,7 +121,7 @@ func TestRequestID(t *testing.T) {
 func TestCORSOptions(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.Use(CORS())
+	r.Use(CORS([]string{"*"}))
 	r.OPTIONS("/path", func(c *gin.Context) {
 		c.Status(http.StatusOK)
 	})
@@ -124,6 +138,28 @@ func TestCORSOptions(t *testing.T) {
 	}
 }
 
+func TestCORS_AllowedOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORS([]string{"https://movies.example.com"}))
+	r.GET("/path", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for origin, want := range map[string]string{
+		"https://movies.example.com": "https://movies.example.com",
+		"https://evil.example.com":   "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/path", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != want {
+			t.Fatalf("%s: expected Access-Control-Allow-Origin %q, got %q", origin, want, got)
+		}
+	}
+}
+
 func TestRateLimit(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
@@ -150,3 +186,212 @@ func TestRateLimit(t *testing.T) {
 		t.Fatalf("expected 429, got %d", w.Code)
 	}
 }
+
+func TestLeakyBucketLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLeakyBucketLimiter(5, 10)
+	limiter.now = func() time.Time { return now }
+
+	// At exactly the refill rate the bucket never runs dry.
+	for i := 0; i < 6; i++ {
+		allowed, remaining, _ := limiter.Consume("steady")
+		if !allowed || remaining != 4 {
+			t.Fatalf("request %d: expected to be allowed with 4 left, got %v with %d", i+1, allowed, remaining)
+		}
+		now = now.Add(100 * time.Millisecond)
+	}
+
+	// A burst gets the capacity and no more.
+	for i := 0; i < 5; i++ {
+		if allowed, remaining, _ := limiter.Consume("burst"); !allowed || remaining != 4-i {
+			t.Fatalf("burst request %d: got %v with %d left", i+1, allowed, remaining)
+		}
+	}
+	allowed, _, retryAfter := limiter.Consume("burst")
+	if allowed || retryAfter != 100*time.Millisecond {
+		t.Fatalf("expected the 6th burst request to wait 100ms, got allowed %v, retry after %v", allowed, retryAfter)
+	}
+	now = now.Add(retryAfter)
+	if allowed, _, _ := limiter.Consume("burst"); !allowed {
+		t.Fatal("expected a request once a token has refilled")
+	}
+}
+
+func TestLeakyBucketLimiter_Evict(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLeakyBucketLimiter(2, 1)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Consume("idle")
+	limiter.Consume("busy")
+	limiter.Consume("busy")
+	now = now.Add(1500 * time.Millisecond)
+
+	// idle is full again; busy still has half a token to go.
+	if dropped := limiter.Evict(); dropped != 1 {
+		t.Fatalf("expected one bucket evicted, got %d", dropped)
+	}
+	if _, ok := limiter.buckets.Load("idle"); ok {
+		t.Fatal("expected the idle bucket to be gone")
+	}
+	if allowed, remaining, _ := limiter.Consume("busy"); !allowed || remaining != 0 {
+		t.Fatalf("expected busy to keep its level, got %v with %d left", allowed, remaining)
+	}
+	if allowed, remaining, _ := limiter.Consume("idle"); !allowed || remaining != 1 {
+		t.Fatalf("expected an evicted client to start with a full bucket, got %v with %d left", allowed, remaining)
+	}
+}
+
+func TestLeakyBucketRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(LeakyBucketRateLimit(t.Context(), 2, 0.5))
+	r.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i, want := range []struct {
+		code             int
+		remaining, retry string
+	}{
+		{http.StatusOK, "1", ""},
+		{http.StatusOK, "0", ""},
+		{http.StatusTooManyRequests, "0", "2"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "127.0.0.1:1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want.code || w.Header().Get("X-RateLimit-Remaining") != want.remaining || w.Header().Get("Retry-After") != want.retry {
+			t.Fatalf("request %d: expected %d with remaining %q and Retry-After %q, got %d with %q and %q", i+1,
+				want.code, want.remaining, want.retry, w.Code, w.Header().Get("X-RateLimit-Remaining"), w.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestBodyLimit_RouteOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(BodyLimit(10))
+	read := func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	r.POST("/small", read)
+	r.POST("/large", BodyLimit(100), read)
+
+	for path, want := range map[string]int{"/small": http.StatusRequestEntityTooLarge, "/large": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(strings.Repeat("x", 50)))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestQueryGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(QueryGuard(64, 3))
+	r.GET("/movies", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for target, want := range map[string]int{
+		"/movies":                                 http.StatusOK,
+		"/movies?genre=drama&page=2&limit=5":      http.StatusOK,
+		"/movies?a=1&b=2&c=3&d=4":                 http.StatusBadRequest,
+		"/movies?q=" + strings.Repeat("x", 64):    http.StatusBadRequest,
+		"/movies?" + strings.Repeat("a=1&", 2000): http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("%.40s: expected %d, got %d", target, want, w.Code)
+		}
+		if want == http.StatusBadRequest && !strings.Contains(w.Body.String(), "query_too_large") {
+			t.Fatalf("%.40s: expected query_too_large, got %s", target, w.Body.String())
+		}
+	}
+}
+
+func TestRetryOnTransient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = 100 * time.Millisecond })
+
+	calls := 0
+	flaky := func(c *gin.Context) {
+		calls++
+		if calls <= 2 {
+			_ = c.Error(errors.New("pq: connection pool exhausted"))
+			c.Header("X-Attempt-Failed", "true")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"attempt": calls})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"attempt": calls})
+	}
+
+	r := gin.New()
+	r.Use(RetryOnTransient(2))
+	r.GET("/movies", flaky)
+	r.POST("/movies", flaky)
+
+	req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || calls != 3 {
+		t.Fatalf("expected 200 on the third attempt, got %d after %d calls", w.Code, calls)
+	}
+	if w.Body.String() != `{"attempt":3}` {
+		t.Fatalf("failed attempts leaked into the body: %s", w.Body)
+	}
+	if w.Header().Get("X-Attempt-Failed") != "" {
+		t.Fatal("failed attempts leaked headers")
+	}
+
+	calls = 0
+	req = httptest.NewRequest(http.MethodPost, "/movies", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable || calls != 1 {
+		t.Fatalf("POST must not be retried, got %d after %d calls", w.Code, calls)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unmatched route expected 404, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddlewareImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := jwt.StaticKeySet("secret")
+	token, _, err := keys.IssueImpersonation("3", "user", "1", time.Minute)
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(AuthMiddleware(keys))
+	r.GET("/me", func(c *gin.Context) {
+		actorID, _ := actor.ID(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString(string(ContextUserID)), "impersonated_by": c.GetString(string(ContextImpersonatedBy)), "actor": actorID})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != `{"actor":1,"impersonated_by":"1","user_id":"3"}` {
+		t.Fatalf("expected the impersonator on the context and as the actor, got %s", w.Body)
+	}
+}
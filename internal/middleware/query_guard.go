@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/apierror"
+)
+
+var errQueryTooLarge = apierror.New(http.StatusBadRequest, "query_too_large", "query string too large")
+
+// QueryGuard rejects requests whose raw query string is longer than
+// maxLength bytes or holds more than maxParams parameters. It only counts
+// separators, so an oversized query is turned away before anything parses
+// it.
+func QueryGuard(maxLength, maxParams int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.Request.URL.RawQuery
+		if len(raw) > maxLength || countParams(raw) > maxParams {
+			apierror.Abort(c, errQueryTooLarge)
+			return
+		}
+		c.Next()
+	}
+}
+
+// countParams counts the non-empty &-separated parts of a raw query.
+func countParams(raw string) int {
+	n := 0
+	for part := range strings.SplitSeq(raw, "&") {
+		if part != "" {
+			n++
+		}
+	}
+	return n
+}
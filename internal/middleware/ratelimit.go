@@ -6,40 +6,52 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/apierror"
 )
 
+var errRateLimited = apierror.New(http.StatusTooManyRequests, "rate_limited", "rate limit exceeded")
+
 type rateState struct {
 	count     int
 	windowEnd time.Time
 }
 
-var rateStore = struct {
+type rateStore struct {
 	mu   sync.Mutex
 	data map[string]rateState
-}{
-	data: make(map[string]rateState),
 }
 
+// RateLimit allows each client IP maxPerMinute requests per minute. Every
+// call keeps its own counters, so a route can add a tighter limit on top of
+// the router-wide one.
 func RateLimit(maxPerMinute int) gin.HandlerFunc {
+	return RateLimitFunc(func() int { return maxPerMinute })
+}
+
+// RateLimitFunc is RateLimit with the limit looked up on every request, so
+// it can change while the server runs.
+func RateLimitFunc(maxPerMinute func() int) gin.HandlerFunc {
 	window := time.Minute
+	store := &rateStore{data: make(map[string]rateState)}
 
 	return func(c *gin.Context) {
 		key := c.ClientIP()
 		now := time.Now()
 
-		rateStore.mu.Lock()
-		state := rateStore.data[key]
+		store.mu.Lock()
+		state := store.data[key]
 		if state.windowEnd.IsZero() || now.After(state.windowEnd) {
 			state.windowEnd = now.Add(window)
 			state.count = 0
 		}
 		state.count++
-		rateStore.data[key] = state
+		store.data[key] = state
 		currentCount := state.count
-		rateStore.mu.Unlock()
+		store.mu.Unlock()
 
-		if currentCount > maxPerMinute {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		if currentCount > maxPerMinute() {
+			apierror.Abort(c, errRateLimited)
 			return
 		}
 
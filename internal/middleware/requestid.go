@@ -5,9 +5,11 @@ import (
 	"encoding/binary"
 	"github.com/gin-gonic/gin"
 	"strconv"
+
+	"golang-project/internal/apierror"
 )
 
-const requestIDHeader = "X-Request-ID"
+const requestIDHeader = apierror.RequestIDHeader
 
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
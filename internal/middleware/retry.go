@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-project/internal/database"
+)
+
+// retryBackoff is the delay before the first retry; it doubles after each one.
+var retryBackoff = 100 * time.Millisecond
+
+// RetryOnTransient re-runs the route handler of GET and HEAD requests that
+// answered 503 because of a transient database error (as recorded with
+// c.Error), up to maxRetries times. The response is buffered until the last
+// attempt, so the client only sees the outcome of that one. Middleware
+// registered after RetryOnTransient runs once.
+func RetryOnTransient(maxRetries int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := c.Writer
+		header := w.Header().Clone()
+		// Start from the writer's status: gin sets 404 and 405 before running
+		// the middleware of unmatched routes.
+		buf := &bufferedWriter{ResponseWriter: w, status: w.Status()}
+		c.Writer = buf
+		defer func() {
+			c.Writer = w
+			buf.flush()
+		}()
+
+		c.Next()
+		delay := retryBackoff
+		for attempt := 0; attempt < maxRetries && buf.status == http.StatusServiceUnavailable && hasTransientError(c); attempt++ {
+			select {
+			case <-time.After(delay):
+			case <-c.Request.Context().Done():
+				return
+			}
+			delay *= 2
+
+			for k := range w.Header() {
+				delete(w.Header(), k)
+			}
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			buf.reset()
+			c.Errors = c.Errors[:0]
+			c.Handler()(c)
+		}
+	}
+}
+
+func hasTransientError(c *gin.Context) bool {
+	for _, e := range c.Errors {
+		if database.IsTransient(e.Err) {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds the status and body of a 503 attempt instead of
+// sending them, so it can be thrown away. Any other response cannot be
+// retried and is passed straight through once its body starts, which keeps
+// large downloads streaming.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	direct  bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	if w.passThrough() {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	if w.passThrough() {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) passThrough() bool {
+	if !w.direct && w.status != http.StatusServiceUnavailable {
+		w.flush()
+		w.direct = true
+	}
+	return w.direct
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.direct {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+func (w *bufferedWriter) reset() {
+	w.status = http.StatusOK
+	w.written = false
+	w.body.Reset()
+}
+
+func (w *bufferedWriter) flush() {
+	if w.direct {
+		return
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
@@ -0,0 +1,3 @@
+ALTER TABLE audit_logs ADD COLUMN actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs(actor_id);
@@ -0,0 +1 @@
+ALTER TABLE reviews ADD COLUMN sentiment_score DOUBLE PRECISION;
@@ -0,0 +1 @@
+ALTER TABLE movies ADD COLUMN review_embargo_until TIMESTAMPTZ;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS movies_director_lower_idx;
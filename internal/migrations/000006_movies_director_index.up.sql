@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS movies_director_lower_idx ON movies (LOWER(director));
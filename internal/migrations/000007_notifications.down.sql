@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notifications;
+ALTER TABLE movies DROP COLUMN IF EXISTS submitted_by_user_id;
@@ -0,0 +1,17 @@
+ALTER TABLE movies ADD COLUMN normalized_average_rating DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+UPDATE movies m
+SET normalized_average_rating = agg.norm_rating
+FROM (
+    SELECT rv.movie_id,
+           AVG(CASE WHEN s.sd IS NULL OR s.sd = 0 THEN 5
+                    ELSE 5 + (rv.rating - s.mean) / s.sd * 2 END) AS norm_rating
+    FROM reviews rv
+    JOIN (
+        SELECT user_id, AVG(rating) AS mean, STDDEV_SAMP(rating) AS sd
+        FROM reviews
+        GROUP BY user_id
+    ) s ON s.user_id = rv.user_id
+    GROUP BY rv.movie_id
+) agg
+WHERE m.id = agg.movie_id;
@@ -0,0 +1 @@
+ALTER TABLE movies ADD COLUMN poster_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_reviews_content_trgm ON reviews USING GIN (content gin_trgm_ops);
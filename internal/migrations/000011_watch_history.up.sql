@@ -0,0 +1,9 @@
+CREATE TABLE watch_history (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    movie_id INTEGER NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    watched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    progress_percent INTEGER CHECK (progress_percent BETWEEN 0 AND 100),
+    PRIMARY KEY (user_id, movie_id)
+);
+
+CREATE INDEX idx_watch_history_user_watched_at ON watch_history(user_id, watched_at DESC);
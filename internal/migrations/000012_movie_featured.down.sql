@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS movies_featured_idx;
+ALTER TABLE movies DROP COLUMN IF EXISTS featured;
@@ -0,0 +1,3 @@
+ALTER TABLE movies ADD COLUMN featured BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX movies_featured_idx ON movies (updated_at DESC) WHERE featured;
@@ -0,0 +1 @@
+ALTER TABLE genres DROP COLUMN IF EXISTS parent_id;
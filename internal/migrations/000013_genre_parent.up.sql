@@ -0,0 +1 @@
+ALTER TABLE genres ADD COLUMN parent_id INTEGER REFERENCES genres(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS movies_pending_published_at_idx;
+ALTER TABLE movies DROP COLUMN IF EXISTS published_at, DROP COLUMN IF EXISTS status;
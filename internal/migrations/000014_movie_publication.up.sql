@@ -0,0 +1,5 @@
+ALTER TABLE movies
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('pending', 'published')),
+    ADD COLUMN published_at TIMESTAMPTZ;
+
+CREATE INDEX movies_pending_published_at_idx ON movies (published_at) WHERE status = 'pending';
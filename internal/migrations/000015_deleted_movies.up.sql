@@ -0,0 +1,4 @@
+CREATE TABLE deleted_movies (
+    movie_id INTEGER PRIMARY KEY,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
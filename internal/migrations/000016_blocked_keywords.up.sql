@@ -0,0 +1,7 @@
+CREATE TABLE blocked_keywords (
+    id SERIAL PRIMARY KEY,
+    keyword TEXT NOT NULL CHECK (keyword <> ''),
+    case_sensitive BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+ALTER TABLE movies ADD COLUMN review_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE movies m
+SET review_count = (SELECT COUNT(*) FROM reviews r WHERE r.movie_id = m.id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_last_review_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS reminder_sent_at,
+    DROP COLUMN IF EXISTS last_review_at,
+    DROP COLUMN IF EXISTS email_on_digest,
+    DROP COLUMN IF EXISTS is_active;
@@ -0,0 +1,10 @@
+ALTER TABLE users
+    ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN email_on_digest BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN last_review_at TIMESTAMPTZ,
+    ADD COLUMN reminder_sent_at TIMESTAMPTZ;
+
+UPDATE users u
+SET last_review_at = (SELECT MAX(r.created_at) FROM reviews r WHERE r.user_id = u.id);
+
+CREATE INDEX idx_users_last_review_at ON users(last_review_at) WHERE email_on_digest;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN reviews_public BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,8 @@
+-- Merged accounts are soft-deleted, so only live accounts keep their email
+-- and username to themselves.
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_email_key,
+    DROP CONSTRAINT IF EXISTS users_username_key;
+
+CREATE UNIQUE INDEX users_email_live_key ON users(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX users_username_live_key ON users(username) WHERE deleted_at IS NULL;
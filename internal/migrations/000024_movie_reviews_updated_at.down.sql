@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS reviews_touch_movie ON reviews;
+DROP FUNCTION IF EXISTS touch_movie_reviews();
+
+ALTER TABLE movies DROP COLUMN IF EXISTS reviews_updated_at;
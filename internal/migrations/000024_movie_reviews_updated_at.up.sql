@@ -0,0 +1,22 @@
+ALTER TABLE movies ADD COLUMN reviews_updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE movies m
+SET reviews_updated_at = COALESCE((SELECT MAX(r.updated_at) FROM reviews r WHERE r.movie_id = m.id), m.created_at, NOW());
+
+-- Every write to reviews moves its movie's timestamp, whichever code path
+-- made it, so cached review lists can be validated against it.
+CREATE FUNCTION touch_movie_reviews() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        UPDATE movies SET reviews_updated_at = NOW() WHERE id = OLD.movie_id;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        UPDATE movies SET reviews_updated_at = NOW() WHERE id = NEW.movie_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reviews_touch_movie
+    AFTER INSERT OR UPDATE OR DELETE ON reviews
+    FOR EACH ROW EXECUTE FUNCTION touch_movie_reviews();
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN reviews_public BOOLEAN NOT NULL DEFAULT TRUE;
+UPDATE users SET reviews_public = NOT reviews_private;
+ALTER TABLE users DROP COLUMN reviews_private;
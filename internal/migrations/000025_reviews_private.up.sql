@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN reviews_private BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET reviews_private = NOT reviews_public;
+ALTER TABLE users DROP COLUMN reviews_public;
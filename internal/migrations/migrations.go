@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations in this directory, so a
+// binary built with MIGRATIONS_SOURCE=embed needs no files on disk.
+package migrations
+
+import "embed"
+
+// FS holds the *.up.sql and *.down.sql files, at its root.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -12,25 +13,145 @@ type User struct {
 	Role         string    `json:"role" db:"role"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	// Review reminders go to active users who opted in with EmailOnDigest
+	// and whose LastReviewAt is old enough, at most once a week going by
+	// ReminderSentAt.
+	IsActive       bool       `json:"-" db:"is_active"`
+	EmailOnDigest  bool       `json:"email_on_digest" db:"email_on_digest"`
+	LastReviewAt   *time.Time `json:"last_review_at,omitempty" db:"last_review_at"`
+	ReminderSentAt *time.Time `json:"-" db:"reminder_sent_at"`
+	// ReviewsPrivate restricts listing the user's reviews to the user and
+	// admins; by default anyone may.
+	ReviewsPrivate bool `json:"reviews_private" db:"reviews_private"`
+}
+
+// PublicUser is the part of a user that is shown to other users.
+type PublicUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// PublicUserProfile is what anyone may see on a user's profile page.
+type PublicUserProfile struct {
+	ID            int       `json:"id"`
+	Username      string    `json:"username"`
+	CreatedAt     time.Time `json:"created_at"`
+	ReviewCount   int       `json:"review_count"`
+	AverageRating float64   `json:"average_rating"`
+	FavoriteGenre *Genre    `json:"favorite_genre"`
+}
+
+// AccountActivity is the summary in the header of a user's own profile.
+// WatchlistSize counts the movies in their watch history.
+type AccountActivity struct {
+	TotalReviews   int        `json:"total_reviews"`
+	LastReviewAt   *time.Time `json:"last_review_at"`
+	AverageRating  float64    `json:"average_rating"`
+	FavoriteGenre  *Genre     `json:"favorite_genre"`
+	WatchlistSize  int        `json:"watchlist_size"`
+	AccountAgeDays int        `json:"account_age_days"`
+}
+
+func (u *User) Public() *PublicUser {
+	return &PublicUser{ID: u.ID, Username: u.Username}
 }
 
 type Genre struct {
 	ID        int       `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
+	ParentID  *int      `json:"parent_id,omitempty" db:"parent_id"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+type GenreStats struct {
+	MovieCount  int `json:"movie_count"`
+	ReviewCount int `json:"review_count"`
+}
+
+type GenreWithStats struct {
+	Genre
+	GenreStats
+}
+
+// Movie statuses. A pending movie is hidden from public listings until the
+// publisher scheduler publishes it at PublishedAt.
+const (
+	MovieStatusPending   = "pending"
+	MovieStatusPublished = "published"
+)
+
 type Movie struct {
-	ID              int       `json:"id" db:"id"`
-	Title           string    `json:"title" db:"title"`
-	Description     string    `json:"description" db:"description"`
-	ReleaseYear     int       `json:"release_year" db:"release_year"`
-	Director        string    `json:"director" db:"director"`
-	DurationMinutes int       `json:"duration_minutes" db:"duration_minutes"`
-	AverageRating   float64   `json:"average_rating" db:"average_rating"`
-	Genres          []Genre   `json:"genres,omitempty"`
-	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	ID              int     `json:"id" db:"id"`
+	Title           string  `json:"title" db:"title"`
+	Description     string  `json:"description" db:"description"`
+	ReleaseYear     int     `json:"release_year" db:"release_year"`
+	Director        string  `json:"director" db:"director"`
+	DurationMinutes int     `json:"duration_minutes" db:"duration_minutes"`
+	PosterURL       string  `json:"poster_url,omitempty" db:"poster_url"`
+	AverageRating   float64 `json:"average_rating" db:"average_rating"`
+	// NormalizedAverageRating averages the movie's ratings after rescaling
+	// each one against its reviewer's own mean and spread.
+	NormalizedAverageRating float64    `json:"normalized_average_rating" db:"normalized_average_rating"`
+	ReviewEmbargoUntil      *time.Time `json:"review_embargo_until,omitempty" db:"review_embargo_until"`
+	SubmittedByUserID       int        `json:"submitted_by_user_id,omitempty" db:"submitted_by_user_id"`
+	Featured                bool       `json:"featured" db:"featured"`
+	Status                  string     `json:"status" db:"status"`
+	PublishedAt             *time.Time `json:"published_at,omitempty" db:"published_at"`
+	Watched                 *bool      `json:"watched,omitempty"`
+	// ReviewCount is kept up to date by the review worker rather than
+	// counted on every read.
+	ReviewCount int       `json:"review_count" db:"review_count"`
+	Genres      []Genre   `json:"genres,omitempty"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// ReviewsUpdatedAt is when any of the movie's reviews was last added,
+	// changed or removed; it validates cached review lists.
+	ReviewsUpdatedAt time.Time `json:"-" db:"reviews_updated_at"`
+}
+
+// SitemapMovie is the little of a movie the sitemap needs.
+type SitemapMovie struct {
+	ID        int
+	Title     string
+	UpdatedAt time.Time
+}
+
+// SimilarDirector is a director whose movies were reviewed by people who
+// also reviewed another director's movies.
+type SimilarDirector struct {
+	Name            string `json:"name"`
+	CoReviewerCount int    `json:"co_reviewer_count"`
+}
+
+// DirectorCount is a director and how many published movies they have.
+type DirectorCount struct {
+	Name       string `json:"name"`
+	MovieCount int    `json:"movie_count"`
+}
+
+// DirectorStats summarizes a director's published movies.
+type DirectorStats struct {
+	MovieCount    int     `json:"movie_count"`
+	AverageRating float64 `json:"average_rating"`
+	BestMovie     *Movie  `json:"best_movie"`
+}
+
+// YearSummary sums up the published movies released in a year and the
+// reviews they got. AverageRating averages the reviewed movies' ratings.
+type YearSummary struct {
+	Year              int           `json:"year"`
+	BestMovie         *Movie        `json:"best_movie"`
+	MostReviewedMovie *Movie        `json:"most_reviewed_movie"`
+	TopReviewers      []TopReviewer `json:"top_reviewers"`
+	AverageRating     float64       `json:"avg_rating"`
+	TotalMovies       int           `json:"total_movies"`
+	TotalReviews      int           `json:"total_reviews"`
+}
+
+// TopReviewer is a user with the number of reviews they wrote.
+type TopReviewer struct {
+	PublicUser
+	ReviewCount int `json:"review_count"`
 }
 
 type MovieGenre struct {
@@ -39,16 +160,36 @@ type MovieGenre struct {
 }
 
 type Review struct {
-	ID        int       `json:"id" db:"id"`
-	MovieID   int       `json:"movie_id" db:"movie_id"`
+	ID             int         `json:"id" db:"id"`
+	MovieID        int         `json:"movie_id" db:"movie_id"`
+	UserID         int         `json:"user_id" db:"user_id"`
+	Rating         int         `json:"rating" db:"rating"`
+	Title          string      `json:"title" db:"title"`
+	Content        string      `json:"content" db:"content"`
+	CreatedAt      time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at" db:"updated_at"`
+	SentimentScore *float64    `json:"sentiment_score" db:"sentiment_score"`
+	User           *PublicUser `json:"user,omitempty"`
+	Movie          *Movie      `json:"movie,omitempty"`
+	// ReactionCounts is filled in on single-review responses and review
+	// lists.
+	ReactionCounts map[string]int `json:"reaction_counts,omitempty"`
+}
+
+// ReactionTypes lists the reactions a user can leave on a review.
+var ReactionTypes = []string{"helpful", "insightful", "funny", "disagree"}
+
+// ReviewReaction is a user's reaction to a review; each user has at most
+// one per review.
+type ReviewReaction struct {
+	ReviewID  int       `json:"review_id" db:"review_id"`
 	UserID    int       `json:"user_id" db:"user_id"`
-	Rating    int       `json:"rating" db:"rating"`
-	Title     string    `json:"title" db:"title"`
-	Content   string    `json:"content" db:"content"`
+	Reaction  string    `json:"reaction" db:"reaction_type"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	User      *User     `json:"user,omitempty"`
-	Movie     *Movie    `json:"movie,omitempty"`
+}
+
+type ReactRequest struct {
+	Reaction string `json:"reaction" validate:"required"`
 }
 
 type AuditLog struct {
@@ -56,11 +197,113 @@ type AuditLog struct {
 	UserID    *int      `json:"user_id" db:"user_id"`
 	MovieID   *int      `json:"movie_id" db:"movie_id"`
 	ReviewID  *int      `json:"review_id" db:"review_id"`
+	ActorID   *int      `json:"actor_id" db:"actor_id"`
 	Event     string    `json:"event" db:"event"`
 	Details   string    `json:"details" db:"details"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// OwnAuditLog is an audit entry as shown to the user it is about. Who acted
+// and the free-form details are internal and left out.
+type OwnAuditLog struct {
+	ID        int       `json:"id"`
+	MovieID   *int      `json:"movie_id"`
+	ReviewID  *int      `json:"review_id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notification is an in-app message for a user. Payload is stored as JSONB;
+// which fields are set depends on EventType.
+type Notification struct {
+	ID        int                 `json:"id" db:"id"`
+	UserID    int                 `json:"user_id" db:"user_id"`
+	EventType string              `json:"event_type" db:"event_type"`
+	Payload   NotificationPayload `json:"payload" db:"payload"`
+	Read      bool                `json:"read" db:"read"`
+	CreatedAt time.Time           `json:"created_at" db:"created_at"`
+}
+
+type NotificationPayload struct {
+	MovieID  int `json:"movie_id,omitempty"`
+	ReviewID int `json:"review_id,omitempty"`
+}
+
+// SearchHit is one result of the site-wide search. Type is "movie", "genre"
+// or "user"; Title holds the movie title, genre name or username.
+type SearchHit struct {
+	Type        string `json:"type"`
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	ReleaseYear int    `json:"release_year,omitempty"`
+	Director    string `json:"director,omitempty"`
+}
+
+// SearchResults groups search hits by type. Users is left out unless the
+// caller asked for users and some matched.
+type SearchResults struct {
+	Movies []SearchHit `json:"movies"`
+	Genres []SearchHit `json:"genres"`
+	Users  []SearchHit `json:"users,omitempty"`
+}
+
+// MovieVersion is a snapshot of a movie's details taken just before an
+// update, so admins can see what changed and restore it.
+type MovieVersion struct {
+	ID              int       `json:"id" db:"id"`
+	MovieID         int       `json:"movie_id" db:"movie_id"`
+	Title           string    `json:"title" db:"title"`
+	Description     string    `json:"description" db:"description"`
+	ReleaseYear     int       `json:"release_year" db:"release_year"`
+	Director        string    `json:"director" db:"director"`
+	DurationMinutes int       `json:"duration_minutes" db:"duration_minutes"`
+	EditedByUserID  *int      `json:"edited_by_user_id,omitempty" db:"edited_by_user_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// WatchHistory records that a user watched a movie. Each user has at most
+// one entry per movie; watching it again moves WatchedAt forward.
+type WatchHistory struct {
+	UserID          int       `json:"user_id" db:"user_id"`
+	MovieID         int       `json:"movie_id" db:"movie_id"`
+	WatchedAt       time.Time `json:"watched_at" db:"watched_at"`
+	ProgressPercent *int      `json:"progress_percent,omitempty" db:"progress_percent"`
+}
+
+type RecordWatchRequest struct {
+	MovieID         int  `json:"movie_id" validate:"required,min=1"`
+	ProgressPercent *int `json:"progress_percent" validate:"omitempty,min=0,max=100"`
+}
+
+// BlockedKeyword is a term reviews may not contain, such as a spoiler or a
+// trade name. Keywords match anywhere in the text, ignoring case unless
+// CaseSensitive is set.
+type BlockedKeyword struct {
+	ID            int       `json:"id" db:"id"`
+	Keyword       string    `json:"keyword" db:"keyword"`
+	CaseSensitive bool      `json:"case_sensitive" db:"case_sensitive"`
+	CreatedBy     *int      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+type BlockedKeywordRequest struct {
+	Keyword       string `json:"keyword" validate:"required,max=100"`
+	CaseSensitive bool   `json:"case_sensitive"`
+}
+
+type CheckContentRequest struct {
+	Content string `json:"content" validate:"required"`
+}
+
+// AuthResponse is returned by register and login. ExpiresIn is the token
+// lifetime in seconds.
+type AuthResponse struct {
+	User      *User     `json:"user"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ExpiresIn int       `json:"expires_in"`
+}
+
 type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Username string `json:"username" validate:"required,min=3,max=100"`
@@ -70,6 +313,13 @@ type CreateUserRequest struct {
 type UpdateUserRequest struct {
 	Email    string `json:"email" validate:"omitempty,email"`
 	Username string `json:"username" validate:"omitempty,min=3,max=100"`
+	// EmailOnDigest turns review reminder emails on or off; nil leaves it.
+	EmailOnDigest *bool `json:"email_on_digest"`
+}
+
+// UpdatePrivacyRequest is the body of PUT /me/privacy.
+type UpdatePrivacyRequest struct {
+	ReviewsPublic *bool `json:"reviews_public"`
 }
 
 type UpdatePasswordRequest struct {
@@ -83,51 +333,112 @@ type LoginRequest struct {
 }
 
 type CreateMovieRequest struct {
-	Title           string   `json:"title" validate:"required,max=255"`
-	Description     string   `json:"description"`
-	ReleaseYear     int      `json:"release_year" validate:"required,min=1800,max=2030"`
-	Director        string   `json:"director" validate:"max=255"`
-	DurationMinutes int      `json:"duration_minutes" validate:"min=1"`
-	GenreIDs        []string `json:"genre_ids" validate:"required,min=1"`
+	Title              string     `json:"title" validate:"required,max=255"`
+	Description        string     `json:"description"`
+	ReleaseYear        int        `json:"release_year" validate:"required,min=1800,max=2030"`
+	Director           string     `json:"director" validate:"max=255"`
+	DurationMinutes    int        `json:"duration_minutes" validate:"min=1"`
+	GenreIDs           []string   `json:"genre_ids" validate:"required,min=1"`
+	ReviewEmbargoUntil *time.Time `json:"review_embargo_until"`
+	PublishedAt        *time.Time `json:"published_at"`
 }
 
 type UpdateMovieRequest struct {
-	Title           string   `json:"title" validate:"max=255"`
-	Description     string   `json:"description"`
-	ReleaseYear     int      `json:"release_year" validate:"min=1800,max=2030"`
-	Director        string   `json:"director" validate:"max=255"`
-	DurationMinutes int      `json:"duration_minutes" validate:"min=1"`
-	GenreIDs        []string `json:"genre_ids"`
+	Title              string     `json:"title" validate:"max=255"`
+	Description        string     `json:"description"`
+	ReleaseYear        int        `json:"release_year" validate:"min=1800,max=2030"`
+	Director           string     `json:"director" validate:"max=255"`
+	DurationMinutes    int        `json:"duration_minutes" validate:"min=1"`
+	GenreIDs           []string   `json:"genre_ids"`
+	ReviewEmbargoUntil *time.Time `json:"review_embargo_until"`
+	// ClearReviewEmbargo is set when the body sends review_embargo_until as
+	// null, which lifts the embargo; leaving the field out keeps it.
+	ClearReviewEmbargo bool `json:"-"`
+}
+
+func (r *UpdateMovieRequest) UnmarshalJSON(data []byte) error {
+	type plain UpdateMovieRequest
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	raw, ok := fields["review_embargo_until"]
+	r.ClearReviewEmbargo = ok && string(raw) == "null"
+	return nil
 }
 
 type CreateGenreRequest struct {
 	Name string `json:"name" validate:"required,max=100"`
 }
 
+// BulkGenreInput is one genre in an import. Parent names another genre,
+// either existing or part of the same import.
+type BulkGenreInput struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	Parent string `json:"parent,omitempty" validate:"max=100"`
+}
+
+type ImportGenresRequest struct {
+	Genres []BulkGenreInput `json:"genres"`
+}
+
+// BulkError reports why one item of a bulk request was not imported. Index
+// is the item's position in the request, from 0.
+type BulkError struct {
+	Index  int      `json:"index"`
+	Name   string   `json:"name"`
+	Errors []string `json:"errors"`
+}
+
+// BulkGenreResult counts the genres an import created and the ones it
+// skipped because they already existed.
+type BulkGenreResult struct {
+	Created int         `json:"created"`
+	Skipped int         `json:"skipped"`
+	Errors  []BulkError `json:"errors"`
+}
+
 type CreateReviewRequest struct {
 	Rating  int    `json:"rating" validate:"required,min=1,max=10"`
 	Title   string `json:"title" validate:"required,max=255"`
 	Content string `json:"content" validate:"required"`
 }
 
+// UpdateReviewRequest changes only the fields that are present: a nil field
+// keeps its current value, while an explicit empty title or content is
+// rejected just as on creation.
 type UpdateReviewRequest struct {
-	Rating  int    `json:"rating" validate:"min=1,max=10"`
-	Title   string `json:"title" validate:"max=255"`
-	Content string `json:"content"`
+	Rating  *int    `json:"rating,omitempty" validate:"omitnil,min=1,max=10"`
+	Title   *string `json:"title,omitempty" validate:"omitnil,min=1,max=255"`
+	Content *string `json:"content,omitempty" validate:"omitnil,min=1"`
 }
 
+// MaxPageLimit is the largest page a list endpoint returns; a bigger ?limit=
+// is capped to it.
+const MaxPageLimit = 100
+
+// PaginationParams is a validated ?page= and ?limit= pair; see
+// handler.ParsePagination.
 type PaginationParams struct {
 	Page  int `json:"page" validate:"min=1"`
 	Limit int `json:"limit" validate:"min=1,max=100"`
 }
 
 type MovieFilters struct {
-	Genre     string  `json:"genre"`
-	GenreID   *int    `json:"-"`
-	Year      int     `json:"year"`
-	MinRating float64 `json:"min_rating"`
-	Search    string  `json:"search"`
-	Sort      string  `json:"sort"`
+	Genre      string   `json:"genre"`
+	GenreID    *int     `json:"-"`
+	Year       int      `json:"year"`
+	MinRating  float64  `json:"min_rating"`
+	Search     string   `json:"search"`
+	DirectorIn []string `json:"director_in"`
+	Sort       string   `json:"sort"`
+	// Director matches a substring of the director's name, ignoring case.
+	Director string `json:"director"`
+	// NormalizedRating makes the rating sorts use NormalizedAverageRating.
+	NormalizedRating bool `json:"normalized_rating"`
 }
 
 type ReviewFilters struct {
@@ -136,32 +447,64 @@ type ReviewFilters struct {
 	Sort      string `json:"sort"`
 }
 
+// UserFilters narrows a user listing. MinReviews and MaxReviews bound the
+// number of reviews written, inclusive; nil leaves that side open, so
+// MaxReviews of 0 finds users without reviews.
 type UserFilters struct {
-	Search string `json:"search"`
-	Role   string `json:"role"`
+	Search     string `json:"search"`
+	Role       string `json:"role"`
+	MinReviews *int   `json:"min_reviews"`
+	MaxReviews *int   `json:"max_reviews"`
 }
 
 type AuditLogFilters struct {
 	Event    string     `json:"event"`
 	UserID   *int       `json:"user_id"`
+	ActorID  *int       `json:"actor_id"`
 	FromDate *time.Time `json:"from_date"`
 	ToDate   *time.Time `json:"to_date"`
+	// Search matches a substring of the event or details, ignoring case.
+	Search string `json:"search"`
+	// Sort is created_desc (the default) or created_asc.
+	Sort string `json:"sort"`
 }
 
+// AuditLogSorts are the accepted AuditLogFilters.Sort values.
+var AuditLogSorts = []string{"created_desc", "created_asc"}
+
 type UserStats struct {
 	AverageRating float64 `json:"average_rating"`
 	FavoriteGenre *Genre  `json:"favorite_genre,omitempty"`
 }
 
 type AdminStats struct {
-	TotalUsers       int     `json:"total_users"`
-	TotalMovies      int     `json:"total_movies"`
-	TotalReviews     int     `json:"total_reviews"`
-	TotalGenres      int     `json:"total_genres"`
-	AverageRating    float64 `json:"average_rating"`
-	UsersLast7Days   int     `json:"users_last_7_days"`
-	ReviewsLast7Days int     `json:"reviews_last_7_days"`
-	MoviesLast7Days  int     `json:"movies_last_7_days"`
+	TotalUsers       int       `json:"total_users"`
+	TotalMovies      int       `json:"total_movies"`
+	TotalReviews     int       `json:"total_reviews"`
+	TotalGenres      int       `json:"total_genres"`
+	AverageRating    float64   `json:"average_rating"`
+	UsersLast7Days   int       `json:"users_last_7_days"`
+	ReviewsLast7Days int       `json:"reviews_last_7_days"`
+	MoviesLast7Days  int       `json:"movies_last_7_days"`
+	GeneratedAt      time.Time `json:"generated_at"`
+}
+
+// ReviewAnalytics summarizes the health of all reviews. A review is flagged
+// when its title or content contains a blocked keyword; words are runs of
+// non-whitespace and the content length is counted in characters.
+type ReviewAnalytics struct {
+	TotalReviews       int         `json:"total_reviews"`
+	FlaggedReviews     int         `json:"flagged_reviews"`
+	FlaggedPercent     float64     `json:"flagged_percent"`
+	AvgWordCount       float64     `json:"avg_word_count"`
+	MedianRating       float64     `json:"median_rating"`
+	RatingDistribution map[int]int `json:"rating_distribution"`
+	AvgContentLength   float64     `json:"avg_content_length"`
+}
+
+type DecadeCount struct {
+	Decade int `json:"decade"`
+	Count  int `json:"count"`
 }
 
 type PaginatedResponse struct {
@@ -170,4 +513,14 @@ type PaginatedResponse struct {
 	Page       int         `json:"page"`
 	Limit      int         `json:"limit"`
 	TotalPages int         `json:"total_pages"`
+	HasNext    bool        `json:"has_next"`
+	HasPrev    bool        `json:"has_prev"`
+	// Meta carries extra, endpoint-specific data about the listing.
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+// MovieListMeta is the Meta of GET /movies. GenreCounts maps every genre ID
+// to its number of movies, for rendering a genre sidebar.
+type MovieListMeta struct {
+	GenreCounts map[int]int `json:"genre_counts"`
 }
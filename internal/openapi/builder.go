@@ -0,0 +1,350 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Access int
+
+const (
+	Public Access = iota
+	Authenticated
+	Admin
+)
+
+const bearerScheme = "bearerAuth"
+
+// Route describes one registered endpoint. Path uses gin syntax (/movies/:id).
+type Route struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Access   Access
+	Query    []Parameter
+	Request  any
+	Status   int
+	Response any
+	Errors   []int
+}
+
+// Page documents the models.PaginatedResponse envelope around items of Of,
+// with an optional meta object shaped like Meta.
+type Page struct{ Of, Meta any }
+
+// List documents a {"data": [...]} envelope around items of Of.
+type List struct{ Of any }
+
+// Object documents an ad-hoc JSON object, typically a gin.H response.
+type Object map[string]any
+
+// Optional marks a field of an Object that may be absent.
+type Optional struct{ Of any }
+
+// Upload documents a multipart/form-data request carrying one file field.
+type Upload struct{ Field string }
+
+// Query declares an optional query parameter of a primitive type.
+func Query(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+type Builder struct {
+	doc   *Document
+	types map[string]reflect.Type
+}
+
+func NewBuilder(info Info, serverURL string) *Builder {
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Servers: []Server{{URL: serverURL}},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Error": {
+					Type:       "object",
+					Properties: map[string]*Schema{"error": {Type: "string"}},
+					Required:   []string{"error"},
+				},
+			},
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	return &Builder{doc: doc, types: make(map[string]reflect.Type)}
+}
+
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+var ginParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+func (b *Builder) Add(routes ...Route) {
+	for _, r := range routes {
+		path := ginParam.ReplaceAllString(r.Path, "{$1}")
+		op := &Operation{
+			Summary:     r.Summary,
+			OperationID: operationID(r.Method, r.Path),
+			Responses:   make(map[string]Response),
+		}
+		if r.Tag != "" {
+			op.Tags = []string{r.Tag}
+		}
+		for _, m := range ginParam.FindAllStringSubmatch(r.Path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: pathParamType(m[1])}})
+		}
+		op.Parameters = append(op.Parameters, r.Query...)
+
+		switch req := r.Request.(type) {
+		case nil:
+		case Upload:
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				"multipart/form-data": {Schema: &Schema{
+					Type:       "object",
+					Properties: map[string]*Schema{req.Field: {Type: "string", Format: "binary"}},
+					Required:   []string{req.Field},
+				}},
+			}}
+		default:
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(b.valueSchema(req))}
+		}
+
+		status := r.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status)}
+		if r.Response != nil {
+			success.Content = jsonContent(b.valueSchema(r.Response))
+		}
+		op.Responses[strconv.Itoa(status)] = success
+
+		errs := append([]int{}, r.Errors...)
+		errs = append(errs, http.StatusInternalServerError)
+		if r.Access != Public {
+			op.Security = []map[string][]string{{bearerScheme: {}}}
+			errs = append(errs, http.StatusUnauthorized)
+		}
+		if r.Access == Admin {
+			errs = append(errs, http.StatusForbidden)
+		}
+		for _, code := range errs {
+			op.Responses[strconv.Itoa(code)] = Response{
+				Description: http.StatusText(code),
+				Content:     jsonContent(&Schema{Ref: "#/components/schemas/Error"}),
+			}
+		}
+
+		item := b.doc.Paths[path]
+		if item == nil {
+			item = make(PathItem)
+			b.doc.Paths[path] = item
+		}
+		item[strings.ToLower(r.Method)] = op
+	}
+}
+
+// pathParamType treats id and *_id path parameters as integers and
+// everything else as strings.
+func pathParamType(name string) string {
+	if name == "id" || strings.HasSuffix(name, "_id") {
+		return "integer"
+	}
+	return "string"
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.Split(path, "/") {
+		part = strings.NewReplacer(":", "by_", "-", "_").Replace(part)
+		if part != "" {
+			id += "_" + part
+		}
+	}
+	return id
+}
+
+func (b *Builder) valueSchema(v any) *Schema {
+	switch v := v.(type) {
+	case *Schema:
+		return v
+	case Page:
+		s := &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"data":        {Type: "array", Items: b.valueSchema(v.Of)},
+				"total":       {Type: "integer"},
+				"page":        {Type: "integer"},
+				"limit":       {Type: "integer"},
+				"total_pages": {Type: "integer"},
+				"has_next":    {Type: "boolean"},
+				"has_prev":    {Type: "boolean"},
+			},
+			Required: []string{"data", "total", "page", "limit", "total_pages", "has_next", "has_prev"},
+		}
+		if v.Meta != nil {
+			s.Properties["meta"] = b.valueSchema(v.Meta)
+		}
+		return s
+	case List:
+		return &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"data": {Type: "array", Items: b.valueSchema(v.Of)}},
+			Required:   []string{"data"},
+		}
+	case Object:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema, len(v))}
+		for name, field := range v {
+			if opt, ok := field.(Optional); ok {
+				s.Properties[name] = b.valueSchema(opt.Of)
+				continue
+			}
+			s.Properties[name] = b.valueSchema(field)
+			s.Required = append(s.Required, name)
+		}
+		sort.Strings(s.Required)
+		return s
+	}
+	return b.schemaFor(reflect.TypeOf(v))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (b *Builder) schemaFor(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if t.Kind() == reflect.Pointer {
+		s := b.schemaFor(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return b.component(t)
+	}
+	return &Schema{}
+}
+
+func (b *Builder) component(t reflect.Type) *Schema {
+	name := t.Name()
+	if existing, ok := b.types[name]; ok && existing != t {
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + name
+	}
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := b.types[name]; ok {
+		return ref
+	}
+	b.types[name] = t
+	b.doc.Components.Schemas[name] = b.structSchema(t)
+	return ref
+}
+
+func (b *Builder) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := b.structSchema(embedded)
+				for k, v := range inner.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, inner.Required...)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		field := b.schemaFor(f.Type)
+		if applyValidation(field, f.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = field
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+// applyValidation copies validator constraints onto s and reports whether
+// the field is required.
+func applyValidation(s *Schema, tag string) bool {
+	if tag == "" || s.Ref != "" {
+		return false
+	}
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			switch s.Type {
+			case "string":
+				if key == "min" {
+					s.MinLength = &n
+				} else {
+					s.MaxLength = &n
+				}
+			case "integer", "number":
+				f := float64(n)
+				if key == "min" {
+					s.Minimum = &f
+				} else {
+					s.Maximum = &f
+				}
+			}
+		}
+	}
+	return required
+}
@@ -10,17 +10,19 @@ import (
 )
 
 type AuditRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout queryTimeout
 }
 
-func NewAuditRepository(db *sql.DB) *AuditRepository {
-	return &AuditRepository{db: db}
+func NewAuditRepository(db *sql.DB, opts ...Option) *AuditRepository {
+	return &AuditRepository{db: db, timeout: newOptions(opts).queryTimeout}
 }
 
 func (r *AuditRepository) Insert(ctx context.Context, log *models.AuditLog) error {
 	var userID interface{}
 	var movieID interface{}
 	var reviewID interface{}
+	var actorID interface{}
 
 	if log.UserID != nil {
 		userID = *log.UserID
@@ -31,43 +33,56 @@ func (r *AuditRepository) Insert(ctx context.Context, log *models.AuditLog) erro
 	if log.ReviewID != nil {
 		reviewID = *log.ReviewID
 	}
+	if log.ActorID != nil {
+		actorID = *log.ActorID
+	}
 
 	return r.db.QueryRowContext(
 		ctx,
-		`INSERT INTO audit_logs (user_id, movie_id, review_id, event, details)
-		 VALUES ($1, $2, $3, $4, $5)
+		`INSERT INTO audit_logs (user_id, movie_id, review_id, actor_id, event, details)
+		 VALUES ($1, $2, $3, $4, $5, $6)
 		 RETURNING id, created_at`,
-		userID, movieID, reviewID, log.Event, log.Details,
+		userID, movieID, reviewID, actorID, log.Event, log.Details,
 	).Scan(&log.ID, &log.CreatedAt)
 }
 
-func (r *AuditRepository) List(ctx context.Context, filters models.AuditLogFilters, limit, offset int) ([]models.AuditLog, int, error) {
+// auditWhere builds the WHERE clause for filters; placeholders start at $1.
+func auditWhere(filters models.AuditLogFilters) (string, []interface{}) {
 	whereParts := []string{"1=1"}
 	args := []interface{}{}
-	argPos := 1
 
 	if filters.Event != "" {
 		args = append(args, filters.Event)
-		whereParts = append(whereParts, "event = $"+fmt.Sprintf("%d", argPos))
-		argPos++
+		whereParts = append(whereParts, fmt.Sprintf("event = $%d", len(args)))
 	}
 	if filters.UserID != nil {
 		args = append(args, *filters.UserID)
-		whereParts = append(whereParts, "user_id = $"+fmt.Sprintf("%d", argPos))
-		argPos++
+		whereParts = append(whereParts, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filters.ActorID != nil {
+		args = append(args, *filters.ActorID)
+		whereParts = append(whereParts, fmt.Sprintf("actor_id = $%d", len(args)))
 	}
 	if filters.FromDate != nil {
 		args = append(args, *filters.FromDate)
-		whereParts = append(whereParts, "created_at >= $"+fmt.Sprintf("%d", argPos))
-		argPos++
+		whereParts = append(whereParts, fmt.Sprintf("created_at >= $%d", len(args)))
 	}
 	if filters.ToDate != nil {
 		args = append(args, *filters.ToDate)
-		whereParts = append(whereParts, "created_at <= $"+fmt.Sprintf("%d", argPos))
-		argPos++
+		whereParts = append(whereParts, fmt.Sprintf("created_at <= $%d", len(args)))
 	}
+	if filters.Search != "" {
+		args = append(args, containsPattern(filters.Search))
+		whereParts = append(whereParts, fmt.Sprintf(`(details ILIKE $%d ESCAPE '\' OR event ILIKE $%d ESCAPE '\')`, len(args), len(args)))
+	}
+	return strings.Join(whereParts, " AND "), args
+}
 
-	whereSQL := strings.Join(whereParts, " AND ")
+func (r *AuditRepository) List(ctx context.Context, filters models.AuditLogFilters, limit, offset int) ([]models.AuditLog, int, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	whereSQL, args := auditWhere(filters)
 
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM audit_logs WHERE %s", whereSQL)
 	var total int
@@ -78,29 +93,57 @@ func (r *AuditRepository) List(ctx context.Context, filters models.AuditLogFilte
 	argsWithPage := append([]interface{}{}, args...)
 	argsWithPage = append(argsWithPage, limit, offset)
 
+	orderBy := "created_at DESC, id DESC"
+	if filters.Sort == "created_asc" {
+		orderBy = "created_at, id"
+	}
+
 	query := fmt.Sprintf(`
-		SELECT id, user_id, movie_id, review_id, event, details, created_at
+		SELECT id, user_id, movie_id, review_id, actor_id, event, details, created_at
 		FROM audit_logs
 		WHERE %s
-		ORDER BY created_at DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereSQL, argPos, argPos+1)
+	`, whereSQL, orderBy, len(args)+1, len(args)+2)
 
-	rows, err := r.db.QueryContext(ctx, query, argsWithPage...)
+	logs, err := r.query(ctx, query, argsWithPage)
+	return logs, total, err
+}
+
+// ListAfter returns up to limit entries matching filters with IDs above
+// afterID, in ID order, so callers can walk the whole table with a cursor.
+func (r *AuditRepository) ListAfter(ctx context.Context, filters models.AuditLogFilters, afterID, limit int) ([]models.AuditLog, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	whereSQL, args := auditWhere(filters)
+	args = append(args, afterID, limit)
+	query := fmt.Sprintf(`
+		SELECT id, user_id, movie_id, review_id, actor_id, event, details, created_at
+		FROM audit_logs
+		WHERE %s AND id > $%d
+		ORDER BY id
+		LIMIT $%d
+	`, whereSQL, len(args)-1, len(args))
+	return r.query(ctx, query, args)
+}
+
+func (r *AuditRepository) query(ctx context.Context, query string, args []interface{}) ([]models.AuditLog, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
 	var logs []models.AuditLog
 	for rows.Next() {
 		var log models.AuditLog
-		var userID, movieID, reviewID sql.NullInt64
+		var userID, movieID, reviewID, actorID sql.NullInt64
 		if err := rows.Scan(
-			&log.ID, &userID, &movieID, &reviewID,
+			&log.ID, &userID, &movieID, &reviewID, &actorID,
 			&log.Event, &log.Details, &log.CreatedAt,
 		); err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		if userID.Valid {
 			uid := int(userID.Int64)
@@ -114,7 +157,11 @@ func (r *AuditRepository) List(ctx context.Context, filters models.AuditLogFilte
 			rid := int(reviewID.Int64)
 			log.ReviewID = &rid
 		}
+		if actorID.Valid {
+			aid := int(actorID.Int64)
+			log.ActorID = &aid
+		}
 		logs = append(logs, log)
 	}
-	return logs, total, rows.Err()
+	return logs, rows.Err()
 }
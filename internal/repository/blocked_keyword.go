@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"golang-project/internal/models"
+)
+
+type BlockedKeywordRepository struct {
+	db      *sql.DB
+	timeout queryTimeout
+}
+
+func NewBlockedKeywordRepository(db *sql.DB, opts ...Option) *BlockedKeywordRepository {
+	return &BlockedKeywordRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+// List returns every blocked keyword in the order they were added.
+func (r *BlockedKeywordRepository) List(ctx context.Context) ([]models.BlockedKeyword, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id, keyword, case_sensitive, created_by, created_at FROM blocked_keywords ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keywords := []models.BlockedKeyword{}
+	for rows.Next() {
+		var k models.BlockedKeyword
+		if err := rows.Scan(&k.ID, &k.Keyword, &k.CaseSensitive, &k.CreatedBy, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		keywords = append(keywords, k)
+	}
+	return keywords, rows.Err()
+}
+
+func (r *BlockedKeywordRepository) GetByID(ctx context.Context, id int) (*models.BlockedKeyword, error) {
+	var k models.BlockedKeyword
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT id, keyword, case_sensitive, created_by, created_at FROM blocked_keywords WHERE id = $1",
+		id,
+	).Scan(&k.ID, &k.Keyword, &k.CaseSensitive, &k.CreatedBy, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *BlockedKeywordRepository) Create(ctx context.Context, k *models.BlockedKeyword) error {
+	return r.db.QueryRowContext(
+		ctx,
+		`INSERT INTO blocked_keywords (keyword, case_sensitive, created_by)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, created_at`,
+		k.Keyword, k.CaseSensitive, k.CreatedBy,
+	).Scan(&k.ID, &k.CreatedAt)
+}
+
+// Update changes the keyword and its case sensitivity. It returns
+// sql.ErrNoRows if the keyword does not exist.
+func (r *BlockedKeywordRepository) Update(ctx context.Context, k *models.BlockedKeyword) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		"UPDATE blocked_keywords SET keyword = $1, case_sensitive = $2 WHERE id = $3",
+		k.Keyword, k.CaseSensitive, k.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete returns sql.ErrNoRows if the keyword does not exist.
+func (r *BlockedKeywordRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM blocked_keywords WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
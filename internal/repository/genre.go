@@ -3,25 +3,27 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"golang-project/internal/models"
 )
 
 type GenreRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout queryTimeout
 }
 
-func NewGenreRepository(db *sql.DB) *GenreRepository {
-	return &GenreRepository{db: db}
+func NewGenreRepository(db *sql.DB, opts ...Option) *GenreRepository {
+	return &GenreRepository{db: db, timeout: newOptions(opts).queryTimeout}
 }
 
 func (r *GenreRepository) GetByID(ctx context.Context, id int) (*models.Genre, error) {
 	var genre models.Genre
 	err := r.db.QueryRowContext(
 		ctx,
-		"SELECT id, name, created_at FROM genres WHERE id = $1",
+		"SELECT id, name, parent_id, created_at FROM genres WHERE id = $1",
 		id,
-	).Scan(&genre.ID, &genre.Name, &genre.CreatedAt)
+	).Scan(&genre.ID, &genre.Name, &genre.ParentID, &genre.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -32,9 +34,9 @@ func (r *GenreRepository) GetByName(ctx context.Context, name string) (*models.G
 	var genre models.Genre
 	err := r.db.QueryRowContext(
 		ctx,
-		"SELECT id, name, created_at FROM genres WHERE name = $1",
+		"SELECT id, name, parent_id, created_at FROM genres WHERE name = $1",
 		name,
-	).Scan(&genre.ID, &genre.Name, &genre.CreatedAt)
+	).Scan(&genre.ID, &genre.Name, &genre.ParentID, &genre.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -64,10 +66,46 @@ func (r *GenreRepository) Delete(ctx context.Context, id int) error {
 	return err
 }
 
+// ImportGenres creates the genres that do not exist yet and points each new
+// one with a Parent at that genre, all in one transaction. Genres that
+// already exist are left untouched. Every parent must exist once the genres
+// are created.
+func (r *GenreRepository) ImportGenres(ctx context.Context, genres []models.BulkGenreInput) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	created := make(map[string]bool, len(genres))
+	for _, g := range genres {
+		var id int
+		err := tx.QueryRowContext(ctx, "INSERT INTO genres (name) VALUES ($1) ON CONFLICT (name) DO NOTHING RETURNING id", g.Name).Scan(&id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		created[g.Name] = err == nil
+	}
+	for _, g := range genres {
+		if g.Parent == "" || !created[g.Name] {
+			continue
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			"UPDATE genres SET parent_id = (SELECT id FROM genres WHERE name = $2) WHERE name = $1",
+			g.Name, g.Parent,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (r *GenreRepository) GetAll(ctx context.Context) ([]models.Genre, error) {
 	rows, err := r.db.QueryContext(
 		ctx,
-		"SELECT id, name, created_at FROM genres ORDER BY name",
+		"SELECT id, name, parent_id, created_at FROM genres ORDER BY name",
 	)
 	if err != nil {
 		return nil, err
@@ -77,7 +115,7 @@ func (r *GenreRepository) GetAll(ctx context.Context) ([]models.Genre, error) {
 	var genres []models.Genre
 	for rows.Next() {
 		var genre models.Genre
-		if err := rows.Scan(&genre.ID, &genre.Name, &genre.CreatedAt); err != nil {
+		if err := rows.Scan(&genre.ID, &genre.Name, &genre.ParentID, &genre.CreatedAt); err != nil {
 			return nil, err
 		}
 		genres = append(genres, genre)
@@ -85,8 +123,51 @@ func (r *GenreRepository) GetAll(ctx context.Context) ([]models.Genre, error) {
 	return genres, rows.Err()
 }
 
+// Stats returns the number of movies in the genre and the number of reviews across them.
+func (r *GenreRepository) Stats(ctx context.Context, id int) (*models.GenreStats, error) {
+	var stats models.GenreStats
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(DISTINCT mg.movie_id), COUNT(rv.id)
+		 FROM movie_genres mg
+		 LEFT JOIN reviews rv ON rv.movie_id = mg.movie_id
+		 WHERE mg.genre_id = $1`,
+		id,
+	).Scan(&stats.MovieCount, &stats.ReviewCount)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 func (r *GenreRepository) Count(ctx context.Context) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM genres").Scan(&count)
 	return count, err
 }
+
+// Search returns up to limit genres whose name contains query, case-insensitively.
+func (r *GenreRepository) Search(ctx context.Context, query string, limit int) ([]models.Genre, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, name, parent_id, created_at FROM genres WHERE LOWER(name) LIKE LOWER($1) ESCAPE '\' ORDER BY name LIMIT $2`,
+		containsPattern(query), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := []models.Genre{}
+	for rows.Next() {
+		var genre models.Genre
+		if err := rows.Scan(&genre.ID, &genre.Name, &genre.ParentID, &genre.CreatedAt); err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+	return genres, rows.Err()
+}
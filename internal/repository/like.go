@@ -0,0 +1,11 @@
+package repository
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns a LIKE pattern matching s anywhere in a value.
+// Wildcards in s match literally; queries using it must say ESCAPE '\'.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
@@ -4,32 +4,41 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"golang-project/internal/models"
 )
 
+// publishedSQL keeps pending movies out of public listings; it expects the
+// movies table aliased as m.
+const publishedSQL = "m.status <> 'pending'"
+
 type MovieRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout queryTimeout
 }
 
-func NewMovieRepository(db *sql.DB) *MovieRepository {
-	return &MovieRepository{db: db}
+func NewMovieRepository(db *sql.DB, opts ...Option) *MovieRepository {
+	return &MovieRepository{db: db, timeout: newOptions(opts).queryTimeout}
 }
 
 func (r *MovieRepository) GetByID(ctx context.Context, id int) (*models.Movie, error) {
 	var movie models.Movie
 	err := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, title, description, release_year, director, duration_minutes, 
-		 average_rating, created_at, updated_at 
+		`SELECT id, title, description, release_year, director, duration_minutes, poster_url,
+		 average_rating, normalized_average_rating, review_count, review_embargo_until, COALESCE(submitted_by_user_id, 0), featured, status, published_at, created_at, updated_at, reviews_updated_at
 		 FROM movies WHERE id = $1`,
 		id,
 	).Scan(
 		&movie.ID, &movie.Title, &movie.Description, &movie.ReleaseYear,
-		&movie.Director, &movie.DurationMinutes, &movie.AverageRating,
-		&movie.CreatedAt, &movie.UpdatedAt,
+		&movie.Director, &movie.DurationMinutes, &movie.PosterURL, &movie.AverageRating, &movie.NormalizedAverageRating, &movie.ReviewCount,
+		&movie.ReviewEmbargoUntil, &movie.SubmittedByUserID, &movie.Featured, &movie.Status, &movie.PublishedAt, &movie.CreatedAt, &movie.UpdatedAt, &movie.ReviewsUpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -40,34 +49,159 @@ func (r *MovieRepository) GetByID(ctx context.Context, id int) (*models.Movie, e
 func (r *MovieRepository) Create(ctx context.Context, movie *models.Movie) error {
 	return r.db.QueryRowContext(
 		ctx,
-		`INSERT INTO movies (title, description, release_year, director, duration_minutes)
-		 VALUES ($1, $2, $3, $4, $5)
-		 RETURNING id, created_at, updated_at`,
+		`INSERT INTO movies (title, description, release_year, director, duration_minutes, review_embargo_until, submitted_by_user_id, status, published_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), COALESCE(NULLIF($8, ''), 'published'), $9)
+		 RETURNING id, status, created_at, updated_at`,
 		movie.Title, movie.Description, movie.ReleaseYear,
-		movie.Director, movie.DurationMinutes,
-	).Scan(&movie.ID, &movie.CreatedAt, &movie.UpdatedAt)
+		movie.Director, movie.DurationMinutes, movie.ReviewEmbargoUntil, movie.SubmittedByUserID, movie.Status, movie.PublishedAt,
+	).Scan(&movie.ID, &movie.Status, &movie.CreatedAt, &movie.UpdatedAt)
 }
 
-func (r *MovieRepository) Update(ctx context.Context, movie *models.Movie) error {
-	_, err := r.db.ExecContext(
-		ctx,
-		`UPDATE movies 
+// updateMovieSQL writes the editable columns of a movie; updateMovieArgs
+// supplies its parameters.
+const updateMovieSQL = `UPDATE movies 
 		 SET title = $1, description = $2, release_year = $3, 
-		     director = $4, duration_minutes = $5, updated_at = NOW()
-		 WHERE id = $6`,
+		     director = $4, duration_minutes = $5, review_embargo_until = $6, updated_at = NOW()
+		 WHERE id = $7`
+
+func updateMovieArgs(movie *models.Movie) []interface{} {
+	return []interface{}{
 		movie.Title, movie.Description, movie.ReleaseYear,
-		movie.Director, movie.DurationMinutes, movie.ID,
-	)
+		movie.Director, movie.DurationMinutes, movie.ReviewEmbargoUntil, movie.ID,
+	}
+}
+
+func (r *MovieRepository) Update(ctx context.Context, movie *models.Movie) error {
+	_, err := r.db.ExecContext(ctx, updateMovieSQL, updateMovieArgs(movie)...)
 	return err
 }
 
+func (r *MovieRepository) UpdatePosterURL(ctx context.Context, id int, posterURL string) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE movies SET poster_url = $1, updated_at = NOW() WHERE id = $2", posterURL, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *MovieRepository) SetFeatured(ctx context.Context, id int, featured bool) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE movies SET featured = $1, updated_at = NOW() WHERE id = $2", featured, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListDueForPublication returns the IDs of pending movies whose
+// published_at is not after now, oldest first.
+func (r *MovieRepository) ListDueForPublication(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id FROM movies
+		 WHERE status = 'pending' AND published_at <= $1
+		 ORDER BY published_at, id
+		 LIMIT $2`,
+		now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Publish marks a pending movie published and reports whether it was still
+// pending. The status check happens in the UPDATE itself, so when several
+// replicas race to publish the same movie exactly one of them gets true.
+func (r *MovieRepository) Publish(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		"UPDATE movies SET status = 'published', updated_at = NOW() WHERE id = $1 AND status = 'pending'",
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Delete removes the movie and leaves a tombstone in deleted_movies so
+// IsDeleted can tell it apart from an ID that never existed.
 func (r *MovieRepository) Delete(ctx context.Context, id int) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM movies WHERE id = $1", id)
-	return err
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM movies WHERE id = $1", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO deleted_movies (movie_id) VALUES ($1) ON CONFLICT DO NOTHING", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CountByGenre returns the number of published movies in each of
+// genreIDs. Genres without movies are missing from the map.
+func (r *MovieRepository) CountByGenre(ctx context.Context, genreIDs []int) (map[int]int, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT mg.genre_id, COUNT(*)
+		 FROM movie_genres mg
+		 JOIN movies m ON m.id = mg.movie_id
+		 WHERE mg.genre_id = ANY($1) AND `+publishedSQL+`
+		 GROUP BY mg.genre_id`,
+		pq.Array(genreIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var genreID, n int
+		if err := rows.Scan(&genreID, &n); err != nil {
+			return nil, err
+		}
+		counts[genreID] = n
+	}
+	return counts, rows.Err()
+}
+
+// IsDeleted reports whether a movie with id existed and was deleted.
+func (r *MovieRepository) IsDeleted(ctx context.Context, id int) (bool, error) {
+	var deleted bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM deleted_movies WHERE movie_id = $1)", id).Scan(&deleted)
+	return deleted, err
 }
 
 func (r *MovieRepository) List(ctx context.Context, filters models.MovieFilters, limit, offset int) ([]models.Movie, int, error) {
-	whereParts := []string{"1=1"}
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	whereParts := []string{publishedSQL}
 	args := []interface{}{}
 
 	if filters.GenreID != nil {
@@ -75,8 +209,8 @@ func (r *MovieRepository) List(ctx context.Context, filters models.MovieFilters,
 		whereParts = append(whereParts, fmt.Sprintf("EXISTS (SELECT 1 FROM movie_genres mg WHERE mg.movie_id = m.id AND mg.genre_id = $%d)", len(args)))
 	}
 	if filters.Genre != "" {
-		args = append(args, "%"+filters.Genre+"%")
-		whereParts = append(whereParts, fmt.Sprintf("EXISTS (SELECT 1 FROM genres g INNER JOIN movie_genres mg ON g.id = mg.genre_id WHERE mg.movie_id = m.id AND LOWER(g.name) LIKE LOWER($%d))", len(args)))
+		args = append(args, containsPattern(filters.Genre))
+		whereParts = append(whereParts, fmt.Sprintf(`EXISTS (SELECT 1 FROM genres g INNER JOIN movie_genres mg ON g.id = mg.genre_id WHERE mg.movie_id = m.id AND LOWER(g.name) LIKE LOWER($%d) ESCAPE '\')`, len(args)))
 	}
 	if filters.Year != 0 {
 		args = append(args, filters.Year)
@@ -87,8 +221,16 @@ func (r *MovieRepository) List(ctx context.Context, filters models.MovieFilters,
 		whereParts = append(whereParts, fmt.Sprintf("m.average_rating >= $%d", len(args)))
 	}
 	if filters.Search != "" {
-		args = append(args, "%"+filters.Search+"%")
-		whereParts = append(whereParts, fmt.Sprintf("(LOWER(m.title) LIKE LOWER($%d) OR LOWER(m.description) LIKE LOWER($%d))", len(args), len(args)))
+		args = append(args, containsPattern(filters.Search))
+		whereParts = append(whereParts, fmt.Sprintf(`(LOWER(m.title) LIKE LOWER($%d) ESCAPE '\' OR LOWER(m.description) LIKE LOWER($%d) ESCAPE '\')`, len(args), len(args)))
+	}
+	if len(filters.DirectorIn) > 0 {
+		args = append(args, pq.Array(filters.DirectorIn))
+		whereParts = append(whereParts, fmt.Sprintf("m.director = ANY($%d)", len(args)))
+	}
+	if filters.Director != "" {
+		args = append(args, containsPattern(filters.Director))
+		whereParts = append(whereParts, fmt.Sprintf(`m.director ILIKE $%d ESCAPE '\'`, len(args)))
 	}
 
 	whereSQL := strings.Join(whereParts, " AND ")
@@ -99,12 +241,16 @@ func (r *MovieRepository) List(ctx context.Context, filters models.MovieFilters,
 		return nil, 0, err
 	}
 
+	ratingColumn := "m.average_rating"
+	if filters.NormalizedRating {
+		ratingColumn = "m.normalized_average_rating"
+	}
 	orderBy := "m.created_at DESC"
 	switch filters.Sort {
 	case "rating_desc":
-		orderBy = "m.average_rating DESC NULLS LAST"
+		orderBy = ratingColumn + " DESC NULLS LAST"
 	case "rating_asc":
-		orderBy = "m.average_rating ASC NULLS LAST"
+		orderBy = ratingColumn + " ASC NULLS LAST"
 	case "year_desc":
 		orderBy = "m.release_year DESC"
 	case "year_asc":
@@ -115,12 +261,58 @@ func (r *MovieRepository) List(ctx context.Context, filters models.MovieFilters,
 		orderBy = "m.title DESC"
 	}
 
+	movies, err := r.queryMovies(ctx, whereSQL, orderBy, args, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return movies, total, nil
+}
+
+// ListByDirector returns a director's films, oldest first. The director is
+// matched case-insensitively, which movies_director_lower_idx serves.
+func (r *MovieRepository) ListByDirector(ctx context.Context, director string, limit, offset int) ([]models.Movie, int, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	const whereSQL = "LOWER(m.director) = LOWER($1) AND " + publishedSQL
+	args := []interface{}{director}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM movies m WHERE `+whereSQL, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	movies, err := r.queryMovies(ctx, whereSQL, "m.release_year ASC, m.id ASC", args, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return movies, total, nil
+}
+
+// ListFeatured returns up to limit featured movies, with genres, most
+// recently updated first.
+func (r *MovieRepository) ListFeatured(ctx context.Context, limit int) ([]models.Movie, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+	return r.queryMovies(ctx, "m.featured AND "+publishedSQL, "m.updated_at DESC, m.id DESC", nil, limit, 0)
+}
+
+// GetByIDs loads the movies with the given IDs, with genres, in no particular order.
+func (r *MovieRepository) GetByIDs(ctx context.Context, ids []int) ([]models.Movie, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+	return r.queryMovies(ctx, "m.id = ANY($1) AND "+publishedSQL, "m.id", []interface{}{pq.Array(ids)}, len(ids), 0)
+}
+
+// queryMovies loads one page of movies with their genres. whereSQL refers to
+// args as $1..$n; the LIMIT and OFFSET placeholders follow them.
+func (r *MovieRepository) queryMovies(ctx context.Context, whereSQL, orderBy string, args []interface{}, limit, offset int) ([]models.Movie, error) {
 	argsWithPage := append([]interface{}{}, args...)
 	argsWithPage = append(argsWithPage, limit, offset)
 
 	query := fmt.Sprintf(`
-		SELECT m.id, m.title, m.description, m.release_year, m.director, m.duration_minutes,
-		       m.average_rating, m.created_at, m.updated_at,
+		SELECT m.id, m.title, m.description, m.release_year, m.director, m.duration_minutes, m.poster_url,
+		       m.average_rating, m.normalized_average_rating, m.review_count, m.review_embargo_until, COALESCE(m.submitted_by_user_id, 0), m.featured, m.status, m.published_at, m.created_at, m.updated_at,
 		       COALESCE(json_agg(json_build_object('id', g.id, 'name', g.name, 'created_at', g.created_at)) FILTER (WHERE g.id IS NOT NULL), '[]') AS genres
 		FROM movies m
 		LEFT JOIN movie_genres mg ON mg.movie_id = m.id
@@ -133,7 +325,7 @@ func (r *MovieRepository) List(ctx context.Context, filters models.MovieFilters,
 
 	rows, err := r.db.QueryContext(ctx, query, argsWithPage...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -143,21 +335,17 @@ func (r *MovieRepository) List(ctx context.Context, filters models.MovieFilters,
 		var genresJSON []byte
 		if err := rows.Scan(
 			&movie.ID, &movie.Title, &movie.Description, &movie.ReleaseYear,
-			&movie.Director, &movie.DurationMinutes, &movie.AverageRating,
-			&movie.CreatedAt, &movie.UpdatedAt, &genresJSON,
+			&movie.Director, &movie.DurationMinutes, &movie.PosterURL, &movie.AverageRating, &movie.NormalizedAverageRating, &movie.ReviewCount,
+			&movie.ReviewEmbargoUntil, &movie.SubmittedByUserID, &movie.Featured, &movie.Status, &movie.PublishedAt, &movie.CreatedAt, &movie.UpdatedAt, &genresJSON,
 		); err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		if err := json.Unmarshal(genresJSON, &movie.Genres); err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		movies = append(movies, movie)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, 0, err
-	}
-
-	return movies, total, nil
+	return movies, rows.Err()
 }
 
 func (r *MovieRepository) SetGenres(ctx context.Context, movieID int, genreIDs []int) error {
@@ -184,8 +372,8 @@ func (r *MovieRepository) UpdateAverageRating(ctx context.Context, movieID int)
 	_, err := r.db.ExecContext(
 		ctx,
 		`UPDATE movies 
-		 SET average_rating = (
-			 SELECT COALESCE(AVG(rating), 0)
+		 SET (average_rating, review_count) = (
+			 SELECT COALESCE(AVG(rating), 0), COUNT(*)
 			 FROM reviews
 			 WHERE movie_id = $1
 		 )
@@ -195,6 +383,102 @@ func (r *MovieRepository) UpdateAverageRating(ctx context.Context, movieID int)
 	return err
 }
 
+// normalizedRatingSQL is the z-score of a review against its author's
+// other ratings, rescaled to 5 + z*2. Reviewers with a single rating or
+// no spread have no scale to compare against and count as 5.
+const normalizedRatingSQL = `CASE WHEN rv.rating IS NULL THEN NULL
+	WHEN s.sd IS NULL OR s.sd = 0 THEN 5
+	ELSE 5 + (rv.rating - s.mean) / s.sd * 2 END`
+
+// UpdateNormalizedRating sets normalized_average_rating to the average of
+// the movie's reviews after normalizing each one by its reviewer's mean and
+// standard deviation.
+func (r *MovieRepository) UpdateNormalizedRating(ctx context.Context, movieID int) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE movies
+		 SET normalized_average_rating = (
+			 SELECT COALESCE(AVG(`+normalizedRatingSQL+`), 0)
+			 FROM reviews rv
+			 JOIN (
+				 SELECT user_id, AVG(rating) AS mean, STDDEV_SAMP(rating) AS sd
+				 FROM reviews
+				 WHERE user_id IN (SELECT user_id FROM reviews WHERE movie_id = $1)
+				 GROUP BY user_id
+			 ) s ON s.user_id = rv.user_id
+			 WHERE rv.movie_id = $1
+		 )
+		 WHERE id = $1`,
+		movieID,
+	)
+	return err
+}
+
+func (r *MovieRepository) ListIDsAfter(ctx context.Context, afterID, limit int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id FROM movies WHERE id > $1 ORDER BY id LIMIT $2", afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RecalculateAverageRatings recomputes the averages and review_count of
+// movieIDs from their reviews, repairing any drift in the counts the review
+// worker keeps.
+func (r *MovieRepository) RecalculateAverageRatings(ctx context.Context, movieIDs []int) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE movies m
+		 SET average_rating = agg.avg_rating, review_count = agg.review_count
+		 FROM (
+			 SELECT mv.id, COALESCE(AVG(rv.rating), 0) AS avg_rating, COUNT(rv.id) AS review_count
+			 FROM movies mv
+			 LEFT JOIN reviews rv ON rv.movie_id = mv.id
+			 WHERE mv.id = ANY($1)
+			 GROUP BY mv.id
+		 ) agg
+		 WHERE m.id = agg.id`,
+		pq.Array(movieIDs),
+	)
+	if err != nil {
+		return err
+	}
+
+	// A new review shifts its author's mean and spread, which changes the
+	// normalized score of every other movie they reviewed, so the batch
+	// job refreshes normalized ratings as well.
+	_, err = r.db.ExecContext(
+		ctx,
+		`UPDATE movies m
+		 SET normalized_average_rating = agg.norm_rating
+		 FROM (
+			 SELECT mv.id, COALESCE(AVG(`+normalizedRatingSQL+`), 0) AS norm_rating
+			 FROM movies mv
+			 LEFT JOIN reviews rv ON rv.movie_id = mv.id
+			 LEFT JOIN (
+				 SELECT user_id, AVG(rating) AS mean, STDDEV_SAMP(rating) AS sd
+				 FROM reviews
+				 GROUP BY user_id
+			 ) s ON s.user_id = rv.user_id
+			 WHERE mv.id = ANY($1)
+			 GROUP BY mv.id
+		 ) agg
+		 WHERE m.id = agg.id`,
+		pq.Array(movieIDs),
+	)
+	return err
+}
+
 func (r *MovieRepository) GetGenresByMovieID(ctx context.Context, movieID int) ([]models.Genre, error) {
 	rows, err := r.db.QueryContext(
 		ctx,
@@ -224,6 +508,35 @@ func (r *MovieRepository) GetGenresByMovieID(ctx context.Context, movieID int) (
 	return genres, nil
 }
 
+// GetGenresByMovieIDs returns the genres of each movie, keyed by movie ID.
+// Movies without genres are absent from the map.
+func (r *MovieRepository) GetGenresByMovieIDs(ctx context.Context, movieIDs []int) (map[int][]models.Genre, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT mg.movie_id, g.id, g.name, g.created_at
+		 FROM genres g
+		 INNER JOIN movie_genres mg ON g.id = mg.genre_id
+		 WHERE mg.movie_id = ANY($1)
+		 ORDER BY g.name`,
+		pq.Array(movieIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := make(map[int][]models.Genre)
+	for rows.Next() {
+		var movieID int
+		var genre models.Genre
+		if err := rows.Scan(&movieID, &genre.ID, &genre.Name, &genre.CreatedAt); err != nil {
+			return nil, err
+		}
+		genres[movieID] = append(genres[movieID], genre)
+	}
+	return genres, rows.Err()
+}
+
 func (r *MovieRepository) Count(ctx context.Context) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM movies").Scan(&count)
@@ -247,3 +560,326 @@ func (r *MovieRepository) CountLast7Days(ctx context.Context) (int, error) {
 	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM movies WHERE created_at >= NOW() - INTERVAL '7 days'").Scan(&count)
 	return count, err
 }
+
+func (r *MovieRepository) CountByDecade(ctx context.Context) ([]models.DecadeCount, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT (FLOOR(release_year / 10) * 10)::int AS decade, COUNT(*)
+		 FROM movies
+		 GROUP BY decade
+		 ORDER BY decade`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []models.DecadeCount
+	for rows.Next() {
+		var dc models.DecadeCount
+		if err := rows.Scan(&dc.Decade, &dc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, dc)
+	}
+	return counts, rows.Err()
+}
+
+func (r *MovieRepository) GetMostControversialMovies(ctx context.Context, limit int) ([]models.Movie, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT m.id, m.title, m.description, m.release_year, m.director, m.duration_minutes, m.poster_url,
+		        m.average_rating, m.normalized_average_rating, m.review_count, m.featured, m.created_at, m.updated_at
+		 FROM movies m
+		 INNER JOIN reviews rv ON rv.movie_id = m.id
+		 WHERE rv.sentiment_score IS NOT NULL AND `+publishedSQL+`
+		 GROUP BY m.id
+		 HAVING COUNT(rv.id) >= 2
+		 ORDER BY STDDEV_POP(rv.sentiment_score) DESC, m.id
+		 LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movies []models.Movie
+	for rows.Next() {
+		var movie models.Movie
+		if err := rows.Scan(
+			&movie.ID, &movie.Title, &movie.Description, &movie.ReleaseYear,
+			&movie.Director, &movie.DurationMinutes, &movie.PosterURL, &movie.AverageRating, &movie.NormalizedAverageRating, &movie.ReviewCount,
+			&movie.Featured, &movie.CreatedAt, &movie.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		movies = append(movies, movie)
+	}
+	return movies, rows.Err()
+}
+
+// GetDirectorCoOccurrences ranks other directors by how many users reviewed
+// both their movies and a movie by director (matched case-insensitively).
+func (r *MovieRepository) GetDirectorCoOccurrences(ctx context.Context, director string, limit int) ([]models.SimilarDirector, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT m2.director, COUNT(DISTINCT r1.user_id)
+		 FROM reviews r1
+		 INNER JOIN movies m1 ON m1.id = r1.movie_id
+		 INNER JOIN reviews r2 ON r2.user_id = r1.user_id
+		 INNER JOIN movies m2 ON m2.id = r2.movie_id
+		 WHERE LOWER(m1.director) = LOWER($1) AND LOWER(m2.director) != LOWER($1) AND m2.director != ''
+		   AND m1.status <> 'pending' AND m2.status <> 'pending'
+		 GROUP BY m2.director
+		 ORDER BY COUNT(DISTINCT r1.user_id) DESC, m2.director
+		 LIMIT $2`,
+		director, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	directors := []models.SimilarDirector{}
+	for rows.Next() {
+		var d models.SimilarDirector
+		if err := rows.Scan(&d.Name, &d.CoReviewerCount); err != nil {
+			return nil, err
+		}
+		directors = append(directors, d)
+	}
+	return directors, rows.Err()
+}
+
+// ListDirectors returns the distinct directors of published movies whose
+// name contains query (ignoring case; "" matches all), with their movie
+// counts, most prolific first.
+func (r *MovieRepository) ListDirectors(ctx context.Context, query string, limit int) ([]models.DirectorCount, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT m.director, COUNT(*)
+		 FROM movies m
+		 WHERE `+publishedSQL+` AND m.director <> '' AND m.director ILIKE $1 ESCAPE '\'
+		 GROUP BY m.director
+		 ORDER BY COUNT(*) DESC, m.director
+		 LIMIT $2`,
+		containsPattern(query), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	directors := []models.DirectorCount{}
+	for rows.Next() {
+		var d models.DirectorCount
+		if err := rows.Scan(&d.Name, &d.MovieCount); err != nil {
+			return nil, err
+		}
+		directors = append(directors, d)
+	}
+	return directors, rows.Err()
+}
+
+// GetDirectorStats counts and averages director's published movies (matched
+// case-insensitively) and picks the best-rated one, ties going to the older
+// movie. A director without movies gets zero stats and no best movie.
+func (r *MovieRepository) GetDirectorStats(ctx context.Context, director string) (*models.DirectorStats, error) {
+	var stats models.DirectorStats
+	var best models.Movie
+	err := r.db.QueryRowContext(
+		ctx,
+		`WITH director_movies AS (
+			SELECT m.id, m.title, m.description, m.release_year, m.director, m.duration_minutes, m.poster_url,
+			       m.average_rating, m.normalized_average_rating, m.review_count, m.featured, m.created_at, m.updated_at
+			FROM movies m
+			WHERE LOWER(m.director) = LOWER($1) AND `+publishedSQL+`
+		), ranked AS (
+			SELECT dm.*,
+			       COUNT(*) OVER () AS movie_count,
+			       AVG(dm.average_rating) OVER () AS director_average,
+			       ROW_NUMBER() OVER (ORDER BY dm.average_rating DESC, dm.release_year, dm.id) AS position
+			FROM director_movies dm
+		)
+		SELECT movie_count, director_average,
+		       id, title, description, release_year, director, duration_minutes, poster_url,
+		       average_rating, normalized_average_rating, review_count, featured, created_at, updated_at
+		FROM ranked
+		WHERE position = 1`,
+		director,
+	).Scan(
+		&stats.MovieCount, &stats.AverageRating,
+		&best.ID, &best.Title, &best.Description, &best.ReleaseYear,
+		&best.Director, &best.DurationMinutes, &best.PosterURL, &best.AverageRating, &best.NormalizedAverageRating, &best.ReviewCount,
+		&best.Featured, &best.CreatedAt, &best.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	stats.BestMovie = &best
+	return &stats, nil
+}
+
+// Year summaries name a best movie only among those with at least
+// yearBestMinReviews reviews, and list yearTopReviewers reviewers.
+const (
+	yearBestMinReviews = 5
+	yearTopReviewers   = 3
+)
+
+// GetYearSummary sums up the published movies released in year and their
+// reviews. A year without movies gets a zero summary.
+func (r *MovieRepository) GetYearSummary(ctx context.Context, year int) (*models.YearSummary, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	summary := models.YearSummary{Year: year}
+	var bestID, mostReviewedID sql.NullInt64
+	var reviewersJSON []byte
+	err := r.db.QueryRowContext(
+		ctx,
+		`WITH year_movies AS (
+			SELECT m.id FROM movies m WHERE m.release_year = $1 AND `+publishedSQL+`
+		), year_reviews AS (
+			SELECT r.movie_id, r.user_id, r.rating
+			FROM reviews r
+			JOIN year_movies ym ON ym.id = r.movie_id
+		), movie_stats AS (
+			SELECT movie_id, COUNT(*) AS review_count, AVG(rating) AS avg_rating
+			FROM year_reviews
+			GROUP BY movie_id
+		), top_reviewers AS (
+			SELECT u.id, u.username, COUNT(*) AS review_count
+			FROM year_reviews yr
+			JOIN users u ON u.id = yr.user_id AND u.deleted_at IS NULL
+			GROUP BY u.id, u.username
+			ORDER BY review_count DESC, u.id
+			LIMIT $3
+		)
+		SELECT (SELECT COUNT(*) FROM year_movies),
+		       (SELECT COUNT(*) FROM year_reviews),
+		       COALESCE((SELECT AVG(avg_rating) FROM movie_stats), 0),
+		       (SELECT movie_id FROM movie_stats WHERE review_count >= $2
+		        ORDER BY avg_rating DESC, review_count DESC, movie_id LIMIT 1),
+		       (SELECT movie_id FROM movie_stats ORDER BY review_count DESC, avg_rating DESC, movie_id LIMIT 1),
+		       COALESCE((SELECT json_agg(json_build_object('id', id, 'username', username, 'review_count', review_count)
+		                 ORDER BY review_count DESC, id) FROM top_reviewers), '[]')`,
+		year, yearBestMinReviews, yearTopReviewers,
+	).Scan(&summary.TotalMovies, &summary.TotalReviews, &summary.AverageRating, &bestID, &mostReviewedID, &reviewersJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(reviewersJSON, &summary.TopReviewers); err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, id := range []sql.NullInt64{bestID, mostReviewedID} {
+		if id.Valid {
+			ids = append(ids, int(id.Int64))
+		}
+	}
+	if len(ids) == 0 {
+		return &summary, nil
+	}
+	movies, err := r.queryMovies(ctx, "m.id = ANY($1)", "m.id", []interface{}{pq.Array(ids)}, len(ids), 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range movies {
+		if int64(movies[i].ID) == bestID.Int64 {
+			summary.BestMovie = &movies[i]
+		}
+		if int64(movies[i].ID) == mostReviewedID.Int64 {
+			summary.MostReviewedMovie = &movies[i]
+		}
+	}
+	return &summary, nil
+}
+
+// ListTopRated returns the best-rated published movies userID has not
+// reviewed, ties going to the movie with more reviews.
+func (r *MovieRepository) ListTopRated(ctx context.Context, userID, limit int) ([]models.Movie, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	whereSQL := publishedSQL + " AND m.average_rating > 0 AND NOT EXISTS (SELECT 1 FROM reviews rv WHERE rv.movie_id = m.id AND rv.user_id = $1)"
+	return r.queryMovies(ctx, whereSQL, "m.average_rating DESC, m.review_count DESC, m.id", []interface{}{userID}, limit, 0)
+}
+
+// ListSitemapMovies returns every movie's ID, title and last update, without
+// the joins a full movie listing needs.
+func (r *MovieRepository) ListSitemapMovies(ctx context.Context) ([]models.SitemapMovie, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, title, updated_at FROM movies m WHERE `+publishedSQL+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movies []models.SitemapMovie
+	for rows.Next() {
+		var m models.SitemapMovie
+		if err := rows.Scan(&m.ID, &m.Title, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		movies = append(movies, m)
+	}
+	return movies, rows.Err()
+}
+
+// ExistsByTitleYear compares titles case-insensitively with whitespace collapsed;
+// title is expected to be normalized the same way.
+func (r *MovieRepository) ExistsByTitleYear(ctx context.Context, title string, year int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT EXISTS(
+			 SELECT 1 FROM movies
+			 WHERE LOWER(REGEXP_REPLACE(TRIM(title), '\s+', ' ', 'g')) = $1 AND release_year = $2
+		 )`,
+		title, year,
+	).Scan(&exists)
+	return exists, err
+}
+
+// Search returns up to limit movies whose title, director or description
+// contains query, case-insensitively. Title matches come first.
+func (r *MovieRepository) Search(ctx context.Context, query string, limit int) ([]models.Movie, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, title, release_year, director
+		 FROM movies m
+		 WHERE (LOWER(title) LIKE LOWER($1) ESCAPE '\' OR LOWER(director) LIKE LOWER($1) ESCAPE '\' OR LOWER(description) LIKE LOWER($1) ESCAPE '\')
+		   AND `+publishedSQL+`
+		 ORDER BY LOWER(title) LIKE LOWER($1) ESCAPE '\' DESC, average_rating DESC, id
+		 LIMIT $2`,
+		containsPattern(query), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movies := []models.Movie{}
+	for rows.Next() {
+		var m models.Movie
+		if err := rows.Scan(&m.ID, &m.Title, &m.ReleaseYear, &m.Director); err != nil {
+			return nil, err
+		}
+		movies = append(movies, m)
+	}
+	return movies, rows.Err()
+}
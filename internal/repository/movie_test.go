@@ -3,519 +3,220 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
-	"time"
 
+	"golang-project/internal/config"
+	"golang-project/internal/database"
 	"golang-project/internal/models"
 )
 
-// MockMovieRepository implements MovieRepository for testing
-type MockMovieRepository struct {
-	movies   map[int]*models.Movie
-	genres   map[int][]int // movie_id -> []genre_id
-	nextID   int
-	nextUser int
+// recordingDriver is a database/sql driver that keeps every query it is
+// given, with its arguments as the driver received them. COUNT queries
+// answer 0 and everything else answers no rows.
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries []recordedQuery
 }
 
-func NewMockMovieRepository() *MockMovieRepository {
-	return &MockMovieRepository{
-		movies: make(map[int]*models.Movie),
-		genres: make(map[int][]int),
-		nextID: 1,
-	}
-}
-
-func (r *MockMovieRepository) GetByID(ctx context.Context, id int) (*models.Movie, error) {
-	if movie, exists := r.movies[id]; exists {
-		// Add genres to the movie
-		if genreIDs, exists := r.genres[id]; exists {
-			movie.Genres = make([]models.Genre, len(genreIDs))
-			for i, genreID := range genreIDs {
-				movie.Genres[i] = models.Genre{ID: genreID, Name: fmt.Sprintf("Genre%d", genreID)}
-			}
-		}
-		return movie, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *MockMovieRepository) Create(ctx context.Context, movie *models.Movie) error {
-	movie.ID = r.nextID
-	movie.CreatedAt = time.Now()
-	movie.UpdatedAt = movie.CreatedAt
-	r.movies[movie.ID] = movie
-	r.nextID++
-	return nil
-}
-
-func (r *MockMovieRepository) Update(ctx context.Context, movie *models.Movie) error {
-	if _, exists := r.movies[movie.ID]; !exists {
-		return sql.ErrNoRows
-	}
-	movie.UpdatedAt = time.Now()
-	r.movies[movie.ID] = movie
-	return nil
+type recordedQuery struct {
+	sql  string
+	args []driver.Value
 }
 
-func (r *MockMovieRepository) Delete(ctx context.Context, id int) error {
-	if _, exists := r.movies[id]; !exists {
-		return sql.ErrNoRows
-	}
-	delete(r.movies, id)
-	delete(r.genres, id)
-	return nil
-}
-
-func (r *MockMovieRepository) List(ctx context.Context, filters models.MovieFilters, limit, offset int) ([]models.Movie, int, error) {
-	result := make([]models.Movie, 0)
-	for _, movie := range r.movies {
-		if r.matchesFilters(movie, filters) {
-			result = append(result, *movie)
-		}
-	}
-
-	// Apply pagination
-	total := len(result)
-	start := offset
-	if start >= total {
-		return []models.Movie{}, total, nil
-	}
-	end := start + limit
-	if end > total {
-		end = total
-	}
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{d}, nil }
 
-	paginated := result[start:end]
-	for i := range paginated {
-		// Add genres to each movie
-		if genreIDs, exists := r.genres[paginated[i].ID]; exists {
-			paginated[i].Genres = make([]models.Genre, len(genreIDs))
-			for j, genreID := range genreIDs {
-				paginated[i].Genres[j] = models.Genre{ID: genreID, Name: fmt.Sprintf("Genre%d", genreID)}
-			}
-		}
-	}
+type recordingConn struct{ d *recordingDriver }
 
-	return paginated, total, nil
-}
+func (recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (recordingConn) Close() error                        { return nil }
+func (recordingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
 
-func (r *MockMovieRepository) GetGenresByMovieID(ctx context.Context, movieID int) ([]models.Genre, error) {
-	if genreIDs, exists := r.genres[movieID]; exists {
-		genres := make([]models.Genre, len(genreIDs))
-		for i, genreID := range genreIDs {
-			genres[i] = models.Genre{ID: genreID, Name: fmt.Sprintf("Genre%d", genreID)}
-		}
-		return genres, nil
+func (c recordingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
 	}
-	return []models.Genre{}, nil
-}
-
-func (r *MockMovieRepository) SetGenres(ctx context.Context, movieID int, genreIDs []int) error {
-	if _, exists := r.movies[movieID]; !exists {
-		return sql.ErrNoRows
+	c.d.mu.Lock()
+	c.d.queries = append(c.d.queries, recordedQuery{sql: query, args: values})
+	c.d.mu.Unlock()
+	if strings.Contains(query, "COUNT(") {
+		return &recordedRows{values: []driver.Value{int64(0)}}, nil
 	}
-	r.genres[movieID] = genreIDs
-	return nil
+	return &recordedRows{}, nil
 }
 
-func (r *MockMovieRepository) UpdateAverageRating(ctx context.Context, movieID int) error {
-	// Mock implementation - in real implementation this would calculate from reviews
-	return nil
+type recordedRows struct {
+	values []driver.Value
+	done   bool
 }
 
-func (r *MockMovieRepository) Count(ctx context.Context) (int, error) {
-	return len(r.movies), nil
+func (r *recordedRows) Columns() []string {
+	return make([]string, max(len(r.values), 1))
 }
-
-func (r *MockMovieRepository) matchesFilters(movie *models.Movie, filters models.MovieFilters) bool {
-	// Check genre filter
-	if filters.GenreID != nil {
-		genreIDs, exists := r.genres[movie.ID]
-		if !exists {
-			return false
-		}
-		found := false
-		for _, gid := range genreIDs {
-			if gid == *filters.GenreID {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-
-	// Check year filter
-	if filters.Year != 0 && movie.ReleaseYear != filters.Year {
-		return false
+func (r *recordedRows) Close() error { return nil }
+func (r *recordedRows) Next(dest []driver.Value) error {
+	if r.done || r.values == nil {
+		return io.EOF
 	}
-
-	// Check minimum rating filter
-	if filters.MinRating > 0 && movie.AverageRating < filters.MinRating {
-		return false
-	}
-
-	// Check search filter
-	if filters.Search != "" {
-		search := filters.Search
-		titleMatch := containsIgnoreCase(movie.Title, search)
-		descriptionMatch := containsIgnoreCase(movie.Description, search)
-		if !titleMatch && !descriptionMatch {
-			return false
-		}
-	}
-
-	return true
-}
-
-func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) && findSubstringIgnoreCase(s, substr)
+	r.done = true
+	copy(dest, r.values)
+	return nil
 }
 
-func findSubstringIgnoreCase(s, substr string) bool {
-	sLower := toLower(s)
-	substrLower := toLower(substr)
-	for i := 0; i <= len(sLower)-len(substrLower); i++ {
-		if sLower[i:i+len(substrLower)] == substrLower {
-			return true
-		}
-	}
-	return false
-}
+var recorder = &recordingDriver{}
 
-func toLower(s string) string {
-	result := make([]rune, 0, len(s))
-	for _, r := range s {
-		if r >= 'A' && r <= 'Z' {
-			result = append(result, r+'a'-'A')
-		} else {
-			result = append(result, r)
-		}
-	}
-	return string(result)
+func init() {
+	sql.Register("recording", recorder)
 }
 
-func TestMovieRepository_GetByID(t *testing.T) {
-	repo := NewMockMovieRepository()
-	ctx := context.Background()
-
-	// Test getting non-existent movie
-	_, err := repo.GetByID(ctx, 1)
-	if err == nil {
-		t.Error("Expected error for non-existent movie")
-	}
-
-	// Create a movie
-	movie := &models.Movie{
-		Title:           "Test Movie",
-		Description:     "Test Description",
-		ReleaseYear:     2023,
-		Director:        "Test Director",
-		DurationMinutes: 120,
-	}
-	err = repo.Create(ctx, movie)
+// openRecording returns a database on the recording driver with the
+// queries of earlier tests forgotten.
+func openRecording(t *testing.T) *sql.DB {
+	t.Helper()
+	recorder.mu.Lock()
+	recorder.queries = nil
+	recorder.mu.Unlock()
+	db, err := sql.Open("recording", "")
 	if err != nil {
-		t.Errorf("Unexpected error creating movie: %v", err)
-	}
-
-	// Test getting existing movie
-	retrieved, err := repo.GetByID(ctx, movie.ID)
-	if err != nil {
-		t.Errorf("Unexpected error getting movie: %v", err)
-	}
-	if retrieved.ID != movie.ID || retrieved.Title != movie.Title {
-		t.Errorf("Retrieved movie doesn't match. Expected: %+v, Got: %+v", movie, retrieved)
+		t.Fatalf("open: %v", err)
 	}
+	t.Cleanup(func() { db.Close() })
+	return db
 }
 
-func TestMovieRepository_Create(t *testing.T) {
-	repo := NewMockMovieRepository()
-	ctx := context.Background()
+func TestMovieList_DirectorInQuery(t *testing.T) {
+	db := openRecording(t)
 
-	movie := &models.Movie{
-		Title:           "New Movie",
-		Description:     "New Description",
-		ReleaseYear:     2024,
-		Director:        "New Director",
-		DurationMinutes: 150,
-	}
-	err := repo.Create(ctx, movie)
-	if err != nil {
-		t.Errorf("Unexpected error creating movie: %v", err)
+	filters := models.MovieFilters{Year: 2010, DirectorIn: []string{"Christopher Nolan", `Denis "V"`}}
+	if _, _, err := NewMovieRepository(db).List(context.Background(), filters, 10, 0); err != nil {
+		t.Fatalf("list: %v", err)
 	}
 
-	if movie.ID == 0 {
-		t.Error("Expected movie ID to be set")
-	}
-	if movie.CreatedAt.IsZero() {
-		t.Error("Expected CreatedAt to be set")
-	}
-	if movie.UpdatedAt.IsZero() {
-		t.Error("Expected UpdatedAt to be set")
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.queries) != 2 {
+		t.Fatalf("expected a count and a page query, got %d", len(recorder.queries))
 	}
-
-	// Verify it was created
-	retrieved, err := repo.GetByID(ctx, movie.ID)
-	if err != nil {
-		t.Errorf("Unexpected error retrieving created movie: %v", err)
-	}
-	if retrieved.Title != movie.Title {
-		t.Errorf("Movie title mismatch. Expected: %s, Got: %s", movie.Title, retrieved.Title)
-	}
-}
-
-func TestMovieRepository_Update(t *testing.T) {
-	repo := NewMockMovieRepository()
-	ctx := context.Background()
-
-	// Create a movie
-	movie := &models.Movie{
-		Title:           "Original Title",
-		Description:     "Original Description",
-		ReleaseYear:     2023,
-		Director:        "Original Director",
-		DurationMinutes: 120,
-	}
-	err := repo.Create(ctx, movie)
-	if err != nil {
-		t.Errorf("Unexpected error creating movie: %v", err)
-	}
-
-	// Update the movie
-	originalUpdatedAt := movie.UpdatedAt
-	movie.Title = "Updated Title"
-	movie.Description = "Updated Description"
-	err = repo.Update(ctx, movie)
-	if err != nil {
-		t.Errorf("Unexpected error updating movie: %v", err)
-	}
-
-	// Verify the update
-	retrieved, err := repo.GetByID(ctx, movie.ID)
-	if err != nil {
-		t.Errorf("Unexpected error retrieving updated movie: %v", err)
-	}
-	if retrieved.Title != "Updated Title" {
-		t.Errorf("Movie title not updated. Expected: Updated Title, Got: %s", retrieved.Title)
-	}
-	if retrieved.UpdatedAt.Before(originalUpdatedAt) {
-		t.Error("Expected UpdatedAt to be updated")
-	}
-
-	// Test updating non-existent movie
-	nonExistent := &models.Movie{ID: 999, Title: "Test"}
-	err = repo.Update(ctx, nonExistent)
-	if err == nil {
-		t.Error("Expected error updating non-existent movie")
+	for _, q := range recorder.queries {
+		if !strings.Contains(q.sql, "m.director = ANY($2)") {
+			t.Fatalf("expected the directors as the second parameter, got %s", q.sql)
+		}
+		if want := `{"Christopher Nolan","Denis \"V\""}`; len(q.args) < 2 || q.args[1] != want {
+			t.Fatalf("expected the directors sent as the array %s, got %v", want, q.args)
+		}
 	}
 }
 
-func TestMovieRepository_Delete(t *testing.T) {
-	repo := NewMockMovieRepository()
+func TestList_SearchMatchesWildcardsLiterally(t *testing.T) {
+	db := openRecording(t)
 	ctx := context.Background()
 
-	// Create a movie
-	movie := &models.Movie{
-		Title:           "To Delete",
-		Description:     "Description",
-		ReleaseYear:     2023,
-		Director:        "Director",
-		DurationMinutes: 120,
+	if _, _, err := NewMovieRepository(db).List(ctx, models.MovieFilters{Genre: "sci_fi", Search: "100%"}, 10, 0); err != nil {
+		t.Fatalf("movie list: %v", err)
 	}
-	err := repo.Create(ctx, movie)
-	if err != nil {
-		t.Errorf("Unexpected error creating movie: %v", err)
-	}
-
-	// Delete the movie
-	err = repo.Delete(ctx, movie.ID)
-	if err != nil {
-		t.Errorf("Unexpected error deleting movie: %v", err)
+	if _, _, err := NewUserRepository(db).List(ctx, models.UserFilters{Search: "a_b%"}, 10, 0); err != nil {
+		t.Fatalf("user list: %v", err)
 	}
 
-	// Verify it was deleted
-	_, err = repo.GetByID(ctx, movie.ID)
-	if err == nil {
-		t.Error("Expected error retrieving deleted movie")
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.queries) != 4 {
+		t.Fatalf("expected a count and a page query per list, got %d", len(recorder.queries))
 	}
-
-	// Test deleting non-existent movie
-	err = repo.Delete(ctx, 999)
-	if err == nil {
-		t.Error("Expected error deleting non-existent movie")
+	for i, q := range recorder.queries {
+		want := []driver.Value{`%sci\_fi%`, `%100\%%`}
+		if i >= 2 {
+			want = []driver.Value{`%a\_b\%%`}
+		}
+		if !slices.Equal(q.args[:len(want)], want) {
+			t.Fatalf("query %d: expected escaped patterns %v, got %v", i, want, q.args)
+		}
+		if strings.Count(q.sql, "LIKE") != strings.Count(q.sql, `ESCAPE '\'`) {
+			t.Fatalf("query %d: every LIKE needs ESCAPE '\\': %s", i, q.sql)
+		}
 	}
 }
 
-func TestMovieRepository_List(t *testing.T) {
-	repo := NewMockMovieRepository()
-	ctx := context.Background()
-
-	// Test empty repository
-	movies, total, err := repo.List(ctx, models.MovieFilters{}, 10, 0)
-	if err != nil {
-		t.Errorf("Unexpected error listing movies: %v", err)
-	}
-	if len(movies) != 0 || total != 0 {
-		t.Errorf("Expected empty list, got %d movies with total %d", len(movies), total)
-	}
-
-	// Create some movies
-	movie1 := &models.Movie{
-		Title:           "Action Movie",
-		Description:     "An action-packed movie",
-		ReleaseYear:     2023,
-		Director:        "Action Director",
-		DurationMinutes: 120,
-		AverageRating:   8.5,
-	}
-	movie2 := &models.Movie{
-		Title:           "Comedy Movie",
-		Description:     "A funny comedy",
-		ReleaseYear:     2022,
-		Director:        "Comedy Director",
-		DurationMinutes: 90,
-		AverageRating:   7.2,
-	}
-	err = repo.Create(ctx, movie1)
-	if err != nil {
-		t.Errorf("Unexpected error creating movie1: %v", err)
-	}
-	err = repo.Create(ctx, movie2)
-	if err != nil {
-		t.Errorf("Unexpected error creating movie2: %v", err)
-	}
-
-	// Test listing all movies
-	movies, total, err = repo.List(ctx, models.MovieFilters{}, 10, 0)
-	if err != nil {
-		t.Errorf("Unexpected error listing movies: %v", err)
-	}
-	if len(movies) != 2 || total != 2 {
-		t.Errorf("Expected 2 movies, got %d with total %d", len(movies), total)
-	}
-
-	// Test year filter
-	movies, total, err = repo.List(ctx, models.MovieFilters{Year: 2023}, 10, 0)
-	if err != nil {
-		t.Errorf("Unexpected error listing movies: %v", err)
-	}
-	if len(movies) != 1 || total != 1 {
-		t.Errorf("Expected 1 movie from 2023, got %d with total %d", len(movies), total)
-	}
-	if movies[0].Title != "Action Movie" {
-		t.Errorf("Expected Action Movie, got %s", movies[0].Title)
-	}
-
-	// Test minimum rating filter
-	movies, total, err = repo.List(ctx, models.MovieFilters{MinRating: 8.0}, 10, 0)
-	if err != nil {
-		t.Errorf("Unexpected error listing movies: %v", err)
-	}
-	if len(movies) != 1 || total != 1 {
-		t.Errorf("Expected 1 movie with rating >= 8.0, got %d with total %d", len(movies), total)
-	}
-	if movies[0].Title != "Action Movie" {
-		t.Errorf("Expected Action Movie, got %s", movies[0].Title)
+// postgresTestDB connects to the database in TEST_DATABASE_DSN and migrates
+// it, skipping the test when the variable is not set.
+func postgresTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
 	}
-
-	// Test search filter
-	movies, total, err = repo.List(ctx, models.MovieFilters{Search: "action"}, 10, 0)
+	db, err := database.InitDB(context.Background(), dsn, config.PoolConfig{MaxOpenConns: 1})
 	if err != nil {
-		t.Errorf("Unexpected error listing movies: %v", err)
-	}
-	if len(movies) != 1 || total != 1 {
-		t.Errorf("Expected 1 movie matching 'action', got %d with total %d", len(movies), total)
+		t.Fatalf("connect: %v", err)
 	}
-	if movies[0].Title != "Action Movie" {
-		t.Errorf("Expected Action Movie, got %s", movies[0].Title)
+	t.Cleanup(func() { db.Close() })
+	if err := database.RunEmbeddedMigrations(db); err != nil {
+		t.Fatalf("migrate: %v", err)
 	}
+	return db
 }
 
-func TestMovieRepository_SetGenres(t *testing.T) {
-	repo := NewMockMovieRepository()
+// TestMovieList_DirectorInPostgres runs the director_in filter against the
+// database in TEST_DATABASE_DSN. The movies it creates are deleted again.
+func TestMovieList_DirectorInPostgres(t *testing.T) {
+	db := postgresTestDB(t)
 	ctx := context.Background()
 
-	// Create a movie
-	movie := &models.Movie{
-		Title:           "Test Movie",
-		Description:     "Test Description",
-		ReleaseYear:     2023,
-		Director:        "Test Director",
-		DurationMinutes: 120,
-	}
-	err := repo.Create(ctx, movie)
-	if err != nil {
-		t.Errorf("Unexpected error creating movie: %v", err)
-	}
-
-	// Set genres
-	genreIDs := []int{1, 2, 3}
-	err = repo.SetGenres(ctx, movie.ID, genreIDs)
-	if err != nil {
-		t.Errorf("Unexpected error setting genres: %v", err)
+	movies := NewMovieRepository(db)
+	const year = 1801
+	for _, director := range []string{"Director In A", "Director In B", "Director In C"} {
+		m := &models.Movie{Title: director + " film", ReleaseYear: year, Director: director, DurationMinutes: 90}
+		if err := movies.Create(ctx, m); err != nil {
+			t.Fatalf("create movie: %v", err)
+		}
+		t.Cleanup(func() { db.Exec(`DELETE FROM movies WHERE id = $1`, m.ID) })
 	}
 
-	// Verify genres were set
-	retrieved, err := repo.GetByID(ctx, movie.ID)
+	filters := models.MovieFilters{Year: year, DirectorIn: []string{"Director In A", "Director In C", "director in b"}}
+	got, total, err := movies.List(ctx, filters, 10, 0)
 	if err != nil {
-		t.Errorf("Unexpected error retrieving movie: %v", err)
+		t.Fatalf("list: %v", err)
 	}
-	if len(retrieved.Genres) != 3 {
-		t.Errorf("Expected 3 genres, got %d", len(retrieved.Genres))
+	var directors []string
+	for _, m := range got {
+		directors = append(directors, m.Director)
 	}
-
-	// Test setting genres for non-existent movie
-	err = repo.SetGenres(ctx, 999, []int{1, 2})
-	if err == nil {
-		t.Error("Expected error setting genres for non-existent movie")
+	slices.Sort(directors)
+	if total != 2 || !slices.Equal(directors, []string{"Director In A", "Director In C"}) {
+		t.Fatalf("expected exact matches on A and C, got %d: %v", total, directors)
 	}
 }
 
-func TestMovieRepository_Count(t *testing.T) {
-	repo := NewMockMovieRepository()
+// TestMovieList_SearchWildcardsPostgres checks against the database in
+// TEST_DATABASE_DSN that % and _ in a search only match themselves.
+func TestMovieList_SearchWildcardsPostgres(t *testing.T) {
+	db := postgresTestDB(t)
 	ctx := context.Background()
 
-	// Test empty repository
-	count, err := repo.Count(ctx)
-	if err != nil {
-		t.Errorf("Unexpected error counting movies: %v", err)
-	}
-	if count != 0 {
-		t.Errorf("Expected count 0, got %d", count)
-	}
-
-	// Create some movies
-	movie1 := &models.Movie{
-		Title:           "Movie 1",
-		Description:     "Description 1",
-		ReleaseYear:     2023,
-		Director:        "Director 1",
-		DurationMinutes: 120,
-	}
-	movie2 := &models.Movie{
-		Title:           "Movie 2",
-		Description:     "Description 2",
-		ReleaseYear:     2024,
-		Director:        "Director 2",
-		DurationMinutes: 150,
-	}
-	err = repo.Create(ctx, movie1)
-	if err != nil {
-		t.Errorf("Unexpected error creating movie1: %v", err)
-	}
-	err = repo.Create(ctx, movie2)
-	if err != nil {
-		t.Errorf("Unexpected error creating movie2: %v", err)
+	movies := NewMovieRepository(db)
+	const year = 1802
+	for _, title := range []string{"Wildcard 100% sure", "Wildcard 1000 sure", "Wildcard a_b", "Wildcard axb"} {
+		m := &models.Movie{Title: title, ReleaseYear: year, DurationMinutes: 90}
+		if err := movies.Create(ctx, m); err != nil {
+			t.Fatalf("create movie: %v", err)
+		}
+		t.Cleanup(func() { db.Exec(`DELETE FROM movies WHERE id = $1`, m.ID) })
 	}
 
-	// Test counting movies
-	count, err = repo.Count(ctx)
-	if err != nil {
-		t.Errorf("Unexpected error counting movies: %v", err)
-	}
-	if count != 2 {
-		t.Errorf("Expected count 2, got %d", count)
+	for search, want := range map[string]string{"100%": "Wildcard 100% sure", "a_b": "Wildcard a_b"} {
+		got, total, err := movies.List(ctx, models.MovieFilters{Year: year, Search: search}, 10, 0)
+		if err != nil {
+			t.Fatalf("search %q: %v", search, err)
+		}
+		if total != 1 || len(got) != 1 || got[0].Title != want {
+			t.Fatalf("search %q: expected only %q, got %d: %+v", search, want, total, got)
+		}
 	}
 }
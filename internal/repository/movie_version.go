@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"golang-project/internal/models"
+)
+
+type MovieVersionRepository struct {
+	db      *sql.DB
+	timeout queryTimeout
+}
+
+func NewMovieVersionRepository(db *sql.DB, opts ...Option) *MovieVersionRepository {
+	return &MovieVersionRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+// UpdateMovie writes movie over its row and stores the row it replaces as
+// a version. Both happen in one statement, so neither is kept without the
+// other.
+func (r *MovieVersionRepository) UpdateMovie(ctx context.Context, movie *models.Movie, editedByUserID *int) error {
+	var editedBy interface{}
+	if editedByUserID != nil {
+		editedBy = *editedByUserID
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`WITH snapshot AS (
+		     INSERT INTO movie_versions (movie_id, title, description, release_year, director, duration_minutes, edited_by_user_id)
+		     SELECT id, title, description, release_year, director, duration_minutes, $8
+		     FROM movies WHERE id = $7
+		 )
+		 `+updateMovieSQL,
+		append(updateMovieArgs(movie), editedBy)...,
+	)
+	return err
+}
+
+// ListByMovie returns a movie's versions, newest first, with the total count.
+func (r *MovieVersionRepository) ListByMovie(ctx context.Context, movieID, limit, offset int) ([]models.MovieVersion, int, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM movie_versions WHERE movie_id = $1", movieID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, movie_id, title, description, release_year, director, duration_minutes, edited_by_user_id, created_at
+		 FROM movie_versions
+		 WHERE movie_id = $1
+		 ORDER BY id DESC
+		 LIMIT $2 OFFSET $3`,
+		movieID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	versions := []models.MovieVersion{}
+	for rows.Next() {
+		v, err := scanMovieVersion(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		versions = append(versions, *v)
+	}
+	return versions, total, rows.Err()
+}
+
+// GetByID returns sql.ErrNoRows when movieID has no version id.
+func (r *MovieVersionRepository) GetByID(ctx context.Context, movieID, id int) (*models.MovieVersion, error) {
+	return scanMovieVersion(r.db.QueryRowContext(
+		ctx,
+		`SELECT id, movie_id, title, description, release_year, director, duration_minutes, edited_by_user_id, created_at
+		 FROM movie_versions
+		 WHERE movie_id = $1 AND id = $2`,
+		movieID, id,
+	))
+}
+
+func scanMovieVersion(row interface{ Scan(...interface{}) error }) (*models.MovieVersion, error) {
+	var v models.MovieVersion
+	var editedBy sql.NullInt64
+	if err := row.Scan(
+		&v.ID, &v.MovieID, &v.Title, &v.Description, &v.ReleaseYear,
+		&v.Director, &v.DurationMinutes, &editedBy, &v.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if editedBy.Valid {
+		id := int(editedBy.Int64)
+		v.EditedByUserID = &id
+	}
+	return &v, nil
+}
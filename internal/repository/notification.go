@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"golang-project/internal/models"
+)
+
+type NotificationRepository struct {
+	db      *sql.DB
+	timeout queryTimeout
+}
+
+func NewNotificationRepository(db *sql.DB, opts ...Option) *NotificationRepository {
+	return &NotificationRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+func (r *NotificationRepository) Insert(ctx context.Context, n *models.Notification) error {
+	payload, err := json.Marshal(n.Payload)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(
+		ctx,
+		`INSERT INTO notifications (user_id, event_type, payload)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, read, created_at`,
+		n.UserID, n.EventType, payload,
+	).Scan(&n.ID, &n.Read, &n.CreatedAt)
+}
+
+// ListByUser returns a user's notifications, newest first, with the total count.
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID, limit, offset int) ([]models.Notification, int, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notifications WHERE user_id = $1", userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, user_id, event_type, payload, read, created_at
+		 FROM notifications
+		 WHERE user_id = $1
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $2 OFFSET $3`,
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		var payload []byte
+		if err := rows.Scan(&n.ID, &n.UserID, &n.EventType, &payload, &n.Read, &n.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(payload, &n.Payload); err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, total, rows.Err()
+}
@@ -3,31 +3,36 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/lib/pq"
+
 	"golang-project/internal/models"
 )
 
 type ReviewRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout queryTimeout
 }
 
-func NewReviewRepository(db *sql.DB) *ReviewRepository {
-	return &ReviewRepository{db: db}
+func NewReviewRepository(db *sql.DB, opts ...Option) *ReviewRepository {
+	return &ReviewRepository{db: db, timeout: newOptions(opts).queryTimeout}
 }
 
 func (r *ReviewRepository) GetByID(ctx context.Context, id int) (*models.Review, error) {
 	var review models.Review
 	err := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at
+		`SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at, sentiment_score
 		 FROM reviews WHERE id = $1`,
 		id,
 	).Scan(
 		&review.ID, &review.MovieID, &review.UserID, &review.Rating,
-		&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt,
+		&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt, &review.SentimentScore,
 	)
 	if err != nil {
 		return nil, err
@@ -36,6 +41,9 @@ func (r *ReviewRepository) GetByID(ctx context.Context, id int) (*models.Review,
 }
 
 func (r *ReviewRepository) GetByMovieID(ctx context.Context, movieID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
 	whereParts := []string{"movie_id = $1"}
 	args := []interface{}{movieID}
 	argPos := 2
@@ -67,7 +75,7 @@ func (r *ReviewRepository) GetByMovieID(ctx context.Context, movieID int, filter
 
 	args = append(args, limit, offset)
 	query := fmt.Sprintf(`
-		SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at
+		SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at, sentiment_score
 		FROM reviews
 		WHERE %s
 		ORDER BY %s
@@ -85,7 +93,7 @@ func (r *ReviewRepository) GetByMovieID(ctx context.Context, movieID int, filter
 		var review models.Review
 		if err := rows.Scan(
 			&review.ID, &review.MovieID, &review.UserID, &review.Rating,
-			&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt,
+			&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt, &review.SentimentScore,
 		); err != nil {
 			return nil, err
 		}
@@ -94,23 +102,29 @@ func (r *ReviewRepository) GetByMovieID(ctx context.Context, movieID int, filter
 	return reviews, rows.Err()
 }
 
-func (r *ReviewRepository) GetByUserID(ctx context.Context, userID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error) {
+// userReviewsWhere builds the WHERE clause shared by GetByUserID and
+// CountByUserIDFiltered.
+func userReviewsWhere(userID int, filters models.ReviewFilters) (string, []interface{}) {
 	whereParts := []string{"user_id = $1"}
 	args := []interface{}{userID}
-	argPos := 2
 
 	if filters.MinRating > 0 {
 		args = append(args, filters.MinRating)
-		whereParts = append(whereParts, fmt.Sprintf("rating >= $%d", argPos))
-		argPos++
+		whereParts = append(whereParts, fmt.Sprintf("rating >= $%d", len(args)))
 	}
 	if filters.MaxRating > 0 {
 		args = append(args, filters.MaxRating)
-		whereParts = append(whereParts, fmt.Sprintf("rating <= $%d", argPos))
-		argPos++
+		whereParts = append(whereParts, fmt.Sprintf("rating <= $%d", len(args)))
 	}
+	return strings.Join(whereParts, " AND "), args
+}
 
-	whereSQL := strings.Join(whereParts, " AND ")
+func (r *ReviewRepository) GetByUserID(ctx context.Context, userID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	whereSQL, args := userReviewsWhere(userID, filters)
+	argPos := len(args) + 1
 
 	orderBy := "created_at DESC"
 	switch filters.Sort {
@@ -126,7 +140,7 @@ func (r *ReviewRepository) GetByUserID(ctx context.Context, userID int, filters
 
 	args = append(args, limit, offset)
 	query := fmt.Sprintf(`
-		SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at
+		SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at, sentiment_score
 		FROM reviews
 		WHERE %s
 		ORDER BY %s
@@ -144,7 +158,7 @@ func (r *ReviewRepository) GetByUserID(ctx context.Context, userID int, filters
 		var review models.Review
 		if err := rows.Scan(
 			&review.ID, &review.MovieID, &review.UserID, &review.Rating,
-			&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt,
+			&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt, &review.SentimentScore,
 		); err != nil {
 			return nil, err
 		}
@@ -157,12 +171,12 @@ func (r *ReviewRepository) GetByMovieAndUser(ctx context.Context, movieID, userI
 	var review models.Review
 	err := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at
+		`SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at, sentiment_score
 		 FROM reviews WHERE movie_id = $1 AND user_id = $2`,
 		movieID, userID,
 	).Scan(
 		&review.ID, &review.MovieID, &review.UserID, &review.Rating,
-		&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt,
+		&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt, &review.SentimentScore,
 	)
 	if err != nil {
 		return nil, err
@@ -170,6 +184,56 @@ func (r *ReviewRepository) GetByMovieAndUser(ctx context.Context, movieID, userI
 	return &review, nil
 }
 
+// GetReviewTarget loads the movie with the ID of userID's review of it, or
+// 0 if there is none, in one query. It returns sql.ErrNoRows if the movie
+// does not exist.
+func (r *ReviewRepository) GetReviewTarget(ctx context.Context, movieID, userID int) (*models.Movie, int, error) {
+	var movie models.Movie
+	var reviewID sql.NullInt64
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT m.id, m.title, m.review_embargo_until, COALESCE(m.submitted_by_user_id, 0), m.status, r.id
+		 FROM movies m
+		 LEFT JOIN reviews r ON r.movie_id = m.id AND r.user_id = $2
+		 WHERE m.id = $1`,
+		movieID, userID,
+	).Scan(&movie.ID, &movie.Title, &movie.ReviewEmbargoUntil, &movie.SubmittedByUserID, &movie.Status, &reviewID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &movie, int(reviewID.Int64), nil
+}
+
+// FindSimilarContent returns a review of the movie whose content has a
+// pg_trgm similarity above threshold, or sql.ErrNoRows if there is none.
+// It matches with the % operator, which unlike similarity() can use the
+// trigram index; the threshold is set for this transaction only, so pooled
+// connections keep the default.
+func (r *ReviewRepository) FindSimilarContent(ctx context.Context, movieID int, content string, threshold float64) (*models.Review, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('pg_trgm.similarity_threshold', $1, true)", strconv.FormatFloat(threshold, 'f', -1, 64)); err != nil {
+		return nil, err
+	}
+	var review models.Review
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT id, user_id FROM reviews WHERE movie_id = $1 AND content % $2 LIMIT 1",
+		movieID, content,
+	).Scan(&review.ID, &review.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &review, tx.Commit()
+}
+
 func (r *ReviewRepository) Create(ctx context.Context, review *models.Review) error {
 	return r.db.QueryRowContext(
 		ctx,
@@ -191,11 +255,47 @@ func (r *ReviewRepository) Update(ctx context.Context, review *models.Review) er
 	return err
 }
 
+func (r *ReviewRepository) UpdateSentimentScore(ctx context.Context, reviewID int, score float64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE reviews SET sentiment_score = $1 WHERE id = $2", score, reviewID)
+	return err
+}
+
 func (r *ReviewRepository) Delete(ctx context.Context, id int) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM reviews WHERE id = $1", id)
 	return err
 }
 
+// DeleteMany deletes the reviews in ids with a single statement, so either
+// all of them go or none do, and returns the ones that existed.
+func (r *ReviewRepository) DeleteMany(ctx context.Context, ids []int) ([]models.Review, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`DELETE FROM reviews WHERE id = ANY($1)
+		 RETURNING id, movie_id, user_id, rating, title, content, created_at, updated_at, sentiment_score`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deleted []models.Review
+	for rows.Next() {
+		var review models.Review
+		if err := rows.Scan(
+			&review.ID, &review.MovieID, &review.UserID, &review.Rating,
+			&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt, &review.SentimentScore,
+		); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, review)
+	}
+	return deleted, rows.Err()
+}
+
 func (r *ReviewRepository) CountByMovieID(ctx context.Context, movieID int) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(
@@ -207,12 +307,59 @@ func (r *ReviewRepository) CountByMovieID(ctx context.Context, movieID int) (int
 }
 
 func (r *ReviewRepository) CountByUserID(ctx context.Context, userID int) (int, error) {
+	return r.CountByUserIDFiltered(ctx, userID, models.ReviewFilters{})
+}
+
+// ListAfter returns up to limit reviews matching the rating filters with IDs
+// above afterID, in ID order. Sort is ignored so the ID works as a cursor.
+func (r *ReviewRepository) ListAfter(ctx context.Context, filters models.ReviewFilters, afterID, limit int) ([]models.Review, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	whereParts := []string{"id > $1"}
+	args := []interface{}{afterID}
+	if filters.MinRating > 0 {
+		args = append(args, filters.MinRating)
+		whereParts = append(whereParts, fmt.Sprintf("rating >= $%d", len(args)))
+	}
+	if filters.MaxRating > 0 {
+		args = append(args, filters.MaxRating)
+		whereParts = append(whereParts, fmt.Sprintf("rating <= $%d", len(args)))
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, movie_id, user_id, rating, title, content, created_at, updated_at, sentiment_score
+		FROM reviews
+		WHERE %s
+		ORDER BY id
+		LIMIT $%d
+	`, strings.Join(whereParts, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []models.Review
+	for rows.Next() {
+		var review models.Review
+		if err := rows.Scan(
+			&review.ID, &review.MovieID, &review.UserID, &review.Rating,
+			&review.Title, &review.Content, &review.CreatedAt, &review.UpdatedAt, &review.SentimentScore,
+		); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// CountByUserIDFiltered counts the reviews GetByUserID would page through
+// with the same filters.
+func (r *ReviewRepository) CountByUserIDFiltered(ctx context.Context, userID int, filters models.ReviewFilters) (int, error) {
+	whereSQL, args := userReviewsWhere(userID, filters)
 	var count int
-	err := r.db.QueryRowContext(
-		ctx,
-		"SELECT COUNT(*) FROM reviews WHERE user_id = $1",
-		userID,
-	).Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reviews WHERE "+whereSQL, args...).Scan(&count)
 	return count, err
 }
 
@@ -244,6 +391,53 @@ func (r *ReviewRepository) GetAverageRatingByUserID(ctx context.Context, userID
 	return avg.Float64, nil
 }
 
+// GetAnalytics aggregates every review in one query. A review counts as
+// flagged when its title or content contains a blocked keyword, compared
+// the way the content filter does. FlaggedPercent is left to the caller.
+func (r *ReviewRepository) GetAnalytics(ctx context.Context) (*models.ReviewAnalytics, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	var a models.ReviewAnalytics
+	var distribution []byte
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*),
+		        COUNT(*) FILTER (WHERE EXISTS (
+		            SELECT 1 FROM blocked_keywords bk
+		            WHERE CASE WHEN bk.case_sensitive
+		                       THEN strpos(rv.title || ' ' || rv.content, bk.keyword) > 0
+		                       ELSE strpos(LOWER(rv.title || ' ' || rv.content), LOWER(bk.keyword)) > 0 END
+		        )),
+		        COALESCE(AVG(CASE WHEN rv.content ~ '^\s*$' THEN 0
+		                          ELSE array_length(regexp_split_to_array(regexp_replace(rv.content, '^\s+|\s+$', '', 'g'), '\s+'), 1) END), 0),
+		        COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY rv.rating), 0),
+		        COALESCE(AVG(char_length(rv.content)), 0),
+		        (SELECT COALESCE(json_object_agg(d.rating, d.n), '{}')
+		         FROM (SELECT rating, COUNT(*) AS n FROM reviews GROUP BY rating) d)
+		 FROM reviews rv`,
+	).Scan(&a.TotalReviews, &a.FlaggedReviews, &a.AvgWordCount, &a.MedianRating, &a.AvgContentLength, &distribution)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(distribution, &a.RatingDistribution); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetUserRatingStats returns the mean and sample standard deviation of a
+// user's ratings. Both are 0 when the user has no reviews, and stddev is 0
+// when they have only one.
+func (r *ReviewRepository) GetUserRatingStats(ctx context.Context, userID int) (mean, stddev float64, err error) {
+	err = r.db.QueryRowContext(
+		ctx,
+		"SELECT COALESCE(AVG(rating), 0), COALESCE(STDDEV_SAMP(rating), 0) FROM reviews WHERE user_id = $1",
+		userID,
+	).Scan(&mean, &stddev)
+	return mean, stddev, err
+}
+
 func (r *ReviewRepository) GetFavoriteGenreByUserID(ctx context.Context, userID int) (*models.Genre, error) {
 	var genre models.Genre
 	err := r.db.QueryRowContext(
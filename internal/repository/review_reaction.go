@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"golang-project/internal/models"
+)
+
+type ReviewReactionRepository struct {
+	db      *sql.DB
+	timeout queryTimeout
+}
+
+func NewReviewReactionRepository(db *sql.DB, opts ...Option) *ReviewReactionRepository {
+	return &ReviewReactionRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+// Upsert stores reaction, replacing the user's earlier reaction to the review.
+func (r *ReviewReactionRepository) Upsert(ctx context.Context, reaction *models.ReviewReaction) error {
+	return r.db.QueryRowContext(
+		ctx,
+		`INSERT INTO review_reactions (review_id, user_id, reaction_type)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (review_id, user_id)
+		 DO UPDATE SET reaction_type = EXCLUDED.reaction_type, created_at = NOW()
+		 RETURNING created_at`,
+		reaction.ReviewID, reaction.UserID, reaction.Reaction,
+	).Scan(&reaction.CreatedAt)
+}
+
+// Delete returns sql.ErrNoRows if the user has not reacted to the review.
+func (r *ReviewReactionRepository) Delete(ctx context.Context, reviewID, userID int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM review_reactions WHERE review_id = $1 AND user_id = $2", reviewID, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CountByReview counts a review's reactions by type. Types nobody picked
+// are left out of the map.
+func (r *ReviewReactionRepository) CountByReview(ctx context.Context, reviewID int) (map[string]int, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT reaction_type, COUNT(*) FROM review_reactions WHERE review_id = $1 GROUP BY reaction_type",
+		reviewID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var reaction string
+		var count int
+		if err := rows.Scan(&reaction, &count); err != nil {
+			return nil, err
+		}
+		counts[reaction] = count
+	}
+	return counts, rows.Err()
+}
+
+// CountByReviews is CountByReview for several reviews in one query.
+// Reviews nobody reacted to are missing from the map.
+func (r *ReviewReactionRepository) CountByReviews(ctx context.Context, reviewIDs []int) (map[int]map[string]int, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT review_id, reaction_type, COUNT(*)
+		 FROM review_reactions
+		 WHERE review_id = ANY($1)
+		 GROUP BY review_id, reaction_type`,
+		pq.Array(reviewIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]map[string]int)
+	for rows.Next() {
+		var reviewID, count int
+		var reaction string
+		if err := rows.Scan(&reviewID, &reaction, &count); err != nil {
+			return nil, err
+		}
+		if counts[reviewID] == nil {
+			counts[reviewID] = make(map[string]int)
+		}
+		counts[reviewID][reaction] = count
+	}
+	return counts, rows.Err()
+}
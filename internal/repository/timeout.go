@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures a repository.
+type Option func(*options)
+
+type options struct {
+	queryTimeout queryTimeout
+}
+
+// WithQueryTimeout bounds each list query to d, on top of whatever deadline
+// the caller's context already carries. Zero leaves queries unbounded.
+func WithQueryTimeout(d time.Duration) Option {
+	return func(o *options) { o.queryTimeout = queryTimeout(d) }
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// queryTimeout is the deadline a repository puts around a single query, so a
+// runaway query is cut off even when the request itself may run longer.
+type queryTimeout time.Duration
+
+// bound derives the context for one query. The caller must call cancel once
+// it is done with the query, including reading its rows.
+func (d queryTimeout) bound(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(d))
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+// blockingDriver is a database/sql driver whose queries never finish on
+// their own: they return only once the query context is done.
+type blockingDriver struct{}
+
+func (blockingDriver) Open(string) (driver.Conn, error) { return blockingConn{}, nil }
+
+type blockingConn struct{}
+
+func (blockingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (blockingConn) Close() error                        { return nil }
+func (blockingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (blockingConn) QueryContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func init() {
+	sql.Register("blocking", blockingDriver{})
+}
+
+func TestQueryTimeout(t *testing.T) {
+	db, err := sql.Open("blocking", "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	movies := NewMovieRepository(db, WithQueryTimeout(20*time.Millisecond))
+	reviews := NewReviewRepository(db, WithQueryTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	if _, _, err := movies.List(context.Background(), models.MovieFilters{}, 10, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("movie list: expected a deadline error, got %v", err)
+	}
+	if _, err := reviews.GetByMovieID(context.Background(), 1, models.ReviewFilters{}, 10, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("review list: expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("queries were not cut off in time: %v", elapsed)
+	}
+
+	// Without a timeout only the caller's context bounds the query.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := NewMovieRepository(db).List(ctx, models.MovieFilters{}, 10, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the caller's cancellation, got %v", err)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"golang-project/internal/models"
 )
@@ -15,46 +18,51 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.User, error)
 	List(ctx context.Context, filters models.UserFilters, limit, offset int) ([]models.User, int, error)
 	UpdateRole(ctx context.Context, id int, role string) error
+	UpdateRoles(ctx context.Context, ids []int, role string) error
 	Update(ctx context.Context, id int, email, username string) error
 	UpdatePassword(ctx context.Context, id int, passwordHash string) error
+	SetEmailOnDigest(ctx context.Context, id int, enabled bool) error
+	SetReviewsPrivate(ctx context.Context, id int, private bool) error
 	Delete(ctx context.Context, id int) error
 	Count(ctx context.Context) (int, error)
 	CountLast7Days(ctx context.Context) (int, error)
 }
 
 type PostgresUserRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout queryTimeout
 }
 
-func NewUserRepository(db *sql.DB) *PostgresUserRepository {
-	return &PostgresUserRepository{db: db}
+func NewUserRepository(db *sql.DB, opts ...Option) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db, timeout: newOptions(opts).queryTimeout}
 }
 
 func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
 		INSERT INTO users (email, username, password_hash, role)
 		VALUES ($1, $2, $3, $4)
-		RETURNING id, role, created_at, updated_at
+		RETURNING id, role, is_active, reviews_private, created_at, updated_at
 	`
 	return r.db.QueryRowContext(ctx, query,
 		user.Email,
 		user.Username,
 		user.PasswordHash,
 		user.Role,
-	).Scan(&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Role, &user.IsActive, &user.ReviewsPrivate, &user.CreatedAt, &user.UpdatedAt)
 }
 
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, username, password_hash, role, created_at, updated_at
+		SELECT id, email, username, password_hash, role, is_active, email_on_digest, reviews_private, last_review_at, reminder_sent_at, created_at, updated_at
 		FROM users
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`
 	var u models.User
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.IsActive, &u.EmailOnDigest, &u.ReviewsPrivate, &u.LastReviewAt, &u.ReminderSentAt, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -67,13 +75,13 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 
 func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	query := `
-		SELECT id, email, username, password_hash, role, created_at, updated_at
+		SELECT id, email, username, password_hash, role, is_active, email_on_digest, reviews_private, last_review_at, reminder_sent_at, created_at, updated_at
 		FROM users
-		WHERE username = $1
+		WHERE username = $1 AND deleted_at IS NULL
 	`
 	var u models.User
 	err := r.db.QueryRowContext(ctx, query, username).Scan(
-		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.IsActive, &u.EmailOnDigest, &u.ReviewsPrivate, &u.LastReviewAt, &u.ReminderSentAt, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -86,13 +94,13 @@ func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username str
 
 func (r *PostgresUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
 	query := `
-		SELECT id, email, username, password_hash, role, created_at, updated_at
+		SELECT id, email, username, password_hash, role, is_active, email_on_digest, reviews_private, last_review_at, reminder_sent_at, created_at, updated_at
 		FROM users
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 	var u models.User
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.IsActive, &u.EmailOnDigest, &u.ReviewsPrivate, &u.LastReviewAt, &u.ReminderSentAt, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -100,25 +108,67 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id int) (*models.U
 	return &u, nil
 }
 
+func (r *PostgresUserRepository) GetByIDs(ctx context.Context, ids []int) ([]models.User, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, email, username, password_hash, role, is_active, email_on_digest, reviews_private, last_review_at, reminder_sent_at, created_at, updated_at
+		FROM users
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.IsActive, &u.EmailOnDigest, &u.ReviewsPrivate, &u.LastReviewAt, &u.ReminderSentAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
 func (r *PostgresUserRepository) List(ctx context.Context, filters models.UserFilters, limit, offset int) ([]models.User, int, error) {
-	whereParts := []string{"1=1"}
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	whereParts := []string{"u.deleted_at IS NULL"}
 	args := []interface{}{}
 	argPos := 1
+	joinSQL := ""
 
 	if filters.Search != "" {
-		args = append(args, "%"+filters.Search+"%")
-		whereParts = append(whereParts, fmt.Sprintf("(LOWER(email) LIKE LOWER($%d) OR LOWER(username) LIKE LOWER($%d))", argPos, argPos))
+		args = append(args, containsPattern(filters.Search))
+		whereParts = append(whereParts, fmt.Sprintf(`(LOWER(u.email) LIKE LOWER($%d) ESCAPE '\' OR LOWER(u.username) LIKE LOWER($%d) ESCAPE '\')`, argPos, argPos))
 		argPos++
 	}
 	if filters.Role != "" {
 		args = append(args, filters.Role)
-		whereParts = append(whereParts, fmt.Sprintf("role = $%d", argPos))
+		whereParts = append(whereParts, fmt.Sprintf("u.role = $%d", argPos))
 		argPos++
 	}
+	if filters.MinReviews != nil || filters.MaxReviews != nil {
+		joinSQL = "LEFT JOIN (SELECT user_id, COUNT(*) AS rc FROM reviews GROUP BY user_id) r ON r.user_id = u.id"
+		if filters.MinReviews != nil {
+			args = append(args, *filters.MinReviews)
+			whereParts = append(whereParts, fmt.Sprintf("COALESCE(r.rc, 0) >= $%d", argPos))
+			argPos++
+		}
+		if filters.MaxReviews != nil {
+			args = append(args, *filters.MaxReviews)
+			whereParts = append(whereParts, fmt.Sprintf("COALESCE(r.rc, 0) <= $%d", argPos))
+			argPos++
+		}
+	}
 
 	whereSQL := strings.Join(whereParts, " AND ")
 
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM users WHERE %s", whereSQL)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM users u %s WHERE %s", joinSQL, whereSQL)
 	var total int
 	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, err
@@ -128,12 +178,12 @@ func (r *PostgresUserRepository) List(ctx context.Context, filters models.UserFi
 	argsWithPage = append(argsWithPage, limit, offset)
 
 	query := fmt.Sprintf(`
-		SELECT id, email, username, password_hash, role, created_at, updated_at
-		FROM users
+		SELECT u.id, u.email, u.username, u.password_hash, u.role, u.is_active, u.email_on_digest, u.reviews_private, u.last_review_at, u.reminder_sent_at, u.created_at, u.updated_at
+		FROM users u %s
 		WHERE %s
-		ORDER BY created_at DESC
+		ORDER BY u.created_at DESC
 		LIMIT $%d OFFSET $%d
-	`, whereSQL, argPos, argPos+1)
+	`, joinSQL, whereSQL, argPos, argPos+1)
 
 	rows, err := r.db.QueryContext(ctx, query, argsWithPage...)
 	if err != nil {
@@ -144,7 +194,7 @@ func (r *PostgresUserRepository) List(ctx context.Context, filters models.UserFi
 	var users []models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.IsActive, &u.EmailOnDigest, &u.ReviewsPrivate, &u.LastReviewAt, &u.ReminderSentAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
 			return nil, 0, err
 		}
 		users = append(users, u)
@@ -153,11 +203,50 @@ func (r *PostgresUserRepository) List(ctx context.Context, filters models.UserFi
 }
 
 func (r *PostgresUserRepository) UpdateRole(ctx context.Context, id int, role string) error {
-	_, err := r.db.ExecContext(ctx, `
+	result, err := r.db.ExecContext(ctx, `
 		UPDATE users SET role = $1, updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND deleted_at IS NULL
 	`, role, id)
-	return err
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdateRoles sets role on every user in ids within one transaction, so
+// either all of them change or none do. A user deleted since the caller
+// looked it up rolls the whole update back with sql.ErrNoRows.
+func (r *PostgresUserRepository) UpdateRoles(ctx context.Context, ids []int, role string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE users SET role = $1, updated_at = NOW()
+			WHERE id = $2 AND deleted_at IS NULL
+		`, role, id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	return tx.Commit()
 }
 
 func (r *PostgresUserRepository) Update(ctx context.Context, id int, email, username string) error {
@@ -166,7 +255,7 @@ func (r *PostgresUserRepository) Update(ctx context.Context, id int, email, user
 		SET email = COALESCE(NULLIF($1, ''), email),
 		    username = COALESCE(NULLIF($2, ''), username),
 		    updated_at = NOW()
-		WHERE id = $3
+		WHERE id = $3 AND deleted_at IS NULL
 	`
 	result, err := r.db.ExecContext(ctx, query, email, username, id)
 	if err != nil {
@@ -185,7 +274,7 @@ func (r *PostgresUserRepository) Update(ctx context.Context, id int, email, user
 func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
 	result, err := r.db.ExecContext(ctx, `
 		UPDATE users SET password_hash = $1, updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND deleted_at IS NULL
 	`, passwordHash, id)
 	if err != nil {
 		return err
@@ -200,8 +289,108 @@ func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, id int, pas
 	return nil
 }
 
+func (r *PostgresUserRepository) SetEmailOnDigest(ctx context.Context, id int, enabled bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET email_on_digest = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, enabled, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) SetReviewsPrivate(ctx context.Context, id int, private bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET reviews_private = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, private, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetLastReviewAt records when the user last posted a review.
+func (r *PostgresUserRepository) SetLastReviewAt(ctx context.Context, id int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET last_review_at = $1 WHERE id = $2", at, id)
+	return err
+}
+
+// FindInactiveReviewers returns the active users who opted in to review
+// reminders and have not posted a review since since. Users who never
+// reviewed anything are left out.
+func (r *PostgresUserRepository) FindInactiveReviewers(ctx context.Context, since time.Time) ([]models.User, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, email, username, password_hash, role, is_active, email_on_digest, reviews_private, last_review_at, reminder_sent_at, created_at, updated_at
+		FROM users
+		WHERE last_review_at < $1 AND is_active = true AND email_on_digest = true AND deleted_at IS NULL
+		ORDER BY id
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role, &u.IsActive, &u.EmailOnDigest, &u.ReviewsPrivate, &u.LastReviewAt, &u.ReminderSentAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// ClaimReminder sets the user's reminder_sent_at to at unless a reminder
+// went out after notBefore, and reports whether it did. The check and the
+// write are one statement, so of several instances running the same tick
+// only one gets to send the email.
+func (r *PostgresUserRepository) ClaimReminder(ctx context.Context, id int, at, notBefore time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET reminder_sent_at = $1
+		WHERE id = $2 AND (reminder_sent_at IS NULL OR reminder_sent_at <= $3)
+	`, at.Truncate(time.Microsecond), id, notBefore)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+// ReleaseReminder undoes a claim made at at whose email could not be sent,
+// putting back prev unless another reminder has been claimed since.
+func (r *PostgresUserRepository) ReleaseReminder(ctx context.Context, id int, at time.Time, prev *time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET reminder_sent_at = $1
+		WHERE id = $2 AND reminder_sent_at = $3
+	`, prev, id, at.Truncate(time.Microsecond))
+	return err
+}
+
 func (r *PostgresUserRepository) Delete(ctx context.Context, id int) error {
-	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1 AND deleted_at IS NULL`, id)
 	if err != nil {
 		return err
 	}
@@ -217,12 +406,89 @@ func (r *PostgresUserRepository) Delete(ctx context.Context, id int) error {
 
 func (r *PostgresUserRepository) Count(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE deleted_at IS NULL").Scan(&count)
 	return count, err
 }
 
 func (r *PostgresUserRepository) CountLast7Days(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE created_at >= NOW() - INTERVAL '7 days'").Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE deleted_at IS NULL AND created_at >= NOW() - INTERVAL '7 days'").Scan(&count)
 	return count, err
 }
+
+// SearchByUsername returns up to limit users whose username contains query,
+// case-insensitively. Unlike List it never matches on email, so it is safe
+// for public search.
+func (r *PostgresUserRepository) SearchByUsername(ctx context.Context, query string, limit int) ([]models.User, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, username FROM users WHERE deleted_at IS NULL AND LOWER(username) LIKE LOWER($1) ESCAPE '\' ORDER BY username LIMIT $2`,
+		containsPattern(query), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Username); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// MergeInto moves sourceID's reviews and watch history to targetID and
+// soft-deletes sourceID, all in one transaction. Rows for a movie targetID
+// already has are left with the source account. It returns how many reviews
+// were moved, or sql.ErrNoRows if sourceID does not exist.
+func (r *PostgresUserRepository) MergeInto(ctx context.Context, sourceID, targetID int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE reviews SET user_id = $2
+		WHERE user_id = $1
+		  AND movie_id NOT IN (SELECT movie_id FROM reviews WHERE user_id = $2)
+	`, sourceID, targetID)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE watch_history SET user_id = $2
+		WHERE user_id = $1
+		  AND movie_id NOT IN (SELECT movie_id FROM watch_history WHERE user_id = $2)
+	`, sourceID, targetID); err != nil {
+		return 0, err
+	}
+
+	result, err = tx.ExecContext(ctx, `
+		UPDATE users SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, sql.ErrNoRows
+	}
+	return int(moved), tx.Commit()
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"golang-project/internal/models"
+)
+
+// TestUserList_Postgres lists users against the database in
+// TEST_DATABASE_DSN, so every column the query selects must exist. The user
+// it creates is deleted again.
+func TestUserList_Postgres(t *testing.T) {
+	db := postgresTestDB(t)
+	ctx := context.Background()
+
+	users := NewUserRepository(db)
+	u := &models.User{Email: "list-postgres@example.com", Username: "list_postgres", PasswordHash: "x", Role: "user"}
+	if err := users.Create(ctx, u); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, u.ID) })
+	if err := users.SetReviewsPrivate(ctx, u.ID, true); err != nil {
+		t.Fatalf("set reviews private: %v", err)
+	}
+
+	got, total, err := users.List(ctx, models.UserFilters{Search: "list_postgres"}, 10, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if total != 1 || len(got) != 1 || got[0].ID != u.ID || !got[0].ReviewsPrivate {
+		t.Fatalf("expected the private user back, got %d: %+v", total, got)
+	}
+}
+
+// TestUserWrites_SkipDeletedPostgres checks against the database in
+// TEST_DATABASE_DSN that a soft-deleted user cannot be changed or deleted.
+func TestUserWrites_SkipDeletedPostgres(t *testing.T) {
+	db := postgresTestDB(t)
+	ctx := context.Background()
+
+	users := NewUserRepository(db)
+	u := &models.User{Email: "deleted-postgres@example.com", Username: "deleted_postgres", PasswordHash: "x", Role: "user"}
+	if err := users.Create(ctx, u); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, u.ID) })
+	if _, err := db.ExecContext(ctx, `UPDATE users SET deleted_at = NOW() WHERE id = $1`, u.ID); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+
+	for name, write := range map[string]func() error{
+		"update role":     func() error { return users.UpdateRole(ctx, u.ID, "admin") },
+		"update roles":    func() error { return users.UpdateRoles(ctx, []int{u.ID}, "admin") },
+		"update":          func() error { return users.Update(ctx, u.ID, "", "renamed") },
+		"update password": func() error { return users.UpdatePassword(ctx, u.ID, "y") },
+		"delete":          func() error { return users.Delete(ctx, u.ID) },
+	} {
+		if err := write(); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("%s: expected sql.ErrNoRows, got %v", name, err)
+		}
+	}
+}
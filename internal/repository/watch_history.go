@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"golang-project/internal/models"
+)
+
+type WatchHistoryRepository struct {
+	db      *sql.DB
+	timeout queryTimeout
+}
+
+func NewWatchHistoryRepository(db *sql.DB, opts ...Option) *WatchHistoryRepository {
+	return &WatchHistoryRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+// RecordWatch inserts w, or updates watched_at and progress_percent when the
+// user already has an entry for the movie.
+func (r *WatchHistoryRepository) RecordWatch(ctx context.Context, w *models.WatchHistory) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO watch_history (user_id, movie_id, watched_at, progress_percent)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id, movie_id)
+		 DO UPDATE SET watched_at = EXCLUDED.watched_at, progress_percent = EXCLUDED.progress_percent`,
+		w.UserID, w.MovieID, w.WatchedAt, w.ProgressPercent,
+	)
+	return err
+}
+
+// GetByUser returns a user's watch history, most recently watched first,
+// with the total count.
+func (r *WatchHistoryRepository) GetByUser(ctx context.Context, userID, limit, offset int) ([]models.WatchHistory, int, error) {
+	ctx, cancel := r.timeout.bound(ctx)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM watch_history WHERE user_id = $1", userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT user_id, movie_id, watched_at, progress_percent
+		 FROM watch_history
+		 WHERE user_id = $1
+		 ORDER BY watched_at DESC, movie_id DESC
+		 LIMIT $2 OFFSET $3`,
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	history := []models.WatchHistory{}
+	for rows.Next() {
+		var w models.WatchHistory
+		if err := rows.Scan(&w.UserID, &w.MovieID, &w.WatchedAt, &w.ProgressPercent); err != nil {
+			return nil, 0, err
+		}
+		history = append(history, w)
+	}
+	return history, total, rows.Err()
+}
+
+// GetByUserAndMovie returns sql.ErrNoRows when the user has not watched the movie.
+func (r *WatchHistoryRepository) GetByUserAndMovie(ctx context.Context, userID, movieID int) (*models.WatchHistory, error) {
+	var w models.WatchHistory
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT user_id, movie_id, watched_at, progress_percent
+		 FROM watch_history
+		 WHERE user_id = $1 AND movie_id = $2`,
+		userID, movieID,
+	).Scan(&w.UserID, &w.MovieID, &w.WatchedAt, &w.ProgressPercent)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Delete returns sql.ErrNoRows when there was no entry to remove.
+func (r *WatchHistoryRepository) Delete(ctx context.Context, userID, movieID int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM watch_history WHERE user_id = $1 AND movie_id = $2", userID, movieID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
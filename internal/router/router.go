@@ -3,18 +3,22 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 
+	"golang-project/internal/config"
 	"golang-project/internal/middleware"
 )
 
-func New() *gin.Engine {
+func New(cfg *config.Config) *gin.Engine {
+	live := cfg.Live()
 	r := gin.New()
 	r.Use(
 		middleware.RequestID(),
+		middleware.Locale(),
 		middleware.Logger(),
-		middleware.RateLimit(60),
+		middleware.RateLimitFunc(live.RateLimit),
 		gin.Recovery(),
-		middleware.CORS(),
-		middleware.BodyLimit(1<<20),
+		middleware.CORSFunc(live.CORSAllowedOrigins),
+		middleware.BodyLimit(cfg.MaxBodyBytes),
+		middleware.RetryOnTransient(2),
 	)
 	return r
 }
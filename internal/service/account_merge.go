@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang-project/internal/models"
+)
+
+var (
+	ErrCannotMergeSelf      = errors.New("source_id and target_id must differ")
+	ErrAccountMergeDisabled = errors.New("account merging is not configured")
+)
+
+// AccountMerger moves one account's data onto another and soft-deletes the
+// first, in a single transaction.
+type AccountMerger interface {
+	MergeInto(ctx context.Context, sourceID, targetID int) (reviewsMoved int, err error)
+}
+
+// WithAccountMerger enables UserService.MergeAccounts.
+func WithAccountMerger(merger AccountMerger) Option {
+	return func(o *options) {
+		o.accountMerger = merger
+	}
+}
+
+// MergeAccounts folds sourceID into targetID: reviews and watch history move
+// to the target, except for movies the target already has, and the source
+// account is soft-deleted.
+func (s *UserService) MergeAccounts(ctx context.Context, sourceID, targetID, adminID int) error {
+	if sourceID == targetID {
+		return ErrCannotMergeSelf
+	}
+	if s.merger == nil {
+		return ErrAccountMergeDisabled
+	}
+	for _, id := range []int{sourceID, targetID} {
+		if _, err := s.repo.GetByID(ctx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+	}
+
+	moved, err := s.merger.MergeInto(ctx, sourceID, targetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	recordAudit(ctx, s.audit, &models.AuditLog{
+		UserID:  &targetID,
+		ActorID: &adminID,
+		Event:   "users_merged",
+		Details: fmt.Sprintf("source_id=%d target_id=%d reviews_moved=%d", sourceID, targetID, moved),
+	})
+	return nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"golang-project/internal/actor"
+	"golang-project/internal/models"
+	"golang-project/internal/storage"
+)
+
+// Option configures optional service dependencies.
+type Option func(*options)
+
+type options struct {
+	audit    AuditWriter
+	tokenTTL time.Duration
+	now      func() time.Time
+	notifier NotificationSender
+	users    UserLookup
+	posters  storage.Storage
+
+	impersonateAdmins bool
+	reviewEditWindow  time.Duration
+	cache             CacheInvalidator
+	watches           WatchLookup
+	reviewTargets     ReviewTargetLookup
+	goneForDeleted    bool
+	contentFilter     ContentFilter
+	movieVersions     MovieVersionRepo
+	reactionCounts    ReactionCounter
+	accountMerger     AccountMerger
+	reviewActivity    ReviewActivityRecorder
+	watchCounter      WatchCounter
+}
+
+func WithAuditWriter(audit AuditWriter) Option {
+	return func(o *options) {
+		if audit != nil {
+			o.audit = audit
+		}
+	}
+}
+
+// WithTokenTTL sets the lifetime of tokens issued by AuthService.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		if ttl > 0 {
+			o.tokenTTL = ttl
+		}
+	}
+}
+
+// WithClock replaces time.Now, mainly so tests can control time-based rules.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		if now != nil {
+			o.now = now
+		}
+	}
+}
+
+func applyOptions(opts []Option) options {
+	o := options{audit: noopAuditWriter{}, tokenTTL: 24 * time.Hour, now: time.Now, notifier: noopNotificationSender{}, cache: noopCacheInvalidator{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+type noopAuditWriter struct{}
+
+func (noopAuditWriter) Insert(ctx context.Context, log *models.AuditLog) error {
+	return nil
+}
+
+// recordAudit stamps the entry with the actor from ctx and writes it. Audit
+// failures are logged rather than failing the action that triggered them.
+func recordAudit(ctx context.Context, audit AuditWriter, entry *models.AuditLog) {
+	if id, ok := actor.ID(ctx); ok {
+		entry.ActorID = &id
+	}
+	if err := audit.Insert(ctx, entry); err != nil {
+		log.Printf("audit insert error: %v", err)
+	}
+}
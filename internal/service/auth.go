@@ -14,41 +14,62 @@ import (
 	"golang-project/pkg/jwt"
 )
 
+// ImpersonationTTL is the lifetime of tokens issued by Impersonate. It is
+// deliberately short and independent of the regular token TTL.
+const ImpersonationTTL = 15 * time.Minute
+
 var (
-	ErrUserExists         = errors.New("user already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUserExists             = errors.New("user already exists")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrCannotImpersonateSelf  = errors.New("cannot impersonate yourself")
+	ErrCannotImpersonateAdmin = errors.New("cannot impersonate another admin")
 )
 
+// WithAdminImpersonation lets AuthService.Impersonate issue tokens for other
+// admins, which it refuses by default.
+func WithAdminImpersonation(allow bool) Option {
+	return func(o *options) {
+		o.impersonateAdmins = allow
+	}
+}
+
 type AuthService struct {
 	users     repository.UserRepository
 	validator *validator.Validate
-	jwtSecret string
+	jwtKeys   *jwt.KeySet
 	tokenTTL  time.Duration
+	audit     AuditWriter
+
+	impersonateAdmins bool
 }
 
-func NewAuthService(users repository.UserRepository, validator *validator.Validate, jwtSecret string) *AuthService {
+func NewAuthService(users repository.UserRepository, validator *validator.Validate, jwtKeys *jwt.KeySet, opts ...Option) *AuthService {
+	o := applyOptions(opts)
 	return &AuthService{
 		users:     users,
 		validator: validator,
-		jwtSecret: jwtSecret,
-		tokenTTL:  24 * time.Hour,
+		jwtKeys:   jwtKeys,
+		tokenTTL:  o.tokenTTL,
+		audit:     o.audit,
+
+		impersonateAdmins: o.impersonateAdmins,
 	}
 }
 
-func (s *AuthService) Register(ctx context.Context, req models.CreateUserRequest) (*models.User, string, error) {
+func (s *AuthService) Register(ctx context.Context, req models.CreateUserRequest) (*models.AuthResponse, error) {
 	if err := s.validator.Struct(req); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	if _, err := s.users.GetByEmail(ctx, req.Email); err == nil {
-		return nil, "", ErrUserExists
+		return nil, ErrUserExists
 	} else if !errors.Is(err, sql.ErrNoRows) {
-		return nil, "", err
+		return nil, err
 	}
 
 	hash, err := jwt.HashPassword(req.Password)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	user := &models.User{
@@ -59,38 +80,89 @@ func (s *AuthService) Register(ctx context.Context, req models.CreateUserRequest
 	}
 
 	if err := s.users.Create(ctx, user); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	token, err := jwt.Generate(fmt.Sprintf("%d", user.ID), user.Role, s.jwtSecret, s.tokenTTL)
-	if err != nil {
-		return nil, "", err
-	}
-
-	return user, token, nil
+	return s.issue(user)
 }
 
-func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*models.User, string, error) {
+func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
 	if err := s.validator.Struct(req); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	user, err := s.users.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, "", ErrInvalidCredentials
+			s.recordLogin(ctx, "login_failed", nil, req.Email)
+			return nil, ErrInvalidCredentials
 		}
-		return nil, "", err
+		return nil, err
 	}
 
 	if err := jwt.CheckPassword(user.PasswordHash, req.Password); err != nil {
-		return nil, "", ErrInvalidCredentials
+		s.recordLogin(ctx, "login_failed", &user.ID, req.Email)
+		return nil, ErrInvalidCredentials
+	}
+
+	resp, err := s.issue(user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordLogin(ctx, "login_success", &user.ID, req.Email)
+	return resp, nil
+}
+
+// issue signs a token for user valid for the configured TTL.
+func (s *AuthService) issue(user *models.User) (*models.AuthResponse, error) {
+	token, expiresAt, err := s.jwtKeys.Issue(fmt.Sprintf("%d", user.ID), user.Role, s.tokenTTL)
+	if err != nil {
+		return nil, err
 	}
+	return &models.AuthResponse{
+		User:      user,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		ExpiresIn: int(s.tokenTTL.Seconds()),
+	}, nil
+}
 
-	token, err := jwt.Generate(fmt.Sprintf("%d", user.ID), user.Role, s.jwtSecret, s.tokenTTL)
+// Impersonate issues adminID a token that acts as targetID for
+// ImpersonationTTL. The token carries adminID in its impersonated_by claim
+// and every issuance is audited.
+func (s *AuthService) Impersonate(ctx context.Context, targetID, adminID int) (*models.AuthResponse, error) {
+	if targetID == adminID {
+		return nil, ErrCannotImpersonateSelf
+	}
+	user, err := s.users.GetByID(ctx, targetID)
 	if err != nil {
-		return nil, "", err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Role == "admin" && !s.impersonateAdmins {
+		return nil, ErrCannotImpersonateAdmin
+	}
+
+	token, expiresAt, err := s.jwtKeys.IssueImpersonation(fmt.Sprintf("%d", user.ID), user.Role, fmt.Sprintf("%d", adminID), ImpersonationTTL)
+	if err != nil {
+		return nil, err
 	}
+	recordAudit(ctx, s.audit, &models.AuditLog{
+		UserID:  &user.ID,
+		Event:   "user_impersonated",
+		Details: fmt.Sprintf("impersonated_by=%d expires_at=%s", adminID, expiresAt.Format(time.RFC3339)),
+	})
+	return &models.AuthResponse{
+		User:      user,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		ExpiresIn: int(ImpersonationTTL.Seconds()),
+	}, nil
+}
 
-	return user, token, nil
+func (s *AuthService) recordLogin(ctx context.Context, event string, userID *int, email string) {
+	recordAudit(ctx, s.audit, &models.AuditLog{UserID: userID, Event: event, Details: "email=" + email})
 }
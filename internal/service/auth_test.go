@@ -2,158 +2,23 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 
+	"golang-project/internal/actor"
 	"golang-project/internal/models"
+	"golang-project/internal/testutil"
 	"golang-project/pkg/jwt"
 )
 
-type memoryUserRepo struct {
-	users map[string]*models.User
-}
-
-func newMemoryUserRepo() *memoryUserRepo {
-	return &memoryUserRepo{users: make(map[string]*models.User)}
-}
-
-func (r *memoryUserRepo) Create(ctx context.Context, user *models.User) error {
-	if _, ok := r.users[user.Email]; ok {
-		return errors.New("duplicate")
-	}
-	now := time.Now()
-	user.ID = len(r.users) + 1
-	user.CreatedAt = now
-	user.UpdatedAt = now
-	r.users[user.Email] = user
-	return nil
-}
-
-func (r *memoryUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	if u, ok := r.users[email]; ok {
-		return u, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) GetByID(ctx context.Context, id int) (*models.User, error) {
-	for _, u := range r.users {
-		if u.ID == id {
-			return u, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) List(ctx context.Context, filters models.UserFilters, limit, offset int) ([]models.User, int, error) {
-	result := make([]models.User, 0, len(r.users))
-	for _, u := range r.users {
-		if filters.Search != "" {
-			if !contains(u.Email, filters.Search) && !contains(u.Username, filters.Search) {
-				continue
-			}
-		}
-		if filters.Role != "" && u.Role != filters.Role {
-			continue
-		}
-		result = append(result, *u)
-	}
-	total := len(result)
-	if offset >= total {
-		return []models.User{}, total, nil
-	}
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-	return result[offset:end], total, nil
-}
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0)
-}
-
-func (r *memoryUserRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
-	for _, u := range r.users {
-		if u.Username == username {
-			return u, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) UpdateRole(ctx context.Context, id int, role string) error {
-	for _, u := range r.users {
-		if u.ID == id {
-			u.Role = role
-			return nil
-		}
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) Update(ctx context.Context, id int, email, username string) error {
-	for _, u := range r.users {
-		if u.ID == id {
-			if email != "" {
-				delete(r.users, u.Email)
-				u.Email = email
-				r.users[email] = u
-			}
-			if username != "" {
-				u.Username = username
-			}
-			u.UpdatedAt = time.Now()
-			return nil
-		}
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) Delete(ctx context.Context, id int) error {
-	for email, u := range r.users {
-		if u.ID == id {
-			delete(r.users, email)
-			return nil
-		}
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
-	for _, u := range r.users {
-		if u.ID == id {
-			u.PasswordHash = passwordHash
-			u.UpdatedAt = time.Now()
-			return nil
-		}
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memoryUserRepo) Count(ctx context.Context) (int, error) {
-	return len(r.users), nil
-}
-
-func (r *memoryUserRepo) CountLast7Days(ctx context.Context) (int, error) {
-	count := 0
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
-	for _, u := range r.users {
-		if u.CreatedAt.After(sevenDaysAgo) {
-			count++
-		}
-	}
-	return count, nil
-}
-
 func TestAuthService_Register(t *testing.T) {
 	secret := "test-secret"
-	repo := newMemoryUserRepo()
-	svc := NewAuthService(repo, validator.New(), secret)
+	repo := testutil.NewMemUserRepo()
+	svc := NewAuthService(repo, validator.New(), jwt.StaticKeySet(secret))
 
 	t.Run("ok", func(t *testing.T) {
 		req := models.CreateUserRequest{
@@ -161,14 +26,15 @@ func TestAuthService_Register(t *testing.T) {
 			Username: "user",
 			Password: "password123",
 		}
-		user, token, err := svc.Register(context.Background(), req)
+		resp, err := svc.Register(context.Background(), req)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
+		user := resp.User
 		if user == nil || user.ID == 0 {
 			t.Fatalf("expected user with ID, got %#v", user)
 		}
-		if token == "" {
+		if resp.Token == "" {
 			t.Fatalf("expected token, got empty")
 		}
 		if user.Role != "user" {
@@ -184,14 +50,14 @@ func TestAuthService_Register(t *testing.T) {
 			PasswordHash: "hash",
 			Role:         "user",
 		}
-		repo.users[existing.Email] = existing
+		repo.Add(existing)
 
 		req := models.CreateUserRequest{
 			Email:    existing.Email,
 			Username: "newuser",
 			Password: "password123",
 		}
-		if _, _, err := svc.Register(context.Background(), req); err == nil || !errors.Is(err, ErrUserExists) {
+		if _, err := svc.Register(context.Background(), req); err == nil || !errors.Is(err, ErrUserExists) {
 			t.Fatalf("expected ErrUserExists, got %v", err)
 		}
 	})
@@ -202,7 +68,7 @@ func TestAuthService_Register(t *testing.T) {
 			Username: "us",
 			Password: "123",
 		}
-		if _, _, err := svc.Register(context.Background(), req); err == nil {
+		if _, err := svc.Register(context.Background(), req); err == nil {
 			t.Fatalf("expected validation error")
 		}
 	})
@@ -210,8 +76,8 @@ func TestAuthService_Register(t *testing.T) {
 
 func TestAuthService_Login(t *testing.T) {
 	secret := "test-secret"
-	repo := newMemoryUserRepo()
-	svc := NewAuthService(repo, validator.New(), secret)
+	repo := testutil.NewMemUserRepo()
+	svc := NewAuthService(repo, validator.New(), jwt.StaticKeySet(secret))
 
 	password := "password123"
 	hash, err := jwt.HashPassword(password)
@@ -225,21 +91,21 @@ func TestAuthService_Login(t *testing.T) {
 		PasswordHash: hash,
 		Role:         "user",
 	}
-	repo.users[user.Email] = user
+	repo.Add(user)
 
 	t.Run("ok", func(t *testing.T) {
 		req := models.LoginRequest{
 			Email:    user.Email,
 			Password: password,
 		}
-		u, token, err := svc.Login(context.Background(), req)
+		resp, err := svc.Login(context.Background(), req)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if u == nil || u.ID != user.ID {
+		if u := resp.User; u == nil || u.ID != user.ID {
 			t.Fatalf("expected user %d, got %#v", user.ID, u)
 		}
-		if token == "" {
+		if resp.Token == "" {
 			t.Fatalf("expected token, got empty")
 		}
 	})
@@ -249,7 +115,7 @@ func TestAuthService_Login(t *testing.T) {
 			Email:    user.Email,
 			Password: "wrong",
 		}
-		if _, _, err := svc.Login(context.Background(), req); err == nil || !errors.Is(err, ErrInvalidCredentials) {
+		if _, err := svc.Login(context.Background(), req); err == nil || !errors.Is(err, ErrInvalidCredentials) {
 			t.Fatalf("expected ErrInvalidCredentials, got %v", err)
 		}
 	})
@@ -259,8 +125,136 @@ func TestAuthService_Login(t *testing.T) {
 			Email:    "missing@example.com",
 			Password: password,
 		}
-		if _, _, err := svc.Login(context.Background(), req); err == nil || !errors.Is(err, ErrInvalidCredentials) {
+		if _, err := svc.Login(context.Background(), req); err == nil || !errors.Is(err, ErrInvalidCredentials) {
 			t.Fatalf("expected ErrInvalidCredentials, got %v", err)
 		}
 	})
 }
+
+func TestAuthService_LoginAudit(t *testing.T) {
+	repo := testutil.NewMemUserRepo()
+	audit := &recordingAuditWriter{}
+	svc := NewAuthService(repo, validator.New(), jwt.StaticKeySet("test-secret"), WithAuditWriter(audit))
+
+	hash, err := jwt.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	repo.Add(&models.User{ID: 1, Email: "user@example.com", Username: "user", PasswordHash: hash, Role: "user"})
+
+	attempts := []models.LoginRequest{
+		{Email: "user@example.com", Password: "wrong-password"},
+		{Email: "missing@example.com", Password: "password123"},
+	}
+	for i, req := range attempts {
+		if _, err := svc.Login(context.Background(), req); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: expected ErrInvalidCredentials, got %v", i, err)
+		}
+		if len(audit.logs) != i+1 {
+			t.Fatalf("attempt %d: expected exactly one new audit entry, got %d total", i, len(audit.logs))
+		}
+		entry := audit.logs[i]
+		if entry.Event != "login_failed" || entry.Details != "email="+req.Email {
+			t.Fatalf("attempt %d: unexpected audit entry %+v", i, entry)
+		}
+		if strings.Contains(entry.Details, req.Password) {
+			t.Fatalf("attempt %d: audit details leak the password: %q", i, entry.Details)
+		}
+	}
+
+	if _, err := svc.Login(context.Background(), models.LoginRequest{Email: "user@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	last := audit.logs[len(audit.logs)-1]
+	if len(audit.logs) != 3 || last.Event != "login_success" || last.UserID == nil || *last.UserID != 1 {
+		t.Fatalf("expected login_success for user 1, got %+v", audit.logs)
+	}
+}
+
+func TestAuthService_TokenExpiry(t *testing.T) {
+	keys := jwt.StaticKeySet("test-secret")
+	ttl := 2 * time.Hour
+	svc := NewAuthService(testutil.NewMemUserRepo(), validator.New(), keys, WithTokenTTL(ttl))
+
+	before := time.Now()
+	resp, err := svc.Register(context.Background(), models.CreateUserRequest{Email: "user@example.com", Username: "user", Password: "password123"})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if resp.ExpiresIn != int(ttl.Seconds()) {
+		t.Fatalf("expected expires_in %d, got %d", int(ttl.Seconds()), resp.ExpiresIn)
+	}
+	if resp.ExpiresAt.Before(before.Add(ttl).Add(-time.Second)) || resp.ExpiresAt.After(time.Now().Add(ttl)) {
+		t.Fatalf("expected expires_at about %s from now, got %s", ttl, resp.ExpiresAt)
+	}
+
+	claims, err := keys.Parse(resp.Token)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if !claims.ExpiresAt.Time.Equal(resp.ExpiresAt) {
+		t.Fatalf("expires_at %s does not match the token's exp %s", resp.ExpiresAt, claims.ExpiresAt.Time)
+	}
+}
+
+func TestAuthService_Impersonate(t *testing.T) {
+	repo := testutil.NewMemUserRepo()
+	audit := &recordingAuditWriter{}
+	keys := jwt.StaticKeySet("test-secret")
+	svc := NewAuthService(repo, validator.New(), keys, WithAuditWriter(audit))
+
+	admin := &models.User{ID: 1, Email: "admin@example.com", Username: "admin", Role: "admin"}
+	other := &models.User{ID: 2, Email: "other@example.com", Username: "other", Role: "admin"}
+	user := &models.User{ID: 3, Email: "user@example.com", Username: "user", Role: "user"}
+	repo.Add(admin)
+	repo.Add(other)
+	repo.Add(user)
+	ctx := actor.WithID(context.Background(), admin.ID)
+
+	resp, err := svc.Impersonate(ctx, user.ID, admin.ID)
+	if err != nil {
+		t.Fatalf("impersonate: %v", err)
+	}
+	if resp.User.ID != user.ID || resp.ExpiresIn != int(ImpersonationTTL.Seconds()) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	claims, err := keys.Parse(resp.Token)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if claims.UserID != "3" || claims.Role != "user" || claims.ImpersonatedBy != "1" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > ImpersonationTTL || ttl < ImpersonationTTL-time.Minute {
+		t.Fatalf("expected the token to expire in about %s, got %s", ImpersonationTTL, ttl)
+	}
+
+	if len(audit.logs) != 1 {
+		t.Fatalf("expected one audit entry, got %+v", audit.logs)
+	}
+	entry := audit.logs[0]
+	if entry.Event != "user_impersonated" || *entry.UserID != user.ID || entry.ActorID == nil || *entry.ActorID != admin.ID {
+		t.Fatalf("unexpected audit entry %+v", entry)
+	}
+	if !strings.Contains(entry.Details, "impersonated_by=1") {
+		t.Fatalf("audit details should name the admin, got %q", entry.Details)
+	}
+
+	if _, err := svc.Impersonate(ctx, other.ID, admin.ID); !errors.Is(err, ErrCannotImpersonateAdmin) {
+		t.Fatalf("expected ErrCannotImpersonateAdmin, got %v", err)
+	}
+	if _, err := svc.Impersonate(ctx, admin.ID, admin.ID); !errors.Is(err, ErrCannotImpersonateSelf) {
+		t.Fatalf("expected ErrCannotImpersonateSelf, got %v", err)
+	}
+	if _, err := svc.Impersonate(ctx, 99, admin.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if len(audit.logs) != 1 {
+		t.Fatalf("refused attempts must not be audited as issued, got %+v", audit.logs)
+	}
+
+	super := NewAuthService(repo, validator.New(), keys, WithAdminImpersonation(true))
+	if _, err := super.Impersonate(ctx, other.ID, admin.ID); err != nil {
+		t.Fatalf("expected admin impersonation to be allowed with the flag, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/actor"
+	"golang-project/internal/models"
+)
+
+// BlocklistRefreshInterval is how long BlocklistService keeps using the
+// keywords it loaded before reading them again, so keywords added on
+// another replica take effect within it.
+const BlocklistRefreshInterval = 5 * time.Minute
+
+var (
+	ErrContentBlocked         = errors.New("content contains blocked term")
+	ErrBlockedKeywordNotFound = errors.New("blocked keyword not found")
+)
+
+type BlockedKeywordRepo interface {
+	List(ctx context.Context) ([]models.BlockedKeyword, error)
+	GetByID(ctx context.Context, id int) (*models.BlockedKeyword, error)
+	Create(ctx context.Context, k *models.BlockedKeyword) error
+	Update(ctx context.Context, k *models.BlockedKeyword) error
+	Delete(ctx context.Context, id int) error
+}
+
+// ContentFilter reports which blocked keywords a text contains.
+type ContentFilter interface {
+	Match(ctx context.Context, content string) ([]string, error)
+}
+
+// WithContentFilter makes ReviewService reject reviews whose title or
+// content contains a blocked keyword with ErrContentBlocked.
+func WithContentFilter(filter ContentFilter) Option {
+	return func(o *options) {
+		o.contentFilter = filter
+	}
+}
+
+// BlocklistService manages blocked keywords and matches text against them.
+type BlocklistService struct {
+	repo      BlockedKeywordRepo
+	validator *validator.Validate
+	audit     AuditWriter
+	now       func() time.Time
+
+	mu       sync.Mutex
+	matchers []keywordMatcher
+	loadedAt time.Time
+}
+
+func NewBlocklistService(repo BlockedKeywordRepo, v *validator.Validate, opts ...Option) *BlocklistService {
+	o := applyOptions(opts)
+	return &BlocklistService{repo: repo, validator: v, audit: o.audit, now: o.now}
+}
+
+// keywordMatcher matches one keyword: case-sensitive keywords with a plain
+// substring search, the others with a case-insensitive regexp.
+type keywordMatcher struct {
+	keyword string
+	re      *regexp.Regexp
+}
+
+func newKeywordMatcher(k models.BlockedKeyword) keywordMatcher {
+	m := keywordMatcher{keyword: k.Keyword}
+	if !k.CaseSensitive {
+		m.re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(k.Keyword))
+	}
+	return m
+}
+
+func (m keywordMatcher) matches(text string) bool {
+	if m.re != nil {
+		return m.re.MatchString(text)
+	}
+	return strings.Contains(text, m.keyword)
+}
+
+// Match returns the blocked keywords found in content, in the order they
+// were added.
+func (s *BlocklistService) Match(ctx context.Context, content string) ([]string, error) {
+	matchers, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matched := []string{}
+	for _, m := range matchers {
+		if m.matches(content) {
+			matched = append(matched, m.keyword)
+		}
+	}
+	return matched, nil
+}
+
+// load returns the cached matchers, reloading them once they are older than
+// BlocklistRefreshInterval. If a reload fails the previous list is kept.
+func (s *BlocklistService) load(ctx context.Context) ([]keywordMatcher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && s.now().Sub(s.loadedAt) < BlocklistRefreshInterval {
+		return s.matchers, nil
+	}
+	keywords, err := s.repo.List(ctx)
+	if err != nil {
+		if s.matchers == nil {
+			return nil, err
+		}
+		log.Printf("blocklist reload error: %v", err)
+		return s.matchers, nil
+	}
+	matchers := make([]keywordMatcher, len(keywords))
+	for i, k := range keywords {
+		matchers[i] = newKeywordMatcher(k)
+	}
+	s.matchers, s.loadedAt = matchers, s.now()
+	return matchers, nil
+}
+
+// invalidate makes the next Match reload the keywords, so changes made
+// through this service apply immediately on this replica.
+func (s *BlocklistService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
+
+func (s *BlocklistService) List(ctx context.Context) ([]models.BlockedKeyword, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *BlocklistService) Create(ctx context.Context, req models.BlockedKeywordRequest) (*models.BlockedKeyword, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	k := &models.BlockedKeyword{Keyword: req.Keyword, CaseSensitive: req.CaseSensitive}
+	if id, ok := actor.ID(ctx); ok {
+		k.CreatedBy = &id
+	}
+	if err := s.repo.Create(ctx, k); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "blocked_keyword_created", Details: blockedKeywordAuditDetails(k)})
+	return k, nil
+}
+
+func (s *BlocklistService) Update(ctx context.Context, id int, req models.BlockedKeywordRequest) (*models.BlockedKeyword, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	k, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlockedKeywordNotFound
+		}
+		return nil, err
+	}
+	k.Keyword, k.CaseSensitive = req.Keyword, req.CaseSensitive
+	if err := s.repo.Update(ctx, k); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlockedKeywordNotFound
+		}
+		return nil, err
+	}
+	s.invalidate()
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "blocked_keyword_updated", Details: blockedKeywordAuditDetails(k)})
+	return k, nil
+}
+
+// Delete removes the keyword and returns it as it was before deletion.
+func (s *BlocklistService) Delete(ctx context.Context, id int) (*models.BlockedKeyword, error) {
+	k, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlockedKeywordNotFound
+		}
+		return nil, err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlockedKeywordNotFound
+		}
+		return nil, err
+	}
+	s.invalidate()
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "blocked_keyword_deleted", Details: blockedKeywordAuditDetails(k)})
+	return k, nil
+}
+
+func blockedKeywordAuditDetails(k *models.BlockedKeyword) string {
+	return fmt.Sprintf("blocked_keyword_id=%d keyword=%s case_sensitive=%t", k.ID, k.Keyword, k.CaseSensitive)
+}
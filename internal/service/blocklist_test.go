@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+func TestBlocklistService_RefreshesCachedKeywords(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := testutil.NewMemBlockedKeywordRepo()
+	svc := NewBlocklistService(repo, validator.New(), WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, models.BlockedKeywordRequest{Keyword: "rosebud"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if matched, _ := svc.Match(ctx, "Rosebud was the sled"); len(matched) != 1 {
+		t.Fatalf("expected keyword added through the service to apply at once, got %v", matched)
+	}
+
+	// A keyword added by another replica is picked up on the next refresh.
+	_ = repo.Create(ctx, &models.BlockedKeyword{Keyword: "sled"})
+	if matched, _ := svc.Match(ctx, "the sled"); len(matched) != 0 {
+		t.Fatalf("expected the cached list to be used, got %v", matched)
+	}
+	now = now.Add(BlocklistRefreshInterval)
+	if matched, _ := svc.Match(ctx, "the sled"); len(matched) != 1 {
+		t.Fatalf("expected the list to be reloaded, got %v", matched)
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang-project/internal/models"
+)
+
+// MaxGenresPerImport bounds how many genres one BulkCreate call may contain.
+const MaxGenresPerImport = 1000
+
+var (
+	ErrNoGenresToImport = errors.New("genres required")
+	ErrTooManyGenres    = fmt.Errorf("genres accepts at most %d items", MaxGenresPerImport)
+)
+
+// BulkCreate imports a genre taxonomy. Genres that already exist are
+// skipped and left as they are, parent included. An item that fails
+// validation, names a parent that neither exists nor is part of the import,
+// or whose parents lead back to it is reported in the result's Errors and
+// left out; the rest are written in a single transaction.
+func (s *GenreService) BulkCreate(ctx context.Context, items []models.BulkGenreInput) (*models.BulkGenreResult, error) {
+	if len(items) == 0 {
+		return nil, ErrNoGenresToImport
+	}
+	if len(items) > MaxGenresPerImport {
+		return nil, ErrTooManyGenres
+	}
+
+	items = slices.Clone(items)
+	result := &models.BulkGenreResult{Errors: []models.BulkError{}}
+	fail := func(i int, name string, msgs ...string) {
+		result.Errors = append(result.Errors, models.BulkError{Index: i, Name: name, Errors: msgs})
+	}
+
+	existing := make(map[string]bool)
+	exists := func(name string) (bool, error) {
+		if found, ok := existing[name]; ok {
+			return found, nil
+		}
+		_, err := s.repo.GetByName(ctx, name)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+		existing[name] = err == nil
+		return err == nil, nil
+	}
+
+	// pending maps each name to import to the index of its last occurrence.
+	pending := make(map[string]int)
+	for i := range items {
+		item := &items[i]
+		item.Name = strings.TrimSpace(item.Name)
+		item.Parent = strings.TrimSpace(item.Parent)
+		if err := s.validator.Struct(item); err != nil {
+			fail(i, item.Name, validationMessages(err)...)
+			continue
+		}
+		if item.Parent == item.Name {
+			fail(i, item.Name, "genre cannot be its own parent")
+			continue
+		}
+		if prev, ok := pending[item.Name]; ok {
+			result.Skipped++
+			if item.Parent == "" {
+				item.Parent = items[prev].Parent
+			}
+		}
+		pending[item.Name] = i
+	}
+
+	// Existing genres are not written at all, so only the new ones remain
+	// pending. They can still be parents of new genres.
+	for name := range pending {
+		found, err := exists(name)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			result.Skipped++
+			delete(pending, name)
+		}
+	}
+
+	// Drop items whose parent will not exist or that sit on a parent cycle,
+	// repeating because dropping one can orphan another item of the import
+	// that named it as parent. Only new genres can form a cycle: existing
+	// ones keep their parents, which are never new genres.
+	for dropped := true; dropped; {
+		dropped = false
+		for name, i := range pending {
+			parent := items[i].Parent
+			if parent == "" {
+				continue
+			}
+			if _, ok := pending[parent]; ok {
+				continue
+			}
+			found, err := exists(parent)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				fail(i, name, fmt.Sprintf("parent genre %q not found", parent))
+				delete(pending, name)
+				dropped = true
+			}
+		}
+		for _, name := range parentCycles(items, pending) {
+			fail(pending[name], name, "genre parents form a cycle")
+			delete(pending, name)
+			dropped = true
+		}
+	}
+
+	genres := make([]models.BulkGenreInput, 0, len(pending))
+	for _, i := range pending {
+		result.Created++
+		genres = append(genres, items[i])
+	}
+	// Import in request order so the IDs follow it.
+	sort.Slice(genres, func(a, b int) bool { return pending[genres[a].Name] < pending[genres[b].Name] })
+	sort.Slice(result.Errors, func(a, b int) bool { return result.Errors[a].Index < result.Errors[b].Index })
+
+	if len(genres) > 0 {
+		if err := s.repo.ImportGenres(ctx, genres); err != nil {
+			return nil, err
+		}
+	}
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "genres_imported", Details: fmt.Sprintf("created=%d skipped=%d failed=%d", result.Created, result.Skipped, len(result.Errors))})
+	return result, nil
+}
+
+// parentCycles returns the pending genres whose chain of pending parents
+// leads back to themselves.
+func parentCycles(items []models.BulkGenreInput, pending map[string]int) []string {
+	var cyclic []string
+	for name := range pending {
+		seen := map[string]bool{name: true}
+		for cur := items[pending[name]].Parent; ; cur = items[pending[cur]].Parent {
+			if _, ok := pending[cur]; !ok {
+				break
+			}
+			if cur == name {
+				cyclic = append(cyclic, name)
+				break
+			}
+			if seen[cur] {
+				break
+			}
+			seen[cur] = true
+		}
+	}
+	return cyclic
+}
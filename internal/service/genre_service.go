@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/go-playground/validator/v10"
 
@@ -22,15 +23,19 @@ type GenreRepo interface {
 	Create(ctx context.Context, genre *models.Genre) error
 	Update(ctx context.Context, genre *models.Genre) error
 	Delete(ctx context.Context, id int) error
+	Stats(ctx context.Context, id int) (*models.GenreStats, error)
+	ImportGenres(ctx context.Context, genres []models.BulkGenreInput) error
 }
 
 type GenreService struct {
 	repo      GenreRepo
 	validator *validator.Validate
+	audit     AuditWriter
 }
 
-func NewGenreService(repo GenreRepo, v *validator.Validate) *GenreService {
-	return &GenreService{repo: repo, validator: v}
+func NewGenreService(repo GenreRepo, v *validator.Validate, opts ...Option) *GenreService {
+	o := applyOptions(opts)
+	return &GenreService{repo: repo, validator: v, audit: o.audit}
 }
 
 func (s *GenreService) List(ctx context.Context) ([]models.Genre, error) {
@@ -48,6 +53,18 @@ func (s *GenreService) Get(ctx context.Context, id int) (*models.Genre, error) {
 	return genre, nil
 }
 
+func (s *GenreService) GetWithStats(ctx context.Context, id int) (*models.GenreWithStats, error) {
+	genre, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.Stats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GenreWithStats{Genre: *genre, GenreStats: *stats}, nil
+}
+
 func (s *GenreService) Create(ctx context.Context, req models.CreateGenreRequest) (*models.Genre, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, err
@@ -63,6 +80,7 @@ func (s *GenreService) Create(ctx context.Context, req models.CreateGenreRequest
 	if err := s.repo.Create(ctx, genre); err != nil {
 		return nil, err
 	}
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "genre_created", Details: genreAuditDetails(genre)})
 	return genre, nil
 }
 
@@ -83,15 +101,26 @@ func (s *GenreService) Update(ctx context.Context, id int, req models.CreateGenr
 	if err := s.repo.Update(ctx, genre); err != nil {
 		return nil, err
 	}
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "genre_updated", Details: genreAuditDetails(genre)})
 	return genre, nil
 }
 
-func (s *GenreService) Delete(ctx context.Context, id int) error {
-	if _, err := s.repo.GetByID(ctx, id); err != nil {
+// Delete removes the genre and returns it as it was before deletion.
+func (s *GenreService) Delete(ctx context.Context, id int) (*models.Genre, error) {
+	genre, err := s.repo.GetByID(ctx, id)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrGenreNotFound
+			return nil, ErrGenreNotFound
 		}
-		return err
+		return nil, err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return nil, err
 	}
-	return s.repo.Delete(ctx, id)
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "genre_deleted", Details: genreAuditDetails(genre)})
+	return genre, nil
+}
+
+func genreAuditDetails(genre *models.Genre) string {
+	return fmt.Sprintf("genre_id=%d name=%s", genre.ID, genre.Name)
 }
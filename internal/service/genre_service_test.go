@@ -2,74 +2,19 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 
+	"golang-project/internal/actor"
 	"golang-project/internal/models"
+	"golang-project/internal/testutil"
 )
 
-type memoryGenreRepo struct {
-	data map[int]*models.Genre
-}
-
-func newMemoryGenreRepo() *memoryGenreRepo {
-	return &memoryGenreRepo{data: make(map[int]*models.Genre)}
-}
-
-func (r *memoryGenreRepo) GetAll(ctx context.Context) ([]models.Genre, error) {
-	result := make([]models.Genre, 0, len(r.data))
-	for _, g := range r.data {
-		result = append(result, *g)
-	}
-	return result, nil
-}
-
-func (r *memoryGenreRepo) GetByID(ctx context.Context, id int) (*models.Genre, error) {
-	if g, ok := r.data[id]; ok {
-		return g, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryGenreRepo) GetByName(ctx context.Context, name string) (*models.Genre, error) {
-	for _, g := range r.data {
-		if g.Name == name {
-			return g, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryGenreRepo) Create(ctx context.Context, genre *models.Genre) error {
-	now := time.Now()
-	genre.ID = len(r.data) + 1
-	genre.CreatedAt = now
-	r.data[genre.ID] = genre
-	return nil
-}
-
-func (r *memoryGenreRepo) Update(ctx context.Context, genre *models.Genre) error {
-	if _, ok := r.data[genre.ID]; !ok {
-		return sql.ErrNoRows
-	}
-	r.data[genre.ID] = genre
-	return nil
-}
-
-func (r *memoryGenreRepo) Delete(ctx context.Context, id int) error {
-	if _, ok := r.data[id]; !ok {
-		return sql.ErrNoRows
-	}
-	delete(r.data, id)
-	return nil
-}
-
 func TestGenreService_Create(t *testing.T) {
-	repo := newMemoryGenreRepo()
+	repo := testutil.NewMemGenreRepo()
 	svc := NewGenreService(repo, validator.New())
 
 	t.Run("ok", func(t *testing.T) {
@@ -83,8 +28,9 @@ func TestGenreService_Create(t *testing.T) {
 	})
 
 	t.Run("duplicate", func(t *testing.T) {
-		repo.data = make(map[int]*models.Genre)
-		_ = repo.Create(context.Background(), &models.Genre{ID: 1, Name: "Comedy", CreatedAt: time.Now()})
+		repo := testutil.NewMemGenreRepo()
+		repo.Add(&models.Genre{ID: 1, Name: "Comedy", CreatedAt: time.Now()})
+		svc := NewGenreService(repo, validator.New())
 
 		if _, err := svc.Create(context.Background(), models.CreateGenreRequest{Name: "Comedy"}); !errors.Is(err, ErrGenreExists) {
 			t.Fatalf("expected ErrGenreExists, got %v", err)
@@ -99,11 +45,11 @@ func TestGenreService_Create(t *testing.T) {
 }
 
 func TestGenreService_Get_Update_Delete(t *testing.T) {
-	repo := newMemoryGenreRepo()
+	repo := testutil.NewMemGenreRepo()
 	svc := NewGenreService(repo, validator.New())
 
 	g := &models.Genre{ID: 1, Name: "Action", CreatedAt: time.Now()}
-	repo.data[g.ID] = g
+	repo.Add(g)
 
 	t.Run("get ok", func(t *testing.T) {
 		got, err := svc.Get(context.Background(), g.ID)
@@ -127,14 +73,193 @@ func TestGenreService_Get_Update_Delete(t *testing.T) {
 	})
 
 	t.Run("delete ok", func(t *testing.T) {
-		if err := svc.Delete(context.Background(), g.ID); err != nil {
+		if _, err := svc.Delete(context.Background(), g.ID); err != nil {
 			t.Fatalf("expected delete ok, got %v", err)
 		}
 	})
 
 	t.Run("delete not found", func(t *testing.T) {
-		if err := svc.Delete(context.Background(), 9999); !errors.Is(err, ErrGenreNotFound) {
+		if _, err := svc.Delete(context.Background(), 9999); !errors.Is(err, ErrGenreNotFound) {
 			t.Fatalf("expected ErrGenreNotFound, got %v", err)
 		}
 	})
 }
+
+func TestGenreService_GetWithStats(t *testing.T) {
+	repo := testutil.NewMemGenreRepo()
+	svc := NewGenreService(repo, validator.New())
+
+	repo.Add(&models.Genre{ID: 1, Name: "Drama", CreatedAt: time.Now()})
+	repo.SetStats(1, models.GenreStats{MovieCount: 3, ReviewCount: 7})
+
+	t.Run("ok", func(t *testing.T) {
+		got, err := svc.GetWithStats(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.Name != "Drama" || got.MovieCount != 3 || got.ReviewCount != 7 {
+			t.Fatalf("unexpected genre stats %+v", got)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, err := svc.GetWithStats(context.Background(), 9999); !errors.Is(err, ErrGenreNotFound) {
+			t.Fatalf("expected ErrGenreNotFound, got %v", err)
+		}
+	})
+}
+
+type recordingAuditWriter struct {
+	logs []models.AuditLog
+}
+
+func (w *recordingAuditWriter) Insert(ctx context.Context, log *models.AuditLog) error {
+	w.logs = append(w.logs, *log)
+	return nil
+}
+
+func TestGenreService_AuditActor(t *testing.T) {
+	repo := testutil.NewMemGenreRepo()
+	audit := &recordingAuditWriter{}
+	svc := NewGenreService(repo, validator.New(), WithAuditWriter(audit))
+
+	ctx := actor.WithID(context.Background(), 42)
+	genre, err := svc.Create(ctx, models.CreateGenreRequest{Name: "Noir"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Delete(context.Background(), genre.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if len(audit.logs) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(audit.logs))
+	}
+	if audit.logs[0].Event != "genre_created" || audit.logs[0].ActorID == nil || *audit.logs[0].ActorID != 42 {
+		t.Fatalf("expected genre_created by actor 42, got %+v", audit.logs[0])
+	}
+	if audit.logs[1].Event != "genre_deleted" || audit.logs[1].ActorID != nil {
+		t.Fatalf("expected genre_deleted without actor, got %+v", audit.logs[1])
+	}
+}
+
+func TestGenreService_BulkCreate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("skips existing", func(t *testing.T) {
+		repo := testutil.NewMemGenreRepo()
+		for _, name := range []string{"Action", "Drama", "Comedy"} {
+			repo.Add(&models.Genre{Name: name})
+		}
+		svc := NewGenreService(repo, validator.New())
+
+		names := []string{"Action", "Drama", "Comedy", "Horror", "Thriller", "Western", "Noir", "Musical", "Romance", "Sci-Fi"}
+		items := make([]models.BulkGenreInput, len(names))
+		for i, name := range names {
+			items[i] = models.BulkGenreInput{Name: name}
+		}
+		result, err := svc.BulkCreate(ctx, items)
+		if err != nil {
+			t.Fatalf("import: %v", err)
+		}
+		if result.Created != 7 || result.Skipped != 3 || len(result.Errors) != 0 {
+			t.Fatalf("expected created=7 skipped=3, got %+v", result)
+		}
+		if n, _ := repo.Count(ctx); n != 10 {
+			t.Fatalf("expected 10 genres, got %d", n)
+		}
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		repo := testutil.NewMemGenreRepo()
+		repo.Add(&models.Genre{Name: "Entertainment"})
+		svc := NewGenreService(repo, validator.New())
+
+		result, err := svc.BulkCreate(ctx, []models.BulkGenreInput{
+			{Name: "Drama", Parent: "Entertainment"},
+			{Name: "Ghost Story", Parent: "Supernatural"},
+			{Name: "Slasher", Parent: "Horror"},
+			{Name: "Horror", Parent: "Entertainment"},
+			{Name: ""},
+		})
+		if err != nil {
+			t.Fatalf("import: %v", err)
+		}
+		if result.Created != 3 || result.Skipped != 0 {
+			t.Fatalf("expected created=3 skipped=0, got %+v", result)
+		}
+		if len(result.Errors) != 2 || result.Errors[0].Index != 1 || result.Errors[0].Name != "Ghost Story" || result.Errors[1].Index != 4 {
+			t.Fatalf("expected errors for items 1 and 4, got %+v", result.Errors)
+		}
+		if _, err := repo.GetByName(ctx, "Ghost Story"); err == nil {
+			t.Fatal("genre with an unknown parent must not be created")
+		}
+
+		parent, _ := repo.GetByName(ctx, "Horror")
+		slasher, err := repo.GetByName(ctx, "Slasher")
+		if err != nil || slasher.ParentID == nil || *slasher.ParentID != parent.ID {
+			t.Fatalf("expected Slasher under Horror, got %+v (%v)", slasher, err)
+		}
+	})
+
+	t.Run("existing genres keep their parent", func(t *testing.T) {
+		repo := testutil.NewMemGenreRepo()
+		entertainment := &models.Genre{Name: "Entertainment"}
+		repo.Add(entertainment)
+		parentID := entertainment.ID
+		repo.Add(&models.Genre{Name: "Drama", ParentID: &parentID})
+		repo.Add(&models.Genre{Name: "Comedy"})
+		svc := NewGenreService(repo, validator.New())
+
+		result, err := svc.BulkCreate(ctx, []models.BulkGenreInput{
+			{Name: "Drama", Parent: "Comedy"},
+			{Name: "Comedy", Parent: "Satire"},
+			{Name: "Satire"},
+		})
+		if err != nil {
+			t.Fatalf("import: %v", err)
+		}
+		if result.Created != 1 || result.Skipped != 2 || len(result.Errors) != 0 {
+			t.Fatalf("expected created=1 skipped=2, got %+v", result)
+		}
+		drama, _ := repo.GetByName(ctx, "Drama")
+		comedy, _ := repo.GetByName(ctx, "Comedy")
+		if drama.ParentID == nil || *drama.ParentID != entertainment.ID || comedy.ParentID != nil {
+			t.Fatalf("expected skipped genres to keep their parents, got Drama %v and Comedy %v", drama.ParentID, comedy.ParentID)
+		}
+	})
+
+	t.Run("parent cycle", func(t *testing.T) {
+		repo := testutil.NewMemGenreRepo()
+		svc := NewGenreService(repo, validator.New())
+
+		result, err := svc.BulkCreate(ctx, []models.BulkGenreInput{
+			{Name: "A", Parent: "B"},
+			{Name: "B", Parent: "C"},
+			{Name: "C", Parent: "A"},
+			{Name: "D", Parent: "A"},
+			{Name: "E"},
+		})
+		if err != nil {
+			t.Fatalf("import: %v", err)
+		}
+		if result.Created != 1 || len(result.Errors) != 4 {
+			t.Fatalf("expected only E created and four errors, got %+v", result)
+		}
+		for i, e := range result.Errors {
+			if e.Index != i {
+				t.Fatalf("expected errors for items 0 to 3, got %+v", result.Errors)
+			}
+		}
+		if n, _ := repo.Count(ctx); n != 1 {
+			t.Fatalf("expected only E to be stored, got %d genres", n)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		svc := NewGenreService(testutil.NewMemGenreRepo(), validator.New())
+		if _, err := svc.BulkCreate(ctx, nil); !errors.Is(err, ErrNoGenresToImport) {
+			t.Fatalf("expected ErrNoGenresToImport, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"golang-project/internal/actor"
+	"golang-project/internal/models"
+)
+
+var ErrMovieVersionNotFound = errors.New("movie version not found")
+
+// MovieVersionRepo stores the snapshots MovieService.Update takes before
+// changing a movie.
+type MovieVersionRepo interface {
+	// UpdateMovie writes movie and, atomically with it, stores the
+	// details it replaces as a version edited by editedByUserID.
+	UpdateMovie(ctx context.Context, movie *models.Movie, editedByUserID *int) error
+	ListByMovie(ctx context.Context, movieID, limit, offset int) ([]models.MovieVersion, int, error)
+	GetByID(ctx context.Context, movieID, id int) (*models.MovieVersion, error)
+}
+
+// WithMovieVersions makes MovieService.Update keep a version of the movie
+// as it was before each update.
+func WithMovieVersions(versions MovieVersionRepo) Option {
+	return func(o *options) {
+		o.movieVersions = versions
+	}
+}
+
+// writeMovie stores movie, keeping the details it replaces as a version
+// when versioning is on.
+func (s *MovieService) writeMovie(ctx context.Context, movie *models.Movie) error {
+	if s.versions == nil {
+		return s.movies.Update(ctx, movie)
+	}
+	var editedBy *int
+	if id, ok := actor.ID(ctx); ok {
+		editedBy = &id
+	}
+	return s.versions.UpdateMovie(ctx, movie, editedBy)
+}
+
+// History lists the versions of a movie, newest first.
+func (s *MovieService) History(ctx context.Context, movieID, page, limit int) (*models.PaginatedResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if _, err := s.movies.GetByID(ctx, movieID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+
+	versions, total := []models.MovieVersion{}, 0
+	if s.versions != nil {
+		var err error
+		if versions, total, err = s.versions.ListByMovie(ctx, movieID, limit, (page-1)*limit); err != nil {
+			return nil, err
+		}
+	}
+	return &models.PaginatedResponse{
+		Data:       versions,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+// Revert writes the details stored in one of a movie's versions back
+// verbatim, empty and zero values included; genres and the poster are not
+// versioned and stay as they are. The revert gets a version of its own.
+func (s *MovieService) Revert(ctx context.Context, movieID, versionID int) (*models.Movie, error) {
+	if s.versions == nil {
+		return nil, ErrMovieVersionNotFound
+	}
+	v, err := s.versions.GetByID(ctx, movieID, versionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMovieVersionNotFound
+		}
+		return nil, err
+	}
+	movie, err := s.movies.GetByID(ctx, movieID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+	movie.Title = v.Title
+	movie.Description = v.Description
+	movie.ReleaseYear = v.ReleaseYear
+	movie.Director = v.Director
+	movie.DurationMinutes = v.DurationMinutes
+	if err := s.writeMovie(ctx, movie); err != nil {
+		return nil, err
+	}
+	if movie.Genres, err = s.movies.GetGenresByMovieID(ctx, movie.ID); err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, s.audit, &models.AuditLog{MovieID: &movie.ID, Event: "movie_updated", Details: movie.Title})
+	return movie, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+// MoviePublishInterval is how often MoviePublisherScheduler looks for
+// movies that are due.
+const MoviePublishInterval = time.Minute
+
+// moviePublishBatchSize bounds how many movies one tick publishes; the
+// rest wait for the next tick.
+const moviePublishBatchSize = 100
+
+type MoviePublicationRepo interface {
+	ListDueForPublication(ctx context.Context, now time.Time, limit int) ([]int, error)
+}
+
+type MovieApprover interface {
+	Approve(ctx context.Context, id int) (*models.Movie, error)
+}
+
+// MoviePublisherScheduler publishes pending movies once their published_at
+// has passed. Ticks never overlap within a process; across replicas the
+// conditional update behind Approve keeps a movie from being published twice.
+type MoviePublisherScheduler struct {
+	movies   MoviePublicationRepo
+	approver MovieApprover
+	now      func() time.Time
+	interval time.Duration
+
+	mu sync.Mutex
+}
+
+func NewMoviePublisherScheduler(movies MoviePublicationRepo, approver MovieApprover, opts ...Option) *MoviePublisherScheduler {
+	o := applyOptions(opts)
+	return &MoviePublisherScheduler{movies: movies, approver: approver, now: o.now, interval: MoviePublishInterval}
+}
+
+// Start runs Tick every MoviePublishInterval until ctx is cancelled.
+func (s *MoviePublisherScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Tick(ctx); err != nil {
+					log.Printf("movie publisher: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Tick publishes the movies that are due now and returns how many it
+// processed. A movie that fails to publish is logged and retried next tick.
+func (s *MoviePublisherScheduler) Tick(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids, err := s.movies.ListDueForPublication(ctx, s.now(), moviePublishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, id := range ids {
+		if _, err := s.approver.Approve(ctx, id); err != nil {
+			log.Printf("movie publisher: publish movie %d: %v", id, err)
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+func TestMoviePublisherScheduler_Tick(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	movies := testutil.NewMemMovieRepo()
+	genres := testutil.NewMemGenreRepo()
+	genres.Add(&models.Genre{ID: 1, Name: "Drama"})
+	audit := testutil.NewMemAuditRepo()
+	svc := NewMovieService(movies, genres, validator.New(), WithAuditWriter(audit), clock)
+	ctx := context.Background()
+
+	due := now.Add(time.Second)
+	later := now.Add(24 * time.Hour)
+	create := func(title string, publishAt time.Time) *models.Movie {
+		t.Helper()
+		movie, err := svc.Create(ctx, models.CreateMovieRequest{Title: title, ReleaseYear: 2025, DurationMinutes: 100, GenreIDs: []string{"1"}, PublishedAt: &publishAt}, false)
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		if movie.Status != models.MovieStatusPending {
+			t.Fatalf("%s: expected pending, got %q", title, movie.Status)
+		}
+		return movie
+	}
+	dueMovie := create("Due", due)
+	laterMovie := create("Later", later)
+
+	if page, _ := svc.List(ctx, models.MovieFilters{}, 1, 10); page.Total != 0 {
+		t.Fatalf("pending movies must not be listed, got %d", page.Total)
+	}
+
+	// The movie became due a second ago.
+	now = due.Add(time.Second)
+	scheduler := NewMoviePublisherScheduler(movies, svc, clock)
+	n, err := scheduler.Tick(ctx)
+	if err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 movie published, got %d", n)
+	}
+	if got, _ := svc.Get(ctx, dueMovie.ID); got.Status != models.MovieStatusPublished {
+		t.Fatalf("expected due movie published, got %q", got.Status)
+	}
+	if got, _ := svc.Get(ctx, laterMovie.ID); got.Status != models.MovieStatusPending {
+		t.Fatalf("expected later movie still pending, got %q", got.Status)
+	}
+	if page, _ := svc.List(ctx, models.MovieFilters{}, 1, 10); page.Total != 1 {
+		t.Fatalf("expected the published movie to be listed, got %d", page.Total)
+	}
+
+	if n, _ := scheduler.Tick(ctx); n != 0 {
+		t.Fatalf("expected nothing left to publish, got %d", n)
+	}
+	published := 0
+	for _, entry := range audit.Logs() {
+		if entry.Event == "movie_published" {
+			published++
+		}
+	}
+	if published != 1 {
+		t.Fatalf("expected one movie_published audit entry, got %d", published)
+	}
+}
+
+func TestPendingMoviesAreHidden(t *testing.T) {
+	movies := testutil.NewMemMovieRepo()
+	pending := &models.Movie{Title: "Soon", Status: models.MovieStatusPending}
+	published := &models.Movie{Title: "Out", Status: models.MovieStatusPublished}
+	movies.Add(pending)
+	movies.Add(published)
+	v := validator.New()
+	svc := NewMovieService(movies, testutil.NewMemGenreRepo(), v)
+	ctx := context.Background()
+
+	if _, err := svc.GetVisible(ctx, pending.ID, false); !errors.Is(err, ErrMovieNotFound) {
+		t.Fatalf("pending movie for a user: expected ErrMovieNotFound, got %v", err)
+	}
+	if _, err := svc.GetVisible(ctx, pending.ID, true); err != nil {
+		t.Fatalf("pending movie for an admin: %v", err)
+	}
+	if found, err := svc.GetByIDs(ctx, []int{pending.ID, published.ID}); err != nil || len(found) != 1 || found[0].ID != published.ID {
+		t.Fatalf("by ids: expected only the published movie, got %+v, %v", found, err)
+	}
+
+	reviews := NewReviewService(testutil.NewMemReviewRepo(), movies, v, nil)
+	req := models.CreateReviewRequest{Rating: 8, Title: "Early", Content: "Saw it at a screening"}
+	if _, err := reviews.Create(ctx, pending.ID, 2, req, true); !errors.Is(err, ErrMovieNotFound) {
+		t.Fatalf("review of a pending movie: expected ErrMovieNotFound, got %v", err)
+	}
+
+	watches := NewWatchHistoryService(testutil.NewMemWatchHistoryRepo(), movies, v)
+	if _, err := watches.RecordWatch(ctx, 2, models.RecordWatchRequest{MovieID: pending.ID}); !errors.Is(err, ErrMovieNotFound) {
+		t.Fatalf("watch of a pending movie: expected ErrMovieNotFound, got %v", err)
+	}
+}
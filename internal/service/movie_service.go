@@ -4,47 +4,132 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"maps"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 
+	"golang-project/internal/actor"
 	"golang-project/internal/models"
+	"golang-project/internal/storage"
 )
 
+// GenreCountsTTL is how long MovieService.GenreCounts reuses its result, so
+// counts may lag behind new movies by up to this long.
+const GenreCountsTTL = 5 * time.Minute
+
+// MaxDirectorFilterValues bounds how many directors a single list query may match.
+const MaxDirectorFilterValues = 20
+
+// MaxMovieIDsPerRequest bounds how many movies GetByIDs loads at once.
+const MaxMovieIDsPerRequest = 100
+
 var (
 	ErrMovieNotFound    = errors.New("movie not found")
+	ErrDirectorNotFound = errors.New("director not found")
+	ErrMovieGone        = errors.New("movie has been deleted")
+	ErrMovieExists      = errors.New("movie already exists")
 	ErrNoGenresProvided = errors.New("at least one genre required")
+	ErrTooManyDirectors = fmt.Errorf("director_in accepts at most %d values", MaxDirectorFilterValues)
+	ErrTooManyMovieIDs  = fmt.Errorf("ids accepts at most %d values", MaxMovieIDsPerRequest)
 )
 
 type MovieRepo interface {
 	List(ctx context.Context, filters models.MovieFilters, limit, offset int) ([]models.Movie, int, error)
+	ListByDirector(ctx context.Context, director string, limit, offset int) ([]models.Movie, int, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.Movie, error)
 	GetByID(ctx context.Context, id int) (*models.Movie, error)
 	Create(ctx context.Context, movie *models.Movie) error
 	Update(ctx context.Context, movie *models.Movie) error
 	Delete(ctx context.Context, id int) error
 	SetGenres(ctx context.Context, movieID int, genreIDs []int) error
 	GetGenresByMovieID(ctx context.Context, movieID int) ([]models.Genre, error)
+	GetGenresByMovieIDs(ctx context.Context, movieIDs []int) (map[int][]models.Genre, error)
+	GetMostControversialMovies(ctx context.Context, limit int) ([]models.Movie, error)
+	GetDirectorCoOccurrences(ctx context.Context, director string, limit int) ([]models.SimilarDirector, error)
+	GetDirectorStats(ctx context.Context, director string) (*models.DirectorStats, error)
+	ListDirectors(ctx context.Context, query string, limit int) ([]models.DirectorCount, error)
+	ExistsByTitleYear(ctx context.Context, title string, year int) (bool, error)
+	UpdatePosterURL(ctx context.Context, id int, posterURL string) error
+	SetFeatured(ctx context.Context, id int, featured bool) error
+	ListFeatured(ctx context.Context, limit int) ([]models.Movie, error)
+	CountByGenre(ctx context.Context, genreIDs []int) (map[int]int, error)
+	Publish(ctx context.Context, id int) (bool, error)
+	IsDeleted(ctx context.Context, id int) (bool, error)
+}
+
+// WithGoneForDeletedMovies makes MovieService.Get return ErrMovieGone
+// instead of ErrMovieNotFound for movies that existed and were deleted.
+func WithGoneForDeletedMovies(enabled bool) Option {
+	return func(o *options) {
+		o.goneForDeleted = enabled
+	}
+}
+
+// WatchLookup tells MovieService.GetWithUserContext whether a user has
+// watched a movie.
+type WatchLookup interface {
+	GetByUserAndMovie(ctx context.Context, userID, movieID int) (*models.WatchHistory, error)
+}
+
+// WithWatchHistory makes MovieService.GetWithUserContext set Movie.Watched.
+func WithWatchHistory(watches WatchLookup) Option {
+	return func(o *options) {
+		o.watches = watches
+	}
 }
 
 type GenreLookup interface {
 	GetByID(ctx context.Context, id int) (*models.Genre, error)
+	GetAll(ctx context.Context) ([]models.Genre, error)
 }
 
 type MovieService struct {
 	movies    MovieRepo
 	genres    GenreLookup
 	validator *validator.Validate
+	audit     AuditWriter
+	posters   storage.Storage
+	cache     CacheInvalidator
+	watches   WatchLookup
+	versions  MovieVersionRepo
+	now       func() time.Time
+
+	goneForDeleted bool
+	genreCounts    genreCountCache
 }
 
-func NewMovieService(movies MovieRepo, genres GenreLookup, v *validator.Validate) *MovieService {
+type genreCountCache struct {
+	mu      sync.Mutex
+	counts  map[int]int
+	expires time.Time
+}
+
+func NewMovieService(movies MovieRepo, genres GenreLookup, v *validator.Validate, opts ...Option) *MovieService {
+	o := applyOptions(opts)
 	return &MovieService{
 		movies:    movies,
 		genres:    genres,
 		validator: v,
+		audit:     o.audit,
+		posters:   o.posters,
+		cache:     o.cache,
+		watches:   o.watches,
+		versions:  o.movieVersions,
+		now:       o.now,
+
+		goneForDeleted: o.goneForDeleted,
 	}
 }
 
 func (s *MovieService) List(ctx context.Context, filters models.MovieFilters, page, limit int) (*models.PaginatedResponse, error) {
+	if len(filters.DirectorIn) > MaxDirectorFilterValues {
+		return nil, ErrTooManyDirectors
+	}
 	if page <= 0 {
 		page = 1
 	}
@@ -68,11 +153,215 @@ func (s *MovieService) List(ctx context.Context, filters models.MovieFilters, pa
 	}, nil
 }
 
+// GenreCounts returns the number of published movies in every genre,
+// including genres without movies.
+func (s *MovieService) GenreCounts(ctx context.Context) (map[int]int, error) {
+	c := &s.genreCounts
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts != nil && s.now().Before(c.expires) {
+		return maps.Clone(c.counts), nil
+	}
+
+	genres, err := s.genres.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(genres))
+	for i, g := range genres {
+		ids[i] = g.ID
+	}
+	counts, err := s.movies.CountByGenre(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if _, ok := counts[id]; !ok {
+			counts[id] = 0
+		}
+	}
+	c.counts, c.expires = counts, s.now().Add(GenreCountsTTL)
+	return maps.Clone(counts), nil
+}
+
+// GetByIDs loads the given movies in the order requested. Duplicates are
+// collapsed and IDs that do not exist are left out.
+func (s *MovieService) GetByIDs(ctx context.Context, ids []int) ([]models.Movie, error) {
+	if len(ids) > MaxMovieIDsPerRequest {
+		return nil, ErrTooManyMovieIDs
+	}
+	unique := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return []models.Movie{}, nil
+	}
+
+	found, err := s.movies.GetByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Movie, len(found))
+	for _, m := range found {
+		byID[m.ID] = m
+	}
+	movies := make([]models.Movie, 0, len(found))
+	for _, id := range unique {
+		if m, ok := byID[id]; ok {
+			movies = append(movies, m)
+		}
+	}
+	return movies, nil
+}
+
+// GenresByMovieIDs loads the genres of several movies in one query.
+func (s *MovieService) GenresByMovieIDs(ctx context.Context, movieIDs []int) (map[int][]models.Genre, error) {
+	if len(movieIDs) == 0 {
+		return map[int][]models.Genre{}, nil
+	}
+	return s.movies.GetGenresByMovieIDs(ctx, movieIDs)
+}
+
+// GetDirectorFilmography lists a director's movies; the name is matched
+// case-insensitively.
+func (s *MovieService) GetDirectorFilmography(ctx context.Context, director string, page, limit int) (*models.PaginatedResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	movies, total, err := s.movies.ListByDirector(ctx, strings.TrimSpace(director), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + limit - 1) / limit
+	return &models.PaginatedResponse{
+		Data:       movies,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// FindSimilarDirectors returns the directors most often reviewed by the same
+// users as director, best match first.
+func (s *MovieService) FindSimilarDirectors(ctx context.Context, director string, limit int) ([]models.SimilarDirector, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 50)
+	return s.movies.GetDirectorCoOccurrences(ctx, strings.TrimSpace(director), limit)
+}
+
+// ListDirectors returns the directors whose name contains query, with how
+// many movies each has, for autocomplete.
+func (s *MovieService) ListDirectors(ctx context.Context, query string, limit int) ([]models.DirectorCount, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 50)
+	return s.movies.ListDirectors(ctx, strings.TrimSpace(query), limit)
+}
+
+// GetDirectorStats summarizes a director's movies; the name is matched
+// case-insensitively. A director with no published movies is
+// ErrDirectorNotFound.
+func (s *MovieService) GetDirectorStats(ctx context.Context, director string) (*models.DirectorStats, error) {
+	stats, err := s.movies.GetDirectorStats(ctx, strings.TrimSpace(director))
+	if err != nil {
+		return nil, err
+	}
+	if stats.MovieCount == 0 {
+		return nil, ErrDirectorNotFound
+	}
+	return stats, nil
+}
+
+// ListControversial returns movies whose reviews disagree the most in sentiment.
+func (s *MovieService) ListControversial(ctx context.Context, limit int) ([]models.Movie, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 50)
+	movies, err := s.movies.GetMostControversialMovies(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	if movies == nil {
+		movies = []models.Movie{}
+	}
+	return movies, nil
+}
+
+func (s *MovieService) ListFeatured(ctx context.Context, limit int) ([]models.Movie, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 50)
+	movies, err := s.movies.ListFeatured(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	if movies == nil {
+		movies = []models.Movie{}
+	}
+	return movies, nil
+}
+
+// SetFeatured pins the movie to, or removes it from, the featured list on
+// the homepage and returns the updated movie.
+func (s *MovieService) SetFeatured(ctx context.Context, id int, featured bool) (*models.Movie, error) {
+	if err := s.movies.SetFeatured(ctx, id, featured); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+	event := "movie_featured"
+	if !featured {
+		event = "movie_unfeatured"
+	}
+	movie, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, s.audit, &models.AuditLog{MovieID: &movie.ID, Event: event, Details: movie.Title})
+	return movie, nil
+}
+
+// Approve publishes a pending movie. Publishing a movie that is already
+// published is not an error; only the call that actually publishes it
+// records the audit entry.
+func (s *MovieService) Approve(ctx context.Context, id int) (*models.Movie, error) {
+	published, err := s.movies.Publish(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	movie, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if published {
+		recordAudit(ctx, s.audit, &models.AuditLog{MovieID: &movie.ID, Event: "movie_published", Details: movie.Title})
+	}
+	return movie, nil
+}
+
 func (s *MovieService) Get(ctx context.Context, id int) (*models.Movie, error) {
 	movie, err := s.movies.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrMovieNotFound
+			return nil, s.notFound(ctx, id)
 		}
 		return nil, err
 	}
@@ -85,7 +374,55 @@ func (s *MovieService) Get(ctx context.Context, id int) (*models.Movie, error) {
 	return movie, nil
 }
 
-func (s *MovieService) Create(ctx context.Context, req models.CreateMovieRequest) (*models.Movie, error) {
+// GetVisible is Get for a caller of the public API: a pending movie is only
+// visible to admins and is ErrMovieNotFound for everyone else.
+func (s *MovieService) GetVisible(ctx context.Context, id int, isAdmin bool) (*models.Movie, error) {
+	movie, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if movie.Status == models.MovieStatusPending && !isAdmin {
+		return nil, ErrMovieNotFound
+	}
+	return movie, nil
+}
+
+// GetWithUserContext is GetVisible with Watched set for userID. Anonymous callers
+// (userID 0) and services built without WithWatchHistory get the plain movie.
+func (s *MovieService) GetWithUserContext(ctx context.Context, id, userID int, isAdmin bool) (*models.Movie, error) {
+	movie, err := s.GetVisible(ctx, id, isAdmin)
+	if err != nil || userID == 0 || s.watches == nil {
+		return movie, err
+	}
+	_, err = s.watches.GetByUserAndMovie(ctx, userID, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	watched := err == nil
+	movie.Watched = &watched
+	return movie, nil
+}
+
+// notFound picks the error for a movie GetByID could not find: ErrMovieGone
+// if it was deleted and WithGoneForDeletedMovies is on, ErrMovieNotFound
+// otherwise.
+func (s *MovieService) notFound(ctx context.Context, id int) error {
+	if !s.goneForDeleted {
+		return ErrMovieNotFound
+	}
+	deleted, err := s.movies.IsDeleted(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		return ErrMovieGone
+	}
+	return ErrMovieNotFound
+}
+
+// Create adds a movie. A movie with the same normalized title and release year
+// is rejected with ErrMovieExists unless allowDuplicate is set.
+func (s *MovieService) Create(ctx context.Context, req models.CreateMovieRequest, allowDuplicate bool) (*models.Movie, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, err
 	}
@@ -93,17 +430,36 @@ func (s *MovieService) Create(ctx context.Context, req models.CreateMovieRequest
 		return nil, ErrNoGenresProvided
 	}
 
+	if !allowDuplicate {
+		exists, err := s.movies.ExistsByTitleYear(ctx, normalizeTitle(req.Title), req.ReleaseYear)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrMovieExists
+		}
+	}
+
 	genreIDs, err := s.validateGenreIDs(ctx, req.GenreIDs)
 	if err != nil {
 		return nil, err
 	}
 
 	movie := &models.Movie{
-		Title:           req.Title,
-		Description:     req.Description,
-		ReleaseYear:     req.ReleaseYear,
-		Director:        req.Director,
-		DurationMinutes: req.DurationMinutes,
+		Title:              req.Title,
+		Description:        req.Description,
+		ReleaseYear:        req.ReleaseYear,
+		Director:           req.Director,
+		DurationMinutes:    req.DurationMinutes,
+		ReviewEmbargoUntil: req.ReviewEmbargoUntil,
+		Status:             models.MovieStatusPublished,
+		PublishedAt:        req.PublishedAt,
+	}
+	if req.PublishedAt != nil && req.PublishedAt.After(s.now()) {
+		movie.Status = models.MovieStatusPending
+	}
+	if id, ok := actor.ID(ctx); ok {
+		movie.SubmittedByUserID = id
 	}
 
 	if err := s.movies.Create(ctx, movie); err != nil {
@@ -120,6 +476,8 @@ func (s *MovieService) Create(ctx context.Context, req models.CreateMovieRequest
 		}
 		movie.Genres = append(movie.Genres, *g)
 	}
+	recordAudit(ctx, s.audit, &models.AuditLog{MovieID: &movie.ID, Event: "movie_created", Details: movie.Title})
+	s.cache.Invalidate(AdminStatsCacheKey)
 	return movie, nil
 }
 
@@ -148,19 +506,27 @@ func (s *MovieService) Update(ctx context.Context, id int, req models.UpdateMovi
 	if req.DurationMinutes != 0 {
 		movie.DurationMinutes = req.DurationMinutes
 	}
-
-	if err := s.movies.Update(ctx, movie); err != nil {
-		return nil, err
+	if req.ReviewEmbargoUntil != nil || req.ClearReviewEmbargo {
+		movie.ReviewEmbargoUntil = req.ReviewEmbargoUntil
 	}
 
+	// Genres are checked before anything is written, so a rejected update
+	// leaves neither a changed movie nor a version behind.
+	var genreIDs []int
 	if req.GenreIDs != nil {
 		if len(req.GenreIDs) == 0 {
 			return nil, ErrNoGenresProvided
 		}
-		genreIDs, err := s.validateGenreIDs(ctx, req.GenreIDs)
-		if err != nil {
+		if genreIDs, err = s.validateGenreIDs(ctx, req.GenreIDs); err != nil {
 			return nil, err
 		}
+	}
+
+	if err := s.writeMovie(ctx, movie); err != nil {
+		return nil, err
+	}
+
+	if req.GenreIDs != nil {
 		if err := s.movies.SetGenres(ctx, movie.ID, genreIDs); err != nil {
 			return nil, err
 		}
@@ -180,17 +546,52 @@ func (s *MovieService) Update(ctx context.Context, id int, req models.UpdateMovi
 		movie.Genres = genres
 	}
 
+	recordAudit(ctx, s.audit, &models.AuditLog{MovieID: &movie.ID, Event: "movie_updated", Details: movie.Title})
 	return movie, nil
 }
 
-func (s *MovieService) Delete(ctx context.Context, id int) error {
-	if _, err := s.movies.GetByID(ctx, id); err != nil {
+// Delete removes the movie and its poster and returns the movie as it was
+// before deletion.
+func (s *MovieService) Delete(ctx context.Context, id int) (*models.Movie, error) {
+	movie, err := s.movies.GetByID(ctx, id)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrMovieNotFound
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+	if err := s.movies.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	s.deletePoster(ctx, movie.PosterURL)
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "movie_deleted", Details: fmt.Sprintf("movie_id=%d title=%s", id, movie.Title)})
+	s.cache.Invalidate(AdminStatsCacheKey)
+	return movie, nil
+}
+
+// ValidateCreateRequest checks a create request without touching the database.
+// Genre IDs are only checked to be numeric, not to exist.
+func (s *MovieService) ValidateCreateRequest(req models.CreateMovieRequest) []string {
+	var msgs []string
+	if err := s.validator.Struct(req); err != nil {
+		msgs = append(msgs, validationMessages(err)...)
+	}
+	for _, idStr := range req.GenreIDs {
+		if _, err := strconv.Atoi(idStr); err != nil {
+			msgs = append(msgs, "genre_ids must be numeric")
+			break
 		}
-		return err
 	}
-	return s.movies.Delete(ctx, id)
+	return msgs
+}
+
+// ValidateCreateRequests validates a batch of create requests. Rows are numbered from 1.
+func (s *MovieService) ValidateCreateRequests(ctx context.Context, reqs []models.CreateMovieRequest) []ValidationResult {
+	results := make([]ValidationResult, 0, len(reqs))
+	for i, req := range reqs {
+		results = append(results, ValidationResult{Row: i + 1, Errors: s.ValidateCreateRequest(req)})
+	}
+	return results
 }
 
 func (s *MovieService) validateGenreIDs(ctx context.Context, ids []string) ([]int, error) {
@@ -210,3 +611,7 @@ func (s *MovieService) validateGenreIDs(ctx context.Context, ids []string) ([]in
 	}
 	return genreIDs, nil
 }
+
+func normalizeTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
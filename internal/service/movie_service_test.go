@@ -2,104 +2,23 @@ package service
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 
+	"golang-project/internal/actor"
 	"golang-project/internal/models"
+	"golang-project/internal/testutil"
 )
 
-type memoryMovieRepo struct {
-	movies      map[int]*models.Movie
-	movieGenres map[int][]int
-}
-
-type movieTestGenreRepo struct {
-	data map[int]*models.Genre
-}
-
-func (g *movieTestGenreRepo) GetByID(ctx context.Context, id int) (*models.Genre, error) {
-	if genre, ok := g.data[id]; ok {
-		return genre, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func newMemoryMovieRepo() *memoryMovieRepo {
-	return &memoryMovieRepo{
-		movies:      make(map[int]*models.Movie),
-		movieGenres: make(map[int][]int),
-	}
-}
-
-func (r *memoryMovieRepo) List(ctx context.Context, filters models.MovieFilters, limit, offset int) ([]models.Movie, int, error) {
-	result := make([]models.Movie, 0, len(r.movies))
-	for _, m := range r.movies {
-		result = append(result, *m)
-	}
-	return result, len(result), nil
-}
-
-func (r *memoryMovieRepo) GetByID(ctx context.Context, id int) (*models.Movie, error) {
-	if m, ok := r.movies[id]; ok {
-		return m, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memoryMovieRepo) Create(ctx context.Context, movie *models.Movie) error {
-	now := time.Now()
-	movie.ID = len(r.movies) + 1
-	movie.CreatedAt = now
-	movie.UpdatedAt = now
-	r.movies[movie.ID] = movie
-	return nil
-}
-
-func (r *memoryMovieRepo) Update(ctx context.Context, movie *models.Movie) error {
-	if _, ok := r.movies[movie.ID]; !ok {
-		return sql.ErrNoRows
-	}
-	movie.UpdatedAt = time.Now()
-	r.movies[movie.ID] = movie
-	return nil
-}
-
-func (r *memoryMovieRepo) Delete(ctx context.Context, id int) error {
-	if _, ok := r.movies[id]; !ok {
-		return sql.ErrNoRows
-	}
-	delete(r.movies, id)
-	delete(r.movieGenres, id)
-	return nil
-}
-
-func (r *memoryMovieRepo) SetGenres(ctx context.Context, movieID int, genreIDs []int) error {
-	if _, ok := r.movies[movieID]; !ok {
-		return sql.ErrNoRows
-	}
-	r.movieGenres[movieID] = genreIDs
-	return nil
-}
-
-func (r *memoryMovieRepo) GetGenresByMovieID(ctx context.Context, movieID int) ([]models.Genre, error) {
-	ids := r.movieGenres[movieID]
-	result := make([]models.Genre, 0, len(ids))
-	for _, id := range ids {
-		result = append(result, models.Genre{ID: id, Name: ""})
-	}
-	return result, nil
-}
-
 func TestMovieService_Create(t *testing.T) {
-	movieRepo := newMemoryMovieRepo()
+	movieRepo := testutil.NewMemMovieRepo()
 	genreID := 1
-	genreLookup := &movieTestGenreRepo{data: map[int]*models.Genre{
-		genreID: {ID: genreID, Name: "Drama"},
-	}}
+	genreLookup := testutil.NewMemGenreRepo()
+	genreLookup.Add(&models.Genre{ID: genreID, Name: "Drama"})
 	svc := NewMovieService(movieRepo, genreLookup, validator.New())
 
 	t.Run("ok", func(t *testing.T) {
@@ -111,7 +30,7 @@ func TestMovieService_Create(t *testing.T) {
 			DurationMinutes: 120,
 			GenreIDs:        []string{"1"},
 		}
-		movie, err := svc.Create(context.Background(), req)
+		movie, err := svc.Create(context.Background(), req, false)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -129,7 +48,7 @@ func TestMovieService_Create(t *testing.T) {
 			ReleaseYear:     2010,
 			DurationMinutes: 100,
 		}
-		if _, err := svc.Create(context.Background(), req); err == nil {
+		if _, err := svc.Create(context.Background(), req, false); err == nil {
 			t.Fatalf("expected error for missing genres")
 		}
 	})
@@ -141,18 +60,32 @@ func TestMovieService_Create(t *testing.T) {
 			DurationMinutes: 100,
 			GenreIDs:        []string{"9999"},
 		}
-		if _, err := svc.Create(context.Background(), req); !errors.Is(err, ErrGenreNotFound) {
+		if _, err := svc.Create(context.Background(), req, false); !errors.Is(err, ErrGenreNotFound) {
 			t.Fatalf("expected ErrGenreNotFound, got %v", err)
 		}
 	})
+
+	t.Run("duplicate title and year", func(t *testing.T) {
+		req := models.CreateMovieRequest{
+			Title:           "INCEPTION ",
+			ReleaseYear:     2010,
+			DurationMinutes: 120,
+			GenreIDs:        []string{"1"},
+		}
+		if _, err := svc.Create(context.Background(), req, false); !errors.Is(err, ErrMovieExists) {
+			t.Fatalf("expected ErrMovieExists, got %v", err)
+		}
+		if _, err := svc.Create(context.Background(), req, true); err != nil {
+			t.Fatalf("expected forced duplicate to succeed, got %v", err)
+		}
+	})
 }
 
 func TestMovieService_Update_Delete(t *testing.T) {
-	movieRepo := newMemoryMovieRepo()
+	movieRepo := testutil.NewMemMovieRepo()
 	genreID := 1
-	genreLookup := &movieTestGenreRepo{data: map[int]*models.Genre{
-		genreID: {ID: genreID, Name: "Drama"},
-	}}
+	genreLookup := testutil.NewMemGenreRepo()
+	genreLookup.Add(&models.Genre{ID: genreID, Name: "Drama"})
 	svc := NewMovieService(movieRepo, genreLookup, validator.New())
 
 	// seed movie
@@ -165,8 +98,7 @@ func TestMovieService_Update_Delete(t *testing.T) {
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
-	movieRepo.movies[m.ID] = m
-	movieRepo.movieGenres[m.ID] = []int{genreID}
+	movieRepo.Add(m, genreID)
 
 	t.Run("update ok", func(t *testing.T) {
 		req := models.UpdateMovieRequest{
@@ -183,6 +115,37 @@ func TestMovieService_Update_Delete(t *testing.T) {
 		}
 	})
 
+	t.Run("null clears review embargo", func(t *testing.T) {
+		until := time.Now().Add(24 * time.Hour)
+		if _, err := svc.Update(context.Background(), m.ID, models.UpdateMovieRequest{ReviewEmbargoUntil: &until}); err != nil {
+			t.Fatalf("set embargo: %v", err)
+		}
+
+		var keep models.UpdateMovieRequest
+		if err := json.Unmarshal([]byte(`{"title":"Kept"}`), &keep); err != nil {
+			t.Fatal(err)
+		}
+		updated, err := svc.Update(context.Background(), m.ID, keep)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updated.ReviewEmbargoUntil == nil {
+			t.Fatal("embargo dropped by an update that left the field out")
+		}
+
+		var clear models.UpdateMovieRequest
+		if err := json.Unmarshal([]byte(`{"review_embargo_until":null}`), &clear); err != nil {
+			t.Fatal(err)
+		}
+		updated, err = svc.Update(context.Background(), m.ID, clear)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updated.ReviewEmbargoUntil != nil {
+			t.Fatalf("expected embargo cleared, got %v", updated.ReviewEmbargoUntil)
+		}
+	})
+
 	t.Run("update not found", func(t *testing.T) {
 		req := models.UpdateMovieRequest{Title: "X"}
 		if _, err := svc.Update(context.Background(), 9999, req); !errors.Is(err, ErrMovieNotFound) {
@@ -191,14 +154,138 @@ func TestMovieService_Update_Delete(t *testing.T) {
 	})
 
 	t.Run("delete ok", func(t *testing.T) {
-		if err := svc.Delete(context.Background(), m.ID); err != nil {
+		if _, err := svc.Delete(context.Background(), m.ID); err != nil {
 			t.Fatalf("expected delete ok, got %v", err)
 		}
 	})
 
 	t.Run("delete not found", func(t *testing.T) {
-		if err := svc.Delete(context.Background(), 9999); !errors.Is(err, ErrMovieNotFound) {
+		if _, err := svc.Delete(context.Background(), 9999); !errors.Is(err, ErrMovieNotFound) {
 			t.Fatalf("expected ErrMovieNotFound, got %v", err)
 		}
 	})
 }
+
+// limitRepo records the limit the capped list methods reach the
+// repository with.
+type limitRepo struct {
+	*testutil.MemMovieRepo
+	limit int
+}
+
+func (r *limitRepo) ListFeatured(ctx context.Context, limit int) ([]models.Movie, error) {
+	r.limit = limit
+	return r.MemMovieRepo.ListFeatured(ctx, limit)
+}
+
+func (r *limitRepo) GetDirectorCoOccurrences(ctx context.Context, director string, limit int) ([]models.SimilarDirector, error) {
+	r.limit = limit
+	return r.MemMovieRepo.GetDirectorCoOccurrences(ctx, director, limit)
+}
+
+func (r *limitRepo) GetMostControversialMovies(ctx context.Context, limit int) ([]models.Movie, error) {
+	r.limit = limit
+	return r.MemMovieRepo.GetMostControversialMovies(ctx, limit)
+}
+
+// TestMovieService_ListLimits checks that capped lists default a missing
+// limit to 10 and clamp a large one to 50.
+func TestMovieService_ListLimits(t *testing.T) {
+	repo := &limitRepo{MemMovieRepo: testutil.NewMemMovieRepo()}
+	svc := NewMovieService(repo, testutil.NewMemGenreRepo(), validator.New())
+	ctx := context.Background()
+
+	lists := map[string]func(limit int) error{
+		"featured": func(limit int) error {
+			_, err := svc.ListFeatured(ctx, limit)
+			return err
+		},
+		"similar directors": func(limit int) error {
+			_, err := svc.FindSimilarDirectors(ctx, "Nolan", limit)
+			return err
+		},
+		"controversial": func(limit int) error {
+			_, err := svc.ListControversial(ctx, limit)
+			return err
+		},
+	}
+	for name, list := range lists {
+		for requested, want := range map[int]int{0: 10, -1: 10, 20: 20, 50: 50, 51: 50, 500: 50} {
+			if err := list(requested); err != nil {
+				t.Fatalf("%s, limit %d: %v", name, requested, err)
+			}
+			if repo.limit != want {
+				t.Fatalf("%s, limit %d: expected %d, got %d", name, requested, want, repo.limit)
+			}
+		}
+	}
+}
+
+func TestMovieService_CreateRecordsSubmitter(t *testing.T) {
+	genres := testutil.NewMemGenreRepo()
+	genres.Add(&models.Genre{ID: 1, Name: "Drama"})
+	svc := NewMovieService(testutil.NewMemMovieRepo(), genres, validator.New())
+	req := models.CreateMovieRequest{Title: "Heat", ReleaseYear: 1995, DurationMinutes: 170, GenreIDs: []string{"1"}}
+
+	movie, err := svc.Create(actor.WithID(context.Background(), 7), req, false)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if movie.SubmittedByUserID != 7 {
+		t.Fatalf("expected submitter 7, got %d", movie.SubmittedByUserID)
+	}
+}
+
+func TestMovieService_GetDeletedMovie(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name string
+		gone bool
+		want error
+	}{
+		{"uniform 404", false, ErrMovieNotFound},
+		{"410 for deleted", true, ErrMovieGone},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			movies := testutil.NewMemMovieRepo()
+			movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+			svc := NewMovieService(movies, testutil.NewMemGenreRepo(), validator.New(), WithGoneForDeletedMovies(tt.gone))
+
+			if _, err := svc.Delete(ctx, 1); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if _, err := svc.Get(ctx, 1); !errors.Is(err, tt.want) {
+				t.Fatalf("deleted movie: expected %v, got %v", tt.want, err)
+			}
+			if _, err := svc.Get(ctx, 2); !errors.Is(err, ErrMovieNotFound) {
+				t.Fatalf("unknown movie: expected ErrMovieNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestYearSummaryService_Cache(t *testing.T) {
+	ctx := context.Background()
+	movies, reviews := testutil.NewMemMovieRepo(), testutil.NewMemReviewRepo()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewYearSummaryService(testutil.MemYearSummaries{Movies: movies, Reviews: reviews, Users: testutil.NewMemUserRepo()},
+		WithClock(func() time.Time { return now }))
+
+	if _, err := s.Get(ctx, 2020); !errors.Is(err, ErrYearNotFound) {
+		t.Fatalf("expected ErrYearNotFound for an empty year, got %v", err)
+	}
+	// An empty year is not cached, so the first movie shows up at once.
+	movies.Add(&models.Movie{ID: 1, Title: "Tenet", ReleaseYear: 2020})
+	if summary, err := s.Get(ctx, 2020); err != nil || summary.TotalMovies != 1 {
+		t.Fatalf("expected one movie, got %+v (err %v)", summary, err)
+	}
+
+	reviews.Add(&models.Review{MovieID: 1, UserID: 1, Rating: 8})
+	if summary, _ := s.Get(ctx, 2020); summary.TotalReviews != 0 {
+		t.Fatalf("expected the cached summary within the TTL, got %d reviews", summary.TotalReviews)
+	}
+	now = now.Add(YearSummaryTTL)
+	if summary, _ := s.Get(ctx, 2020); summary.TotalReviews != 1 {
+		t.Fatalf("expected a fresh summary after the TTL, got %d reviews", summary.TotalReviews)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"golang-project/internal/models"
+)
+
+// Notification event types.
+const (
+	NotificationReviewPosted = "review_posted"
+)
+
+// NotificationEvent describes something a user should be told about.
+type NotificationEvent struct {
+	Type     string
+	MovieID  int
+	ReviewID int
+}
+
+// NotificationSender delivers a notification to a user.
+type NotificationSender interface {
+	Notify(ctx context.Context, userID int, event NotificationEvent) error
+}
+
+// WithNotificationSender makes ReviewService notify the user who submitted a
+// movie when someone else reviews it.
+func WithNotificationSender(sender NotificationSender) Option {
+	return func(o *options) {
+		if sender != nil {
+			o.notifier = sender
+		}
+	}
+}
+
+type noopNotificationSender struct{}
+
+func (noopNotificationSender) Notify(ctx context.Context, userID int, event NotificationEvent) error {
+	return nil
+}
+
+type NotificationRepo interface {
+	Insert(ctx context.Context, n *models.Notification) error
+	ListByUser(ctx context.Context, userID, limit, offset int) ([]models.Notification, int, error)
+}
+
+// InAppNotificationSender stores notifications in the notifications table,
+// where users read them through GET /me/notifications.
+type InAppNotificationSender struct {
+	repo NotificationRepo
+}
+
+func NewInAppNotificationSender(repo NotificationRepo) *InAppNotificationSender {
+	return &InAppNotificationSender{repo: repo}
+}
+
+func (s *InAppNotificationSender) Notify(ctx context.Context, userID int, event NotificationEvent) error {
+	return s.repo.Insert(ctx, &models.Notification{
+		UserID:    userID,
+		EventType: event.Type,
+		Payload:   models.NotificationPayload{MovieID: event.MovieID, ReviewID: event.ReviewID},
+	})
+}
+
+// List returns a page of the user's notifications, newest first.
+func (s *InAppNotificationSender) List(ctx context.Context, userID, page, limit int) (*models.PaginatedResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	notifications, total, err := s.repo.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaginatedResponse{
+		Data:       notifications,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+// notify sends event and logs failures; a lost notification must not fail
+// the action that triggered it.
+func notify(ctx context.Context, sender NotificationSender, userID int, event NotificationEvent) {
+	if err := sender.Notify(ctx, userID, event); err != nil {
+		log.Printf("notification error: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+func TestReviewService_NotifiesMovieSubmitter(t *testing.T) {
+	const submitter, reviewer = 1, 2
+
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Heat", SubmittedByUserID: submitter})
+	movies.Add(&models.Movie{ID: 2, Title: "Ronin"})
+	notifications := testutil.NewMemNotificationRepo()
+	sender := NewInAppNotificationSender(notifications)
+	svc := NewReviewService(testutil.NewMemReviewRepo(), movies, validator.New(), nil, WithNotificationSender(sender))
+	req := models.CreateReviewRequest{Rating: 8, Title: "Tense", Content: "Great shootout"}
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, 1, submitter, req, false); err != nil {
+		t.Fatalf("self review: %v", err)
+	}
+	if got := notifications.All(); len(got) != 0 {
+		t.Fatalf("self review must not notify, got %+v", got)
+	}
+
+	if _, err := svc.Create(ctx, 2, reviewer, req, false); err != nil {
+		t.Fatalf("review of unattributed movie: %v", err)
+	}
+	if got := notifications.All(); len(got) != 0 {
+		t.Fatalf("movie without submitter must not notify, got %+v", got)
+	}
+
+	req.Content = "The heist drags, but the score carries it"
+	review, err := svc.Create(ctx, 1, reviewer, req, false)
+	if err != nil {
+		t.Fatalf("review: %v", err)
+	}
+	got := notifications.All()
+	if len(got) != 1 {
+		t.Fatalf("expected 1 notification, got %+v", got)
+	}
+	n := got[0]
+	if n.UserID != submitter || n.EventType != NotificationReviewPosted ||
+		n.Payload.MovieID != 1 || n.Payload.ReviewID != review.ID || n.Read {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+
+	page, err := sender.List(ctx, submitter, 1, 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page.Total != 1 {
+		t.Fatalf("expected submitter to see 1 notification, got %d", page.Total)
+	}
+	if page, _ := sender.List(ctx, reviewer, 1, 10); page.Total != 0 {
+		t.Fatalf("reviewer must not see the submitter's notifications, got %d", page.Total)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"golang-project/internal/models"
+	"golang-project/internal/storage"
+)
+
+// MaxPosterSize is the largest poster image accepted, in bytes.
+const MaxPosterSize = 5 << 20
+
+// PosterURLPrefix is where stored posters are served from; poster_url is
+// this prefix followed by the storage key.
+const PosterURLPrefix = "/static/posters/"
+
+var (
+	ErrPosterTooLarge        = fmt.Errorf("poster must be at most %d MB", MaxPosterSize>>20)
+	ErrUnsupportedPosterType = errors.New("poster must be a JPEG, PNG or WebP image")
+	ErrPostersDisabled       = errors.New("poster storage is not configured")
+)
+
+// posterExtensions lists the accepted image types, as sniffed from the
+// file content, with the extension they are stored under.
+var posterExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// WithPosterStorage enables MovieService.UploadPoster.
+func WithPosterStorage(posters storage.Storage) Option {
+	return func(o *options) {
+		o.posters = posters
+	}
+}
+
+// UploadPoster stores body as the movie's poster and points poster_url at
+// it. The type is detected from the content rather than trusted from the
+// client. A previously uploaded poster is removed once the new one is in
+// place.
+func (s *MovieService) UploadPoster(ctx context.Context, movieID int, body io.Reader) (*models.Movie, error) {
+	if s.posters == nil {
+		return nil, ErrPostersDisabled
+	}
+	movie, err := s.movies.GetByID(ctx, movieID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+	oldURL := movie.PosterURL
+
+	data, err := io.ReadAll(io.LimitReader(body, MaxPosterSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxPosterSize {
+		return nil, ErrPosterTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := posterExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedPosterType
+	}
+
+	key, err := posterKey(movieID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.posters.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+	if err := s.movies.UpdatePosterURL(ctx, movieID, PosterURLPrefix+key); err != nil {
+		s.deletePoster(ctx, PosterURLPrefix+key)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+	s.deletePoster(ctx, oldURL)
+
+	movie.PosterURL = PosterURLPrefix + key
+	recordAudit(ctx, s.audit, &models.AuditLog{MovieID: &movie.ID, Event: "movie_poster_updated", Details: key})
+	return movie, nil
+}
+
+// deletePoster removes the stored file behind posterURL. URLs that were set
+// by hand rather than uploaded are left alone, and failures only leave an
+// orphaned file behind, so they are logged.
+func (s *MovieService) deletePoster(ctx context.Context, posterURL string) {
+	key, ok := strings.CutPrefix(posterURL, PosterURLPrefix)
+	if !ok || s.posters == nil {
+		return
+	}
+	if err := s.posters.Delete(ctx, key); err != nil {
+		log.Printf("poster cleanup error: %v", err)
+	}
+}
+
+// posterKey names a new poster file. Every upload gets a fresh name so
+// caches never serve a replaced image.
+func posterKey(movieID int, ext string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s%s", movieID, hex.EncodeToString(b), ext), nil
+}
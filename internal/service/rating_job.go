@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrRecalculationRunning = errors.New("rating recalculation already running")
+
+const defaultRecalculationBatchSize = 500
+
+type RatingJobState string
+
+const (
+	RatingJobIdle      RatingJobState = "idle"
+	RatingJobRunning   RatingJobState = "running"
+	RatingJobCompleted RatingJobState = "completed"
+	RatingJobFailed    RatingJobState = "failed"
+)
+
+type RatingRecalculationRepo interface {
+	Count(ctx context.Context) (int, error)
+	ListIDsAfter(ctx context.Context, afterID, limit int) ([]int, error)
+	RecalculateAverageRatings(ctx context.Context, movieIDs []int) error
+}
+
+type RatingJobStatus struct {
+	State      RatingJobState `json:"state"`
+	Total      int            `json:"total"`
+	Processed  int            `json:"processed"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// RatingRecalculator rebuilds movies.average_rating from reviews in the
+// background. Only one run may be in progress at a time.
+type RatingRecalculator struct {
+	movies    RatingRecalculationRepo
+	batchSize int
+
+	mu     sync.Mutex
+	status RatingJobStatus
+	done   chan struct{}
+}
+
+func NewRatingRecalculator(movies RatingRecalculationRepo, batchSize int) *RatingRecalculator {
+	if batchSize <= 0 {
+		batchSize = defaultRecalculationBatchSize
+	}
+	return &RatingRecalculator{
+		movies:    movies,
+		batchSize: batchSize,
+		status:    RatingJobStatus{State: RatingJobIdle},
+	}
+}
+
+// Start launches a recalculation and returns immediately. The job is detached
+// from ctx cancellation so it outlives the triggering request.
+func (r *RatingRecalculator) Start(ctx context.Context) (RatingJobStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status.State == RatingJobRunning {
+		return r.status, ErrRecalculationRunning
+	}
+
+	now := time.Now()
+	r.status = RatingJobStatus{State: RatingJobRunning, StartedAt: &now}
+	r.done = make(chan struct{})
+
+	go r.run(context.WithoutCancel(ctx), r.done)
+
+	return r.status, nil
+}
+
+func (r *RatingRecalculator) Status() RatingJobStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// Wait blocks until the current run, if any, has finished.
+func (r *RatingRecalculator) Wait() {
+	r.mu.Lock()
+	done := r.done
+	r.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+func (r *RatingRecalculator) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	err := r.recalculate(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.status.FinishedAt = &now
+	if err != nil {
+		log.Printf("rating recalculation failed: %v", err)
+		r.status.State = RatingJobFailed
+		r.status.Error = err.Error()
+		return
+	}
+	r.status.State = RatingJobCompleted
+}
+
+func (r *RatingRecalculator) recalculate(ctx context.Context) error {
+	total, err := r.movies.Count(ctx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.status.Total = total
+	r.mu.Unlock()
+
+	afterID := 0
+	for {
+		ids, err := r.movies.ListIDsAfter(ctx, afterID, r.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := r.movies.RecalculateAverageRatings(ctx, ids); err != nil {
+			return err
+		}
+
+		afterID = ids[len(ids)-1]
+		r.mu.Lock()
+		r.status.Processed += len(ids)
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type ratingJobRepo struct {
+	ids     []int
+	batches [][]int
+	failOn  int
+}
+
+func (r *ratingJobRepo) Count(ctx context.Context) (int, error) {
+	return len(r.ids), nil
+}
+
+func (r *ratingJobRepo) ListIDsAfter(ctx context.Context, afterID, limit int) ([]int, error) {
+	var out []int
+	for _, id := range r.ids {
+		if id > afterID && len(out) < limit {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+func (r *ratingJobRepo) RecalculateAverageRatings(ctx context.Context, movieIDs []int) error {
+	if r.failOn != 0 && len(r.batches)+1 == r.failOn {
+		return errors.New("db down")
+	}
+	r.batches = append(r.batches, movieIDs)
+	return nil
+}
+
+func TestRatingRecalculator_Batches(t *testing.T) {
+	repo := &ratingJobRepo{ids: []int{1, 2, 5, 7, 9}}
+	job := NewRatingRecalculator(repo, 2)
+
+	if job.Status().State != RatingJobIdle {
+		t.Fatalf("expected idle state, got %s", job.Status().State)
+	}
+
+	if _, err := job.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	job.Wait()
+
+	status := job.Status()
+	if status.State != RatingJobCompleted || status.Processed != 5 || status.Total != 5 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if status.FinishedAt == nil {
+		t.Fatalf("expected finished_at to be set")
+	}
+	if len(repo.batches) != 3 {
+		t.Fatalf("expected 3 batches, got %d", len(repo.batches))
+	}
+}
+
+func TestRatingRecalculator_Failure(t *testing.T) {
+	repo := &ratingJobRepo{ids: []int{1, 2, 3}, failOn: 2}
+	job := NewRatingRecalculator(repo, 2)
+
+	if _, err := job.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	job.Wait()
+
+	status := job.Status()
+	if status.State != RatingJobFailed || status.Error == "" {
+		t.Fatalf("expected failed state, got %+v", status)
+	}
+	if status.Processed != 2 {
+		t.Fatalf("expected 2 processed before failure, got %d", status.Processed)
+	}
+}
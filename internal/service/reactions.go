@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang-project/internal/models"
+)
+
+var (
+	ErrInvalidReaction  = fmt.Errorf("reaction must be one of %s", strings.Join(models.ReactionTypes, ", "))
+	ErrReactionNotFound = errors.New("reaction not found")
+)
+
+// ReactionCounter counts a review's reactions by type.
+type ReactionCounter interface {
+	CountByReview(ctx context.Context, reviewID int) (map[string]int, error)
+	// CountByReviews counts the reactions of several reviews at once.
+	// Reviews nobody reacted to are missing from the map.
+	CountByReviews(ctx context.Context, reviewIDs []int) (map[int]map[string]int, error)
+}
+
+// ReviewGetter looks up a single review.
+type ReviewGetter interface {
+	GetByID(ctx context.Context, id int) (*models.Review, error)
+}
+
+type ReviewReactionRepo interface {
+	ReactionCounter
+	Upsert(ctx context.Context, reaction *models.ReviewReaction) error
+	Delete(ctx context.Context, reviewID, userID int) error
+}
+
+// WithReactionCounts makes ReviewService.Get and the review lists set
+// Review.ReactionCounts.
+func WithReactionCounts(counter ReactionCounter) Option {
+	return func(o *options) {
+		o.reactionCounts = counter
+	}
+}
+
+// reactionCounts loads a review's reaction counts with every reaction type
+// present, so clients need not tell a zero from a missing key.
+func reactionCounts(ctx context.Context, counter ReactionCounter, reviewID int) (map[string]int, error) {
+	counts, err := counter.CountByReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	return allReactions(counts), nil
+}
+
+// attachReactionCounts sets ReactionCounts on every review with a single
+// query. It does nothing when counter is nil.
+func attachReactionCounts(ctx context.Context, counter ReactionCounter, reviews []models.Review) error {
+	if counter == nil || len(reviews) == 0 {
+		return nil
+	}
+	ids := make([]int, len(reviews))
+	for i, r := range reviews {
+		ids[i] = r.ID
+	}
+	counts, err := counter.CountByReviews(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range reviews {
+		reviews[i].ReactionCounts = allReactions(counts[reviews[i].ID])
+	}
+	return nil
+}
+
+// allReactions returns counts with every reaction type present.
+func allReactions(counts map[string]int) map[string]int {
+	all := make(map[string]int, len(models.ReactionTypes))
+	for _, reaction := range models.ReactionTypes {
+		all[reaction] = counts[reaction]
+	}
+	return all
+}
+
+type ReactService struct {
+	reactions ReviewReactionRepo
+	reviews   ReviewGetter
+}
+
+func NewReactService(reactions ReviewReactionRepo, reviews ReviewGetter) *ReactService {
+	return &ReactService{reactions: reactions, reviews: reviews}
+}
+
+// React records userID's reaction to a review, replacing any earlier one,
+// and returns the review with its updated counts.
+func (s *ReactService) React(ctx context.Context, reviewID, userID int, reaction string) (*models.Review, error) {
+	if !slices.Contains(models.ReactionTypes, reaction) {
+		return nil, ErrInvalidReaction
+	}
+	review, err := s.review(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reactions.Upsert(ctx, &models.ReviewReaction{ReviewID: reviewID, UserID: userID, Reaction: reaction}); err != nil {
+		return nil, err
+	}
+	return s.withCounts(ctx, review)
+}
+
+// Unreact removes userID's reaction to a review.
+func (s *ReactService) Unreact(ctx context.Context, reviewID, userID int) (*models.Review, error) {
+	review, err := s.review(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reactions.Delete(ctx, reviewID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReactionNotFound
+		}
+		return nil, err
+	}
+	return s.withCounts(ctx, review)
+}
+
+func (s *ReactService) review(ctx context.Context, id int) (*models.Review, error) {
+	review, err := s.reviews.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return review, nil
+}
+
+func (s *ReactService) withCounts(ctx context.Context, review *models.Review) (*models.Review, error) {
+	counts, err := reactionCounts(ctx, s.reactions, review.ID)
+	if err != nil {
+		return nil, err
+	}
+	review.ReactionCounts = counts
+	return review, nil
+}
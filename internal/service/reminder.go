@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+const (
+	// ReviewReminderInterval is how often ReminderScheduler looks for
+	// inactive reviewers.
+	ReviewReminderInterval = 24 * time.Hour
+	// ReviewReminderInactivity is how long a user goes without reviewing
+	// before they are reminded.
+	ReviewReminderInactivity = 30 * 24 * time.Hour
+	// ReviewReminderCooldown is the least time between two reminders to the
+	// same user.
+	ReviewReminderCooldown = 7 * 24 * time.Hour
+)
+
+// reviewReminderMovies is how many movies a reminder suggests.
+const reviewReminderMovies = 3
+
+// MailSender delivers a plain-text email.
+type MailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// ReviewActivityRecorder remembers when each user last posted a review.
+type ReviewActivityRecorder interface {
+	SetLastReviewAt(ctx context.Context, userID int, at time.Time) error
+}
+
+// WithReviewActivity makes ReviewService.Create record the author's
+// last_review_at, which review reminders go by.
+func WithReviewActivity(users ReviewActivityRecorder) Option {
+	return func(o *options) {
+		o.reviewActivity = users
+	}
+}
+
+// ReminderUserRepo finds the users due a reminder. ClaimReminder records a
+// reminder as sent at at unless one went out after notBefore, reporting
+// whether it did, so instances sharing the database never remind the same
+// user twice; ReleaseReminder puts back prev when the email then fails.
+type ReminderUserRepo interface {
+	FindInactiveReviewers(ctx context.Context, since time.Time) ([]models.User, error)
+	ClaimReminder(ctx context.Context, id int, at, notBefore time.Time) (bool, error)
+	ReleaseReminder(ctx context.Context, id int, at time.Time, prev *time.Time) error
+}
+
+type TopRatedMovieRepo interface {
+	ListTopRated(ctx context.Context, userID, limit int) ([]models.Movie, error)
+}
+
+// ReminderScheduler emails users who opted in to reminders and have not
+// reviewed anything for ReviewReminderInactivity, suggesting top-rated
+// movies they have not reviewed yet.
+type ReminderScheduler struct {
+	users    ReminderUserRepo
+	movies   TopRatedMovieRepo
+	mail     MailSender
+	now      func() time.Time
+	interval time.Duration
+
+	mu sync.Mutex
+}
+
+func NewReminderScheduler(users ReminderUserRepo, movies TopRatedMovieRepo, mail MailSender, opts ...Option) *ReminderScheduler {
+	o := applyOptions(opts)
+	return &ReminderScheduler{users: users, movies: movies, mail: mail, now: o.now, interval: ReviewReminderInterval}
+}
+
+// Start runs Tick every ReviewReminderInterval until ctx is cancelled.
+func (s *ReminderScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Tick(ctx); err != nil {
+					log.Printf("review reminders: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Tick sends the reminders that are due and returns how many went out.
+// Users reminded within ReviewReminderCooldown are skipped, as are users
+// with nothing left to suggest. Each reminder is claimed in the database
+// before it is sent, so another instance ticking at the same time skips
+// it. A failed send gives the claim back and is retried on the next tick.
+func (s *ReminderScheduler) Tick(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	users, err := s.users.FindInactiveReviewers(ctx, now.Add(-ReviewReminderInactivity))
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, u := range users {
+		if u.ReminderSentAt != nil && now.Sub(*u.ReminderSentAt) < ReviewReminderCooldown {
+			continue
+		}
+		movies, err := s.movies.ListTopRated(ctx, u.ID, reviewReminderMovies)
+		if err != nil {
+			log.Printf("review reminders: top-rated movies for user %d: %v", u.ID, err)
+			continue
+		}
+		if len(movies) == 0 {
+			continue
+		}
+		claimed, err := s.users.ClaimReminder(ctx, u.ID, now, now.Add(-ReviewReminderCooldown))
+		if err != nil {
+			log.Printf("review reminders: claim user %d: %v", u.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.mail.Send(ctx, u.Email, "Movies waiting for your review", reminderBody(u, movies)); err != nil {
+			log.Printf("review reminders: send to user %d: %v", u.ID, err)
+			if err := s.users.ReleaseReminder(ctx, u.ID, now, u.ReminderSentAt); err != nil {
+				log.Printf("review reminders: release user %d: %v", u.ID, err)
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func reminderBody(u models.User, movies []models.Movie) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\nIt has been a while since your last review. These top-rated movies are still waiting for yours:\n\n", u.Username)
+	for _, m := range movies {
+		fmt.Fprintf(&b, "- %s (%d), rated %.1f\n", m.Title, m.ReleaseYear, m.AverageRating)
+	}
+	b.WriteString("\nYou can turn these emails off by setting email_on_digest to false in your profile.\n")
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+type sentMail struct{ to, subject, body string }
+
+type recordingMailSender struct {
+	sent []sentMail
+}
+
+func (m *recordingMailSender) Send(ctx context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, sentMail{to, subject, body})
+	return nil
+}
+
+func TestReminderScheduler_Tick(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	users := testutil.NewMemUserRepo()
+	movies := testutil.NewMemMovieRepo()
+	for i, title := range []string{"Heat", "Alien", "Fargo", "Brazil", "Ran"} {
+		movies.Add(&models.Movie{ID: i + 1, Title: title, ReleaseYear: 1980 + i, AverageRating: float64(9 - i)})
+	}
+	reviews := NewReviewService(testutil.NewMemReviewRepo(), movies, validator.New(), nil, WithReviewActivity(users), clock)
+
+	review := func(u *models.User, movieID int, at time.Time) {
+		t.Helper()
+		now = at
+		req := models.CreateReviewRequest{Rating: 8, Title: "Worth it", Content: "Review by " + u.Username}
+		if _, err := reviews.Create(ctx, movieID, u.ID, req, false); err != nil {
+			t.Fatalf("review: %v", err)
+		}
+		movies.AddReviewers(movieID, u.ID)
+	}
+	register := func(name string) *models.User {
+		t.Helper()
+		u := &models.User{Email: name + "@example.com", Username: name, Role: "user"}
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		if err := users.SetEmailOnDigest(ctx, u.ID, true); err != nil {
+			t.Fatalf("opt in: %v", err)
+		}
+		return u
+	}
+	reminders := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	inactive := register("inactive")
+	review(inactive, 1, reminders.Add(-40*24*time.Hour))
+	active := register("active")
+	review(active, 2, reminders.Add(-2*24*time.Hour))
+
+	mail := &recordingMailSender{}
+	scheduler := NewReminderScheduler(users, movies, mail, clock)
+	now = reminders
+	n, err := scheduler.Tick(ctx)
+	if err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if n != 1 || len(mail.sent) != 1 || mail.sent[0].to != inactive.Email {
+		t.Fatalf("expected one reminder to %s, got %d: %+v", inactive.Email, n, mail.sent)
+	}
+	// Heat is the best-rated movie, but inactive already reviewed it.
+	body := mail.sent[0].body
+	for _, title := range []string{"Alien", "Fargo", "Brazil"} {
+		if !strings.Contains(body, title) {
+			t.Fatalf("expected %s to be suggested, got %q", title, body)
+		}
+	}
+	if strings.Contains(body, "Heat") || strings.Contains(body, "Ran") {
+		t.Fatalf("expected only the top three unreviewed movies, got %q", body)
+	}
+
+	// A day later the cooldown still holds.
+	now = reminders.Add(24 * time.Hour)
+	if n, err := scheduler.Tick(ctx); err != nil || n != 0 || len(mail.sent) != 1 {
+		t.Fatalf("expected no reminder within the cooldown, got %d (%v)", n, err)
+	}
+}
+
+// staleReviewers answers FindInactiveReviewers with a list read earlier,
+// like an instance that queried before another one sent the reminders.
+type staleReviewers struct {
+	*testutil.MemUserRepo
+	users []models.User
+}
+
+func (r staleReviewers) FindInactiveReviewers(ctx context.Context, since time.Time) ([]models.User, error) {
+	return r.users, nil
+}
+
+type failingMailSender struct{}
+
+func (failingMailSender) Send(ctx context.Context, to, subject, body string) error {
+	return errors.New("smtp down")
+}
+
+func TestReminderScheduler_ClaimsBeforeSending(t *testing.T) {
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	users := testutil.NewMemUserRepo()
+	u := &models.User{Email: "inactive@example.com", Username: "inactive", Role: "user"}
+	if err := users.Create(ctx, u); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := users.SetEmailOnDigest(ctx, u.ID, true); err != nil {
+		t.Fatalf("opt in: %v", err)
+	}
+	if err := users.SetLastReviewAt(ctx, u.ID, now.Add(-40*24*time.Hour)); err != nil {
+		t.Fatalf("last review: %v", err)
+	}
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 1, Title: "Alien", ReleaseYear: 1979, AverageRating: 8})
+
+	// A failed send gives the reminder back for the next tick.
+	if n, err := NewReminderScheduler(users, movies, failingMailSender{}, clock).Tick(ctx); err != nil || n != 0 {
+		t.Fatalf("expected nothing sent, got %d (%v)", n, err)
+	}
+	due, err := users.FindInactiveReviewers(ctx, now)
+	if err != nil || len(due) != 1 || due[0].ReminderSentAt != nil {
+		t.Fatalf("expected the failed reminder to be released, got %+v (%v)", due, err)
+	}
+
+	// Two instances that both saw the user as due send one email between them.
+	first, second := &recordingMailSender{}, &recordingMailSender{}
+	if _, err := NewReminderScheduler(users, movies, first, clock).Tick(ctx); err != nil {
+		t.Fatalf("first tick: %v", err)
+	}
+	if _, err := NewReminderScheduler(staleReviewers{users, due}, movies, second, clock).Tick(ctx); err != nil {
+		t.Fatalf("second tick: %v", err)
+	}
+	if len(first.sent) != 1 || len(second.sent) != 0 {
+		t.Fatalf("expected exactly one reminder, got %d and %d", len(first.sent), len(second.sent))
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"golang-project/internal/models"
+)
+
+type ReviewAnalyticsRepo interface {
+	GetAnalytics(ctx context.Context) (*models.ReviewAnalytics, error)
+}
+
+// ReviewAnalyticsService reports review quality metrics to admins.
+type ReviewAnalyticsService struct {
+	repo ReviewAnalyticsRepo
+}
+
+func NewReviewAnalyticsService(repo ReviewAnalyticsRepo) *ReviewAnalyticsService {
+	return &ReviewAnalyticsService{repo: repo}
+}
+
+// Get returns the analytics with FlaggedPercent filled in and every rating
+// from 1 to 10 present in RatingDistribution, zero if nobody gave it.
+func (s *ReviewAnalyticsService) Get(ctx context.Context) (*models.ReviewAnalytics, error) {
+	a, err := s.repo.GetAnalytics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if a.TotalReviews > 0 {
+		a.FlaggedPercent = float64(a.FlaggedReviews) * 100 / float64(a.TotalReviews)
+	}
+	distribution := make(map[int]int, 10)
+	for rating := 1; rating <= 10; rating++ {
+		distribution[rating] = a.RatingDistribution[rating]
+	}
+	a.RatingDistribution = distribution
+	return a, nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log"
+	"slices"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -11,25 +14,95 @@ import (
 	"golang-project/internal/models"
 )
 
+// MaxBulkDeleteReviews bounds how many reviews BulkDelete removes at once.
+const MaxBulkDeleteReviews = 100
+
 var (
-	ErrReviewExists   = errors.New("review already exists")
-	ErrReviewNotFound = errors.New("review not found")
+	ErrReviewExists     = errors.New("review already exists")
+	ErrReviewNotFound   = errors.New("review not found")
+	ErrEditWindowClosed = errors.New("review can no longer be edited")
+	ErrNoReviewIDs      = errors.New("review_ids required")
+	ErrTooManyReviewIDs = fmt.Errorf("review_ids accepts at most %d values", MaxBulkDeleteReviews)
 )
 
+// WithReviewEditWindow limits how long after posting a review its author
+// may edit it. Zero, the default, allows editing at any time.
+func WithReviewEditWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.reviewEditWindow = window
+	}
+}
+
+// ErrReviewEmbargoed is returned when a movie does not accept reviews yet.
+type ErrReviewEmbargoed struct {
+	Until time.Time
+}
+
+func (e ErrReviewEmbargoed) Error() string {
+	return "reviews embargoed until " + e.Until.Format(time.RFC3339)
+}
+
+// SimilarReviewThreshold is the trigram similarity above which a new
+// review counts as a copy of one already posted for the movie.
+const SimilarReviewThreshold = 0.7
+
+// ErrSimilarReviewExists is returned when a new review repeats the content
+// of an existing review of the same movie.
+type ErrSimilarReviewExists struct {
+	ExistingID int
+}
+
+func (e ErrSimilarReviewExists) Error() string {
+	return "similar review exists"
+}
+
 type ReviewRepo interface {
 	GetByID(ctx context.Context, id int) (*models.Review, error)
 	GetByMovieAndUser(ctx context.Context, movieID, userID int) (*models.Review, error)
+	FindSimilarContent(ctx context.Context, movieID int, content string, threshold float64) (*models.Review, error)
 	GetByMovieID(ctx context.Context, movieID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error)
 	GetByUserID(ctx context.Context, userID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error)
 	Create(ctx context.Context, review *models.Review) error
 	Update(ctx context.Context, review *models.Review) error
 	Delete(ctx context.Context, id int) error
+	DeleteMany(ctx context.Context, ids []int) ([]models.Review, error)
 	CountByUserID(ctx context.Context, userID int) (int, error)
+	CountByUserIDFiltered(ctx context.Context, userID int, filters models.ReviewFilters) (int, error)
+	ListAfter(ctx context.Context, filters models.ReviewFilters, afterID, limit int) ([]models.Review, error)
 }
 
 type MovieLookup interface {
 	GetByID(ctx context.Context, id int) (*models.Movie, error)
 	UpdateAverageRating(ctx context.Context, movieID int) error
+	UpdateNormalizedRating(ctx context.Context, movieID int) error
+}
+
+// ReviewTargetLookup loads the movie a review is posted to together with
+// the ID of the user's existing review of it, or 0 if there is none, so
+// ReviewService.Create needs one round trip instead of two. It returns
+// sql.ErrNoRows if the movie does not exist.
+type ReviewTargetLookup interface {
+	GetReviewTarget(ctx context.Context, movieID, userID int) (*models.Movie, int, error)
+}
+
+// WithReviewTargetLookup makes ReviewService.Create check that the movie
+// exists and that the user has not reviewed it yet in a single query.
+func WithReviewTargetLookup(targets ReviewTargetLookup) Option {
+	return func(o *options) {
+		o.reviewTargets = targets
+	}
+}
+
+// UserLookup loads the authors attached to reviews returned by ReviewService.
+type UserLookup interface {
+	GetByID(ctx context.Context, id int) (*models.User, error)
+}
+
+// WithUserLookup makes ReviewService.Create return the review with its author.
+func WithUserLookup(users UserLookup) Option {
+	return func(o *options) {
+		o.users = users
+	}
 }
 
 type ReviewService struct {
@@ -37,14 +110,37 @@ type ReviewService struct {
 	movies    MovieLookup
 	validator *validator.Validate
 	events    chan<- ReviewEvent
+	now       func() time.Time
+	notifier  NotificationSender
+	users     UserLookup
+	cache     CacheInvalidator
+	targets   ReviewTargetLookup
+	filter    ContentFilter
+	reactions ReactionCounter
+	activity  ReviewActivityRecorder
+	audit     AuditWriter
+
+	editWindow time.Duration
 }
 
-func NewReviewService(reviews ReviewRepo, movies MovieLookup, v *validator.Validate, events chan<- ReviewEvent) *ReviewService {
+func NewReviewService(reviews ReviewRepo, movies MovieLookup, v *validator.Validate, events chan<- ReviewEvent, opts ...Option) *ReviewService {
+	o := applyOptions(opts)
 	return &ReviewService{
 		reviews:   reviews,
 		movies:    movies,
 		validator: v,
 		events:    events,
+		now:       o.now,
+		notifier:  o.notifier,
+		users:     o.users,
+		cache:     o.cache,
+		targets:   o.reviewTargets,
+		filter:    o.contentFilter,
+		reactions: o.reactionCounts,
+		activity:  o.reviewActivity,
+		audit:     o.audit,
+
+		editWindow: o.reviewEditWindow,
 	}
 }
 
@@ -56,7 +152,11 @@ func (s *ReviewService) ListByMovie(ctx context.Context, movieID int, filters mo
 		limit = 10
 	}
 	offset := (page - 1) * limit
-	return s.reviews.GetByMovieID(ctx, movieID, filters, limit, offset)
+	reviews, err := s.reviews.GetByMovieID(ctx, movieID, filters, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return reviews, attachReactionCounts(ctx, s.reactions, reviews)
 }
 
 func (s *ReviewService) ListByUser(ctx context.Context, userID int, filters models.ReviewFilters, page, limit int) ([]models.Review, error) {
@@ -67,28 +167,94 @@ func (s *ReviewService) ListByUser(ctx context.Context, userID int, filters mode
 		limit = 10
 	}
 	offset := (page - 1) * limit
-	return s.reviews.GetByUserID(ctx, userID, filters, limit, offset)
+	reviews, err := s.reviews.GetByUserID(ctx, userID, filters, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return reviews, attachReactionCounts(ctx, s.reactions, reviews)
+}
+
+// ListByUserPage is ListByUser with the total of matching reviews, for
+// clients that page through them.
+func (s *ReviewService) ListByUserPage(ctx context.Context, userID int, filters models.ReviewFilters, page, limit int) (*models.PaginatedResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	reviews, err := s.ListByUser(ctx, userID, filters, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.reviews.CountByUserIDFiltered(ctx, userID, filters)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaginatedResponse{
+		Data:       reviews,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+// Export returns the next batch of reviews after afterID in ID order, for
+// exports that walk every matching review.
+func (s *ReviewService) Export(ctx context.Context, filters models.ReviewFilters, afterID, limit int) ([]models.Review, error) {
+	return s.reviews.ListAfter(ctx, filters, afterID, limit)
+}
+
+func (s *ReviewService) Get(ctx context.Context, id int) (*models.Review, error) {
+	review, err := s.reviews.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	if s.reactions != nil {
+		if review.ReactionCounts, err = reactionCounts(ctx, s.reactions, review.ID); err != nil {
+			return nil, err
+		}
+	}
+	return review, nil
 }
 
 func (s *ReviewService) CountByUser(ctx context.Context, userID int) (int, error) {
 	return s.reviews.CountByUserID(ctx, userID)
 }
 
-func (s *ReviewService) Create(ctx context.Context, movieID, userID int, req models.CreateReviewRequest) (*models.Review, error) {
+// Create adds a review. Admins may review movies that are still under a
+// review embargo.
+func (s *ReviewService) Create(ctx context.Context, movieID, userID int, req models.CreateReviewRequest, isAdmin bool) (*models.Review, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, err
 	}
+	if err := s.checkContent(ctx, req.Title, req.Content); err != nil {
+		return nil, err
+	}
 
-	// ensure movie exists
-	if _, err := s.movies.GetByID(ctx, movieID); err != nil {
+	movie, existingID, err := s.reviewTarget(ctx, movieID, userID)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrMovieNotFound
 		}
 		return nil, err
 	}
-
-	if _, err := s.reviews.GetByMovieAndUser(ctx, movieID, userID); err == nil {
+	// A pending movie is not in the catalogue yet, so it cannot be reviewed.
+	if movie.Status == models.MovieStatusPending {
+		return nil, ErrMovieNotFound
+	}
+	if !isAdmin && movie.ReviewEmbargoUntil != nil && s.now().Before(*movie.ReviewEmbargoUntil) {
+		return nil, ErrReviewEmbargoed{Until: *movie.ReviewEmbargoUntil}
+	}
+	if existingID != 0 {
 		return nil, ErrReviewExists
+	}
+	if similar, err := s.reviews.FindSimilarContent(ctx, movieID, req.Content, SimilarReviewThreshold); err == nil {
+		return nil, ErrSimilarReviewExists{ExistingID: similar.ID}
 	} else if !errors.Is(err, sql.ErrNoRows) {
 		return nil, err
 	}
@@ -103,18 +269,128 @@ func (s *ReviewService) Create(ctx context.Context, movieID, userID int, req mod
 	if err := s.reviews.Create(ctx, review); err != nil {
 		return nil, err
 	}
-	_ = s.movies.UpdateAverageRating(ctx, movieID)
-	s.emitEvent(ReviewEvent{
+	s.cache.Invalidate(AdminStatsCacheKey)
+	if s.activity != nil {
+		if err := s.activity.SetLastReviewAt(ctx, userID, s.now()); err != nil {
+			log.Printf("review service: record last review time: %v", err)
+		}
+	}
+	// The review worker recalculates the movie's ratings; they are only
+	// updated inline when there is no worker or its queue is full.
+	queued := s.emitEvent(ReviewEvent{
 		Type:     EventReviewCreated,
 		MovieID:  movieID,
 		UserID:   userID,
 		ReviewID: review.ID,
 		Time:     time.Now(),
 	})
+	if !queued {
+		s.updateRatings(ctx, movieID)
+	}
+	if movie.SubmittedByUserID != 0 && movie.SubmittedByUserID != review.UserID {
+		notify(ctx, s.notifier, movie.SubmittedByUserID, NotificationEvent{
+			Type:     NotificationReviewPosted,
+			MovieID:  movieID,
+			ReviewID: review.ID,
+		})
+	}
+	s.attachAuthor(ctx, review)
 	return review, nil
 }
 
-func (s *ReviewService) Update(ctx context.Context, id int, userID int, req models.UpdateReviewRequest) (*models.Review, error) {
+// checkContent returns ErrContentBlocked if any of texts contains a blocked
+// keyword.
+func (s *ReviewService) checkContent(ctx context.Context, texts ...string) error {
+	if s.filter == nil {
+		return nil
+	}
+	for _, text := range texts {
+		matched, err := s.filter.Match(ctx, text)
+		if err != nil {
+			return err
+		}
+		if len(matched) > 0 {
+			return ErrContentBlocked
+		}
+	}
+	return nil
+}
+
+// reviewTarget loads the movie and the user's existing review ID, using the
+// combined lookup when one is configured.
+func (s *ReviewService) reviewTarget(ctx context.Context, movieID, userID int) (*models.Movie, int, error) {
+	if s.targets != nil {
+		return s.targets.GetReviewTarget(ctx, movieID, userID)
+	}
+	movie, err := s.movies.GetByID(ctx, movieID)
+	if err != nil {
+		return nil, 0, err
+	}
+	existing, err := s.reviews.GetByMovieAndUser(ctx, movieID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return movie, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return movie, existing.ID, nil
+}
+
+// GetAverageRating returns the movie's stored average rating without
+// recomputing it. New reviews reach it through the review worker, so it is
+// eventually consistent: it may miss reviews created within the last
+// ReviewRatingStaleAfter.
+func (s *ReviewService) GetAverageRating(ctx context.Context, movieID int) (float64, error) {
+	movie, err := s.movies.GetByID(ctx, movieID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrMovieNotFound
+		}
+		return 0, err
+	}
+	return movie.AverageRating, nil
+}
+
+// ReviewsUpdatedAt reports when the movie's reviews last changed, or the zero
+// time if the movie does not exist.
+func (s *ReviewService) ReviewsUpdatedAt(ctx context.Context, movieID int) (time.Time, error) {
+	movie, err := s.movies.GetByID(ctx, movieID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return movie.ReviewsUpdatedAt, nil
+}
+
+// updateRatings refreshes the movie's raw and normalized averages. Other
+// movies by the same reviewer drift until the next batch recalculation.
+func (s *ReviewService) updateRatings(ctx context.Context, movieID int) {
+	_ = s.movies.UpdateAverageRating(ctx, movieID)
+	_ = s.movies.UpdateNormalizedRating(ctx, movieID)
+}
+
+// attachAuthor fills review.User. The review already exists at this point,
+// so a failed lookup is logged and the review is returned without it.
+func (s *ReviewService) attachAuthor(ctx context.Context, review *models.Review) {
+	if s.users == nil {
+		return
+	}
+	user, err := s.users.GetByID(ctx, review.UserID)
+	if err != nil {
+		log.Printf("review author lookup error: %v", err)
+		return
+	}
+	review.User = user.Public()
+}
+
+// Update edits the caller's own review. Outside the edit window only admins
+// may still change their reviews.
+func (s *ReviewService) Update(ctx context.Context, id int, userID int, req models.UpdateReviewRequest, isAdmin bool) (*models.Review, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
 	review, err := s.reviews.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -125,63 +401,151 @@ func (s *ReviewService) Update(ctx context.Context, id int, userID int, req mode
 	if review.UserID != userID {
 		return nil, ErrInvalidCredentials
 	}
+	if s.editWindow > 0 && !isAdmin && s.now().Sub(review.CreatedAt) > s.editWindow {
+		return nil, ErrEditWindowClosed
+	}
 
-	if req.Rating != 0 {
-		review.Rating = req.Rating
+	var texts []string
+	if req.Rating != nil {
+		review.Rating = *req.Rating
 	}
-	if req.Title != "" {
-		review.Title = req.Title
+	if req.Title != nil {
+		review.Title = *req.Title
+		texts = append(texts, review.Title)
 	}
-	if req.Content != "" {
-		review.Content = req.Content
+	if req.Content != nil {
+		review.Content = *req.Content
+		texts = append(texts, review.Content)
+	}
+	if err := s.checkContent(ctx, texts...); err != nil {
+		return nil, err
 	}
 
 	if err := s.reviews.Update(ctx, review); err != nil {
 		return nil, err
 	}
-	_ = s.movies.UpdateAverageRating(ctx, review.MovieID)
-	s.emitEvent(ReviewEvent{
+	// As in Create, the worker recalculates the ratings if it got the event.
+	queued := s.emitEvent(ReviewEvent{
 		Type:     EventReviewUpdated,
 		MovieID:  review.MovieID,
 		UserID:   review.UserID,
 		ReviewID: review.ID,
 		Time:     time.Now(),
 	})
+	if !queued {
+		s.updateRatings(ctx, review.MovieID)
+	}
 	return review, nil
 }
 
-func (s *ReviewService) Delete(ctx context.Context, id int, requester int, isAdmin bool) error {
+// Delete removes a review; only its author or an admin may do so. It
+// returns the review as it was before deletion.
+func (s *ReviewService) Delete(ctx context.Context, id int, requester int, isAdmin bool) (*models.Review, error) {
 	review, err := s.reviews.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrReviewNotFound
+			return nil, ErrReviewNotFound
 		}
-		return err
+		return nil, err
 	}
 	if !isAdmin && review.UserID != requester {
-		return ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	}
 
 	if err := s.reviews.Delete(ctx, id); err != nil {
-		return err
+		return nil, err
 	}
-	_ = s.movies.UpdateAverageRating(ctx, review.MovieID)
-	s.emitEvent(ReviewEvent{
+	s.cache.Invalidate(AdminStatsCacheKey)
+	queued := s.emitEvent(ReviewEvent{
 		Type:     EventReviewDeleted,
 		MovieID:  review.MovieID,
 		UserID:   review.UserID,
 		ReviewID: review.ID,
 		Time:     time.Now(),
 	})
-	return nil
+	if !queued {
+		s.updateRatings(ctx, review.MovieID)
+	}
+	return review, nil
+}
+
+// ReviewDeleteResult is the outcome of BulkDelete for one ID: Err is nil if
+// the review was deleted and ErrReviewNotFound if there was none.
+type ReviewDeleteResult struct {
+	ReviewID int
+	Err      error
 }
 
-func (s *ReviewService) emitEvent(e ReviewEvent) {
+// BulkDelete removes the reviews in ids in one transaction, for moderators
+// clearing spam. IDs without a review are reported rather than failing the
+// request, and duplicates count once. The affected movies' ratings are
+// recalculated and every deletion is emitted like a single Delete.
+func (s *ReviewService) BulkDelete(ctx context.Context, ids []int) ([]ReviewDeleteResult, error) {
+	if len(ids) == 0 {
+		return nil, ErrNoReviewIDs
+	}
+	if len(ids) > MaxBulkDeleteReviews {
+		return nil, ErrTooManyReviewIDs
+	}
+	unique := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	deleted, err := s.reviews.DeleteMany(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Review, len(deleted))
+	var movieIDs []int
+	for _, review := range deleted {
+		byID[review.ID] = review
+		if !slices.Contains(movieIDs, review.MovieID) {
+			movieIDs = append(movieIDs, review.MovieID)
+		}
+	}
+	for _, movieID := range movieIDs {
+		s.updateRatings(ctx, movieID)
+	}
+	if len(deleted) > 0 {
+		s.cache.Invalidate(AdminStatsCacheKey)
+	}
+
+	results := make([]ReviewDeleteResult, 0, len(unique))
+	for _, id := range unique {
+		review, ok := byID[id]
+		if !ok {
+			results = append(results, ReviewDeleteResult{ReviewID: id, Err: ErrReviewNotFound})
+			continue
+		}
+		// The ratings are already recomputed above, and a bulk delete can
+		// outgrow the event queue, so the audit entries are written here
+		// rather than left to the review worker.
+		recordAudit(ctx, s.audit, &models.AuditLog{
+			UserID:   &review.UserID,
+			MovieID:  &review.MovieID,
+			ReviewID: &review.ID,
+			Event:    string(EventReviewDeleted),
+		})
+		results = append(results, ReviewDeleteResult{ReviewID: id})
+	}
+	return results, nil
+}
+
+// emitEvent queues e for the review worker without blocking and reports
+// whether it was queued.
+func (s *ReviewService) emitEvent(e ReviewEvent) bool {
 	if s.events == nil {
-		return
+		return false
 	}
 	select {
 	case s.events <- e:
+		return true
 	default:
+		return false
 	}
 }
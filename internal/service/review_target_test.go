@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+// countingMovies counts GetByID calls so the test can tell whether Create
+// went through the combined lookup.
+type countingMovies struct {
+	*testutil.MemMovieRepo
+	gets int
+}
+
+func (m *countingMovies) GetByID(ctx context.Context, id int) (*models.Movie, error) {
+	m.gets++
+	return m.MemMovieRepo.GetByID(ctx, id)
+}
+
+func TestReviewService_CreateChecksTargetInOneLookup(t *testing.T) {
+	movies := &countingMovies{MemMovieRepo: testutil.NewMemMovieRepo()}
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	req := models.CreateReviewRequest{Rating: 8, Title: "Tense", Content: "Great shootout"}
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name     string
+		combined bool
+		wantGets int
+	}{
+		{"separate queries", false, 3},
+		{"combined lookup", true, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reviews := testutil.NewMemReviewRepo()
+			var opts []Option
+			if tt.combined {
+				opts = append(opts, WithReviewTargetLookup(testutil.MemReviewTargets{Movies: movies.MemMovieRepo, Reviews: reviews}))
+			}
+			movies.gets = 0
+			svc := NewReviewService(reviews, movies, validator.New(), nil, opts...)
+
+			if _, err := svc.Create(ctx, 99, 1, req, false); !errors.Is(err, ErrMovieNotFound) {
+				t.Fatalf("missing movie: expected ErrMovieNotFound, got %v", err)
+			}
+			if _, err := svc.Create(ctx, 1, 1, req, false); err != nil {
+				t.Fatalf("first review: %v", err)
+			}
+			req := req
+			req.Content = "Different words entirely this time"
+			if _, err := svc.Create(ctx, 1, 1, req, false); !errors.Is(err, ErrReviewExists) {
+				t.Fatalf("second review: expected ErrReviewExists, got %v", err)
+			}
+			if movies.gets != tt.wantGets {
+				t.Fatalf("expected %d movie lookups, got %d", tt.wantGets, movies.gets)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"golang-project/internal/models"
@@ -22,27 +23,133 @@ type ReviewEvent struct {
 	UserID   int
 	ReviewID int
 	Time     time.Time
+	// Done, if set, is closed once the worker has handled the event. An
+	// event with only Done set is a marker, see FlushReviewEvents.
+	Done chan struct{}
+}
+
+// ReviewRatingStaleAfter bounds how long a movie's ratings may lag a new
+// review: ReviewService.Create leaves the recalculation to the worker, which
+// counts every event it handles later than this in StaleEvents.
+const ReviewRatingStaleAfter = 5 * time.Second
+
+// ReviewWorker is a running review event consumer, see StartReviewWorker.
+type ReviewWorker struct {
+	done    chan struct{}
+	lastLag atomic.Int64
+	stale   atomic.Int64
+}
+
+// Done is closed once the worker has stopped.
+func (w *ReviewWorker) Done() <-chan struct{} {
+	return w.done
+}
+
+// EventLag is how long the last event the worker handled had waited since
+// it was emitted.
+func (w *ReviewWorker) EventLag() time.Duration {
+	return time.Duration(w.lastLag.Load())
+}
+
+// StaleEvents counts the events handled more than ReviewRatingStaleAfter
+// after they were emitted.
+func (w *ReviewWorker) StaleEvents() int64 {
+	return w.stale.Load()
+}
+
+func (w *ReviewWorker) recordLag(e ReviewEvent) {
+	if e.Time.IsZero() {
+		return
+	}
+	lag := time.Since(e.Time)
+	w.lastLag.Store(int64(lag))
+	if lag > ReviewRatingStaleAfter {
+		w.stale.Add(1)
+		log.Printf("review worker: %s event for movie %d handled %s after it was emitted", e.Type, e.MovieID, lag.Round(time.Millisecond))
+	}
+}
+
+// FlushReviewEvents waits until the worker has handled every event queued
+// on events before the call.
+func FlushReviewEvents(ctx context.Context, events chan<- ReviewEvent) error {
+	done := make(chan struct{})
+	select {
+	case events <- ReviewEvent{Done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 type MovieRater interface {
 	UpdateAverageRating(ctx context.Context, movieID int) error
+	UpdateNormalizedRating(ctx context.Context, movieID int) error
 }
 
 type AuditWriter interface {
 	Insert(ctx context.Context, log *models.AuditLog) error
 }
 
-func StartReviewWorker(ctx context.Context, events <-chan ReviewEvent, movies MovieRater, audit AuditWriter) {
+type ReviewSentimentRepo interface {
+	ReviewGetter
+	UpdateSentimentScore(ctx context.Context, reviewID int, score float64) error
+}
+
+// StartReviewWorker consumes events in the background until ctx is done or
+// events is closed, in which case the events already queued are handled
+// first.
+func StartReviewWorker(ctx context.Context, events <-chan ReviewEvent, movies MovieRater, audit AuditWriter, reviews ReviewSentimentRepo, analyzer SentimentAnalyzer) *ReviewWorker {
+	w := &ReviewWorker{done: make(chan struct{})}
 	go func() {
+		defer close(w.done)
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case e := <-events:
-				handleReviewEvent(ctx, e, movies, audit)
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				if e.Type != "" {
+					handleReviewEvent(ctx, e, movies, audit)
+					scoreReviewSentiment(ctx, e, reviews, analyzer)
+					w.recordLag(e)
+				}
+				if e.Done != nil {
+					close(e.Done)
+				}
 			}
 		}
 	}()
+	return w
+}
+
+func scoreReviewSentiment(ctx context.Context, e ReviewEvent, reviews ReviewSentimentRepo, analyzer SentimentAnalyzer) {
+	if reviews == nil || analyzer == nil || e.ReviewID == 0 {
+		return
+	}
+	if e.Type != EventReviewCreated && e.Type != EventReviewUpdated {
+		return
+	}
+
+	review, err := reviews.GetByID(ctx, e.ReviewID)
+	if err != nil {
+		log.Printf("review worker: load review for sentiment error: %v", err)
+		return
+	}
+	score, err := analyzer.Analyze(review.Title + " " + review.Content)
+	if err != nil {
+		log.Printf("review worker: sentiment analysis error: %v", err)
+		return
+	}
+	if err := reviews.UpdateSentimentScore(ctx, e.ReviewID, score); err != nil {
+		log.Printf("review worker: update sentiment score error: %v", err)
+	}
 }
 
 func handleReviewEvent(ctx context.Context, e ReviewEvent, movies MovieRater, audit AuditWriter) {
@@ -50,6 +157,9 @@ func handleReviewEvent(ctx context.Context, e ReviewEvent, movies MovieRater, au
 		if err := movies.UpdateAverageRating(ctx, e.MovieID); err != nil {
 			log.Printf("review worker: update average rating error: %v", err)
 		}
+		if err := movies.UpdateNormalizedRating(ctx, e.MovieID); err != nil {
+			log.Printf("review worker: update normalized rating error: %v", err)
+		}
 	}
 
 	if audit == nil {
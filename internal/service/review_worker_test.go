@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+func TestReviewWorker_ReviewCountSurvivesDroppedEvents(t *testing.T) {
+	ctx := context.Background()
+	reviews := testutil.NewMemReviewRepo()
+	movies := testutil.NewMemMovieRepo()
+	movies.RateFrom(reviews)
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	for userID := 1; userID <= 3; userID++ {
+		reviews.Add(&models.Review{MovieID: 1, UserID: userID, Rating: 8})
+	}
+	events := make(chan ReviewEvent, 1)
+
+	// Only one of the three creates made it into the queue.
+	worker := StartReviewWorker(ctx, events, movies, nil, nil, nil)
+	events <- ReviewEvent{Type: EventReviewCreated, MovieID: 1, Time: time.Now()}
+	close(events)
+	<-worker.Done()
+
+	movie, err := movies.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("get movie: %v", err)
+	}
+	if movie.ReviewCount != 3 {
+		t.Fatalf("expected review_count 3 despite the dropped events, got %d", movie.ReviewCount)
+	}
+}
+
+func TestReviewWorker_UpdatesAverageRatingAfterCreate(t *testing.T) {
+	ctx := context.Background()
+	reviews := testutil.NewMemReviewRepo()
+	movies := testutil.NewMemMovieRepo()
+	movies.RateFrom(reviews)
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	events := make(chan ReviewEvent, 4)
+	svc := NewReviewService(reviews, movies, validator.New(), events)
+
+	workerCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	worker := StartReviewWorker(workerCtx, events, movies, nil, nil, nil)
+
+	for userID, req := range map[int]models.CreateReviewRequest{
+		1: {Rating: 6, Title: "Long", Content: "Too long for its own good."},
+		2: {Rating: 9, Title: "Tense", Content: "The bank heist is unmatched."},
+	} {
+		if _, err := svc.Create(ctx, 1, userID, req, false); err != nil {
+			t.Fatalf("create review: %v", err)
+		}
+	}
+
+	// Events are handled in order, so once a marker sent after the reviews
+	// is done both recalculations have run.
+	done := make(chan struct{})
+	events <- ReviewEvent{Done: done}
+	select {
+	case <-done:
+	case <-time.After(ReviewRatingStaleAfter):
+		t.Fatal("worker did not handle the events within ReviewRatingStaleAfter")
+	}
+
+	avg, err := svc.GetAverageRating(ctx, 1)
+	if err != nil {
+		t.Fatalf("get average rating: %v", err)
+	}
+	if avg != 7.5 {
+		t.Fatalf("expected average rating 7.5, got %v", avg)
+	}
+	if n := worker.StaleEvents(); n != 0 {
+		t.Fatalf("expected no stale events, got %d", n)
+	}
+	if _, err := svc.GetAverageRating(ctx, 99); !errors.Is(err, ErrMovieNotFound) {
+		t.Fatalf("missing movie: expected ErrMovieNotFound, got %v", err)
+	}
+
+	flushCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := FlushReviewEvents(flushCtx, events); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+}
+
+func TestReviewWorker_RecalculatesAfterUpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	reviews := testutil.NewMemReviewRepo()
+	movies := testutil.NewMemMovieRepo()
+	movies.RateFrom(reviews)
+	movies.Add(&models.Movie{ID: 1, Title: "Heat"})
+	first := &models.Review{MovieID: 1, UserID: 1, Rating: 6}
+	second := &models.Review{MovieID: 1, UserID: 2, Rating: 8}
+	reviews.Add(first)
+	reviews.Add(second)
+	events := make(chan ReviewEvent, 2)
+	svc := NewReviewService(reviews, movies, validator.New(), events)
+
+	rating := 10
+	if _, err := svc.Update(ctx, first.ID, 1, models.UpdateReviewRequest{Rating: &rating}, false); err != nil {
+		t.Fatalf("update review: %v", err)
+	}
+	if _, err := svc.Delete(ctx, second.ID, 2, false); err != nil {
+		t.Fatalf("delete review: %v", err)
+	}
+	// Both events were queued, so nothing was recalculated inline.
+	if movie, _ := movies.GetByID(ctx, 1); movie.ReviewCount != 0 {
+		t.Fatalf("expected ratings left to the worker, got review_count %d", movie.ReviewCount)
+	}
+
+	worker := StartReviewWorker(ctx, events, movies, nil, nil, nil)
+	close(events)
+	<-worker.Done()
+	movie, err := movies.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("get movie: %v", err)
+	}
+	if movie.AverageRating != 10 || movie.ReviewCount != 1 {
+		t.Fatalf("expected average 10 over 1 review, got %v over %d", movie.AverageRating, movie.ReviewCount)
+	}
+}
+
+func TestReviewWorker_UpdatesNormalizedRating(t *testing.T) {
+	ctx := context.Background()
+	reviews := testutil.NewMemReviewRepo()
+	movies := testutil.NewMemMovieRepo()
+	movies.RateFrom(reviews)
+	for id := 1; id <= 3; id++ {
+		movies.Add(&models.Movie{ID: id, Title: "Movie"})
+	}
+	// User 1 rates harshly (mean 5, sample stddev 2); user 2 only ever
+	// gives 9s and user 3 rated once, so both count as 5.
+	for movieID, rating := range map[int]int{1: 3, 2: 5, 3: 7} {
+		reviews.Add(&models.Review{MovieID: movieID, UserID: 1, Rating: rating})
+	}
+	reviews.Add(&models.Review{MovieID: 1, UserID: 2, Rating: 9})
+	reviews.Add(&models.Review{MovieID: 2, UserID: 2, Rating: 9})
+	reviews.Add(&models.Review{MovieID: 3, UserID: 3, Rating: 2})
+
+	events := make(chan ReviewEvent, 3)
+	worker := StartReviewWorker(ctx, events, movies, nil, nil, nil)
+	for id := 1; id <= 3; id++ {
+		events <- ReviewEvent{Type: EventReviewCreated, MovieID: id, Time: time.Now()}
+	}
+	close(events)
+	<-worker.Done()
+
+	for id, want := range map[int]float64{1: 4, 2: 5, 3: 6} {
+		movie, err := movies.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get movie %d: %v", id, err)
+		}
+		if math.Abs(movie.NormalizedAverageRating-want) > 1e-9 {
+			t.Fatalf("movie %d: expected normalized rating %v, got %v", id, want, movie.NormalizedAverageRating)
+		}
+	}
+}
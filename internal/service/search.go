@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang-project/internal/models"
+)
+
+// Search limits.
+const (
+	MinSearchQueryLength = 2
+	SearchResultsPerType = 5
+)
+
+var ErrSearchQueryTooShort = fmt.Errorf("q must be at least %d characters", MinSearchQueryLength)
+
+type MovieSearchRepo interface {
+	Search(ctx context.Context, query string, limit int) ([]models.Movie, error)
+}
+
+type GenreSearchRepo interface {
+	Search(ctx context.Context, query string, limit int) ([]models.Genre, error)
+}
+
+type UserSearchRepo interface {
+	SearchByUsername(ctx context.Context, query string, limit int) ([]models.User, error)
+}
+
+// SearchService backs the site-wide search box: one query, a few results
+// of each kind.
+type SearchService struct {
+	movies MovieSearchRepo
+	genres GenreSearchRepo
+	users  UserSearchRepo
+}
+
+func NewSearchService(movies MovieSearchRepo, genres GenreSearchRepo, users UserSearchRepo) *SearchService {
+	return &SearchService{movies: movies, genres: genres, users: users}
+}
+
+// Search matches query against movie titles, directors and descriptions and
+// against genre names, and against usernames when includeUsers is set. Each
+// group holds at most SearchResultsPerType hits.
+func (s *SearchService) Search(ctx context.Context, query string, includeUsers bool) (*models.SearchResults, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < MinSearchQueryLength {
+		return nil, ErrSearchQueryTooShort
+	}
+
+	movies, err := s.movies.Search(ctx, query, SearchResultsPerType)
+	if err != nil {
+		return nil, err
+	}
+	genres, err := s.genres.Search(ctx, query, SearchResultsPerType)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &models.SearchResults{
+		Movies: make([]models.SearchHit, len(movies)),
+		Genres: make([]models.SearchHit, len(genres)),
+	}
+	for i, m := range movies {
+		results.Movies[i] = models.SearchHit{Type: "movie", ID: m.ID, Title: m.Title, ReleaseYear: m.ReleaseYear, Director: m.Director}
+	}
+	for i, g := range genres {
+		results.Genres[i] = models.SearchHit{Type: "genre", ID: g.ID, Title: g.Name}
+	}
+
+	if includeUsers {
+		users, err := s.users.SearchByUsername(ctx, query, SearchResultsPerType)
+		if err != nil {
+			return nil, err
+		}
+		results.Users = make([]models.SearchHit, len(users))
+		for i, u := range users {
+			results.Users[i] = models.SearchHit{Type: "user", ID: u.ID, Title: u.Username}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+type SentimentAnalyzer interface {
+	Analyze(content string) (float64, error)
+}
+
+var (
+	positiveWords = wordSet("amazing", "awesome", "beautiful", "brilliant", "captivating", "charming",
+		"enjoyable", "excellent", "fantastic", "fun", "funny", "good", "great", "impressive", "love",
+		"loved", "masterpiece", "moving", "perfect", "powerful", "recommend", "stunning", "superb",
+		"touching", "wonderful")
+	negativeWords = wordSet("awful", "bad", "boring", "disappointing", "dull", "hate", "hated",
+		"horrible", "mediocre", "mess", "pointless", "poor", "predictable", "ridiculous", "slow",
+		"stupid", "terrible", "tedious", "waste", "weak", "worst")
+)
+
+func wordSet(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+// BuiltinSentimentAnalyzer scores text by the balance of positive and
+// negative words it contains. Scores range from -1 (all negative) to 1
+// (all positive); text with no known words scores 0.
+type BuiltinSentimentAnalyzer struct{}
+
+func (BuiltinSentimentAnalyzer) Analyze(content string) (float64, error) {
+	words := strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	var pos, neg int
+	for _, w := range words {
+		if _, ok := positiveWords[w]; ok {
+			pos++
+		} else if _, ok := negativeWords[w]; ok {
+			neg++
+		}
+	}
+	if pos+neg == 0 {
+		return 0, nil
+	}
+	return float64(pos-neg) / float64(pos+neg), nil
+}
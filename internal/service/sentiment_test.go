@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+func TestBuiltinSentimentAnalyzer(t *testing.T) {
+	analyzer := BuiltinSentimentAnalyzer{}
+
+	cases := []struct {
+		content string
+		check   func(float64) bool
+	}{
+		{"great wonderful amazing", func(s float64) bool { return s > 0 }},
+		{"Boring, predictable and a total waste.", func(s float64) bool { return s < 0 }},
+		{"Great cast but a boring plot.", func(s float64) bool { return s == 0 }},
+		{"I watched it on Tuesday.", func(s float64) bool { return s == 0 }},
+	}
+
+	for _, tc := range cases {
+		score, err := analyzer.Analyze(tc.content)
+		if err != nil {
+			t.Fatalf("analyze %q: %v", tc.content, err)
+		}
+		if !tc.check(score) {
+			t.Fatalf("unexpected score %v for %q", score, tc.content)
+		}
+	}
+}
+
+type sentimentReviewRepo struct {
+	reviews map[int]*models.Review
+	scored  chan int
+}
+
+func (r *sentimentReviewRepo) GetByID(ctx context.Context, id int) (*models.Review, error) {
+	return r.reviews[id], nil
+}
+
+func (r *sentimentReviewRepo) UpdateSentimentScore(ctx context.Context, reviewID int, score float64) error {
+	r.reviews[reviewID].SentimentScore = &score
+	r.scored <- reviewID
+	return nil
+}
+
+type noopMovieRater struct{}
+
+func (noopMovieRater) UpdateAverageRating(ctx context.Context, movieID int) error    { return nil }
+func (noopMovieRater) UpdateNormalizedRating(ctx context.Context, movieID int) error { return nil }
+
+func TestReviewWorker_ScoresSentiment(t *testing.T) {
+	repo := &sentimentReviewRepo{
+		reviews: map[int]*models.Review{1: {ID: 1, MovieID: 1, Title: "Masterpiece", Content: "great wonderful amazing"}},
+		scored:  make(chan int, 1),
+	}
+	events := make(chan ReviewEvent, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	StartReviewWorker(ctx, events, noopMovieRater{}, nil, repo, BuiltinSentimentAnalyzer{})
+	events <- ReviewEvent{Type: EventReviewCreated, MovieID: 1, ReviewID: 1, Time: time.Now()}
+
+	select {
+	case <-repo.scored:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for sentiment score")
+	}
+	if score := repo.reviews[1].SentimentScore; score == nil || *score != 1 {
+		t.Fatalf("expected score 1, got %v", score)
+	}
+}
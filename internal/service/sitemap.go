@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"golang-project/internal/models"
+	"golang-project/internal/sitemap"
+)
+
+// SitemapTTL is how long a rendered sitemap is served before it is rebuilt.
+const SitemapTTL = time.Hour
+
+var ErrSitemapNotFound = errors.New("sitemap not found")
+
+type SitemapMovieRepo interface {
+	ListSitemapMovies(ctx context.Context) ([]models.SitemapMovie, error)
+}
+
+// SitemapService renders the public movie pages as a sitemap and keeps the
+// result in memory for SitemapTTL.
+type SitemapService struct {
+	movies  SitemapMovieRepo
+	siteURL string
+	perFile int
+	now     func() time.Time
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	files   [][]byte
+	expires time.Time
+}
+
+// NewSitemapService links movies under siteURL, e.g.
+// https://example.com/movies/42-heat.
+func NewSitemapService(movies SitemapMovieRepo, siteURL string, opts ...Option) *SitemapService {
+	o := applyOptions(opts)
+	return &SitemapService{
+		movies:  movies,
+		siteURL: strings.TrimRight(siteURL, "/"),
+		perFile: sitemap.MaxURLsPerFile,
+		now:     o.now,
+	}
+}
+
+// MovieSlug is the path segment of a movie page. The ID keeps it unique and
+// stable when two movies share a title.
+func MovieSlug(id int, title string) string {
+	if slug := sitemap.Slug(title); slug != "" {
+		return strconv.Itoa(id) + "-" + slug
+	}
+	return strconv.Itoa(id)
+}
+
+// SitemapPartPath is where part n of a split sitemap is served.
+func SitemapPartPath(n int) string {
+	return fmt.Sprintf("/sitemaps/%d.xml", n)
+}
+
+// File returns sitemap file n: 0 is /sitemap.xml, which becomes an index
+// of parts 1.. once the catalogue outgrows a single file.
+func (s *SitemapService) File(ctx context.Context, n int) ([]byte, error) {
+	files, err := s.render(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n >= len(files) {
+		return nil, ErrSitemapNotFound
+	}
+	return files[n], nil
+}
+
+func (s *SitemapService) render(ctx context.Context) ([][]byte, error) {
+	s.mu.Lock()
+	files, expires := s.files, s.expires
+	s.mu.Unlock()
+	if files != nil && s.now().Before(expires) {
+		return files, nil
+	}
+
+	v, err, _ := s.group.Do("sitemap", func() (interface{}, error) {
+		movies, err := s.movies.ListSitemapMovies(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		urls := make([]sitemap.URL, len(movies))
+		for i, m := range movies {
+			urls[i] = sitemap.URL{
+				Loc:     s.siteURL + "/movies/" + url.PathEscape(MovieSlug(m.ID, m.Title)),
+				LastMod: m.UpdatedAt,
+			}
+		}
+		files, err := sitemap.Render(urls, s.perFile, func(n int) string { return s.siteURL + SitemapPartPath(n) })
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.files = files
+		s.expires = s.now().Add(SitemapTTL)
+		s.mu.Unlock()
+		return files, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([][]byte), nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"golang-project/internal/models"
+)
+
+const DefaultAdminStatsTTL = 30 * time.Second
+
+// AdminStatsCacheKey is the key writes pass to CacheInvalidator when they
+// change the counts in AdminStats.
+const AdminStatsCacheKey = "admin_stats"
+
+// CacheInvalidator drops cached data that a write has made stale.
+type CacheInvalidator interface {
+	Invalidate(key string)
+}
+
+// WithCacheInvalidator makes MovieService and ReviewService invalidate
+// cached stats after creating or deleting movies and reviews.
+func WithCacheInvalidator(cache CacheInvalidator) Option {
+	return func(o *options) {
+		if cache != nil {
+			o.cache = cache
+		}
+	}
+}
+
+type noopCacheInvalidator struct{}
+
+func (noopCacheInvalidator) Invalidate(key string) {}
+
+// AdminStatsCache serves admin dashboard stats from memory for a short TTL.
+// Concurrent misses share a single computation. Responses may be up to ttl
+// old; AdminStats.GeneratedAt tells clients how fresh they are.
+type AdminStatsCache struct {
+	compute func(ctx context.Context) (*models.AdminStats, error)
+	ttl     time.Duration
+	now     func() time.Time
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	stats   *models.AdminStats
+	expires time.Time
+	// gen counts invalidations, so a computation that started before one
+	// does not store its already stale result.
+	gen uint64
+}
+
+func NewAdminStatsCache(compute func(ctx context.Context) (*models.AdminStats, error), ttl time.Duration) *AdminStatsCache {
+	if ttl <= 0 {
+		ttl = DefaultAdminStatsTTL
+	}
+	return &AdminStatsCache{compute: compute, ttl: ttl, now: time.Now}
+}
+
+// Get returns cached stats, recomputing them when expired or when refresh is set.
+func (c *AdminStatsCache) Get(ctx context.Context, refresh bool) (*models.AdminStats, error) {
+	if !refresh {
+		c.mu.Lock()
+		stats, expires := c.stats, c.expires
+		c.mu.Unlock()
+		if stats != nil && c.now().Before(expires) {
+			return stats, nil
+		}
+	}
+
+	v, err, _ := c.group.Do(AdminStatsCacheKey, func() (interface{}, error) {
+		c.mu.Lock()
+		gen := c.gen
+		c.mu.Unlock()
+
+		// Detach from the caller so one cancelled request doesn't fail the
+		// others waiting on the same computation.
+		stats, err := c.compute(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		if c.gen == gen {
+			c.stats = stats
+			c.expires = c.now().Add(c.ttl)
+		}
+		c.mu.Unlock()
+		return stats, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*models.AdminStats), nil
+}
+
+// Invalidate drops the cached stats when key is AdminStatsCacheKey; the next
+// Get recomputes them.
+func (c *AdminStatsCache) Invalidate(key string) {
+	if key != AdminStatsCacheKey {
+		return
+	}
+	c.mu.Lock()
+	c.stats = nil
+	c.gen++
+	c.mu.Unlock()
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+func TestAdminStatsCache(t *testing.T) {
+	var calls int32
+	cache := NewAdminStatsCache(func(ctx context.Context) (*models.AdminStats, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return &models.AdminStats{TotalUsers: int(n), GeneratedAt: time.Now()}, nil
+	}, time.Minute)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	first, err := cache.Get(context.Background(), false)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	second, _ := cache.Get(context.Background(), false)
+	if first != second || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected cached stats, got %d computations", calls)
+	}
+
+	refreshed, _ := cache.Get(context.Background(), true)
+	if refreshed.TotalUsers != 2 {
+		t.Fatalf("expected refresh to recompute, got %+v", refreshed)
+	}
+
+	now = now.Add(2 * time.Minute)
+	expired, _ := cache.Get(context.Background(), false)
+	if expired.TotalUsers != 3 {
+		t.Fatalf("expected recompute after ttl, got %+v", expired)
+	}
+
+	cache.Invalidate(AdminStatsCacheKey)
+	if invalidated, _ := cache.Get(context.Background(), false); invalidated.TotalUsers != 4 {
+		t.Fatalf("expected recompute after invalidate, got %+v", invalidated)
+	}
+}
+
+func TestAdminStatsCache_SharesConcurrentMisses(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	cache := NewAdminStatsCache(func(ctx context.Context) (*models.AdminStats, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return &models.AdminStats{TotalMovies: 7}, nil
+	}, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats, err := cache.Get(context.Background(), false)
+			if err != nil || stats.TotalMovies != 7 {
+				t.Errorf("unexpected result %+v err %v", stats, err)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected one shared computation, got %d", calls)
+	}
+}
+
+func TestAdminStatsCache_InvalidatedByWrites(t *testing.T) {
+	ctx := context.Background()
+	users := testutil.NewMemUserRepo()
+	movies := testutil.NewMemMovieRepo()
+	reviews := testutil.NewMemReviewRepo()
+	genres := testutil.NewMemGenreRepo()
+	genres.Add(&models.Genre{ID: 1, Name: "Drama"})
+	v := validator.New()
+
+	userSvc := NewUserService(users, reviews, v, nil)
+	cache := NewAdminStatsCache(func(ctx context.Context) (*models.AdminStats, error) {
+		return userSvc.GetAdminStats(ctx, users, movies, reviews, genres)
+	}, time.Hour)
+	movieSvc := NewMovieService(movies, genres, v, WithCacheInvalidator(cache))
+	reviewSvc := NewReviewService(reviews, movies, v, nil, WithCacheInvalidator(cache))
+
+	if stats, err := cache.Get(ctx, false); err != nil || stats.TotalMovies != 0 {
+		t.Fatalf("expected no movies yet, got %+v (err %v)", stats, err)
+	}
+
+	movie, err := movieSvc.Create(ctx, models.CreateMovieRequest{Title: "Heat", ReleaseYear: 1995, DurationMinutes: 170, GenreIDs: []string{"1"}}, false)
+	if err != nil {
+		t.Fatalf("create movie: %v", err)
+	}
+	if stats, _ := cache.Get(ctx, false); stats.TotalMovies != 1 {
+		t.Fatalf("expected the new movie to bust the cache, got %+v", stats)
+	}
+
+	if _, err := reviewSvc.Create(ctx, movie.ID, 1, models.CreateReviewRequest{Rating: 8, Title: "Tense", Content: "Great shootout"}, false); err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+	if stats, _ := cache.Get(ctx, false); stats.TotalReviews != 1 {
+		t.Fatalf("expected the new review to bust the cache, got %+v", stats)
+	}
+}
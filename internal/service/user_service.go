@@ -4,16 +4,29 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/sync/errgroup"
 
 	"golang-project/internal/models"
 )
 
+// MaxBulkRoleUsers bounds how many users BulkUpdateRole changes at once.
+const MaxBulkRoleUsers = 50
+
 var (
 	ErrUserNotFound     = errors.New("user not found")
 	ErrInvalidRole      = errors.New("invalid role")
 	ErrCannotDeleteSelf = errors.New("cannot delete yourself")
+	ErrCannotUpdateSelf = errors.New("cannot change your own role")
+	ErrNoUserIDs        = errors.New("user_ids required")
+	ErrUserLookupQuery  = errors.New("exactly one of email or username required")
+	ErrInvalidAuditSort = errors.New("sort must be created_desc or created_asc")
+	ErrTooManyUserIDs   = fmt.Errorf("user_ids accepts at most %d values", MaxBulkRoleUsers)
 	allowedRoles        = map[string]struct{}{"user": {}, "admin": {}}
 )
 
@@ -22,18 +35,23 @@ type UserRepo interface {
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.User, error)
 	List(ctx context.Context, filters models.UserFilters, limit, offset int) ([]models.User, int, error)
 	UpdateRole(ctx context.Context, id int, role string) error
+	UpdateRoles(ctx context.Context, ids []int, role string) error
 	Update(ctx context.Context, id int, email, username string) error
 	UpdatePassword(ctx context.Context, id int, passwordHash string) error
+	SetEmailOnDigest(ctx context.Context, id int, enabled bool) error
+	SetReviewsPrivate(ctx context.Context, id int, private bool) error
 	Delete(ctx context.Context, id int) error
 	Count(ctx context.Context) (int, error)
 	CountLast7Days(ctx context.Context) (int, error)
 }
 
 type ReviewStatsRepo interface {
-	GetAverageRatingByUserID(ctx context.Context, userID int) (float64, error)
+	GetUserRatingStats(ctx context.Context, userID int) (mean, stddev float64, err error)
 	GetFavoriteGenreByUserID(ctx context.Context, userID int) (*models.Genre, error)
+	CountByUserID(ctx context.Context, userID int) (int, error)
 }
 
 type UserService struct {
@@ -41,6 +59,10 @@ type UserService struct {
 	reviewStats    ReviewStatsRepo
 	validator      *validator.Validate
 	passwordHasher PasswordHasher
+	audit          AuditWriter
+	merger         AccountMerger
+	watches        WatchCounter
+	now            func() time.Time
 }
 
 type PasswordHasher interface {
@@ -48,12 +70,17 @@ type PasswordHasher interface {
 	CheckPassword(hash, password string) error
 }
 
-func NewUserService(repo UserRepo, reviewStats ReviewStatsRepo, v *validator.Validate, passwordHasher PasswordHasher) *UserService {
+func NewUserService(repo UserRepo, reviewStats ReviewStatsRepo, v *validator.Validate, passwordHasher PasswordHasher, opts ...Option) *UserService {
+	o := applyOptions(opts)
 	return &UserService{
 		repo:           repo,
 		reviewStats:    reviewStats,
 		validator:      v,
 		passwordHasher: passwordHasher,
+		audit:          o.audit,
+		merger:         o.accountMerger,
+		watches:        o.watchCounter,
+		now:            o.now,
 	}
 }
 
@@ -68,6 +95,35 @@ func (s *UserService) GetByID(ctx context.Context, id int) (*models.User, error)
 	return user, nil
 }
 
+// Lookup finds a user by exact email or username; exactly one of them must
+// be given.
+func (s *UserService) Lookup(ctx context.Context, email, username string) (*models.User, error) {
+	email, username = strings.TrimSpace(email), strings.TrimSpace(username)
+	var user *models.User
+	var err error
+	switch {
+	case email != "" && username == "":
+		user, err = s.repo.GetByEmail(ctx, email)
+	case username != "" && email == "":
+		user, err = s.repo.GetByUsername(ctx, username)
+	default:
+		return nil, ErrUserLookupQuery
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// GetByIDs loads several users at once, in no particular order. Unknown IDs
+// are left out.
+func (s *UserService) GetByIDs(ctx context.Context, ids []int) ([]models.User, error) {
+	if len(ids) == 0 {
+		return []models.User{}, nil
+	}
+	return s.repo.GetByIDs(ctx, ids)
+}
+
 func (s *UserService) List(ctx context.Context, filters models.UserFilters, page, limit int) (*models.PaginatedResponse, error) {
 	if page <= 0 {
 		page = 1
@@ -97,7 +153,75 @@ func (s *UserService) UpdateRole(ctx context.Context, id int, role string) error
 	if _, err := s.repo.GetByID(ctx, id); err != nil {
 		return ErrUserNotFound
 	}
-	return s.repo.UpdateRole(ctx, id, role)
+	if err := s.repo.UpdateRole(ctx, id, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	recordAudit(ctx, s.audit, &models.AuditLog{UserID: &id, Event: "user_role_updated", Details: "role=" + role})
+	return nil
+}
+
+// BulkError is the reason one user of a bulk operation was skipped.
+type BulkError struct {
+	UserID int
+	Err    error
+}
+
+// BulkUpdateRole sets role on every user in userIDs except adminID and IDs
+// that do not exist, which are reported back instead. The remaining users
+// are updated in one transaction. Duplicate IDs count once.
+func (s *UserService) BulkUpdateRole(ctx context.Context, userIDs []int, role string, adminID int) (int, []BulkError, error) {
+	if _, ok := allowedRoles[role]; !ok {
+		return 0, nil, ErrInvalidRole
+	}
+	if len(userIDs) == 0 {
+		return 0, nil, ErrNoUserIDs
+	}
+	if len(userIDs) > MaxBulkRoleUsers {
+		return 0, nil, ErrTooManyUserIDs
+	}
+
+	existing, err := s.repo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return 0, nil, err
+	}
+	found := make(map[int]bool, len(existing))
+	for _, u := range existing {
+		found[u.ID] = true
+	}
+
+	var ids []int
+	var failures []BulkError
+	seen := make(map[int]bool, len(userIDs))
+	for _, id := range userIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		switch {
+		case id == adminID:
+			failures = append(failures, BulkError{UserID: id, Err: ErrCannotUpdateSelf})
+		case !found[id]:
+			failures = append(failures, BulkError{UserID: id, Err: ErrUserNotFound})
+		default:
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > 0 {
+		if err := s.repo.UpdateRoles(ctx, ids, role); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return 0, nil, ErrUserNotFound
+			}
+			return 0, nil, err
+		}
+	}
+	for _, id := range ids {
+		recordAudit(ctx, s.audit, &models.AuditLog{UserID: &id, Event: "user_role_updated", Details: "role=" + role})
+	}
+	return len(ids), failures, nil
 }
 
 func (s *UserService) Update(ctx context.Context, id int, req models.UpdateUserRequest) error {
@@ -136,7 +260,19 @@ func (s *UserService) Update(ctx context.Context, id int, req models.UpdateUserR
 		}
 	}
 
-	return s.repo.Update(ctx, id, email, username)
+	if err := s.repo.Update(ctx, id, email, username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if req.EmailOnDigest != nil {
+		if err := s.repo.SetEmailOnDigest(ctx, id, *req.EmailOnDigest); err != nil {
+			return err
+		}
+	}
+	recordAudit(ctx, s.audit, &models.AuditLog{UserID: &id, Event: "user_updated"})
+	return nil
 }
 
 func (s *UserService) Delete(ctx context.Context, id int, adminID int) error {
@@ -144,7 +280,7 @@ func (s *UserService) Delete(ctx context.Context, id int, adminID int) error {
 		return ErrCannotDeleteSelf
 	}
 
-	_, err := s.repo.GetByID(ctx, id)
+	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrUserNotFound
@@ -152,7 +288,27 @@ func (s *UserService) Delete(ctx context.Context, id int, adminID int) error {
 		return err
 	}
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	// user_id would dangle after the delete, so the target is kept in details.
+	recordAudit(ctx, s.audit, &models.AuditLog{Event: "user_deleted", Details: fmt.Sprintf("user_id=%d email=%s", id, user.Email)})
+	return nil
+}
+
+// UpdatePrivacy sets whether other users may list userID's reviews.
+func (s *UserService) UpdatePrivacy(ctx context.Context, userID int, reviewsPublic bool) error {
+	if err := s.repo.SetReviewsPrivate(ctx, userID, !reviewsPublic); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	recordAudit(ctx, s.audit, &models.AuditLog{UserID: &userID, Event: "user_privacy_updated", Details: fmt.Sprintf("reviews_public=%t", reviewsPublic)})
+	return nil
 }
 
 func (s *UserService) UpdateProfile(ctx context.Context, userID int, req models.UpdateUserRequest) (*models.User, error) {
@@ -185,11 +341,17 @@ func (s *UserService) UpdatePassword(ctx context.Context, userID int, currentPas
 		return err
 	}
 
-	return s.repo.UpdatePassword(ctx, userID, hash)
+	if err := s.repo.UpdatePassword(ctx, userID, hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
 }
 
 func (s *UserService) GetUserStats(ctx context.Context, userID int) (*models.UserStats, error) {
-	avgRating, err := s.reviewStats.GetAverageRatingByUserID(ctx, userID)
+	avgRating, _, err := s.reviewStats.GetUserRatingStats(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -209,60 +371,130 @@ func (s *UserService) GetUserStats(ctx context.Context, userID int) (*models.Use
 	return stats, nil
 }
 
-func (s *UserService) GetAdminStats(ctx context.Context, userRepo UserRepo, movieRepo MovieCountRepo, reviewRepo ReviewCountRepo, genreRepo GenreCountRepo) (*models.AdminStats, error) {
-	totalUsers, err := userRepo.Count(ctx)
+// GetPublicProfile returns what anyone may see about a user: no email or
+// role, just their name and review stats. Deleted users are ErrUserNotFound.
+func (s *UserService) GetPublicProfile(ctx context.Context, userID int) (*models.PublicUserProfile, error) {
+	user, err := s.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-
-	totalMovies, err := movieRepo.Count(ctx)
+	stats, err := s.GetUserStats(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-
-	totalReviews, err := reviewRepo.Count(ctx)
+	count, err := s.reviewStats.CountByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	return &models.PublicUserProfile{
+		ID:            user.ID,
+		Username:      user.Username,
+		CreatedAt:     user.CreatedAt,
+		ReviewCount:   count,
+		AverageRating: stats.AverageRating,
+		FavoriteGenre: stats.FavoriteGenre,
+	}, nil
+}
 
-	totalGenres, err := genreRepo.Count(ctx)
+// GetActivity summarizes a user's own activity for their profile header.
+// WatchlistSize stays 0 unless a WatchCounter was configured.
+func (s *UserService) GetActivity(ctx context.Context, userID int) (*models.AccountActivity, error) {
+	user, err := s.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-
-	avgRating, err := movieRepo.GetAverageRating(ctx)
+	stats, err := s.GetUserStats(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-
-	usersLast7Days, err := userRepo.CountLast7Days(ctx)
+	count, err := s.reviewStats.CountByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	activity := &models.AccountActivity{
+		TotalReviews:   count,
+		LastReviewAt:   user.LastReviewAt,
+		AverageRating:  stats.AverageRating,
+		FavoriteGenre:  stats.FavoriteGenre,
+		AccountAgeDays: int(s.now().Sub(user.CreatedAt).Hours() / 24),
+	}
+	if s.watches != nil {
+		if _, activity.WatchlistSize, err = s.watches.GetByUser(ctx, userID, 1, 0); err != nil {
+			return nil, err
+		}
+	}
+	return activity, nil
+}
 
-	reviewsLast7Days, err := reviewRepo.CountLast7Days(ctx)
-	if err != nil {
+func (s *UserService) GetAdminStats(ctx context.Context, userRepo UserRepo, movieRepo MovieCountRepo, reviewRepo ReviewCountRepo, genreRepo GenreCountRepo) (*models.AdminStats, error) {
+	stats := &models.AdminStats{}
+	g, ctx := errgroup.WithContext(ctx)
+
+	count := func(dst *int, fn func(context.Context) (int, error)) {
+		g.Go(func() error {
+			v, err := fn(ctx)
+			if err != nil {
+				return err
+			}
+			*dst = v
+			return nil
+		})
+	}
+	count(&stats.TotalUsers, userRepo.Count)
+	count(&stats.TotalMovies, movieRepo.Count)
+	count(&stats.TotalReviews, reviewRepo.Count)
+	count(&stats.TotalGenres, genreRepo.Count)
+	count(&stats.UsersLast7Days, userRepo.CountLast7Days)
+	count(&stats.ReviewsLast7Days, reviewRepo.CountLast7Days)
+	count(&stats.MoviesLast7Days, movieRepo.CountLast7Days)
+	g.Go(func() error {
+		avg, err := movieRepo.GetAverageRating(ctx)
+		if err != nil {
+			return err
+		}
+		stats.AverageRating = avg
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
+	stats.GeneratedAt = s.now()
+	return stats, nil
+}
 
-	moviesLast7Days, err := movieRepo.CountLast7Days(ctx)
+// GetMoviesByDecade returns movie counts per release decade in ascending order.
+// With includeEmpty, decades between the earliest and latest one that have no
+// movies are returned with a zero count.
+func (s *UserService) GetMoviesByDecade(ctx context.Context, movieRepo MovieCountRepo, includeEmpty bool) ([]models.DecadeCount, error) {
+	counts, err := movieRepo.CountByDecade(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if counts == nil {
+		counts = []models.DecadeCount{}
+	}
+	if !includeEmpty || len(counts) < 2 {
+		return counts, nil
+	}
 
-	return &models.AdminStats{
-		TotalUsers:       totalUsers,
-		TotalMovies:      totalMovies,
-		TotalReviews:     totalReviews,
-		TotalGenres:      totalGenres,
-		AverageRating:    avgRating,
-		UsersLast7Days:   usersLast7Days,
-		ReviewsLast7Days: reviewsLast7Days,
-		MoviesLast7Days:  moviesLast7Days,
-	}, nil
+	filled := make([]models.DecadeCount, 0, (counts[len(counts)-1].Decade-counts[0].Decade)/10+1)
+	i := 0
+	for decade := counts[0].Decade; decade <= counts[len(counts)-1].Decade; decade += 10 {
+		if counts[i].Decade == decade {
+			filled = append(filled, counts[i])
+			i++
+			continue
+		}
+		filled = append(filled, models.DecadeCount{Decade: decade})
+	}
+	return filled, nil
 }
 
 func (s *UserService) ListAuditLogs(ctx context.Context, auditRepo AuditLogRepo, filters models.AuditLogFilters, page, limit int) (*models.PaginatedResponse, error) {
+	if filters.Sort != "" && !slices.Contains(models.AuditLogSorts, filters.Sort) {
+		return nil, ErrInvalidAuditSort
+	}
 	if page <= 0 {
 		page = 1
 	}
@@ -286,10 +518,38 @@ func (s *UserService) ListAuditLogs(ctx context.Context, auditRepo AuditLogRepo,
 	}, nil
 }
 
+// ExportAuditLogs returns the next batch of entries after afterID in ID
+// order, for exports that walk every matching entry.
+func (s *UserService) ExportAuditLogs(ctx context.Context, auditRepo AuditLogRepo, filters models.AuditLogFilters, afterID, limit int) ([]models.AuditLog, error) {
+	return auditRepo.ListAfter(ctx, filters, afterID, limit)
+}
+
+// ListOwnAuditLogs lists the audit entries about userID, without internal fields.
+func (s *UserService) ListOwnAuditLogs(ctx context.Context, auditRepo AuditLogRepo, userID, page, limit int) (*models.PaginatedResponse, error) {
+	resp, err := s.ListAuditLogs(ctx, auditRepo, models.AuditLogFilters{UserID: &userID}, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	logs := resp.Data.([]models.AuditLog)
+	own := make([]models.OwnAuditLog, 0, len(logs))
+	for _, l := range logs {
+		own = append(own, models.OwnAuditLog{
+			ID:        l.ID,
+			MovieID:   l.MovieID,
+			ReviewID:  l.ReviewID,
+			Event:     l.Event,
+			CreatedAt: l.CreatedAt,
+		})
+	}
+	resp.Data = own
+	return resp, nil
+}
+
 type MovieCountRepo interface {
 	Count(ctx context.Context) (int, error)
 	GetAverageRating(ctx context.Context) (float64, error)
 	CountLast7Days(ctx context.Context) (int, error)
+	CountByDecade(ctx context.Context) ([]models.DecadeCount, error)
 }
 
 type ReviewCountRepo interface {
@@ -303,4 +563,5 @@ type GenreCountRepo interface {
 
 type AuditLogRepo interface {
 	List(ctx context.Context, filters models.AuditLogFilters, limit, offset int) ([]models.AuditLog, int, error)
+	ListAfter(ctx context.Context, filters models.AuditLogFilters, afterID, limit int) ([]models.AuditLog, error)
 }
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+func TestUserService_List(t *testing.T) {
+	users := testutil.NewMemUserRepo()
+	svc := NewUserService(users, testutil.NewMemReviewRepo(), validator.New(), nil)
+
+	users.Add(&models.User{Email: "alice@example.com", Username: "alice", Role: "admin"})
+	users.Add(&models.User{Email: "bob@example.com", Username: "bob", Role: "user"})
+	users.Add(&models.User{Email: "carol@example.com", Username: "carol", Role: "user"})
+
+	resp, err := svc.List(context.Background(), models.UserFilters{Role: "user"}, 1, 1)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	page, ok := resp.Data.([]models.User)
+	if !ok || len(page) != 1 {
+		t.Fatalf("expected one user on the page, got %#v", resp.Data)
+	}
+	if resp.Total != 2 || resp.TotalPages != 2 || page[0].Role != "user" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	resp, err = svc.List(context.Background(), models.UserFilters{Search: "ALICE"}, 1, 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page := resp.Data.([]models.User); resp.Total != 1 || page[0].Username != "alice" {
+		t.Fatalf("expected search to match alice, got %+v", resp.Data)
+	}
+}
+
+func TestUserService_GetUserStats(t *testing.T) {
+	reviews := testutil.NewMemReviewRepo()
+	svc := NewUserService(testutil.NewMemUserRepo(), reviews, validator.New(), nil)
+	for i, rating := range []int{2, 4, 9} {
+		reviews.Add(&models.Review{MovieID: i + 1, UserID: 1, Rating: rating})
+	}
+	reviews.Add(&models.Review{MovieID: 1, UserID: 2, Rating: 7})
+
+	stats, err := svc.GetUserStats(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.AverageRating != 5 {
+		t.Fatalf("expected mean 5, got %+v", stats)
+	}
+}
+
+func TestUserService_BulkUpdateRole(t *testing.T) {
+	users := testutil.NewMemUserRepo()
+	svc := NewUserService(users, testutil.NewMemReviewRepo(), validator.New(), nil)
+	ctx := context.Background()
+
+	admin := &models.User{Email: "admin@example.com", Username: "admin", Role: "admin"}
+	editor := &models.User{Email: "editor@example.com", Username: "editor", Role: "user"}
+	users.Add(admin)
+	users.Add(editor)
+
+	updated, failures, err := svc.BulkUpdateRole(ctx, []int{editor.ID, 999, admin.ID}, "admin", admin.ID)
+	if err != nil {
+		t.Fatalf("bulk update: %v", err)
+	}
+	if updated != 1 || len(failures) != 2 {
+		t.Fatalf("expected 1 update and 2 failures, got %d and %+v", updated, failures)
+	}
+	if failures[0].UserID != 999 || !errors.Is(failures[0].Err, ErrUserNotFound) {
+		t.Fatalf("expected 999 to be reported missing, got %+v", failures[0])
+	}
+	if failures[1].UserID != admin.ID || !errors.Is(failures[1].Err, ErrCannotUpdateSelf) {
+		t.Fatalf("expected the admin's own ID to be refused, got %+v", failures[1])
+	}
+	if u, _ := users.GetByID(ctx, editor.ID); u.Role != "admin" {
+		t.Fatalf("expected editor to become admin, got %q", u.Role)
+	}
+
+	if _, _, err := svc.BulkUpdateRole(ctx, []int{editor.ID}, "editor", admin.ID); !errors.Is(err, ErrInvalidRole) {
+		t.Fatalf("expected ErrInvalidRole, got %v", err)
+	}
+	tooMany := make([]int, MaxBulkRoleUsers+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+	if _, _, err := svc.BulkUpdateRole(ctx, tooMany, "user", admin.ID); !errors.Is(err, ErrTooManyUserIDs) {
+		t.Fatalf("expected ErrTooManyUserIDs, got %v", err)
+	}
+}
+
+// staleUserLookup reports every requested user as existing, standing in for
+// a user deleted between the lookup and the update.
+type staleUserLookup struct {
+	*testutil.MemUserRepo
+}
+
+func (r staleUserLookup) GetByIDs(ctx context.Context, ids []int) ([]models.User, error) {
+	users := make([]models.User, len(ids))
+	for i, id := range ids {
+		users[i] = models.User{ID: id}
+	}
+	return users, nil
+}
+
+func TestUserService_BulkUpdateRole_UserDeletedMeanwhile(t *testing.T) {
+	users := testutil.NewMemUserRepo()
+	svc := NewUserService(staleUserLookup{users}, testutil.NewMemReviewRepo(), validator.New(), nil)
+	ctx := context.Background()
+
+	editor := &models.User{Email: "editor@example.com", Username: "editor", Role: "user"}
+	users.Add(editor)
+
+	if _, _, err := svc.BulkUpdateRole(ctx, []int{editor.ID, 999}, "admin", 0); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if u, _ := users.GetByID(ctx, editor.ID); u.Role != "user" {
+		t.Fatalf("expected the update to be rolled back, got role %q", u.Role)
+	}
+}
+
+// staleUserByID still finds a user that was soft-deleted after the lookup.
+type staleUserByID struct {
+	*testutil.MemUserRepo
+	user *models.User
+}
+
+func (r staleUserByID) GetByID(ctx context.Context, id int) (*models.User, error) {
+	return r.user, nil
+}
+
+func TestUserService_WritesToDeletedUser(t *testing.T) {
+	users := testutil.NewMemUserRepo()
+	merged := &models.User{Email: "merged@example.com", Username: "merged", Role: "user"}
+	users.Add(merged)
+	if err := users.SoftDelete(merged.ID); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	svc := NewUserService(staleUserByID{users, merged}, testutil.NewMemReviewRepo(), validator.New(), nil)
+	ctx := context.Background()
+
+	if err := svc.UpdateRole(ctx, merged.ID, "admin"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("update role: expected ErrUserNotFound, got %v", err)
+	}
+	if err := svc.Update(ctx, merged.ID, models.UpdateUserRequest{Username: "renamed"}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("update: expected ErrUserNotFound, got %v", err)
+	}
+	if err := svc.Delete(ctx, merged.ID, 0); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("delete: expected ErrUserNotFound, got %v", err)
+	}
+}
+
+// favoriteGenreReviews fills in the favorite genre MemReviewRepo leaves nil.
+type favoriteGenreReviews struct {
+	*testutil.MemReviewRepo
+	genre *models.Genre
+}
+
+func (r favoriteGenreReviews) GetFavoriteGenreByUserID(ctx context.Context, userID int) (*models.Genre, error) {
+	return r.genre, nil
+}
+
+func TestUserService_GetActivity(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lastReview := now.Add(-2 * time.Hour)
+	users := testutil.NewMemUserRepo()
+	users.Add(&models.User{ID: 1, Email: "alice@example.com", Username: "alice", CreatedAt: now.AddDate(0, 0, -45).Add(time.Hour), LastReviewAt: &lastReview})
+	users.Add(&models.User{ID: 2, Email: "bob@example.com", Username: "bob", CreatedAt: now})
+
+	reviews := favoriteGenreReviews{MemReviewRepo: testutil.NewMemReviewRepo(), genre: &models.Genre{ID: 3, Name: "Drama"}}
+	for movieID, rating := range map[int]int{1: 6, 2: 8, 3: 10} {
+		if err := reviews.Create(ctx, &models.Review{MovieID: movieID, UserID: 1, Rating: rating}); err != nil {
+			t.Fatalf("create review: %v", err)
+		}
+	}
+	_ = reviews.Create(ctx, &models.Review{MovieID: 1, UserID: 2, Rating: 1})
+	watches := testutil.NewMemWatchHistoryRepo()
+	for movieID := 1; movieID <= 4; movieID++ {
+		_ = watches.RecordWatch(ctx, &models.WatchHistory{UserID: 1, MovieID: movieID, WatchedAt: now})
+	}
+
+	svc := NewUserService(users, reviews, validator.New(), nil, WithWatchCounter(watches), WithClock(func() time.Time { return now }))
+	got, err := svc.GetActivity(ctx, 1)
+	if err != nil {
+		t.Fatalf("get activity: %v", err)
+	}
+	want := models.AccountActivity{
+		TotalReviews:   3,
+		LastReviewAt:   &lastReview,
+		AverageRating:  8,
+		FavoriteGenre:  reviews.genre,
+		WatchlistSize:  4,
+		AccountAgeDays: 44,
+	}
+	if *got != want {
+		t.Fatalf("expected %+v, got %+v", want, *got)
+	}
+
+	if _, err := svc.GetActivity(ctx, 99); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("missing user: expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUserService_GetAdminStats_GeneratedAt(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	users := testutil.NewMemUserRepo()
+	reviews := testutil.NewMemReviewRepo()
+	svc := NewUserService(users, reviews, validator.New(), nil, WithClock(func() time.Time { return now }))
+
+	stats, err := svc.GetAdminStats(context.Background(), users, testutil.NewMemMovieRepo(), reviews, testutil.NewMemGenreRepo())
+	if err != nil {
+		t.Fatalf("get admin stats: %v", err)
+	}
+	if !stats.GeneratedAt.Equal(now) {
+		t.Fatalf("expected generated_at from the clock, got %v", stats.GeneratedAt)
+	}
+}
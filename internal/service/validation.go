@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationResult describes validation problems found for a single item of a batch.
+type ValidationResult struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+// Valid reports whether the item passed validation.
+func (r ValidationResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// validationMessages turns validator errors into short human readable messages
+// such as "title required" or "release_year must be at least 1800".
+func validationMessages(err error) []string {
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return []string{err.Error()}
+	}
+	msgs := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		field := snakeCase(fe.Field())
+		switch fe.Tag() {
+		case "required":
+			msgs = append(msgs, field+" required")
+		case "min":
+			msgs = append(msgs, field+" must be at least "+fe.Param())
+		case "max":
+			msgs = append(msgs, field+" must be at most "+fe.Param())
+		default:
+			msgs = append(msgs, field+" is invalid")
+		}
+	}
+	return msgs
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(rune(s[i-1])) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+)
+
+var ErrWatchNotFound = errors.New("watch history entry not found")
+
+type WatchHistoryRepo interface {
+	RecordWatch(ctx context.Context, w *models.WatchHistory) error
+	GetByUser(ctx context.Context, userID, limit, offset int) ([]models.WatchHistory, int, error)
+	GetByUserAndMovie(ctx context.Context, userID, movieID int) (*models.WatchHistory, error)
+	Delete(ctx context.Context, userID, movieID int) error
+}
+
+// WatchCounter reports how many movies a user has watched; GetByUser's
+// total is all UserService.GetActivity needs.
+type WatchCounter interface {
+	GetByUser(ctx context.Context, userID, limit, offset int) ([]models.WatchHistory, int, error)
+}
+
+// WithWatchCounter makes UserService.GetActivity report the size of the
+// user's watch history.
+func WithWatchCounter(watches WatchCounter) Option {
+	return func(o *options) {
+		o.watchCounter = watches
+	}
+}
+
+type WatchHistoryService struct {
+	history   WatchHistoryRepo
+	movies    MovieLookup
+	validator *validator.Validate
+	now       func() time.Time
+}
+
+func NewWatchHistoryService(history WatchHistoryRepo, movies MovieLookup, v *validator.Validate, opts ...Option) *WatchHistoryService {
+	o := applyOptions(opts)
+	return &WatchHistoryService{history: history, movies: movies, validator: v, now: o.now}
+}
+
+// RecordWatch marks the movie as watched by the user. Watching a movie again
+// replaces the earlier entry's watched_at and progress_percent.
+func (s *WatchHistoryService) RecordWatch(ctx context.Context, userID int, req models.RecordWatchRequest) (*models.WatchHistory, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	movie, err := s.movies.GetByID(ctx, req.MovieID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+	if movie.Status == models.MovieStatusPending {
+		return nil, ErrMovieNotFound
+	}
+
+	w := &models.WatchHistory{
+		UserID:          userID,
+		MovieID:         req.MovieID,
+		WatchedAt:       s.now().UTC(),
+		ProgressPercent: req.ProgressPercent,
+	}
+	if err := s.history.RecordWatch(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// List returns a page of the user's watch history, most recent first.
+func (s *WatchHistoryService) List(ctx context.Context, userID, page, limit int) (*models.PaginatedResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	history, total, err := s.history.GetByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaginatedResponse{
+		Data:       history,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+func (s *WatchHistoryService) Delete(ctx context.Context, userID, movieID int) error {
+	if err := s.history.Delete(ctx, userID, movieID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrWatchNotFound
+		}
+		return err
+	}
+	return nil
+}
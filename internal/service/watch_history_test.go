@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+func TestWatchHistoryService_RecordWatchUpserts(t *testing.T) {
+	const userID = 7
+
+	movies := testutil.NewMemMovieRepo()
+	movies.Add(&models.Movie{ID: 5, Title: "Heat"})
+	history := testutil.NewMemWatchHistoryRepo()
+	now := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	svc := NewWatchHistoryService(history, movies, validator.New(), WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	half, full := 50, 100
+	if _, err := svc.RecordWatch(ctx, userID, models.RecordWatchRequest{MovieID: 5, ProgressPercent: &half}); err != nil {
+		t.Fatalf("first watch: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := svc.RecordWatch(ctx, userID, models.RecordWatchRequest{MovieID: 5, ProgressPercent: &full}); err != nil {
+		t.Fatalf("second watch: %v", err)
+	}
+
+	page, err := svc.List(ctx, userID, 1, 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	entries := page.Data.([]models.WatchHistory)
+	if page.Total != 1 || len(entries) != 1 {
+		t.Fatalf("expected a single entry, got %+v", page)
+	}
+	if w := entries[0]; !w.WatchedAt.Equal(now) || w.ProgressPercent == nil || *w.ProgressPercent != 100 {
+		t.Fatalf("expected entry updated to the second watch, got %+v", w)
+	}
+
+	if _, err := svc.RecordWatch(ctx, userID, models.RecordWatchRequest{MovieID: 99}); !errors.Is(err, ErrMovieNotFound) {
+		t.Fatalf("expected ErrMovieNotFound, got %v", err)
+	}
+	over := 101
+	if _, err := svc.RecordWatch(ctx, userID, models.RecordWatchRequest{MovieID: 5, ProgressPercent: &over}); err == nil {
+		t.Fatal("expected progress above 100 to be rejected")
+	}
+
+	yes, no := true, false
+	movieSvc := NewMovieService(movies, testutil.NewMemGenreRepo(), validator.New(), WithWatchHistory(history))
+	for _, tc := range []struct {
+		userID int
+		want   *bool
+	}{{userID, &yes}, {userID + 1, &no}, {0, nil}} {
+		movie, err := movieSvc.GetWithUserContext(ctx, 5, tc.userID, false)
+		if err != nil {
+			t.Fatalf("get for user %d: %v", tc.userID, err)
+		}
+		if (movie.Watched == nil) != (tc.want == nil) || (tc.want != nil && *movie.Watched != *tc.want) {
+			t.Fatalf("user %d: expected watched %v, got %v", tc.userID, tc.want, movie.Watched)
+		}
+	}
+
+	if err := svc.Delete(ctx, userID, 5); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := svc.Delete(ctx, userID, 5); !errors.Is(err, ErrWatchNotFound) {
+		t.Fatalf("expected ErrWatchNotFound, got %v", err)
+	}
+}
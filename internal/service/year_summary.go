@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+// YearSummaryTTL is how long a year's summary is served from memory before
+// it is recomputed.
+const YearSummaryTTL = time.Hour
+
+var ErrYearNotFound = errors.New("no movies released that year")
+
+type YearSummaryRepo interface {
+	GetYearSummary(ctx context.Context, year int) (*models.YearSummary, error)
+}
+
+// YearSummaryService builds the "year in review" summaries and keeps each
+// one in memory for YearSummaryTTL.
+type YearSummaryService struct {
+	repo YearSummaryRepo
+	now  func() time.Time
+
+	mu      sync.Mutex
+	cached  map[int]*models.YearSummary
+	expires map[int]time.Time
+}
+
+func NewYearSummaryService(repo YearSummaryRepo, opts ...Option) *YearSummaryService {
+	o := applyOptions(opts)
+	return &YearSummaryService{
+		repo:    repo,
+		now:     o.now,
+		cached:  make(map[int]*models.YearSummary),
+		expires: make(map[int]time.Time),
+	}
+}
+
+// Get returns the summary of the movies released in year. A year without
+// published movies is ErrYearNotFound, and is not cached so the summary
+// appears as soon as one is added.
+func (s *YearSummaryService) Get(ctx context.Context, year int) (*models.YearSummary, error) {
+	s.mu.Lock()
+	summary, expires := s.cached[year], s.expires[year]
+	s.mu.Unlock()
+	if summary != nil && s.now().Before(expires) {
+		return summary, nil
+	}
+
+	summary, err := s.repo.GetYearSummary(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	if summary.TotalMovies == 0 {
+		return nil, ErrYearNotFound
+	}
+	if summary.TopReviewers == nil {
+		summary.TopReviewers = []models.TopReviewer{}
+	}
+
+	s.mu.Lock()
+	s.cached[year] = summary
+	s.expires[year] = s.now().Add(YearSummaryTTL)
+	s.mu.Unlock()
+	return summary, nil
+}
@@ -0,0 +1,125 @@
+// Package sitemap renders sitemaps in the sitemaps.org 0.9 format.
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxURLsPerFile is the most URLs the protocol allows in one sitemap file.
+const MaxURLsPerFile = 50000
+
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlset struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []entry  `xml:"url"`
+}
+
+type index struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []entry  `xml:"sitemap"`
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func newEntry(loc string, lastMod time.Time) entry {
+	e := entry{Loc: loc}
+	if !lastMod.IsZero() {
+		e.LastMod = lastMod.UTC().Format(time.RFC3339)
+	}
+	return e
+}
+
+// Render returns the files of a sitemap for urls. files[0] is served at
+// /sitemap.xml: a plain urlset when urls fit in one file of perFile entries,
+// otherwise an index of the parts files[1:], which partURL(n) locates.
+func Render(urls []URL, perFile int, partURL func(n int) string) ([][]byte, error) {
+	if len(urls) <= perFile {
+		file, err := renderURLSet(urls)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{file}, nil
+	}
+
+	idx := index{Xmlns: xmlns}
+	files := [][]byte{nil}
+	for start := 0; start < len(urls); start += perFile {
+		part := urls[start:min(start+perFile, len(urls))]
+		file, err := renderURLSet(part)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+		idx.Sitemaps = append(idx.Sitemaps, newEntry(partURL(len(files)-1), latest(part)))
+	}
+	var err error
+	if files[0], err = marshal(idx); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func renderURLSet(urls []URL) ([]byte, error) {
+	set := urlset{Xmlns: xmlns, URLs: make([]entry, len(urls))}
+	for i, u := range urls {
+		set.URLs[i] = newEntry(u.Loc, u.LastMod)
+	}
+	return marshal(set)
+}
+
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("encode sitemap: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func latest(urls []URL) time.Time {
+	var t time.Time
+	for _, u := range urls {
+		if u.LastMod.After(t) {
+			t = u.LastMod
+		}
+	}
+	return t
+}
+
+// Slug lowercases s and joins its runs of letters and digits with hyphens,
+// e.g. "Léon: The Professional" becomes "léon-the-professional".
+func Slug(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
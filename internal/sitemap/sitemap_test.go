@@ -0,0 +1,101 @@
+package sitemap
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("update %s: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s mismatch\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func testURLs(n int) []URL {
+	urls := make([]URL, n)
+	for i := range urls {
+		urls[i] = URL{
+			Loc:     fmt.Sprintf("https://example.com/movies/%d-movie?a=1&b=2", i+1),
+			LastMod: time.Date(2025, 3, i+1, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600)),
+		}
+	}
+	urls[n-1].LastMod = time.Time{}
+	return urls
+}
+
+func partURL(n int) string {
+	return fmt.Sprintf("https://example.com/sitemaps/%d.xml", n)
+}
+
+func TestRender_Golden(t *testing.T) {
+	files, err := Render(testURLs(3), MaxURLsPerFile, partURL)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected a single urlset, got %d files", len(files))
+	}
+	golden(t, "urlset.xml", files[0])
+
+	files, err = Render(testURLs(3), 2, partURL)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	golden(t, "index.xml", files[0])
+}
+
+func TestRender_SplitThreshold(t *testing.T) {
+	for _, tc := range []struct{ urls, perFile, files int }{
+		{0, 2, 1},
+		{2, 2, 1},
+		{3, 2, 3},
+		{4, 2, 3},
+		{5, 2, 4},
+	} {
+		var urls []URL
+		if tc.urls > 0 {
+			urls = testURLs(tc.urls)
+		}
+		files, err := Render(urls, tc.perFile, partURL)
+		if err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		if len(files) != tc.files {
+			t.Errorf("%d urls, %d per file: expected %d files, got %d", tc.urls, tc.perFile, tc.files, len(files))
+		}
+		if len(files) > 1 && !bytes.Contains(files[0], []byte("<sitemapindex")) {
+			t.Errorf("%d urls, %d per file: expected an index first, got\n%s", tc.urls, tc.perFile, files[0])
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	for in, want := range map[string]string{
+		"Heat":                     "heat",
+		"Léon: The Professional":   "léon-the-professional",
+		"  2001: A Space Odyssey ": "2001-a-space-odyssey",
+		"!!!":                      "",
+	} {
+		if got := Slug(in); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
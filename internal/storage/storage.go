@@ -0,0 +1,36 @@
+// Package storage keeps uploaded files such as movie posters.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// Storage stores objects under flat keys such as "12-9f2c0a1b.png".
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Presigner is implemented by backends that can hand out temporary direct
+// download links, so objects are served without passing through the API.
+type Presigner interface {
+	PresignGet(key string, ttl time.Duration) (string, error)
+}
+
+// ValidKey reports whether key is a single path element that cannot escape
+// the storage root.
+func ValidKey(key string) bool {
+	return key != "" && key != "." && key != ".." &&
+		!strings.ContainsAny(key, `/\`) && path.Clean(key) == key
+}
@@ -0,0 +1,61 @@
+package testutil
+
+import "context"
+
+// MemAccountMerger merges accounts across the in-memory repositories. Watches
+// may be nil when a test has no watch history.
+type MemAccountMerger struct {
+	Users   *MemUserRepo
+	Reviews *MemReviewRepo
+	Watches *MemWatchHistoryRepo
+}
+
+func (m MemAccountMerger) MergeInto(ctx context.Context, sourceID, targetID int) (int, error) {
+	if _, err := m.Users.GetByID(ctx, sourceID); err != nil {
+		return 0, err
+	}
+	moved := m.Reviews.reassign(sourceID, targetID)
+	if m.Watches != nil {
+		m.Watches.reassign(sourceID, targetID)
+	}
+	return moved, m.Users.SoftDelete(sourceID)
+}
+
+// reassign moves sourceID's reviews to targetID, except for movies targetID
+// has already reviewed.
+func (r *MemReviewRepo) reassign(sourceID, targetID int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reviewed := make(map[int]bool)
+	for _, rv := range r.data {
+		if rv.UserID == targetID {
+			reviewed[rv.MovieID] = true
+		}
+	}
+	moved := 0
+	for _, id := range sortedKeys(r.data) {
+		if rv := r.data[id]; rv.UserID == sourceID && !reviewed[rv.MovieID] {
+			rv.UserID = targetID
+			moved++
+		}
+	}
+	return moved
+}
+
+// reassign moves sourceID's watch history to targetID, except for movies
+// targetID has already watched.
+func (r *MemWatchHistoryRepo) reassign(sourceID, targetID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, w := range r.entries {
+		if k.userID != sourceID {
+			continue
+		}
+		to := watchKey{targetID, k.movieID}
+		if _, ok := r.entries[to]; !ok {
+			delete(r.entries, k)
+			w.UserID = targetID
+			r.entries[to] = w
+		}
+	}
+}
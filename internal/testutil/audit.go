@@ -0,0 +1,91 @@
+package testutil
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+type MemAuditRepo struct {
+	mu   sync.Mutex
+	logs []models.AuditLog
+}
+
+func NewMemAuditRepo() *MemAuditRepo {
+	return &MemAuditRepo{logs: make([]models.AuditLog, 0)}
+}
+
+// Logs returns a copy of everything inserted so far, oldest first.
+func (r *MemAuditRepo) Logs() []models.AuditLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.AuditLog(nil), r.logs...)
+}
+
+func (r *MemAuditRepo) Insert(ctx context.Context, log *models.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log.ID = len(r.logs) + 1
+	log.CreatedAt = time.Now()
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+func (r *MemAuditRepo) List(ctx context.Context, filters models.AuditLogFilters, limit, offset int) ([]models.AuditLog, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	filtered := make([]models.AuditLog, 0)
+	for _, log := range r.logs {
+		if auditMatches(log, filters) {
+			filtered = append(filtered, log)
+		}
+	}
+	// Entries are kept in insertion order, which is created_asc.
+	if filters.Sort != "created_asc" {
+		slices.Reverse(filtered)
+	}
+	page, total := paginate(filtered, limit, offset)
+	return page, total, nil
+}
+
+func (r *MemAuditRepo) ListAfter(ctx context.Context, filters models.AuditLogFilters, afterID, limit int) ([]models.AuditLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]models.AuditLog, 0)
+	for _, log := range r.logs {
+		if log.ID > afterID && auditMatches(log, filters) {
+			res = append(res, log)
+			if len(res) == limit {
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func auditMatches(log models.AuditLog, filters models.AuditLogFilters) bool {
+	if filters.Event != "" && log.Event != filters.Event {
+		return false
+	}
+	if filters.UserID != nil && (log.UserID == nil || *log.UserID != *filters.UserID) {
+		return false
+	}
+	if filters.ActorID != nil && (log.ActorID == nil || *log.ActorID != *filters.ActorID) {
+		return false
+	}
+	if filters.FromDate != nil && log.CreatedAt.Before(*filters.FromDate) {
+		return false
+	}
+	if filters.ToDate != nil && log.CreatedAt.After(*filters.ToDate) {
+		return false
+	}
+	if search := strings.ToLower(filters.Search); search != "" &&
+		!strings.Contains(strings.ToLower(log.Event), search) && !strings.Contains(strings.ToLower(log.Details), search) {
+		return false
+	}
+	return true
+}
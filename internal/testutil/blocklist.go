@@ -0,0 +1,70 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+type MemBlockedKeywordRepo struct {
+	mu       sync.Mutex
+	nextID   int
+	keywords map[int]models.BlockedKeyword
+}
+
+func NewMemBlockedKeywordRepo() *MemBlockedKeywordRepo {
+	return &MemBlockedKeywordRepo{nextID: 1, keywords: make(map[int]models.BlockedKeyword)}
+}
+
+func (r *MemBlockedKeywordRepo) List(ctx context.Context) ([]models.BlockedKeyword, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keywords := make([]models.BlockedKeyword, 0, len(r.keywords))
+	for _, id := range sortedKeys(r.keywords) {
+		keywords = append(keywords, r.keywords[id])
+	}
+	return keywords, nil
+}
+
+func (r *MemBlockedKeywordRepo) GetByID(ctx context.Context, id int) (*models.BlockedKeyword, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k, ok := r.keywords[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &k, nil
+}
+
+func (r *MemBlockedKeywordRepo) Create(ctx context.Context, k *models.BlockedKeyword) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k.ID = r.nextID
+	r.nextID++
+	k.CreatedAt = time.Now()
+	r.keywords[k.ID] = *k
+	return nil
+}
+
+func (r *MemBlockedKeywordRepo) Update(ctx context.Context, k *models.BlockedKeyword) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keywords[k.ID]; !ok {
+		return sql.ErrNoRows
+	}
+	r.keywords[k.ID] = *k
+	return nil
+}
+
+func (r *MemBlockedKeywordRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keywords[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.keywords, id)
+	return nil
+}
@@ -0,0 +1,6 @@
+// Package testutil provides in-memory implementations of the repository
+// interfaces used by the service layer. It is imported only from _test.go
+// files, so none of it ends up in the production binaries. Tests should use
+// these rather than defining their own fakes, so the fakes keep up with the
+// interfaces in one place.
+package testutil
@@ -0,0 +1,70 @@
+package factory
+
+import (
+	"fmt"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+// Repos is a set of in-memory repositories holding one catalogue.
+type Repos struct {
+	Users   *testutil.MemUserRepo
+	Genres  *testutil.MemGenreRepo
+	Movies  *testutil.MemMovieRepo
+	Reviews *testutil.MemReviewRepo
+}
+
+func NewRepos() Repos {
+	return Repos{
+		Users:   testutil.NewMemUserRepo(),
+		Genres:  testutil.NewMemGenreRepo(),
+		Movies:  testutil.NewMemMovieRepo(),
+		Reviews: testutil.NewMemReviewRepo(),
+	}
+}
+
+// Catalog is what SeedCatalog stored, in creation order.
+type Catalog struct {
+	Genre   *models.Genre
+	Movies  []*models.Movie
+	Users   []*models.User
+	Reviews []*models.Review
+}
+
+// SeedCatalog stores nMovies movies in one new genre and spreads nReviews
+// reviews over them round-robin, creating just enough users that nobody
+// reviews a movie twice. Ratings vary from 1 to 10, and each movie's
+// ReviewCount and AverageRating match its reviews.
+func SeedCatalog(repos Repos, nMovies, nReviews int) Catalog {
+	cat := Catalog{Genre: &models.Genre{Name: fmt.Sprintf("Genre %d", next())}}
+	repos.Genres.Add(cat.Genre)
+
+	for range nMovies {
+		movie := NewMovie()
+		repos.Movies.Add(movie, cat.Genre.ID)
+		cat.Movies = append(cat.Movies, movie)
+	}
+	if nMovies == 0 {
+		return cat
+	}
+
+	for range (nReviews + nMovies - 1) / nMovies {
+		user := NewUser()
+		repos.Users.Add(user)
+		cat.Users = append(cat.Users, user)
+	}
+
+	sums := make([]int, nMovies)
+	for i := range nReviews {
+		movie, user := cat.Movies[i%nMovies], cat.Users[i/nMovies]
+		review := NewReview(ReviewOf(movie), ReviewBy(user), Rated(1+i*3%10))
+		repos.Reviews.Add(review)
+		cat.Reviews = append(cat.Reviews, review)
+
+		sums[i%nMovies] += review.Rating
+		movie.ReviewCount++
+		movie.AverageRating = float64(sums[i%nMovies]) / float64(movie.ReviewCount)
+	}
+	return cat
+}
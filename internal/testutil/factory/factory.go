@@ -0,0 +1,156 @@
+// Package factory builds valid models for tests with sensible defaults, so a
+// test only spells out the fields it cares about:
+//
+//	admin := factory.NewUser(factory.Role("admin"))
+//	movie := factory.NewMovie(factory.Year(1995), factory.Director("Michael Mann"))
+//	review := factory.NewReview(factory.ReviewOf(movie), factory.ReviewBy(admin), factory.Rated(9))
+//
+// Options are plain functions on the model, so a test can also pass its own
+// func(*models.Movie) for a field without a named option.
+package factory
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-project/internal/models"
+	"golang-project/pkg/jwt"
+)
+
+// DefaultPassword is the password of every user built without Password.
+const DefaultPassword = "password123"
+
+// seq makes the generated emails, usernames and titles unique across a test
+// binary, even when tests run in parallel.
+var seq atomic.Int64
+
+func next() int64 { return seq.Add(1) }
+
+// defaultHash is DefaultPassword hashed once, as bcrypt is slow on purpose.
+var defaultHash = sync.OnceValue(func() string { return hash(DefaultPassword) })
+
+func hash(password string) string {
+	h, err := jwt.HashPassword(password)
+	if err != nil {
+		panic(fmt.Sprintf("factory: hash password: %v", err))
+	}
+	return h
+}
+
+type UserOption func(*models.User)
+
+// NewUser returns an active user with public reviews, a unique username and
+// email, the "user" role and DefaultPassword. Its ID is set once it is stored.
+func NewUser(opts ...UserOption) *models.User {
+	n := next()
+	now := time.Now()
+	user := &models.User{
+		Email:     fmt.Sprintf("user%d@example.com", n),
+		Username:  fmt.Sprintf("user%d", n),
+		Role:      "user",
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, opt := range opts {
+		opt(user)
+	}
+	if user.PasswordHash == "" {
+		user.PasswordHash = defaultHash()
+	}
+	return user
+}
+
+// Username sets the username and derives the email from it.
+func Username(name string) UserOption {
+	return func(u *models.User) {
+		u.Username = name
+		u.Email = name + "@example.com"
+	}
+}
+
+func Email(email string) UserOption {
+	return func(u *models.User) { u.Email = email }
+}
+
+func Role(role string) UserOption {
+	return func(u *models.User) { u.Role = role }
+}
+
+// Password hashes password as registration would.
+func Password(password string) UserOption {
+	return func(u *models.User) { u.PasswordHash = hash(password) }
+}
+
+type MovieOption func(*models.Movie)
+
+// NewMovie returns a published two-hour movie from 2000 with a unique title.
+func NewMovie(opts ...MovieOption) *models.Movie {
+	n := next()
+	now := time.Now()
+	movie := &models.Movie{
+		Title:           fmt.Sprintf("Movie %d", n),
+		Description:     "A movie made for tests.",
+		ReleaseYear:     2000,
+		Director:        fmt.Sprintf("Director %d", n),
+		DurationMinutes: 120,
+		Status:          models.MovieStatusPublished,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	for _, opt := range opts {
+		opt(movie)
+	}
+	return movie
+}
+
+func Title(title string) MovieOption {
+	return func(m *models.Movie) { m.Title = title }
+}
+
+func Director(director string) MovieOption {
+	return func(m *models.Movie) { m.Director = director }
+}
+
+func Year(year int) MovieOption {
+	return func(m *models.Movie) { m.ReleaseYear = year }
+}
+
+// Rating sets the stored average rating, as the review worker would.
+func Rating(rating float64) MovieOption {
+	return func(m *models.Movie) { m.AverageRating = rating }
+}
+
+type ReviewOption func(*models.Review)
+
+// NewReview returns a review rated 7 with a unique title. Pass ReviewOf and
+// ReviewBy to attach it to a stored movie and user.
+func NewReview(opts ...ReviewOption) *models.Review {
+	n := next()
+	now := time.Now()
+	review := &models.Review{
+		Rating:    7,
+		Title:     fmt.Sprintf("Review %d", n),
+		Content:   "Worth watching.",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, opt := range opts {
+		opt(review)
+	}
+	return review
+}
+
+func ReviewOf(movie *models.Movie) ReviewOption {
+	return func(r *models.Review) { r.MovieID = movie.ID }
+}
+
+func ReviewBy(user *models.User) ReviewOption {
+	return func(r *models.Review) { r.UserID = user.ID }
+}
+
+func Rated(rating int) ReviewOption {
+	return func(r *models.Review) { r.Rating = rating }
+}
@@ -0,0 +1,58 @@
+package factory_test
+
+import (
+	"context"
+	"testing"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil/factory"
+	"golang-project/pkg/jwt"
+)
+
+func TestNewUser(t *testing.T) {
+	a, b := factory.NewUser(), factory.NewUser(factory.Username("admin"), factory.Role("admin"))
+	if a.Email == "" || a.Username == "" || a.Email == b.Email || a.Username == b.Username {
+		t.Fatalf("expected distinct users, got %+v and %+v", a, b)
+	}
+	if b.Email != "admin@example.com" || b.Role != "admin" || a.Role != "user" {
+		t.Fatalf("options not applied: %+v", b)
+	}
+	if err := jwt.CheckPassword(a.PasswordHash, factory.DefaultPassword); err != nil {
+		t.Fatalf("expected the default password to match: %v", err)
+	}
+}
+
+func TestSeedCatalog(t *testing.T) {
+	ctx := context.Background()
+	repos := factory.NewRepos()
+	cat := factory.SeedCatalog(repos, 3, 7)
+
+	if len(cat.Movies) != 3 || len(cat.Users) != 3 || len(cat.Reviews) != 7 {
+		t.Fatalf("expected 3 movies, 3 users and 7 reviews, got %d, %d and %d", len(cat.Movies), len(cat.Users), len(cat.Reviews))
+	}
+	if n, _ := repos.Reviews.Count(ctx); n != 7 {
+		t.Fatalf("expected 7 stored reviews, got %d", n)
+	}
+
+	seen := make(map[[2]int]bool)
+	for _, movie := range cat.Movies {
+		reviews, err := repos.Reviews.GetByMovieID(ctx, movie.ID, models.ReviewFilters{}, 10, 0)
+		if err != nil || len(reviews) != movie.ReviewCount {
+			t.Fatalf("movie %d: review count %d, stored %d (err %v)", movie.ID, movie.ReviewCount, len(reviews), err)
+		}
+		sum := 0
+		for _, r := range reviews {
+			if seen[[2]int{r.MovieID, r.UserID}] {
+				t.Fatalf("user %d reviewed movie %d twice", r.UserID, r.MovieID)
+			}
+			seen[[2]int{r.MovieID, r.UserID}] = true
+			sum += r.Rating
+		}
+		if want := float64(sum) / float64(len(reviews)); movie.AverageRating != want {
+			t.Fatalf("movie %d: average %v, want %v", movie.ID, movie.AverageRating, want)
+		}
+		if genres, _ := repos.Movies.GetGenresByMovieID(ctx, movie.ID); len(genres) != 1 || genres[0].ID != cat.Genre.ID {
+			t.Fatalf("movie %d: expected the seeded genre, got %+v", movie.ID, genres)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+type MemGenreRepo struct {
+	mu     sync.Mutex
+	nextID int
+	data   map[int]*models.Genre
+	stats  map[int]models.GenreStats
+}
+
+func NewMemGenreRepo() *MemGenreRepo {
+	return &MemGenreRepo{
+		nextID: 1,
+		data:   make(map[int]*models.Genre),
+		stats:  make(map[int]models.GenreStats),
+	}
+}
+
+// Add stores genre as-is, assigning an ID only when it has none.
+func (r *MemGenreRepo) Add(genre *models.Genre) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.put(genre)
+}
+
+func (r *MemGenreRepo) put(genre *models.Genre) {
+	if genre.ID == 0 {
+		genre.ID = r.nextID
+	}
+	if genre.ID >= r.nextID {
+		r.nextID = genre.ID + 1
+	}
+	r.data[genre.ID] = genre
+}
+
+// SetStats sets what Stats reports for the genre.
+func (r *MemGenreRepo) SetStats(id int, stats models.GenreStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats[id] = stats
+}
+
+func (r *MemGenreRepo) GetAll(ctx context.Context) ([]models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]models.Genre, 0, len(r.data))
+	for _, id := range sortedKeys(r.data) {
+		res = append(res, *r.data[id])
+	}
+	return res, nil
+}
+
+func (r *MemGenreRepo) GetByID(ctx context.Context, id int) (*models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if g, ok := r.data[id]; ok {
+		return g, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *MemGenreRepo) GetByName(ctx context.Context, name string) (*models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, g := range r.data {
+		if g.Name == name {
+			return g, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *MemGenreRepo) Create(ctx context.Context, genre *models.Genre) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	genre.ID = 0
+	genre.CreatedAt = time.Now()
+	r.put(genre)
+	return nil
+}
+
+func (r *MemGenreRepo) Update(ctx context.Context, genre *models.Genre) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.data[genre.ID]; !ok {
+		return sql.ErrNoRows
+	}
+	r.data[genre.ID] = genre
+	return nil
+}
+
+func (r *MemGenreRepo) ImportGenres(ctx context.Context, genres []models.BulkGenreInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byName := make(map[string]*models.Genre, len(r.data))
+	for _, g := range r.data {
+		byName[g.Name] = g
+	}
+	created := make(map[string]bool, len(genres))
+	for _, in := range genres {
+		if _, ok := byName[in.Name]; !ok {
+			g := &models.Genre{Name: in.Name, CreatedAt: time.Now()}
+			r.put(g)
+			byName[g.Name] = g
+			created[g.Name] = true
+		}
+	}
+	for _, in := range genres {
+		if in.Parent == "" || !created[in.Name] {
+			continue
+		}
+		parent, ok := byName[in.Parent]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		parentID := parent.ID
+		byName[in.Name].ParentID = &parentID
+	}
+	return nil
+}
+
+func (r *MemGenreRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.data[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.data, id)
+	return nil
+}
+
+func (r *MemGenreRepo) Stats(ctx context.Context, id int) (*models.GenreStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats[id]
+	return &stats, nil
+}
+
+func (r *MemGenreRepo) Count(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.data), nil
+}
+
+func (r *MemGenreRepo) Search(ctx context.Context, query string, limit int) ([]models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]models.Genre, 0)
+	for _, id := range sortedKeys(r.data) {
+		if g := r.data[id]; strings.Contains(strings.ToLower(g.Name), strings.ToLower(query)) {
+			res = append(res, *g)
+		}
+	}
+	page, _ := paginate(res, limit, 0)
+	return page, nil
+}
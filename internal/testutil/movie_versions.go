@@ -0,0 +1,69 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+// MemMovieVersionRepo keeps versions of the movies in a MemMovieRepo.
+type MemMovieVersionRepo struct {
+	mu       sync.Mutex
+	movies   *MemMovieRepo
+	versions []models.MovieVersion
+}
+
+func NewMemMovieVersionRepo(movies *MemMovieRepo) *MemMovieVersionRepo {
+	return &MemMovieVersionRepo{movies: movies}
+}
+
+func (r *MemMovieVersionRepo) UpdateMovie(ctx context.Context, movie *models.Movie, editedByUserID *int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.movies.mu.Lock()
+	defer r.movies.mu.Unlock()
+	prev, ok := r.movies.movies[movie.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	r.versions = append(r.versions, models.MovieVersion{
+		ID:              len(r.versions) + 1,
+		MovieID:         prev.ID,
+		Title:           prev.Title,
+		Description:     prev.Description,
+		ReleaseYear:     prev.ReleaseYear,
+		Director:        prev.Director,
+		DurationMinutes: prev.DurationMinutes,
+		EditedByUserID:  editedByUserID,
+		CreatedAt:       time.Now(),
+	})
+	movie.UpdatedAt = time.Now()
+	r.movies.movies[movie.ID] = movie
+	return nil
+}
+
+func (r *MemMovieVersionRepo) ListByMovie(ctx context.Context, movieID, limit, offset int) ([]models.MovieVersion, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions := []models.MovieVersion{}
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if r.versions[i].MovieID == movieID {
+			versions = append(versions, r.versions[i])
+		}
+	}
+	page, total := paginate(versions, limit, offset)
+	return page, total, nil
+}
+
+func (r *MemMovieVersionRepo) GetByID(ctx context.Context, movieID, id int) (*models.MovieVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id < 1 || id > len(r.versions) || r.versions[id-1].MovieID != movieID {
+		return nil, sql.ErrNoRows
+	}
+	v := r.versions[id-1]
+	return &v, nil
+}
@@ -0,0 +1,630 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+type MemMovieRepo struct {
+	mu          sync.Mutex
+	nextID      int
+	movies      map[int]*models.Movie
+	movieGenres map[int][]int
+	reviewers   map[int][]int
+	deleted     map[int]bool
+	// ratedFrom, when set, is what UpdateAverageRating and
+	// UpdateNormalizedRating recompute from; see RateFrom.
+	ratedFrom *MemReviewRepo
+}
+
+// RateFrom makes UpdateAverageRating and UpdateNormalizedRating recompute
+// a movie's ratings from the reviews stored in reviews, the way the Postgres
+// repository does. Without it they leave the stored values alone.
+func (r *MemMovieRepo) RateFrom(reviews *MemReviewRepo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ratedFrom = reviews
+}
+
+func NewMemMovieRepo() *MemMovieRepo {
+	return &MemMovieRepo{
+		nextID:      1,
+		movies:      make(map[int]*models.Movie),
+		movieGenres: make(map[int][]int),
+		reviewers:   make(map[int][]int),
+		deleted:     make(map[int]bool),
+	}
+}
+
+// Add stores movie as-is, assigning an ID only when it has none.
+func (r *MemMovieRepo) Add(movie *models.Movie, genreIDs ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.put(movie)
+	if len(genreIDs) > 0 {
+		r.movieGenres[movie.ID] = genreIDs
+	}
+}
+
+func (r *MemMovieRepo) put(movie *models.Movie) {
+	if movie.ID == 0 {
+		movie.ID = r.nextID
+	}
+	if movie.ID >= r.nextID {
+		r.nextID = movie.ID + 1
+	}
+	r.movies[movie.ID] = movie
+}
+
+// AddReviewers records that userIDs reviewed the movie, for
+// GetDirectorCoOccurrences.
+func (r *MemMovieRepo) AddReviewers(movieID int, userIDs ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reviewers[movieID] = append(r.reviewers[movieID], userIDs...)
+}
+
+// Len reports how many movies are stored.
+func (r *MemMovieRepo) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.movies)
+}
+
+func (r *MemMovieRepo) List(ctx context.Context, filters models.MovieFilters, limit, offset int) ([]models.Movie, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := make([]models.Movie, 0, len(r.movies))
+	for _, id := range sortedKeys(r.movies) {
+		m := r.movies[id]
+		if m.Status == models.MovieStatusPending {
+			continue
+		}
+		if filters.GenreID != nil && !slices.Contains(r.movieGenres[id], *filters.GenreID) {
+			continue
+		}
+		if filters.Year != 0 && m.ReleaseYear != filters.Year {
+			continue
+		}
+		if filters.MinRating > 0 && m.AverageRating < filters.MinRating {
+			continue
+		}
+		if filters.Search != "" {
+			search := strings.ToLower(filters.Search)
+			if !strings.Contains(strings.ToLower(m.Title), search) && !strings.Contains(strings.ToLower(m.Description), search) {
+				continue
+			}
+		}
+		if len(filters.DirectorIn) > 0 && !slices.Contains(filters.DirectorIn, m.Director) {
+			continue
+		}
+		if filters.Director != "" && !strings.Contains(strings.ToLower(m.Director), strings.ToLower(filters.Director)) {
+			continue
+		}
+		all = append(all, *m)
+	}
+	page, total := paginate(all, limit, offset)
+	return page, total, nil
+}
+
+func (r *MemMovieRepo) ListByDirector(ctx context.Context, director string, limit, offset int) ([]models.Movie, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := make([]models.Movie, 0)
+	for _, id := range sortedKeys(r.movies) {
+		if m := r.movies[id]; strings.EqualFold(m.Director, director) && m.Status != models.MovieStatusPending {
+			all = append(all, *m)
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].ReleaseYear < all[j].ReleaseYear })
+	page, total := paginate(all, limit, offset)
+	return page, total, nil
+}
+
+func (r *MemMovieRepo) GetByIDs(ctx context.Context, ids []int) ([]models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	movies := make([]models.Movie, 0, len(ids))
+	for _, id := range ids {
+		if m, ok := r.movies[id]; ok && m.Status != models.MovieStatusPending {
+			movie := *m
+			movie.Genres = r.genresOf(id)
+			movies = append(movies, movie)
+		}
+	}
+	return movies, nil
+}
+
+func (r *MemMovieRepo) GetByID(ctx context.Context, id int) (*models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.movies[id]; ok {
+		movie := *m
+		return &movie, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *MemMovieRepo) Create(ctx context.Context, movie *models.Movie) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	movie.ID = 0
+	movie.CreatedAt = now
+	movie.UpdatedAt = now
+	if movie.Status == "" {
+		movie.Status = models.MovieStatusPublished
+	}
+	r.put(movie)
+	return nil
+}
+
+func (r *MemMovieRepo) Update(ctx context.Context, movie *models.Movie) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.movies[movie.ID]; !ok {
+		return sql.ErrNoRows
+	}
+	movie.UpdatedAt = time.Now()
+	r.movies[movie.ID] = movie
+	return nil
+}
+
+func (r *MemMovieRepo) UpdatePosterURL(ctx context.Context, id int, posterURL string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.movies[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	m.PosterURL = posterURL
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemMovieRepo) SetFeatured(ctx context.Context, id int, featured bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.movies[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	m.Featured = featured
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemMovieRepo) ListDueForPublication(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := make([]models.Movie, 0)
+	for _, id := range sortedKeys(r.movies) {
+		m := r.movies[id]
+		if m.Status == models.MovieStatusPending && m.PublishedAt != nil && !m.PublishedAt.After(now) {
+			due = append(due, *m)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].PublishedAt.Before(*due[j].PublishedAt) })
+	page, _ := paginate(due, limit, 0)
+	ids := make([]int, len(page))
+	for i, m := range page {
+		ids[i] = m.ID
+	}
+	return ids, nil
+}
+
+func (r *MemMovieRepo) Publish(ctx context.Context, id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.movies[id]
+	if !ok || m.Status != models.MovieStatusPending {
+		return false, nil
+	}
+	m.Status = models.MovieStatusPublished
+	m.UpdatedAt = time.Now()
+	return true, nil
+}
+
+// ListTopRated treats the users recorded with AddReviewers as the movie's
+// reviewers.
+func (r *MemMovieRepo) ListTopRated(ctx context.Context, userID, limit int) ([]models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	top := make([]models.Movie, 0)
+	for _, id := range sortedKeys(r.movies) {
+		m := r.movies[id]
+		if m.Status == models.MovieStatusPending || m.AverageRating <= 0 || slices.Contains(r.reviewers[id], userID) {
+			continue
+		}
+		top = append(top, *m)
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		if top[i].AverageRating != top[j].AverageRating {
+			return top[i].AverageRating > top[j].AverageRating
+		}
+		return top[i].ReviewCount > top[j].ReviewCount
+	})
+	page, _ := paginate(top, limit, 0)
+	return page, nil
+}
+
+func (r *MemMovieRepo) ListFeatured(ctx context.Context, limit int) ([]models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	featured := make([]models.Movie, 0)
+	for _, id := range sortedKeys(r.movies) {
+		if m := r.movies[id]; m.Featured && m.Status != models.MovieStatusPending {
+			movie := *m
+			movie.Genres = r.genresOf(id)
+			featured = append(featured, movie)
+		}
+	}
+	sort.SliceStable(featured, func(i, j int) bool {
+		if !featured[i].UpdatedAt.Equal(featured[j].UpdatedAt) {
+			return featured[i].UpdatedAt.After(featured[j].UpdatedAt)
+		}
+		return featured[i].ID > featured[j].ID
+	})
+	page, _ := paginate(featured, limit, 0)
+	return page, nil
+}
+
+func (r *MemMovieRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.movies[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.movies, id)
+	delete(r.movieGenres, id)
+	r.deleted[id] = true
+	return nil
+}
+
+func (r *MemMovieRepo) CountByGenre(ctx context.Context, genreIDs []int) (map[int]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[int]int)
+	for movieID, gids := range r.movieGenres {
+		if m, ok := r.movies[movieID]; !ok || m.Status == models.MovieStatusPending {
+			continue
+		}
+		for _, gid := range gids {
+			if slices.Contains(genreIDs, gid) {
+				counts[gid]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (r *MemMovieRepo) IsDeleted(ctx context.Context, id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deleted[id], nil
+}
+
+func (r *MemMovieRepo) SetGenres(ctx context.Context, movieID int, genreIDs []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.movies[movieID]; !ok {
+		return sql.ErrNoRows
+	}
+	r.movieGenres[movieID] = genreIDs
+	return nil
+}
+
+func (r *MemMovieRepo) GetGenresByMovieID(ctx context.Context, movieID int) ([]models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.genresOf(movieID), nil
+}
+
+func (r *MemMovieRepo) GetGenresByMovieIDs(ctx context.Context, movieIDs []int) (map[int][]models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make(map[int][]models.Genre, len(movieIDs))
+	for _, id := range movieIDs {
+		if genres := r.genresOf(id); len(genres) > 0 {
+			res[id] = genres
+		}
+	}
+	return res, nil
+}
+
+// genresOf returns ID-only genres for the movie; names live in MemGenreRepo.
+func (r *MemMovieRepo) genresOf(movieID int) []models.Genre {
+	ids := r.movieGenres[movieID]
+	res := make([]models.Genre, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, models.Genre{ID: id, CreatedAt: time.Now()})
+	}
+	return res
+}
+
+func (r *MemMovieRepo) UpdateAverageRating(ctx context.Context, movieID int) error {
+	reviews := r.ratingSource()
+	if reviews == nil {
+		return nil
+	}
+	sum, count := 0, 0
+	for _, rv := range reviews {
+		if rv.MovieID == movieID {
+			sum += rv.Rating
+			count++
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.movies[movieID]
+	if !ok {
+		return nil
+	}
+	m.AverageRating, m.ReviewCount = 0, count
+	if count > 0 {
+		m.AverageRating = float64(sum) / float64(count)
+	}
+	return nil
+}
+
+// UpdateNormalizedRating mirrors the repository's normalizedRatingSQL: each
+// rating becomes 5 + z*2 against its author's mean and sample standard
+// deviation, or 5 when the author has no spread.
+func (r *MemMovieRepo) UpdateNormalizedRating(ctx context.Context, movieID int) error {
+	reviews := r.ratingSource()
+	if reviews == nil {
+		return nil
+	}
+	byUser := make(map[int][]float64)
+	for _, rv := range reviews {
+		byUser[rv.UserID] = append(byUser[rv.UserID], float64(rv.Rating))
+	}
+	sum, count := 0.0, 0
+	for _, rv := range reviews {
+		if rv.MovieID != movieID {
+			continue
+		}
+		norm := 5.0
+		if mean, sd := meanStddev(byUser[rv.UserID]); sd > 0 {
+			norm = 5 + (float64(rv.Rating)-mean)/sd*2
+		}
+		sum += norm
+		count++
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.movies[movieID]
+	if !ok {
+		return nil
+	}
+	m.NormalizedAverageRating = 0
+	if count > 0 {
+		m.NormalizedAverageRating = sum / float64(count)
+	}
+	return nil
+}
+
+// ratingSource returns the reviews set by RateFrom, or nil.
+func (r *MemMovieRepo) ratingSource() []models.Review {
+	r.mu.Lock()
+	reviews := r.ratedFrom
+	r.mu.Unlock()
+	if reviews == nil {
+		return nil
+	}
+	return reviews.ratings()
+}
+
+// meanStddev returns the mean and sample standard deviation of values; the
+// deviation is 0 for fewer than two values.
+func meanStddev(values []float64) (mean, sd float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	for _, v := range values {
+		sd += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sd / float64(len(values)-1))
+}
+
+func (r *MemMovieRepo) Count(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.movies), nil
+}
+
+func (r *MemMovieRepo) GetAverageRating(ctx context.Context) (float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sum := 0.0
+	count := 0
+	for _, m := range r.movies {
+		if m.AverageRating > 0 {
+			sum += m.AverageRating
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return sum / float64(count), nil
+}
+
+func (r *MemMovieRepo) CountLast7Days(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
+	for _, m := range r.movies {
+		if m.CreatedAt.After(sevenDaysAgo) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemMovieRepo) CountByDecade(ctx context.Context) ([]models.DecadeCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byDecade := make(map[int]int)
+	for _, m := range r.movies {
+		byDecade[m.ReleaseYear/10*10]++
+	}
+	counts := make([]models.DecadeCount, 0, len(byDecade))
+	for decade, count := range byDecade {
+		counts = append(counts, models.DecadeCount{Decade: decade, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Decade < counts[j].Decade })
+	return counts, nil
+}
+
+func (r *MemMovieRepo) GetMostControversialMovies(ctx context.Context, limit int) ([]models.Movie, error) {
+	return []models.Movie{}, nil
+}
+
+func (r *MemMovieRepo) GetDirectorCoOccurrences(ctx context.Context, director string, limit int) ([]models.SimilarDirector, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fans := make(map[int]bool)
+	for id, m := range r.movies {
+		if strings.EqualFold(m.Director, director) && m.Status != models.MovieStatusPending {
+			for _, userID := range r.reviewers[id] {
+				fans[userID] = true
+			}
+		}
+	}
+	shared := make(map[string]map[int]bool)
+	for id, m := range r.movies {
+		if m.Director == "" || strings.EqualFold(m.Director, director) || m.Status == models.MovieStatusPending {
+			continue
+		}
+		for _, userID := range r.reviewers[id] {
+			if fans[userID] {
+				if shared[m.Director] == nil {
+					shared[m.Director] = make(map[int]bool)
+				}
+				shared[m.Director][userID] = true
+			}
+		}
+	}
+	directors := make([]models.SimilarDirector, 0, len(shared))
+	for name, users := range shared {
+		directors = append(directors, models.SimilarDirector{Name: name, CoReviewerCount: len(users)})
+	}
+	sort.Slice(directors, func(i, j int) bool {
+		if directors[i].CoReviewerCount != directors[j].CoReviewerCount {
+			return directors[i].CoReviewerCount > directors[j].CoReviewerCount
+		}
+		return directors[i].Name < directors[j].Name
+	})
+	if len(directors) > limit {
+		directors = directors[:limit]
+	}
+	return directors, nil
+}
+
+func (r *MemMovieRepo) ListDirectors(ctx context.Context, query string, limit int) ([]models.DirectorCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	query = strings.ToLower(query)
+	counts := make(map[string]int)
+	for _, m := range r.movies {
+		if m.Status != models.MovieStatusPending && m.Director != "" && strings.Contains(strings.ToLower(m.Director), query) {
+			counts[m.Director]++
+		}
+	}
+	directors := make([]models.DirectorCount, 0, len(counts))
+	for name, n := range counts {
+		directors = append(directors, models.DirectorCount{Name: name, MovieCount: n})
+	}
+	sort.Slice(directors, func(i, j int) bool {
+		if directors[i].MovieCount != directors[j].MovieCount {
+			return directors[i].MovieCount > directors[j].MovieCount
+		}
+		return directors[i].Name < directors[j].Name
+	})
+	if len(directors) > limit {
+		directors = directors[:limit]
+	}
+	return directors, nil
+}
+
+func (r *MemMovieRepo) GetDirectorStats(ctx context.Context, director string) (*models.DirectorStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stats models.DirectorStats
+	var sum float64
+	for _, id := range sortedKeys(r.movies) {
+		m := r.movies[id]
+		if !strings.EqualFold(m.Director, director) || m.Status == models.MovieStatusPending {
+			continue
+		}
+		stats.MovieCount++
+		sum += m.AverageRating
+		if best := stats.BestMovie; best == nil || m.AverageRating > best.AverageRating ||
+			(m.AverageRating == best.AverageRating && m.ReleaseYear < best.ReleaseYear) {
+			movie := *m
+			stats.BestMovie = &movie
+		}
+	}
+	if stats.MovieCount > 0 {
+		stats.AverageRating = sum / float64(stats.MovieCount)
+	}
+	return &stats, nil
+}
+
+func (r *MemMovieRepo) ListSitemapMovies(ctx context.Context) ([]models.SitemapMovie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	movies := make([]models.SitemapMovie, 0, len(r.movies))
+	for _, id := range sortedKeys(r.movies) {
+		m := r.movies[id]
+		if m.Status == models.MovieStatusPending {
+			continue
+		}
+		movies = append(movies, models.SitemapMovie{ID: m.ID, Title: m.Title, UpdatedAt: m.UpdatedAt})
+	}
+	return movies, nil
+}
+
+func (r *MemMovieRepo) ExistsByTitleYear(ctx context.Context, title string, year int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.movies {
+		if strings.Join(strings.Fields(strings.ToLower(m.Title)), " ") == title && m.ReleaseYear == year {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemMovieRepo) Search(ctx context.Context, query string, limit int) ([]models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q := strings.ToLower(query)
+	var byTitle, other []models.Movie
+	for _, id := range sortedKeys(r.movies) {
+		m := r.movies[id]
+		switch {
+		case m.Status == models.MovieStatusPending:
+		case strings.Contains(strings.ToLower(m.Title), q):
+			byTitle = append(byTitle, *m)
+		case strings.Contains(strings.ToLower(m.Director), q), strings.Contains(strings.ToLower(m.Description), q):
+			other = append(other, *m)
+		}
+	}
+	page, _ := paginate(append(byTitle, other...), limit, 0)
+	return page, nil
+}
@@ -0,0 +1,47 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+type MemNotificationRepo struct {
+	mu            sync.Mutex
+	notifications []models.Notification
+}
+
+func NewMemNotificationRepo() *MemNotificationRepo {
+	return &MemNotificationRepo{}
+}
+
+// All returns a copy of everything inserted so far, oldest first.
+func (r *MemNotificationRepo) All() []models.Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.Notification(nil), r.notifications...)
+}
+
+func (r *MemNotificationRepo) Insert(ctx context.Context, n *models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n.ID = len(r.notifications) + 1
+	n.CreatedAt = time.Now()
+	r.notifications = append(r.notifications, *n)
+	return nil
+}
+
+func (r *MemNotificationRepo) ListByUser(ctx context.Context, userID, limit, offset int) ([]models.Notification, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mine := make([]models.Notification, 0)
+	for i := len(r.notifications) - 1; i >= 0; i-- {
+		if r.notifications[i].UserID == userID {
+			mine = append(mine, r.notifications[i])
+		}
+	}
+	page, total := paginate(mine, limit, offset)
+	return page, total, nil
+}
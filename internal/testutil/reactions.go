@@ -0,0 +1,69 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+type reactionKey struct{ reviewID, userID int }
+
+type MemReviewReactionRepo struct {
+	mu        sync.Mutex
+	reactions map[reactionKey]models.ReviewReaction
+}
+
+func NewMemReviewReactionRepo() *MemReviewReactionRepo {
+	return &MemReviewReactionRepo{reactions: make(map[reactionKey]models.ReviewReaction)}
+}
+
+func (r *MemReviewReactionRepo) Upsert(ctx context.Context, reaction *models.ReviewReaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reaction.CreatedAt = time.Now()
+	r.reactions[reactionKey{reaction.ReviewID, reaction.UserID}] = *reaction
+	return nil
+}
+
+func (r *MemReviewReactionRepo) Delete(ctx context.Context, reviewID, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := reactionKey{reviewID, userID}
+	if _, ok := r.reactions[key]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.reactions, key)
+	return nil
+}
+
+func (r *MemReviewReactionRepo) CountByReview(ctx context.Context, reviewID int) (map[string]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[string]int)
+	for key, reaction := range r.reactions {
+		if key.reviewID == reviewID {
+			counts[reaction.Reaction]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *MemReviewReactionRepo) CountByReviews(ctx context.Context, reviewIDs []int) (map[int]map[string]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[int]map[string]int)
+	for key, reaction := range r.reactions {
+		if !slices.Contains(reviewIDs, key.reviewID) {
+			continue
+		}
+		if counts[key.reviewID] == nil {
+			counts[key.reviewID] = make(map[string]int)
+		}
+		counts[key.reviewID][reaction.Reaction]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,217 @@
+package testutil_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"golang-project/internal/models"
+	"golang-project/internal/testutil"
+)
+
+// These tests pin down the behaviour the service and handler tests rely on:
+// missing rows come back as sql.ErrNoRows, like the Postgres repositories,
+// and every repository is safe for concurrent use.
+
+func TestMemUserRepo(t *testing.T) {
+	repo := testutil.NewMemUserRepo()
+	ctx := context.Background()
+
+	if _, err := repo.GetByID(ctx, 1); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("missing user: expected sql.ErrNoRows, got %v", err)
+	}
+	if _, err := repo.GetByEmail(ctx, "a@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("missing email: expected sql.ErrNoRows, got %v", err)
+	}
+
+	alice := &models.User{Email: "a@example.com", Username: "alice", PasswordHash: "x", Role: "user"}
+	bob := &models.User{Email: "b@example.com", Username: "bob", PasswordHash: "x", Role: "admin"}
+	for _, u := range []*models.User{alice, bob} {
+		if err := repo.Create(ctx, u); err != nil {
+			t.Fatalf("create %s: %v", u.Username, err)
+		}
+	}
+	if alice.ID == 0 || alice.CreatedAt.IsZero() {
+		t.Fatalf("create did not fill in the ID and timestamps: %+v", alice)
+	}
+
+	got, err := repo.GetByEmail(ctx, "a@example.com")
+	if err != nil || got.ID != alice.ID {
+		t.Fatalf("get by email: got %+v, %v", got, err)
+	}
+
+	users, total, err := repo.List(ctx, models.UserFilters{Role: "admin"}, 10, 0)
+	if err != nil || total != 1 || users[0].ID != bob.ID {
+		t.Fatalf("list admins: got %+v (total %d), %v", users, total, err)
+	}
+	if users, total, _ = repo.List(ctx, models.UserFilters{}, 1, 1); len(users) != 1 || total != 2 {
+		t.Fatalf("list page 2: got %d users, total %d", len(users), total)
+	}
+
+	if err := repo.UpdateRole(ctx, alice.ID, "admin"); err != nil {
+		t.Fatalf("update role: %v", err)
+	}
+	if err := repo.UpdateRole(ctx, 999, "admin"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("update missing user: expected sql.ErrNoRows, got %v", err)
+	}
+	if err := repo.UpdatePassword(ctx, alice.ID, "y"); err != nil {
+		t.Fatalf("update password: %v", err)
+	}
+	if got, _ = repo.GetByID(ctx, alice.ID); got.Role != "admin" || got.PasswordHash != "y" {
+		t.Fatalf("updates not stored: %+v", got)
+	}
+	if n, _ := repo.Count(ctx); n != 2 {
+		t.Fatalf("count: got %d, want 2", n)
+	}
+}
+
+func TestMemGenreRepo(t *testing.T) {
+	repo := testutil.NewMemGenreRepo()
+	ctx := context.Background()
+
+	genre := &models.Genre{Name: "Drama"}
+	if err := repo.Create(ctx, genre); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got, err := repo.GetByName(ctx, "Drama"); err != nil || got.ID != genre.ID {
+		t.Fatalf("get by name: got %+v, %v", got, err)
+	}
+
+	genre.Name = "Thriller"
+	if err := repo.Update(ctx, genre); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Update(ctx, &models.Genre{ID: 999, Name: "X"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("update missing genre: expected sql.ErrNoRows, got %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, genre.ID); got.Name != "Thriller" {
+		t.Fatalf("update not stored: %+v", got)
+	}
+
+	if err := repo.Delete(ctx, genre.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, genre.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("deleted genre: expected sql.ErrNoRows, got %v", err)
+	}
+	if err := repo.Delete(ctx, genre.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("delete twice: expected sql.ErrNoRows, got %v", err)
+	}
+	if n, _ := repo.Count(ctx); n != 0 {
+		t.Fatalf("count: got %d, want 0", n)
+	}
+}
+
+func TestMemMovieRepo(t *testing.T) {
+	repo := testutil.NewMemMovieRepo()
+	ctx := context.Background()
+
+	movie := &models.Movie{Title: "Heat", Director: "Michael Mann", ReleaseYear: 1995}
+	if err := repo.Create(ctx, movie); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.SetGenres(ctx, movie.ID, []int{1, 2}); err != nil {
+		t.Fatalf("set genres: %v", err)
+	}
+	counts, err := repo.CountByGenre(ctx, []int{1, 3})
+	if err != nil || counts[1] != 1 || counts[3] != 0 {
+		t.Fatalf("count by genre: got %v, %v", counts, err)
+	}
+
+	movie.Title = "Heat (1995)"
+	if err := repo.Update(ctx, movie); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Update(ctx, &models.Movie{ID: 999}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("update missing movie: expected sql.ErrNoRows, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, movie.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, movie.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("deleted movie: expected sql.ErrNoRows, got %v", err)
+	}
+	if deleted, _ := repo.IsDeleted(ctx, movie.ID); !deleted {
+		t.Fatal("expected the movie to be reported as deleted")
+	}
+}
+
+func TestMemReviewRepo(t *testing.T) {
+	repo := testutil.NewMemReviewRepo()
+	ctx := context.Background()
+
+	for userID := 1; userID <= 3; userID++ {
+		if err := repo.Create(ctx, &models.Review{MovieID: 1, UserID: userID, Rating: userID * 2}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if err := repo.Create(ctx, &models.Review{MovieID: 2, UserID: 1, Rating: 10}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	reviews, err := repo.GetByMovieID(ctx, 1, models.ReviewFilters{}, 10, 0)
+	if err != nil || len(reviews) != 3 {
+		t.Fatalf("by movie: got %d reviews, %v", len(reviews), err)
+	}
+	if reviews, _ = repo.GetByMovieID(ctx, 1, models.ReviewFilters{}, 2, 2); len(reviews) != 1 {
+		t.Fatalf("by movie, offset 2: got %d reviews, want 1", len(reviews))
+	}
+	if n, _ := repo.CountByUserID(ctx, 1); n != 2 {
+		t.Fatalf("count by user: got %d, want 2", n)
+	}
+
+	review, err := repo.GetByMovieAndUser(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("by movie and user: %v", err)
+	}
+	if _, err := repo.GetByMovieAndUser(ctx, 2, 2); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("missing review: expected sql.ErrNoRows, got %v", err)
+	}
+
+	review.Rating = 1
+	if err := repo.Update(ctx, review); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Delete(ctx, review.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, review.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("deleted review: expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+// TestMemReposConcurrent is meant to be run with -race.
+func TestMemReposConcurrent(t *testing.T) {
+	users := testutil.NewMemUserRepo()
+	reviews := testutil.NewMemReviewRepo()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u := &models.User{Email: fmt.Sprintf("u%d@example.com", i), Username: fmt.Sprintf("u%d", i)}
+			if err := users.Create(ctx, u); err != nil {
+				t.Errorf("create user: %v", err)
+				return
+			}
+			_, _ = users.GetByID(ctx, u.ID)
+			_, _, _ = users.List(ctx, models.UserFilters{}, 5, 0)
+			_ = reviews.Create(ctx, &models.Review{MovieID: 1, UserID: u.ID, Rating: 5})
+			_, _ = reviews.GetByMovieID(ctx, 1, models.ReviewFilters{}, 5, 0)
+		}()
+	}
+	wg.Wait()
+
+	if n, _ := users.Count(ctx); n != 20 {
+		t.Fatalf("users: got %d, want 20", n)
+	}
+	if n, _ := reviews.Count(ctx); n != 20 {
+		t.Fatalf("reviews: got %d, want 20", n)
+	}
+}
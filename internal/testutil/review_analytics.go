@@ -0,0 +1,58 @@
+package testutil
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang-project/internal/models"
+)
+
+// MemReviewAnalytics implements GetAnalytics on top of the review and
+// blocked keyword repos, matching keywords like the Postgres query.
+type MemReviewAnalytics struct {
+	Reviews  *MemReviewRepo
+	Keywords *MemBlockedKeywordRepo
+}
+
+func (a MemReviewAnalytics) GetAnalytics(ctx context.Context) (*models.ReviewAnalytics, error) {
+	keywords, err := a.Keywords.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	flagged := func(text string) bool {
+		for _, k := range keywords {
+			if k.CaseSensitive && strings.Contains(text, k.Keyword) ||
+				!k.CaseSensitive && regexp.MustCompile("(?i)"+regexp.QuoteMeta(k.Keyword)).MatchString(text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	analytics := &models.ReviewAnalytics{RatingDistribution: make(map[int]int)}
+	var ratings []int
+	var words, chars int
+	a.Reviews.mu.Lock()
+	for _, rv := range a.Reviews.data {
+		analytics.TotalReviews++
+		if flagged(rv.Title + " " + rv.Content) {
+			analytics.FlaggedReviews++
+		}
+		analytics.RatingDistribution[rv.Rating]++
+		ratings = append(ratings, rv.Rating)
+		words += len(strings.Fields(rv.Content))
+		chars += utf8.RuneCountInString(rv.Content)
+	}
+	a.Reviews.mu.Unlock()
+
+	if n := len(ratings); n > 0 {
+		sort.Ints(ratings)
+		analytics.MedianRating = float64(ratings[(n-1)/2]+ratings[n/2]) / 2
+		analytics.AvgWordCount = float64(words) / float64(n)
+		analytics.AvgContentLength = float64(chars) / float64(n)
+	}
+	return analytics, nil
+}
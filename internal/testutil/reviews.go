@@ -0,0 +1,273 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+type MemReviewRepo struct {
+	mu     sync.Mutex
+	nextID int
+	data   map[int]*models.Review
+}
+
+func NewMemReviewRepo() *MemReviewRepo {
+	return &MemReviewRepo{nextID: 1, data: make(map[int]*models.Review)}
+}
+
+// Add stores review as-is, assigning an ID only when it has none.
+func (r *MemReviewRepo) Add(review *models.Review) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.put(review)
+}
+
+func (r *MemReviewRepo) put(review *models.Review) {
+	if review.ID == 0 {
+		review.ID = r.nextID
+	}
+	if review.ID >= r.nextID {
+		r.nextID = review.ID + 1
+	}
+	r.data[review.ID] = review
+}
+
+func (r *MemReviewRepo) GetByID(ctx context.Context, id int) (*models.Review, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rv, ok := r.data[id]; ok {
+		return rv, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *MemReviewRepo) GetByMovieAndUser(ctx context.Context, movieID, userID int) (*models.Review, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rv := range r.data {
+		if rv.MovieID == movieID && rv.UserID == userID {
+			return rv, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// FindSimilarContent compares content with the trigram similarity pg_trgm
+// uses, so tests see the same matches as Postgres.
+func (r *MemReviewRepo) FindSimilarContent(ctx context.Context, movieID int, content string, threshold float64) (*models.Review, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range sortedKeys(r.data) {
+		rv := r.data[id]
+		if rv.MovieID == movieID && TrigramSimilarity(rv.Content, content) > threshold {
+			return &models.Review{ID: rv.ID, UserID: rv.UserID}, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *MemReviewRepo) GetByMovieID(ctx context.Context, movieID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error) {
+	return r.filter(func(rv *models.Review) bool { return rv.MovieID == movieID }, filters, limit, offset), nil
+}
+
+func (r *MemReviewRepo) GetByUserID(ctx context.Context, userID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error) {
+	return r.filter(func(rv *models.Review) bool { return rv.UserID == userID }, filters, limit, offset), nil
+}
+
+func (r *MemReviewRepo) CountByUserIDFiltered(ctx context.Context, userID int, filters models.ReviewFilters) (int, error) {
+	return len(r.filter(func(rv *models.Review) bool { return rv.UserID == userID }, filters, 0, 0)), nil
+}
+
+func (r *MemReviewRepo) ListAfter(ctx context.Context, filters models.ReviewFilters, afterID, limit int) ([]models.Review, error) {
+	return r.filter(func(rv *models.Review) bool { return rv.ID > afterID }, filters, limit, 0), nil
+}
+
+func (r *MemReviewRepo) filter(match func(*models.Review) bool, filters models.ReviewFilters, limit, offset int) []models.Review {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]models.Review, 0)
+	for _, id := range sortedKeys(r.data) {
+		rv := r.data[id]
+		if !match(rv) {
+			continue
+		}
+		if filters.MinRating > 0 && rv.Rating < filters.MinRating {
+			continue
+		}
+		if filters.MaxRating > 0 && rv.Rating > filters.MaxRating {
+			continue
+		}
+		res = append(res, *rv)
+	}
+	page, _ := paginate(res, limit, offset)
+	return page
+}
+
+func (r *MemReviewRepo) Create(ctx context.Context, review *models.Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	review.ID = 0
+	review.CreatedAt = now
+	review.UpdatedAt = now
+	r.put(review)
+	return nil
+}
+
+func (r *MemReviewRepo) Update(ctx context.Context, review *models.Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.data[review.ID]; !ok {
+		return sql.ErrNoRows
+	}
+	review.UpdatedAt = time.Now()
+	r.data[review.ID] = review
+	return nil
+}
+
+func (r *MemReviewRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.data[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.data, id)
+	return nil
+}
+
+func (r *MemReviewRepo) DeleteMany(ctx context.Context, ids []int) ([]models.Review, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted []models.Review
+	for _, id := range ids {
+		if rv, ok := r.data[id]; ok {
+			deleted = append(deleted, *rv)
+			delete(r.data, id)
+		}
+	}
+	return deleted, nil
+}
+
+func (r *MemReviewRepo) UpdateSentimentScore(ctx context.Context, reviewID int, score float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rv, ok := r.data[reviewID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	rv.SentimentScore = &score
+	return nil
+}
+
+func (r *MemReviewRepo) CountByUserID(ctx context.Context, userID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, rv := range r.data {
+		if rv.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemReviewRepo) Count(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.data), nil
+}
+
+func (r *MemReviewRepo) CountLast7Days(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
+	for _, rv := range r.data {
+		if rv.CreatedAt.After(sevenDaysAgo) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemReviewRepo) GetAverageRatingByUserID(ctx context.Context, userID int) (float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sum := 0
+	count := 0
+	for _, rv := range r.data {
+		if rv.UserID == userID {
+			sum += rv.Rating
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return float64(sum) / float64(count), nil
+}
+
+func (r *MemReviewRepo) GetUserRatingStats(ctx context.Context, userID int) (float64, float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ratings []float64
+	sum := 0.0
+	for _, rv := range r.data {
+		if rv.UserID == userID {
+			ratings = append(ratings, float64(rv.Rating))
+			sum += float64(rv.Rating)
+		}
+	}
+	if len(ratings) == 0 {
+		return 0, 0, nil
+	}
+	mean := sum / float64(len(ratings))
+	if len(ratings) == 1 {
+		return mean, 0, nil
+	}
+	sq := 0.0
+	for _, v := range ratings {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(ratings)-1)), nil
+}
+
+// ratings returns a copy of every stored review, for MemMovieRepo to
+// compute ratings from.
+func (r *MemReviewRepo) ratings() []models.Review {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]models.Review, 0, len(r.data))
+	for _, id := range sortedKeys(r.data) {
+		res = append(res, *r.data[id])
+	}
+	return res
+}
+
+func (r *MemReviewRepo) GetFavoriteGenreByUserID(ctx context.Context, userID int) (*models.Genre, error) {
+	return nil, nil
+}
+
+// MemReviewTargets implements GetReviewTarget on top of a movie and a
+// review repo.
+type MemReviewTargets struct {
+	Movies  *MemMovieRepo
+	Reviews *MemReviewRepo
+}
+
+func (t MemReviewTargets) GetReviewTarget(ctx context.Context, movieID, userID int) (*models.Movie, int, error) {
+	movie, err := t.Movies.GetByID(ctx, movieID)
+	if err != nil {
+		return nil, 0, err
+	}
+	existing, err := t.Reviews.GetByMovieAndUser(ctx, movieID, userID)
+	if err != nil {
+		return movie, 0, nil
+	}
+	return movie, existing.ID, nil
+}
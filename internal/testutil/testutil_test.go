@@ -0,0 +1,59 @@
+package testutil_test
+
+import (
+	"math"
+	"testing"
+
+	"golang-project/internal/repository"
+	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+)
+
+var (
+	_ service.UserRepo             = (*testutil.MemUserRepo)(nil)
+	_ service.GenreRepo            = (*testutil.MemGenreRepo)(nil)
+	_ service.GenreCountRepo       = (*testutil.MemGenreRepo)(nil)
+	_ service.MovieRepo            = (*testutil.MemMovieRepo)(nil)
+	_ service.MovieLookup          = (*testutil.MemMovieRepo)(nil)
+	_ service.ReviewTargetLookup   = testutil.MemReviewTargets{}
+	_ service.BlockedKeywordRepo   = (*testutil.MemBlockedKeywordRepo)(nil)
+	_ service.MovieCountRepo       = (*testutil.MemMovieRepo)(nil)
+	_ service.SitemapMovieRepo     = (*testutil.MemMovieRepo)(nil)
+	_ service.MoviePublicationRepo = (*testutil.MemMovieRepo)(nil)
+	_ service.ReviewRepo           = (*testutil.MemReviewRepo)(nil)
+	_ service.ReviewStatsRepo      = (*testutil.MemReviewRepo)(nil)
+	_ service.ReviewCountRepo      = (*testutil.MemReviewRepo)(nil)
+	_ service.ReviewSentimentRepo  = (*testutil.MemReviewRepo)(nil)
+	_ service.AuditLogRepo         = (*testutil.MemAuditRepo)(nil)
+	_ service.WatchHistoryRepo     = (*testutil.MemWatchHistoryRepo)(nil)
+	_ service.MovieVersionRepo     = (*testutil.MemMovieVersionRepo)(nil)
+	_ service.ReviewReactionRepo   = (*testutil.MemReviewReactionRepo)(nil)
+	_ service.MovieSearchRepo      = (*testutil.MemMovieRepo)(nil)
+	_ service.GenreSearchRepo      = (*testutil.MemGenreRepo)(nil)
+	_ service.UserSearchRepo       = (*testutil.MemUserRepo)(nil)
+	_ service.AccountMerger        = testutil.MemAccountMerger{}
+	_ service.ReminderUserRepo     = (*testutil.MemUserRepo)(nil)
+	_ service.TopRatedMovieRepo    = (*testutil.MemMovieRepo)(nil)
+	_ service.YearSummaryRepo      = testutil.MemYearSummaries{}
+	_ service.ReviewAnalyticsRepo  = testutil.MemReviewAnalytics{}
+
+	_ repository.UserRepository = (*testutil.MemUserRepo)(nil)
+)
+
+// TestTrigramSimilarity checks values against what pg_trgm's similarity()
+// returns for the same input.
+func TestTrigramSimilarity(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want float64
+	}{
+		{"word", "two words", 4.0 / 11},
+		{"Heat", "heat!", 1},
+		{"abc", "xyz", 0},
+		{"", "abc", 0},
+	} {
+		if got := testutil.TrigramSimilarity(tc.a, tc.b); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-project/internal/models"
+)
+
+type MemUserRepo struct {
+	mu           sync.Mutex
+	nextID       int
+	byID         map[int]*models.User
+	byEmail      map[string]*models.User
+	reviewCounts map[int]int
+	deleted      map[int]*models.User
+}
+
+func NewMemUserRepo() *MemUserRepo {
+	return &MemUserRepo{
+		nextID:       1,
+		byID:         make(map[int]*models.User),
+		byEmail:      make(map[string]*models.User),
+		reviewCounts: make(map[int]int),
+		deleted:      make(map[int]*models.User),
+	}
+}
+
+// SetReviewCount sets the review count List filters on for the user.
+func (r *MemUserRepo) SetReviewCount(id, count int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reviewCounts[id] = count
+}
+
+// Add stores user as-is, assigning an ID only when it has none.
+func (r *MemUserRepo) Add(user *models.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.put(user)
+}
+
+// Deleted reports whether the user was soft-deleted.
+func (r *MemUserRepo) Deleted(id int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.deleted[id]
+	return ok
+}
+
+// SoftDelete hides the user from every lookup, as deleted_at does.
+func (r *MemUserRepo) SoftDelete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.byID[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.byID, id)
+	delete(r.byEmail, u.Email)
+	r.deleted[id] = u
+	return nil
+}
+
+func (r *MemUserRepo) put(user *models.User) {
+	if user.ID == 0 {
+		user.ID = r.nextID
+	}
+	if user.ID >= r.nextID {
+		r.nextID = user.ID + 1
+	}
+	r.byID[user.ID] = user
+	r.byEmail[user.Email] = user
+}
+
+func (r *MemUserRepo) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byEmail[user.Email]; ok {
+		return errors.New("duplicate")
+	}
+	now := time.Now()
+	user.ID = 0
+	user.IsActive = true
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.put(user)
+	return nil
+}
+
+func (r *MemUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.byEmail[email]; ok {
+		return u, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *MemUserRepo) GetByID(ctx context.Context, id int) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.byID[id]; ok {
+		return u, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *MemUserRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.byID {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *MemUserRepo) GetByIDs(ctx context.Context, ids []int) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := make([]models.User, 0, len(ids))
+	for _, id := range ids {
+		if u, ok := r.byID[id]; ok {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+func (r *MemUserRepo) List(ctx context.Context, filters models.UserFilters, limit, offset int) ([]models.User, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]models.User, 0, len(r.byID))
+	for _, id := range sortedKeys(r.byID) {
+		u := r.byID[id]
+		if filters.Search != "" {
+			search := strings.ToLower(filters.Search)
+			if !strings.Contains(strings.ToLower(u.Email), search) && !strings.Contains(strings.ToLower(u.Username), search) {
+				continue
+			}
+		}
+		if filters.Role != "" && u.Role != filters.Role {
+			continue
+		}
+		if filters.MinReviews != nil && r.reviewCounts[id] < *filters.MinReviews {
+			continue
+		}
+		if filters.MaxReviews != nil && r.reviewCounts[id] > *filters.MaxReviews {
+			continue
+		}
+		result = append(result, *u)
+	}
+	page, total := paginate(result, limit, offset)
+	return page, total, nil
+}
+
+func (r *MemUserRepo) UpdateRole(ctx context.Context, id int, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.byID[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.Role = role
+	return nil
+}
+
+// UpdateRoles changes nothing unless every id exists, like the
+// transactional PostgreSQL implementation.
+func (r *MemUserRepo) UpdateRoles(ctx context.Context, ids []int, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := r.byID[id]; !ok {
+			return sql.ErrNoRows
+		}
+	}
+	for _, id := range ids {
+		r.byID[id].Role = role
+	}
+	return nil
+}
+
+func (r *MemUserRepo) Update(ctx context.Context, id int, email, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.byID[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if email != "" && email != u.Email {
+		delete(r.byEmail, u.Email)
+		u.Email = email
+		r.byEmail[email] = u
+	}
+	if username != "" {
+		u.Username = username
+	}
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemUserRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.byID[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.byID, id)
+	delete(r.byEmail, u.Email)
+	return nil
+}
+
+func (r *MemUserRepo) SetEmailOnDigest(ctx context.Context, id int, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.byID[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.EmailOnDigest = enabled
+	return nil
+}
+
+func (r *MemUserRepo) SetReviewsPrivate(ctx context.Context, id int, private bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.byID[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.ReviewsPrivate = private
+	return nil
+}
+
+func (r *MemUserRepo) SetLastReviewAt(ctx context.Context, id int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.byID[id]; ok {
+		u.LastReviewAt = &at
+	}
+	return nil
+}
+
+func (r *MemUserRepo) FindInactiveReviewers(ctx context.Context, since time.Time) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []models.User
+	for _, id := range sortedKeys(r.byID) {
+		u := r.byID[id]
+		if u.LastReviewAt != nil && u.LastReviewAt.Before(since) && u.IsActive && u.EmailOnDigest {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+func (r *MemUserRepo) ClaimReminder(ctx context.Context, id int, at, notBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.byID[id]
+	if !ok || (u.ReminderSentAt != nil && u.ReminderSentAt.After(notBefore)) {
+		return false, nil
+	}
+	u.ReminderSentAt = &at
+	return true, nil
+}
+
+func (r *MemUserRepo) ReleaseReminder(ctx context.Context, id int, at time.Time, prev *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.byID[id]; ok && u.ReminderSentAt != nil && u.ReminderSentAt.Equal(at) {
+		u.ReminderSentAt = prev
+	}
+	return nil
+}
+
+func (r *MemUserRepo) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.byID[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.PasswordHash = passwordHash
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemUserRepo) Count(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.byID), nil
+}
+
+func (r *MemUserRepo) CountLast7Days(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
+	for _, u := range r.byID {
+		if u.CreatedAt.After(sevenDaysAgo) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemUserRepo) SearchByUsername(ctx context.Context, query string, limit int) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]models.User, 0)
+	for _, id := range sortedKeys(r.byID) {
+		if u := r.byID[id]; strings.Contains(strings.ToLower(u.Username), strings.ToLower(query)) {
+			res = append(res, models.User{ID: u.ID, Username: u.Username})
+		}
+	}
+	page, _ := paginate(res, limit, 0)
+	return page, nil
+}
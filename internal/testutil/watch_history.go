@@ -0,0 +1,68 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"golang-project/internal/models"
+)
+
+type watchKey struct{ userID, movieID int }
+
+type MemWatchHistoryRepo struct {
+	mu      sync.Mutex
+	entries map[watchKey]models.WatchHistory
+}
+
+func NewMemWatchHistoryRepo() *MemWatchHistoryRepo {
+	return &MemWatchHistoryRepo{entries: make(map[watchKey]models.WatchHistory)}
+}
+
+func (r *MemWatchHistoryRepo) RecordWatch(ctx context.Context, w *models.WatchHistory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[watchKey{w.UserID, w.MovieID}] = *w
+	return nil
+}
+
+func (r *MemWatchHistoryRepo) GetByUser(ctx context.Context, userID, limit, offset int) ([]models.WatchHistory, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mine := make([]models.WatchHistory, 0)
+	for k, w := range r.entries {
+		if k.userID == userID {
+			mine = append(mine, w)
+		}
+	}
+	sort.Slice(mine, func(i, j int) bool {
+		if !mine[i].WatchedAt.Equal(mine[j].WatchedAt) {
+			return mine[i].WatchedAt.After(mine[j].WatchedAt)
+		}
+		return mine[i].MovieID > mine[j].MovieID
+	})
+	page, total := paginate(mine, limit, offset)
+	return page, total, nil
+}
+
+func (r *MemWatchHistoryRepo) GetByUserAndMovie(ctx context.Context, userID, movieID int) (*models.WatchHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.entries[watchKey{userID, movieID}]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &w, nil
+}
+
+func (r *MemWatchHistoryRepo) Delete(ctx context.Context, userID, movieID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := watchKey{userID, movieID}
+	if _, ok := r.entries[k]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.entries, k)
+	return nil
+}
@@ -0,0 +1,81 @@
+package testutil
+
+import (
+	"context"
+	"sort"
+
+	"golang-project/internal/models"
+)
+
+// MemYearSummaries implements GetYearSummary on top of the movie, review and
+// user repos, with the same thresholds as the Postgres query.
+type MemYearSummaries struct {
+	Movies  *MemMovieRepo
+	Reviews *MemReviewRepo
+	Users   *MemUserRepo
+}
+
+func (y MemYearSummaries) GetYearSummary(ctx context.Context, year int) (*models.YearSummary, error) {
+	summary := &models.YearSummary{Year: year, TopReviewers: []models.TopReviewer{}}
+
+	movies := make(map[int]models.Movie)
+	y.Movies.mu.Lock()
+	for id, m := range y.Movies.movies {
+		if m.ReleaseYear == year && m.Status != models.MovieStatusPending {
+			movies[id] = *m
+		}
+	}
+	y.Movies.mu.Unlock()
+	summary.TotalMovies = len(movies)
+
+	counts, sums, byUser := make(map[int]int), make(map[int]int), make(map[int]int)
+	y.Reviews.mu.Lock()
+	for _, rv := range y.Reviews.data {
+		if _, ok := movies[rv.MovieID]; ok {
+			counts[rv.MovieID]++
+			sums[rv.MovieID] += rv.Rating
+			byUser[rv.UserID]++
+			summary.TotalReviews++
+		}
+	}
+	y.Reviews.mu.Unlock()
+
+	avgs := make(map[int]float64, len(counts))
+	var best, mostReviewed int
+	var total float64
+	for _, id := range sortedKeys(counts) {
+		avgs[id] = float64(sums[id]) / float64(counts[id])
+		total += avgs[id]
+		if counts[id] >= 5 && (best == 0 || avgs[id] > avgs[best] || (avgs[id] == avgs[best] && counts[id] > counts[best])) {
+			best = id
+		}
+		if mostReviewed == 0 || counts[id] > counts[mostReviewed] ||
+			(counts[id] == counts[mostReviewed] && avgs[id] > avgs[mostReviewed]) {
+			mostReviewed = id
+		}
+	}
+	if len(counts) > 0 {
+		summary.AverageRating = total / float64(len(counts))
+	}
+	if m, ok := movies[best]; ok {
+		summary.BestMovie = &m
+	}
+	if m, ok := movies[mostReviewed]; ok {
+		summary.MostReviewedMovie = &m
+	}
+
+	for _, userID := range sortedKeys(byUser) {
+		user, err := y.Users.GetByID(ctx, userID)
+		if err != nil {
+			continue
+		}
+		summary.TopReviewers = append(summary.TopReviewers, models.TopReviewer{PublicUser: *user.Public(), ReviewCount: byUser[userID]})
+	}
+	sort.SliceStable(summary.TopReviewers, func(i, j int) bool {
+		return summary.TopReviewers[i].ReviewCount > summary.TopReviewers[j].ReviewCount
+	})
+	if len(summary.TopReviewers) > 3 {
+		summary.TopReviewers = summary.TopReviewers[:3]
+	}
+	return summary, nil
+}
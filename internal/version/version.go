@@ -0,0 +1,44 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version, Commit and Date are overridden at build time, e.g.
+// -ldflags "-X golang-project/internal/version.Version=1.2.0 -X golang-project/internal/version.Commit=abc123 -X golang-project/internal/version.Date=2024-05-01T12:00:00Z".
+var (
+	Version = "dev"
+	Commit  = "unknown"
+	Date    = "unknown"
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// BuildInfo describes the running binary. A commit or date not set through
+// -ldflags is taken from the VCS stamp go build embeds, when there is one,
+// so plain builds and tests still get sensible values.
+func BuildInfo() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: Date,
+		GoVersion: runtime.Version(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "unknown":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "unknown":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,55 @@
+// Package bind decodes request bodies while rejecting fields the caller did
+// not expect, so clients cannot set attributes like role through endpoints
+// that were never meant to change them.
+package bind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidBody = errors.New("invalid request body")
+
+// UnknownFieldError reports a body key that is not in the allowed list.
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field: %s", e.Field)
+}
+
+// BindStrict decodes the JSON body of c into T after checking that every
+// top-level key is one of allowedFields. The body must be a JSON object.
+func BindStrict[T any](c *gin.Context, allowedFields []string) (T, error) {
+	var out T
+	var fields map[string]interface{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&fields); err != nil || fields == nil {
+		return out, ErrInvalidBody
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !slices.Contains(allowedFields, k) {
+			return out, &UnknownFieldError{Field: k}
+		}
+	}
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return out, ErrInvalidBody
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return out, ErrInvalidBody
+	}
+	return out, nil
+}
@@ -0,0 +1,46 @@
+package bind
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type profile struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+func contextWithBody(body string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+	return c
+}
+
+func TestBindStrict(t *testing.T) {
+	allowed := []string{"email", "username"}
+
+	got, err := BindStrict[profile](contextWithBody(`{"email":"a@example.com"}`), allowed)
+	if err != nil {
+		t.Fatalf("allowed fields: %v", err)
+	}
+	if got.Email != "a@example.com" || got.Username != "" {
+		t.Fatalf("unexpected result %+v", got)
+	}
+
+	_, err = BindStrict[profile](contextWithBody(`{"email":"x","role":"admin"}`), allowed)
+	var unknown *UnknownFieldError
+	if !errors.As(err, &unknown) || unknown.Field != "role" {
+		t.Fatalf("expected unknown field role, got %v", err)
+	}
+
+	for _, body := range []string{``, `null`, `[]`, `{"email":`, `{"email":5}`} {
+		if _, err := BindStrict[profile](contextWithBody(body), allowed); !errors.Is(err, ErrInvalidBody) {
+			t.Fatalf("%q: expected ErrInvalidBody, got %v", body, err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+// FuzzJWTParse feeds arbitrary strings to Parse, which must reject them
+// with an error and never panic.
+func FuzzJWTParse(f *testing.F) {
+	const secret = "fuzz-secret"
+	valid, err := Generate("42", "user", secret, time.Hour)
+	if err != nil {
+		f.Fatalf("generate: %v", err)
+	}
+	for _, seed := range []string{
+		valid,
+		valid[:len(valid)-4],
+		"",
+		".",
+		"..",
+		"a.b.c",
+		"eyJhbGciOiJub25lIn0.eyJ1aWQiOiIxIn0.",
+		"eyJhbGciOiJIUzI1NiIsImtpZCI6MX0.e30.sig",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, token string) {
+		claims, err := Parse(token, secret)
+		if err == nil && claims == nil {
+			t.Fatalf("Parse(%q) returned neither claims nor an error", token)
+		}
+		if token == valid && (err != nil || claims.UserID != "42") {
+			t.Fatalf("valid token rejected: %+v, %v", claims, err)
+		}
+	})
+}
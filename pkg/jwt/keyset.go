@@ -0,0 +1,146 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync/atomic"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnknownKeyID = errors.New("unknown key id")
+
+// KeySet holds every secret that is currently accepted for verification.
+// Tokens are signed with the current key and carry its id in the "kid"
+// header, so old keys can stay valid while clients pick up new tokens.
+// The keys can be swapped with Replace while the set is in use.
+type KeySet struct {
+	state atomic.Pointer[keyState]
+}
+
+type keyState struct {
+	currentKID string
+	keys       map[string][]byte
+}
+
+func newKeySet(state *keyState) *KeySet {
+	ks := &KeySet{}
+	ks.state.Store(state)
+	return ks
+}
+
+func NewKeySet(currentKID string, keys map[string]string) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("key set is empty")
+	}
+	if _, ok := keys[currentKID]; !ok {
+		return nil, fmt.Errorf("current key %q not found in key set", currentKID)
+	}
+
+	state := &keyState{currentKID: currentKID, keys: make(map[string][]byte, len(keys))}
+	for kid, secret := range keys {
+		if secret == "" {
+			return nil, fmt.Errorf("key %q has empty secret", kid)
+		}
+		state.keys[kid] = []byte(secret)
+	}
+	return newKeySet(state), nil
+}
+
+// ParseKeySet builds a KeySet from a JSON object mapping key ids to secrets.
+func ParseKeySet(currentKID, raw string) (*KeySet, error) {
+	var keys map[string]string
+	if err := json.Unmarshal([]byte(raw), &keys); err != nil {
+		return nil, fmt.Errorf("parse key set: %w", err)
+	}
+	return NewKeySet(currentKID, keys)
+}
+
+// StaticKeySet wraps a single secret. Tokens are issued without a kid header.
+func StaticKeySet(secret string) *KeySet {
+	return newKeySet(&keyState{keys: map[string][]byte{"": []byte(secret)}})
+}
+
+func (ks *KeySet) CurrentKID() string {
+	return ks.state.Load().currentKID
+}
+
+// Replace makes ks sign and verify with the keys of other from now on, so
+// everything holding ks picks up rotated secrets without being rebuilt.
+func (ks *KeySet) Replace(other *KeySet) {
+	ks.state.Store(other.state.Load())
+}
+
+// Equal reports whether ks and other hold the same keys and current key.
+func (ks *KeySet) Equal(other *KeySet) bool {
+	a, b := ks.state.Load(), other.state.Load()
+	return a.currentKID == b.currentKID && maps.EqualFunc(a.keys, b.keys, bytes.Equal)
+}
+
+func (ks *KeySet) Generate(userID, role string, ttl time.Duration) (string, error) {
+	token, _, err := ks.Issue(userID, role, ttl)
+	return token, err
+}
+
+// Issue is Generate that also returns the expiry written into the token,
+// which is truncated to whole seconds.
+func (ks *KeySet) Issue(userID, role string, ttl time.Duration) (string, time.Time, error) {
+	return ks.issue(Claims{UserID: userID, Role: role}, ttl)
+}
+
+// IssueImpersonation issues a token for userID that records the admin it
+// was really issued to in the impersonated_by claim.
+func (ks *KeySet) IssueImpersonation(userID, role, impersonatedBy string, ttl time.Duration) (string, time.Time, error) {
+	return ks.issue(Claims{UserID: userID, Role: role, ImpersonatedBy: impersonatedBy}, ttl)
+}
+
+func (ks *KeySet) issue(claims Claims, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwtlib.RegisteredClaims{
+		IssuedAt:  jwtlib.NewNumericDate(now),
+		ExpiresAt: jwtlib.NewNumericDate(now.Add(ttl)),
+	}
+
+	state := ks.state.Load()
+	token := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims)
+	if state.currentKID != "" {
+		token.Header["kid"] = state.currentKID
+	}
+	signed, err := token.SignedString(state.keys[state.currentKID])
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, claims.ExpiresAt.Time, nil
+}
+
+func (ks *KeySet) Parse(tokenString string) (*Claims, error) {
+	token, err := jwtlib.ParseWithClaims(tokenString, &Claims{}, ks.keyFunc, jwtlib.WithValidMethods([]string{jwtlib.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+
+	return claims, nil
+}
+
+func (ks *KeySet) keyFunc(token *jwtlib.Token) (interface{}, error) {
+	state := ks.state.Load()
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		// Tokens issued before rotation was enabled carry no kid.
+		kid = state.currentKID
+	}
+	key, ok := state.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return key, nil
+}
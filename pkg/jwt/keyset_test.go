@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeySet_Rotation(t *testing.T) {
+	before, err := NewKeySet("a", map[string]string{"a": "secret-a"})
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+
+	tokenA, err := before.Generate("1", "user", time.Hour)
+	if err != nil {
+		t.Fatalf("generate with a: %v", err)
+	}
+
+	during, err := ParseKeySet("b", `{"a":"secret-a","b":"secret-b"}`)
+	if err != nil {
+		t.Fatalf("parse key set: %v", err)
+	}
+
+	claims, err := during.Parse(tokenA)
+	if err != nil {
+		t.Fatalf("token signed with a should still be accepted: %v", err)
+	}
+	if claims.UserID != "1" || claims.Role != "user" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	tokenB, err := during.Generate("2", "admin", time.Hour)
+	if err != nil {
+		t.Fatalf("generate with b: %v", err)
+	}
+	if _, err := before.Parse(tokenB); !errors.Is(err, ErrUnknownKeyID) {
+		t.Fatalf("expected unknown kid error, got %v", err)
+	}
+
+	after, err := NewKeySet("b", map[string]string{"b": "secret-b"})
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	if _, err := after.Parse(tokenA); err == nil {
+		t.Fatalf("token signed with retired key a should be rejected")
+	}
+	if _, err := after.Parse(tokenB); err != nil {
+		t.Fatalf("token signed with b should be accepted: %v", err)
+	}
+}
+
+func TestKeySet_StaticSecret(t *testing.T) {
+	token, err := Generate("1", "user", "secret", time.Hour)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	rotating, err := NewKeySet("v1", map[string]string{"v1": "secret"})
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	if _, err := rotating.Parse(token); err != nil {
+		t.Fatalf("token without kid should verify with current key: %v", err)
+	}
+	if _, err := Parse(token, "other"); err == nil {
+		t.Fatalf("expected signature error with wrong secret")
+	}
+}
+
+func TestNewKeySet_Invalid(t *testing.T) {
+	if _, err := NewKeySet("missing", map[string]string{"a": "secret"}); err == nil {
+		t.Fatalf("expected error for unknown current kid")
+	}
+	if _, err := NewKeySet("a", map[string]string{"a": ""}); err == nil {
+		t.Fatalf("expected error for empty secret")
+	}
+	if _, err := ParseKeySet("a", "not json"); err == nil {
+		t.Fatalf("expected error for malformed json")
+	}
+}
+
+func TestKeySet_Replace(t *testing.T) {
+	ks := StaticKeySet("old")
+	token, _ := ks.Generate("1", "user", time.Hour)
+
+	next, _ := NewKeySet("v2", map[string]string{"v2": "new"})
+	if ks.Equal(next) {
+		t.Fatal("expected different key sets not to be equal")
+	}
+	ks.Replace(next)
+	if !ks.Equal(next) || ks.CurrentKID() != "v2" {
+		t.Fatalf("expected ks to hold the replacement keys, current kid %q", ks.CurrentKID())
+	}
+	if _, err := ks.Parse(token); err == nil {
+		t.Fatal("expected a token signed with the replaced secret to be rejected")
+	}
+	fresh, _ := ks.Generate("1", "user", time.Hour)
+	if _, err := next.Parse(fresh); err != nil {
+		t.Fatalf("expected new tokens to be signed with the replacement keys: %v", err)
+	}
+}
@@ -3,14 +3,11 @@ package tests
 import (
 	"bytes"
 	"context"
-	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
-	"sync"
 	"testing"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -19,620 +16,11 @@ import (
 	"golang-project/internal/middleware"
 	"golang-project/internal/models"
 	"golang-project/internal/service"
+	"golang-project/internal/testutil"
+	"golang-project/internal/testutil/factory"
 	"golang-project/pkg/jwt"
 )
 
-// In-memory stubs for integration-style HTTP tests.
-
-type memUserRepo struct {
-	mu      sync.Mutex
-	byID    map[int]*models.User
-	byEmail map[string]*models.User
-}
-
-func newMemUserRepo() *memUserRepo {
-	return &memUserRepo{
-		byID:    make(map[int]*models.User),
-		byEmail: make(map[string]*models.User),
-	}
-}
-
-func (r *memUserRepo) Create(ctx context.Context, user *models.User) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	id := len(r.byID) + 1
-	now := time.Now()
-	user.ID = id
-	user.CreatedAt = now
-	user.UpdatedAt = now
-	r.byID[id] = user
-	r.byEmail[user.Email] = user
-	return nil
-}
-
-func (r *memUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if u, ok := r.byEmail[email]; ok {
-		return u, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memUserRepo) GetByID(ctx context.Context, id int) (*models.User, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if u, ok := r.byID[id]; ok {
-		return u, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memUserRepo) List(ctx context.Context, filters models.UserFilters, limit, offset int) ([]models.User, int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	result := make([]models.User, 0, len(r.byID))
-	for _, u := range r.byID {
-		if filters.Search != "" {
-			if !contains(u.Email, filters.Search) && !contains(u.Username, filters.Search) {
-				continue
-			}
-		}
-		if filters.Role != "" && u.Role != filters.Role {
-			continue
-		}
-		result = append(result, *u)
-	}
-	total := len(result)
-	if offset >= total {
-		return []models.User{}, total, nil
-	}
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-	return result[offset:end], total, nil
-}
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0)
-}
-
-func (r *memUserRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for _, u := range r.byID {
-		if u.Username == username {
-			return u, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memUserRepo) UpdateRole(ctx context.Context, id int, role string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if u, ok := r.byID[id]; ok {
-		u.Role = role
-		return nil
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memUserRepo) Update(ctx context.Context, id int, email, username string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	u, ok := r.byID[id]
-	if !ok {
-		return sql.ErrNoRows
-	}
-	if email != "" && email != u.Email {
-		delete(r.byEmail, u.Email)
-		u.Email = email
-		r.byEmail[email] = u
-	}
-	if username != "" {
-		u.Username = username
-	}
-	u.UpdatedAt = time.Now()
-	return nil
-}
-
-func (r *memUserRepo) Delete(ctx context.Context, id int) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	u, ok := r.byID[id]
-	if !ok {
-		return sql.ErrNoRows
-	}
-	delete(r.byID, id)
-	delete(r.byEmail, u.Email)
-	return nil
-}
-
-func (r *memUserRepo) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if u, ok := r.byID[id]; ok {
-		u.PasswordHash = passwordHash
-		u.UpdatedAt = time.Now()
-		return nil
-	}
-	return sql.ErrNoRows
-}
-
-func (r *memUserRepo) Count(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return len(r.byID), nil
-}
-
-func (r *memUserRepo) CountLast7Days(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	count := 0
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
-	for _, u := range r.byID {
-		if u.CreatedAt.After(sevenDaysAgo) {
-			count++
-		}
-	}
-	return count, nil
-}
-
-type memGenreRepo struct {
-	mu   sync.Mutex
-	data map[int]*models.Genre
-}
-
-func newMemGenreRepo() *memGenreRepo {
-	return &memGenreRepo{data: make(map[int]*models.Genre)}
-}
-
-func (r *memGenreRepo) GetAll(ctx context.Context) ([]models.Genre, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	res := make([]models.Genre, 0, len(r.data))
-	for _, g := range r.data {
-		res = append(res, *g)
-	}
-	return res, nil
-}
-
-func (r *memGenreRepo) GetByID(ctx context.Context, id int) (*models.Genre, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if g, ok := r.data[id]; ok {
-		return g, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memGenreRepo) GetByName(ctx context.Context, name string) (*models.Genre, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for _, g := range r.data {
-		if g.Name == name {
-			return g, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memGenreRepo) Create(ctx context.Context, genre *models.Genre) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	id := len(r.data) + 1
-	now := time.Now()
-	genre.ID = id
-	genre.CreatedAt = now
-	r.data[id] = genre
-	return nil
-}
-
-func (r *memGenreRepo) Update(ctx context.Context, genre *models.Genre) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if _, ok := r.data[genre.ID]; !ok {
-		return sql.ErrNoRows
-	}
-	r.data[genre.ID] = genre
-	return nil
-}
-
-func (r *memGenreRepo) Delete(ctx context.Context, id int) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if _, ok := r.data[id]; !ok {
-		return sql.ErrNoRows
-	}
-	delete(r.data, id)
-	return nil
-}
-
-func (r *memGenreRepo) Count(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return len(r.data), nil
-}
-
-type memMovieRepo struct {
-	mu          sync.Mutex
-	movies      map[int]*models.Movie
-	movieGenres map[int][]int
-}
-
-func newMemMovieRepo() *memMovieRepo {
-	return &memMovieRepo{
-		movies:      make(map[int]*models.Movie),
-		movieGenres: make(map[int][]int),
-	}
-}
-
-func (r *memMovieRepo) List(ctx context.Context, filters models.MovieFilters, limit, offset int) ([]models.Movie, int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	all := make([]models.Movie, 0, len(r.movies))
-	for _, m := range r.movies {
-		all = append(all, *m)
-	}
-	total := len(all)
-	if offset >= total {
-		return []models.Movie{}, total, nil
-	}
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-	return all[offset:end], total, nil
-}
-
-func (r *memMovieRepo) GetByID(ctx context.Context, id int) (*models.Movie, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if m, ok := r.movies[id]; ok {
-		return m, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memMovieRepo) Create(ctx context.Context, movie *models.Movie) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	id := len(r.movies) + 1
-	now := time.Now()
-	movie.ID = id
-	movie.CreatedAt = now
-	movie.UpdatedAt = now
-	r.movies[id] = movie
-	return nil
-}
-
-func (r *memMovieRepo) Update(ctx context.Context, movie *models.Movie) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if _, ok := r.movies[movie.ID]; !ok {
-		return sql.ErrNoRows
-	}
-	movie.UpdatedAt = time.Now()
-	r.movies[movie.ID] = movie
-	return nil
-}
-
-func (r *memMovieRepo) Delete(ctx context.Context, id int) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if _, ok := r.movies[id]; !ok {
-		return sql.ErrNoRows
-	}
-	delete(r.movies, id)
-	delete(r.movieGenres, id)
-	return nil
-}
-
-func (r *memMovieRepo) SetGenres(ctx context.Context, movieID int, genreIDs []int) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if _, ok := r.movies[movieID]; !ok {
-		return sql.ErrNoRows
-	}
-	r.movieGenres[movieID] = genreIDs
-	return nil
-}
-
-func (r *memMovieRepo) GetGenresByMovieID(ctx context.Context, movieID int) ([]models.Genre, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	ids := r.movieGenres[movieID]
-	res := make([]models.Genre, 0, len(ids))
-	for _, id := range ids {
-		res = append(res, models.Genre{ID: id, Name: "", CreatedAt: time.Now()})
-	}
-	return res, nil
-}
-
-func (r *memMovieRepo) UpdateAverageRating(ctx context.Context, movieID int) error {
-	return nil
-}
-
-func (r *memMovieRepo) Count(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return len(r.movies), nil
-}
-
-func (r *memMovieRepo) GetAverageRating(ctx context.Context) (float64, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	sum := 0.0
-	count := 0
-	for _, m := range r.movies {
-		if m.AverageRating > 0 {
-			sum += m.AverageRating
-			count++
-		}
-	}
-	if count == 0 {
-		return 0, nil
-	}
-	return sum / float64(count), nil
-}
-
-func (r *memMovieRepo) CountLast7Days(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	count := 0
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
-	for _, m := range r.movies {
-		if m.CreatedAt.After(sevenDaysAgo) {
-			count++
-		}
-	}
-	return count, nil
-}
-
-type memReviewRepo struct {
-	mu      sync.Mutex
-	data    map[int]*models.Review
-	byMovie map[int][]*models.Review
-}
-
-func newMemReviewRepo() *memReviewRepo {
-	return &memReviewRepo{
-		data:    make(map[int]*models.Review),
-		byMovie: make(map[int][]*models.Review),
-	}
-}
-
-func (r *memReviewRepo) GetByID(ctx context.Context, id int) (*models.Review, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if rv, ok := r.data[id]; ok {
-		return rv, nil
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memReviewRepo) GetByMovieAndUser(ctx context.Context, movieID, userID int) (*models.Review, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for _, rv := range r.byMovie[movieID] {
-		if rv.UserID == userID {
-			return rv, nil
-		}
-	}
-	return nil, sql.ErrNoRows
-}
-
-func (r *memReviewRepo) GetByMovieID(ctx context.Context, movieID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	all := r.byMovie[movieID]
-	filtered := make([]*models.Review, 0, len(all))
-	for _, rv := range all {
-		if filters.MinRating > 0 && rv.Rating < filters.MinRating {
-			continue
-		}
-		if filters.MaxRating > 0 && rv.Rating > filters.MaxRating {
-			continue
-		}
-		filtered = append(filtered, rv)
-	}
-	all = filtered
-	total := len(all)
-	if offset >= total {
-		return []models.Review{}, nil
-	}
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-	res := make([]models.Review, 0, end-offset)
-	for _, rv := range all[offset:end] {
-		res = append(res, *rv)
-	}
-	return res, nil
-}
-
-func (r *memReviewRepo) GetByUserID(ctx context.Context, userID int, filters models.ReviewFilters, limit, offset int) ([]models.Review, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	all := make([]*models.Review, 0, len(r.data))
-	for _, rv := range r.data {
-		if rv.UserID == userID {
-			if filters.MinRating > 0 && rv.Rating < filters.MinRating {
-				continue
-			}
-			if filters.MaxRating > 0 && rv.Rating > filters.MaxRating {
-				continue
-			}
-			all = append(all, rv)
-		}
-	}
-	total := len(all)
-	if offset >= total {
-		return []models.Review{}, nil
-	}
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-	res := make([]models.Review, 0, end-offset)
-	for _, rv := range all[offset:end] {
-		res = append(res, *rv)
-	}
-	return res, nil
-}
-
-func (r *memReviewRepo) Create(ctx context.Context, review *models.Review) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	id := len(r.data) + 1
-	now := time.Now()
-	review.ID = id
-	review.CreatedAt = now
-	review.UpdatedAt = now
-	r.data[id] = review
-	r.byMovie[review.MovieID] = append(r.byMovie[review.MovieID], review)
-	return nil
-}
-
-func (r *memReviewRepo) Update(ctx context.Context, review *models.Review) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if _, ok := r.data[review.ID]; !ok {
-		return sql.ErrNoRows
-	}
-	review.UpdatedAt = time.Now()
-	r.data[review.ID] = review
-	for i, rv := range r.byMovie[review.MovieID] {
-		if rv.ID == review.ID {
-			r.byMovie[review.MovieID][i] = review
-			break
-		}
-	}
-	return nil
-}
-
-func (r *memReviewRepo) Delete(ctx context.Context, id int) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	review, ok := r.data[id]
-	if !ok {
-		return sql.ErrNoRows
-	}
-	delete(r.data, id)
-	arr := r.byMovie[review.MovieID]
-	for i, rv := range arr {
-		if rv.ID == id {
-			arr = append(arr[:i], arr[i+1:]...)
-			break
-		}
-	}
-	r.byMovie[review.MovieID] = arr
-	return nil
-}
-
-func (r *memReviewRepo) CountByUserID(ctx context.Context, userID int) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	count := 0
-	for _, rv := range r.data {
-		if rv.UserID == userID {
-			count++
-		}
-	}
-	return count, nil
-}
-
-func (r *memReviewRepo) Count(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return len(r.data), nil
-}
-
-func (r *memReviewRepo) CountLast7Days(ctx context.Context) (int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	count := 0
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
-	for _, rv := range r.data {
-		if rv.CreatedAt.After(sevenDaysAgo) {
-			count++
-		}
-	}
-	return count, nil
-}
-
-func (r *memReviewRepo) GetAverageRatingByUserID(ctx context.Context, userID int) (float64, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	sum := 0
-	count := 0
-	for _, rv := range r.data {
-		if rv.UserID == userID {
-			sum += rv.Rating
-			count++
-		}
-	}
-	if count == 0 {
-		return 0, nil
-	}
-	return float64(sum) / float64(count), nil
-}
-
-func (r *memReviewRepo) GetFavoriteGenreByUserID(ctx context.Context, userID int) (*models.Genre, error) {
-	return nil, nil
-}
-
-type memAuditRepo struct {
-	mu   sync.Mutex
-	logs []models.AuditLog
-}
-
-func newMemAuditRepo() *memAuditRepo {
-	return &memAuditRepo{logs: make([]models.AuditLog, 0)}
-}
-
-func (r *memAuditRepo) Insert(ctx context.Context, log *models.AuditLog) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	log.ID = len(r.logs) + 1
-	log.CreatedAt = time.Now()
-	r.logs = append(r.logs, *log)
-	return nil
-}
-
-func (r *memAuditRepo) List(ctx context.Context, filters models.AuditLogFilters, limit, offset int) ([]models.AuditLog, int, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	filtered := make([]models.AuditLog, 0)
-	for _, log := range r.logs {
-		if filters.Event != "" && log.Event != filters.Event {
-			continue
-		}
-		if filters.UserID != nil && (log.UserID == nil || *log.UserID != *filters.UserID) {
-			continue
-		}
-		if filters.FromDate != nil && log.CreatedAt.Before(*filters.FromDate) {
-			continue
-		}
-		if filters.ToDate != nil && log.CreatedAt.After(*filters.ToDate) {
-			continue
-		}
-		filtered = append(filtered, log)
-	}
-	total := len(filtered)
-	if offset >= total {
-		return []models.AuditLog{}, total, nil
-	}
-	end := offset + limit
-	if end > total {
-		end = total
-	}
-	return filtered[offset:end], total, nil
-}
-
 type jwtPasswordHasher struct{}
 
 func (h *jwtPasswordHasher) HashPassword(password string) (string, error) {
@@ -644,33 +32,39 @@ func (h *jwtPasswordHasher) CheckPassword(hash, password string) error {
 }
 
 func buildTestRouter(t *testing.T) *gin.Engine {
+	router, _ := buildTestApp(t)
+	return router
+}
+
+// buildTestApp also returns the repositories behind the router, for tests
+// that seed data directly instead of through the API.
+func buildTestApp(t *testing.T) (*gin.Engine, factory.Repos) {
 	t.Helper()
 	gin.SetMode(gin.TestMode)
 
 	validator := validator.New()
 	secret := "test-secret"
 
-	userRepo := newMemUserRepo()
-	genreRepo := newMemGenreRepo()
-	movieRepo := newMemMovieRepo()
-	reviewRepo := newMemReviewRepo()
-	auditRepo := newMemAuditRepo()
+	repos := factory.NewRepos()
+	userRepo, genreRepo, movieRepo, reviewRepo := repos.Users, repos.Genres, repos.Movies, repos.Reviews
+	auditRepo := testutil.NewMemAuditRepo()
 
-	authSvc := service.NewAuthService(userRepo, validator, secret)
-	genreSvc := service.NewGenreService(genreRepo, validator)
-	movieSvc := service.NewMovieService(movieRepo, genreRepo, validator)
+	audit := service.WithAuditWriter(auditRepo)
+	authSvc := service.NewAuthService(userRepo, validator, jwt.StaticKeySet(secret), audit)
+	genreSvc := service.NewGenreService(genreRepo, validator, audit)
+	movieSvc := service.NewMovieService(movieRepo, genreRepo, validator, audit)
 	reviewSvc := service.NewReviewService(reviewRepo, movieRepo, validator, nil)
 	passwordHasher := &jwtPasswordHasher{}
-	userSvc := service.NewUserService(userRepo, reviewRepo, validator, passwordHasher)
+	userSvc := service.NewUserService(userRepo, reviewRepo, validator, passwordHasher, audit)
 
 	authH := handler.NewAuthHandler(authSvc)
 	genreH := handler.NewGenreHandler(genreSvc)
 	movieH := handler.NewMovieHandler(movieSvc)
 	reviewH := handler.NewReviewHandler(reviewSvc)
-	userH := handler.NewUserHandler(userSvc, reviewSvc, userRepo, movieRepo, reviewRepo, genreRepo, auditRepo)
+	userH := handler.NewUserHandler(userSvc, reviewSvc, userRepo, movieRepo, reviewRepo, genreRepo, auditRepo, nil)
 
 	router := gin.New()
-	router.Use(middleware.Logger(), gin.Recovery(), middleware.RequestID(), middleware.CORS(), middleware.BodyLimit(1<<20))
+	router.Use(middleware.Logger(), gin.Recovery(), middleware.RequestID(), middleware.CORS([]string{"*"}), middleware.BodyLimit(1<<20))
 
 	api := router.Group("/api/v1")
 
@@ -685,13 +79,16 @@ func buildTestRouter(t *testing.T) *gin.Engine {
 	api.GET("/movies/:id", movieH.Get)
 	api.GET("/movies/:id/reviews", reviewH.ListByMovie)
 	api.GET("/users/:id/reviews", userH.UserReviews)
+	api.GET("/users/:id/profile", userH.GetPublicProfile)
 
-	admin := api.Group("/", middleware.AuthMiddleware(secret), middleware.RequireRoles("admin"))
+	admin := api.Group("/", middleware.AuthMiddleware(jwt.StaticKeySet(secret)), middleware.RequireRoles("admin"))
 	admin.GET("/users", userH.ListUsers)
 	admin.GET("/users/:id", userH.GetUser)
 	admin.PUT("/users/:id", userH.UpdateUser)
 	admin.PUT("/users/:id/role", userH.UpdateRole)
 	admin.DELETE("/users/:id", userH.DeleteUser)
+	admin.GET("/audit-logs", userH.ListAuditLogs)
+	admin.GET("/stats/movies-by-decade", userH.GetMoviesByDecade)
 	admin.POST("/genres", genreH.Create)
 	admin.PUT("/genres/:id", genreH.Update)
 	admin.DELETE("/genres/:id", genreH.Delete)
@@ -699,26 +96,16 @@ func buildTestRouter(t *testing.T) *gin.Engine {
 	admin.PUT("/movies/:id", movieH.Update)
 	admin.DELETE("/movies/:id", movieH.Delete)
 
-	protected := api.Group("/", middleware.AuthMiddleware(secret))
+	protected := api.Group("/", middleware.AuthMiddleware(jwt.StaticKeySet(secret)))
 	protected.GET("/me", userH.Me)
 	protected.GET("/me/reviews", userH.MyReviews)
 	protected.POST("/movies/:id/reviews", reviewH.Create)
 	protected.PUT("/reviews/:id", reviewH.Update)
 	protected.DELETE("/reviews/:id", reviewH.Delete)
 
-	// seed admin user
-	hash, err := jwt.HashPassword("adminpass")
-	if err != nil {
-		t.Fatalf("hash admin password: %v", err)
-	}
-	_ = userRepo.Create(context.Background(), &models.User{
-		Email:        "admin@example.com",
-		Username:     "admin",
-		PasswordHash: hash,
-		Role:         "admin",
-	})
+	userRepo.Add(factory.NewUser(factory.Username("admin"), factory.Role("admin"), factory.Password("adminpass")))
 
-	return router
+	return router, repos
 }
 
 func TestIntegration_Flow(t *testing.T) {
@@ -780,7 +167,8 @@ func TestIntegration_ReviewUpdate_ForbiddenForOtherUser(t *testing.T) {
 
 	reviewID := createReview(t, router, user1Token, movieID, 9, "Title", "Body")
 
-	body, _ := json.Marshal(models.UpdateReviewRequest{Rating: 5, Title: "Other user", Content: "Should be forbidden"})
+	rating, title, content := 5, "Other user", "Should be forbidden"
+	body, _ := json.Marshal(models.UpdateReviewRequest{Rating: &rating, Title: &title, Content: &content})
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/reviews/"+reviewID, bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+user2Token)
@@ -918,6 +306,53 @@ func TestIntegration_UserReviews(t *testing.T) {
 	}
 }
 
+func TestIntegration_PublicProfile(t *testing.T) {
+	router, repos := buildTestApp(t)
+
+	adminToken := login(t, router, "admin@example.com", "adminpass")
+	genreID := createGenre(t, router, adminToken, "Drama")
+	movieID := createMovie(t, router, adminToken, "Inception", genreID)
+
+	register(t, router, "user@example.com", "user", "password123")
+	userToken := login(t, router, "user@example.com", "password123")
+	createReview(t, router, userToken, movieID, 9, "Great", "Nice")
+
+	user, err := repos.Users.GetByEmail(context.Background(), "user@example.com")
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+strconv.Itoa(user.ID)+"/profile", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get()
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /users/:id/profile expected 200, got %d body %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("parse profile err=%v", err)
+	}
+	for _, private := range []string{"email", "password_hash", "role"} {
+		if _, ok := resp[private]; ok {
+			t.Fatalf("profile must not include %q: %s", private, w.Body.String())
+		}
+	}
+	if resp["username"] != "user" || resp["review_count"] != float64(1) || resp["average_rating"] != float64(9) {
+		t.Fatalf("unexpected profile: %s", w.Body.String())
+	}
+
+	if err := repos.Users.SoftDelete(user.ID); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if w := get(); w.Code != http.StatusNotFound {
+		t.Fatalf("profile of a deleted user expected 404, got %d body %s", w.Code, w.Body.String())
+	}
+}
+
 func TestIntegration_AdminUsers(t *testing.T) {
 	router := buildTestRouter(t)
 
@@ -986,6 +421,27 @@ func TestIntegration_AdminUpdateRole(t *testing.T) {
 	if w.Code != http.StatusNoContent {
 		t.Fatalf("admin PUT /users/:id/role expected 204, got %d body %s", w.Code, w.Body.String())
 	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/audit-logs?actor_id=1&event=user_role_updated", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin GET /audit-logs expected 200, got %d", w.Code)
+	}
+	var logsResp struct {
+		Data  []models.AuditLog `json:"data"`
+		Total int               `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &logsResp); err != nil {
+		t.Fatalf("parse audit logs: %v", err)
+	}
+	if logsResp.Total != 1 || logsResp.Data[0].ActorID == nil || *logsResp.Data[0].ActorID != 1 {
+		t.Fatalf("expected one role update by admin, got %+v", logsResp)
+	}
+	if logsResp.Data[0].UserID == nil || strconv.Itoa(*logsResp.Data[0].UserID) != userID {
+		t.Fatalf("expected audit entry for user %s, got %+v", userID, logsResp.Data[0])
+	}
 }
 
 func TestIntegration_AdminGenresUpdateDelete(t *testing.T) {
@@ -1049,16 +505,9 @@ func TestIntegration_AdminMoviesUpdateDelete(t *testing.T) {
 }
 
 func TestIntegration_MovieReviewsList(t *testing.T) {
-	router := buildTestRouter(t)
-
-	adminToken := login(t, router, "admin@example.com", "adminpass")
-	genreID := createGenre(t, router, adminToken, "Drama")
-	movieID := createMovie(t, router, adminToken, "Inception", genreID)
-
-	register(t, router, "user@example.com", "user", "password123")
-	userToken := login(t, router, "user@example.com", "password123")
-
-	createReview(t, router, userToken, movieID, 9, "Great", "Nice")
+	router, repos := buildTestApp(t)
+	cat := factory.SeedCatalog(repos, 2, 5)
+	movieID := strconv.Itoa(cat.Movies[0].ID)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/"+movieID+"/reviews", nil)
 	w := httptest.NewRecorder()
@@ -1067,12 +516,14 @@ func TestIntegration_MovieReviewsList(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("GET /movies/:id/reviews expected 200, got %d body %s", w.Code, w.Body.String())
 	}
-	var resp map[string]interface{}
+	var resp struct {
+		Data []models.Review `json:"data"`
+	}
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("parse /movies/:id/reviews response err=%v", err)
 	}
-	if resp["data"] == nil {
-		t.Fatalf("/movies/:id/reviews response should contain 'data'")
+	if len(resp.Data) != cat.Movies[0].ReviewCount {
+		t.Fatalf("expected %d reviews, got %d", cat.Movies[0].ReviewCount, len(resp.Data))
 	}
 }
 
@@ -1176,6 +627,48 @@ func TestIntegration_AdminDeleteSelf_Forbidden(t *testing.T) {
 	}
 }
 
+func TestIntegration_MoviesByDecade(t *testing.T) {
+	router, repos := buildTestApp(t)
+	adminToken := login(t, router, "admin@example.com", "adminpass")
+
+	for _, year := range []int{1994, 1999, 2008, 2021, 2020} {
+		repos.Movies.Add(factory.NewMovie(factory.Year(year)))
+	}
+
+	get := func(path string) []models.DecadeCount {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s expected 200, got %d", path, w.Code)
+		}
+		var resp struct {
+			Data []models.DecadeCount `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		return resp.Data
+	}
+
+	got := get("/api/v1/stats/movies-by-decade")
+	want := []models.DecadeCount{{Decade: 1990, Count: 2}, {Decade: 2000, Count: 1}, {Decade: 2020, Count: 2}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	withEmpty := get("/api/v1/stats/movies-by-decade?include_empty=true")
+	if len(withEmpty) != 4 || withEmpty[2] != (models.DecadeCount{Decade: 2010, Count: 0}) {
+		t.Fatalf("expected empty 2010s to be included, got %v", withEmpty)
+	}
+}
+
 func login(t *testing.T, r *gin.Engine, email, password string) string {
 	body, _ := json.Marshal(models.LoginRequest{Email: email, Password: password})
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
@@ -1262,7 +755,7 @@ func createReview(t *testing.T, r *gin.Engine, token, movieID string, rating int
 }
 
 func updateReview(t *testing.T, r *gin.Engine, token, reviewID string, rating int, title, content string) {
-	body, _ := json.Marshal(models.UpdateReviewRequest{Rating: rating, Title: title, Content: content})
+	body, _ := json.Marshal(models.UpdateReviewRequest{Rating: &rating, Title: &title, Content: &content})
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/reviews/"+reviewID, bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)